@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// voiceAlias maps a logical voice name like "narrator" to a provider voice
+type voiceAlias struct {
+	Provider string `json:"provider" yaml:"provider"`
+	Voice    string `json:"voice" yaml:"voice"`
+	Model    string `json:"model,omitempty" yaml:"model"`
+}
+
+// Aliases loaded from MCP_SAY_VOICE_ALIASES, keyed by lower case name
+var voiceAliases map[string]voiceAlias
+
+// ttsHandlers are the provider tools a voice alias can switch a call to
+var ttsHandlers = map[string]ToolHandlerFunc{
+	say.ProviderOpenAI:     handleOpenAITTS,
+	say.ProviderGoogle:     handleGoogleTTS,
+	say.ProviderElevenLabs: handleElevenLabsTTS,
+	say.ProviderSay:        handleSayTTS,
+}
+
+// parseVoiceAliases parses a JSON object of alias name to {provider, voice, model}
+func parseVoiceAliases(value string) (map[string]voiceAlias, error) {
+	var raw map[string]voiceAlias
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return nil, fmt.Errorf("voice aliases must be a JSON object of name to {provider, voice, model}: %v", err)
+	}
+	aliases := make(map[string]voiceAlias, len(raw))
+	for name, alias := range raw {
+		if err := validateVoiceAlias(alias); err != nil {
+			return nil, fmt.Errorf("voice alias %q: %v", name, err)
+		}
+		key := strings.ToLower(name)
+		if _, ok := aliases[key]; ok || key == "" {
+			return nil, fmt.Errorf("voice alias %q is empty or defined twice", name)
+		}
+		aliases[key] = alias
+	}
+	return aliases, nil
+}
+
+func validateVoiceAlias(alias voiceAlias) error {
+	if alias.Voice == "" {
+		return fmt.Errorf("voice must not be empty")
+	}
+	switch alias.Provider {
+	case say.ProviderOpenAI, say.ProviderElevenLabs:
+	case say.ProviderGoogle:
+		if _, err := say.ParseGoogleVoice(alias.Voice); err != nil {
+			return err
+		}
+	case say.ProviderSay:
+		if !say.ValidSayVoice(alias.Voice) {
+			return fmt.Errorf("voice contains invalid characters: %s", alias.Voice)
+		}
+		// say_tts is only registered on macOS, elsewhere the alias would lead nowhere
+		if runtime.GOOS != "darwin" {
+			return fmt.Errorf("provider say is only available on macOS")
+		}
+	default:
+		return fmt.Errorf("unknown provider %q (use google, openai, elevenlabs or say)", alias.Provider)
+	}
+	return nil
+}
+
+// lookupVoiceAlias returns the alias a voice argument names, if any
+func lookupVoiceAlias(arguments map[string]any) (string, voiceAlias, bool) {
+	name, _ := arguments["voice"].(string)
+	alias, ok := voiceAliases[strings.ToLower(name)]
+	return name, alias, ok
+}
+
+// apply returns a copy of arguments with the alias' voice and model. The call's model is
+// kept when the provider stays the same and the alias has none, it means nothing to another provider.
+func (a voiceAlias) apply(arguments map[string]any, provider string) map[string]any {
+	resolved := maps.Clone(arguments)
+	resolved["voice"] = a.Voice
+	if a.Model != "" {
+		resolved["model"] = a.Model
+	} else if a.Provider != provider {
+		delete(resolved, "model")
+	}
+	return resolved
+}
+
+// WithVoiceAlias resolves a voice argument naming an alias before the handler runs.
+// provider is the tool's own provider, an alias for another one hands the call to that
+// provider's tool. Tools that take a provider argument pass "" to have it rewritten instead.
+func WithVoiceAlias(provider string, handler ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		name, alias, ok := lookupVoiceAlias(arguments)
+		if !ok {
+			return handler(ctx, request)
+		}
+		current := provider
+		if current == "" {
+			current, _ = arguments["provider"].(string)
+		}
+		resolved := alias.apply(arguments, current)
+		log.Info("Resolved voice alias", "alias", name, "provider", alias.Provider, "voice", alias.Voice, "model", alias.Model)
+		request.Params.Arguments = resolved
+		switch {
+		case provider == "":
+			resolved["provider"] = alias.Provider
+		case alias.Provider != provider:
+			log.Info("Voice alias switches provider", "alias", name, "from", provider, "to", alias.Provider)
+			return ttsHandlers[alias.Provider](ctx, request)
+		}
+		return handler(ctx, request)
+	}
+}
+
+// voiceAliasNames lists the configured aliases for tool descriptions
+func voiceAliasNames() string {
+	return strings.Join(slices.Sorted(maps.Keys(voiceAliases)), ", ")
+}
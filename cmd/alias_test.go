@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func useVoiceAliases(t *testing.T, value string) {
+	t.Helper()
+	aliases, err := parseVoiceAliases(value)
+	require.NoError(t, err)
+	prev := voiceAliases
+	voiceAliases = aliases
+	t.Cleanup(func() { voiceAliases = prev })
+}
+
+func TestParseVoiceAliases(t *testing.T) {
+	aliases, err := parseVoiceAliases(`{
+		"Narrator": {"provider": "google", "voice": "Charon", "model": "gemini-2.5-pro-preview-tts"},
+		"assistant": {"provider": "openai", "voice": "nova"}
+	}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]voiceAlias{
+		"narrator":  {Provider: "google", Voice: "Charon", Model: "gemini-2.5-pro-preview-tts"},
+		"assistant": {Provider: "openai", Voice: "nova"},
+	}, aliases)
+
+	for value, errMsg := range map[string]string{
+		`[]`: "must be a JSON object",
+		`{"a": {"provider": "polly", "voice": "Joanna"}}`:                                             `unknown provider "polly"`,
+		`{"a": {"provider": "openai"}}`:                                                               "voice must not be empty",
+		`{"a": {"provider": "say", "voice": "Alex; rm"}}`:                                             "invalid characters",
+		`{"a": {"provider": "openai", "voice": "nova"}, "A": {"provider": "openai", "voice": "ash"}}`: "defined twice",
+	} {
+		_, err := parseVoiceAliases(value)
+		require.Error(t, err, value)
+		assert.Contains(t, err.Error(), errMsg, value)
+	}
+
+	_, err = parseVoiceAliases(`{"mac": {"provider": "say", "voice": "Alex"}}`)
+	if runtime.GOOS == "darwin" {
+		assert.NoError(t, err)
+	} else {
+		assert.ErrorContains(t, err, "provider say is only available on macOS")
+	}
+}
+
+func TestWithVoiceAlias(t *testing.T) {
+	useVoiceAliases(t, `{
+		"narrator": {"provider": "google", "voice": "Charon", "model": "gemini-2.5-pro-preview-tts"},
+		"assistant": {"provider": "openai", "voice": "nova"}
+	}`)
+
+	var got map[string]any
+	record := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		got = request.GetArguments()
+		return mcp.NewToolResultText("ok"), nil
+	}
+	call := func(handler ToolHandlerFunc, arguments map[string]any) {
+		got = nil
+		_, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: arguments}})
+		require.NoError(t, err)
+	}
+
+	t.Run("same provider", func(t *testing.T) {
+		call(WithVoiceAlias("openai", record), map[string]any{"text": "Hi", "voice": "Assistant", "model": "tts-1"})
+		assert.Equal(t, map[string]any{"text": "Hi", "voice": "nova", "model": "tts-1"}, got)
+	})
+
+	t.Run("switches provider", func(t *testing.T) {
+		prev := ttsHandlers["google"]
+		ttsHandlers["google"] = record
+		t.Cleanup(func() { ttsHandlers["google"] = prev })
+
+		called := false
+		openai := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			called = true
+			return mcp.NewToolResultText("ok"), nil
+		}
+		call(WithVoiceAlias("openai", openai), map[string]any{"text": "Hi", "voice": "narrator", "model": "tts-1", "speed": 1.5})
+		assert.False(t, called)
+		assert.Equal(t, map[string]any{"text": "Hi", "voice": "Charon", "model": "gemini-2.5-pro-preview-tts", "speed": 1.5}, got)
+	})
+
+	t.Run("provider argument", func(t *testing.T) {
+		call(WithVoiceAlias("", record), map[string]any{"ssml": "<speak>Hi</speak>", "provider": "google", "voice": "assistant", "model": "gemini-2.5-pro-preview-tts"})
+		assert.Equal(t, map[string]any{"ssml": "<speak>Hi</speak>", "provider": "openai", "voice": "nova"}, got)
+	})
+
+	t.Run("not an alias", func(t *testing.T) {
+		arguments := map[string]any{"text": "Hi", "voice": "alloy"}
+		call(WithVoiceAlias("openai", record), arguments)
+		assert.Equal(t, arguments, got)
+	})
+}
+
+func TestVoiceAliasSegments(t *testing.T) {
+	useVoiceAliases(t, `{"narrator": {"provider": "google", "voice": "Charon", "model": "gemini-2.5-pro-preview-tts"}}`)
+
+	segments, err := parseSequenceSegments([]any{
+		map[string]any{"text": "Once upon a time.", "provider": "openai", "voice": "narrator"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []sequenceSegment{{Text: "Once upon a time.", Provider: "google", Voice: "Charon", Model: "gemini-2.5-pro-preview-tts"}}, segments)
+
+	items, err := parseBatchItems([]any{map[string]any{"id": "intro", "text": "Hello", "voice": "narrator"}}, "google")
+	require.NoError(t, err)
+	assert.Equal(t, "Charon", items[0].Voice)
+
+	_, err = parseBatchItems([]any{map[string]any{"id": "intro", "text": "Hello", "voice": "narrator"}}, "openai")
+	assert.ErrorContains(t, err, `voice alias "narrator" is a google voice, not openai`)
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// audioHandler serves the audio of utterances in the history, so a browser or a
+// player on another machine can fetch clips too large to return inline
+func audioHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /audio/latest", func(w http.ResponseWriter, r *http.Request) {
+		u, ok := history.latest()
+		if !ok {
+			http.Error(w, "no audio in the history yet", http.StatusNotFound)
+			return
+		}
+		serveUtterance(w, r, u)
+	})
+	mux.HandleFunc("GET /audio/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		u, ok := history.get(id)
+		if !ok {
+			http.Error(w, fmt.Sprintf("no utterance with id %q in the history", id), http.StatusNotFound)
+			return
+		}
+		if u.audio == nil {
+			http.Error(w, fmt.Sprintf("utterance %s has no audio, its stream was not played to the end", id), http.StatusNotFound)
+			return
+		}
+		serveUtterance(w, r, u)
+	})
+	return mux
+}
+
+// serveUtterance writes an utterance's audio as a WAV or MP3 file, with range support for seeking
+func serveUtterance(w http.ResponseWriter, r *http.Request, u *utterance) {
+	log.Debug("Serving utterance audio", "id", u.ID, "remote", r.RemoteAddr)
+	name := batchFileName(u.ID, u.audio)
+	w.Header().Set("Content-Type", u.audio.MIMEType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", name))
+	w.Header().Set("X-Utterance-Id", u.ID)
+	http.ServeContent(w, r, name, u.Time, bytes.NewReader(u.audio.Encoded()))
+}
+
+// requireToken rejects requests without an "Authorization: Bearer <token>" header with 401
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			log.Warn("Rejected unauthenticated request", "path", r.URL.Path, "remote", r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp-say"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// listenAddr returns the address to listen on. Without a token only loopback addresses
+// are allowed, and an address without a host binds to localhost instead of every interface.
+func listenAddr(addr, token string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid audio server address %q: %v", addr, err)
+	}
+	if token != "" {
+		return addr, nil
+	}
+	if host == "" {
+		return net.JoinHostPort("127.0.0.1", port), nil
+	}
+	if host == "localhost" {
+		return addr, nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return addr, nil
+	}
+	return "", fmt.Errorf("audio server address %q is reachable from other machines, set MCP_SAY_AUTH_TOKEN to require a token or bind to a loopback address like 127.0.0.1:8765", addr)
+}
+
+// startAudioServer serves the history's audio over HTTP on addr until the returned server
+// is closed. A non-empty token is required as a bearer token on every request.
+func startAudioServer(addr, token string) (*http.Server, error) {
+	addr, err := listenAddr(addr, token)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start audio server: %v", err)
+	}
+	handler := audioHandler()
+	if token != "" {
+		handler = requireToken(token, handler)
+	}
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("Audio server failed", "error", err)
+		}
+	}()
+	log.Info("Serving synthesized audio", "url", fmt.Sprintf("http://%s/audio/latest", listener.Addr()), "auth", token != "")
+	return srv, nil
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudioHandler(t *testing.T) {
+	useHistory(t, 10)
+	server := httptest.NewServer(audioHandler())
+	defer server.Close()
+
+	get := func(path string, header ...string) (*http.Response, []byte) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		if len(header) == 2 {
+			req.Header.Set(header[0], header[1])
+		}
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res, body
+	}
+
+	res, _ := get("/audio/latest")
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	pcm := pcmAudio(generateTestAudio(24000, 0.1, 440.0))
+	recordUtterance(context.Background(), say.ProviderGoogle, say.Options{Text: "First"}, pcm)
+	recordUtterance(context.Background(), say.ProviderOpenAI, say.Options{Text: "Second"}, &say.Audio{Data: []byte("ID3 mp3"), Encoding: say.EncodingMP3})
+	recordUtterance(context.Background(), say.ProviderOpenAI, say.Options{Text: "Cancelled"}, nil)
+	entries := history.recent(3)
+
+	// The cancelled stream has no audio, so latest skips it
+	res, body := get("/audio/latest")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "audio/mpeg", res.Header.Get("Content-Type"))
+	assert.Equal(t, entries[1].ID, res.Header.Get("X-Utterance-Id"))
+	assert.Equal(t, "ID3 mp3", string(body))
+
+	res, body = get("/audio/" + entries[2].ID)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "audio/wav", res.Header.Get("Content-Type"))
+	assert.Equal(t, pcm.Encoded(), body)
+
+	res, body = get("/audio/"+entries[2].ID, "Range", "bytes=0-3")
+	assert.Equal(t, http.StatusPartialContent, res.StatusCode)
+	assert.Equal(t, "RIFF", string(body))
+
+	res, _ = get("/audio/" + entries[0].ID)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	res, _ = get("/audio/missing")
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestListenAddr(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1:8765", "localhost:8765", "[::1]:8765"} {
+		got, err := listenAddr(addr, "")
+		assert.NoError(t, err, addr)
+		assert.Equal(t, addr, got)
+	}
+	// Without a token, an address without a host binds to localhost only
+	got, err := listenAddr(":8765", "")
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:8765", got)
+	for _, addr := range []string{"0.0.0.0:8765", "192.168.1.10:8765", "8765"} {
+		_, err := listenAddr(addr, "")
+		assert.Error(t, err, addr)
+	}
+
+	got, err = listenAddr(":8765", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, ":8765", got)
+	got, err = listenAddr("0.0.0.0:8765", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.0.0:8765", got)
+}
+
+func TestRequireToken(t *testing.T) {
+	server := httptest.NewServer(requireToken("secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})))
+	defer server.Close()
+
+	for _, tt := range []struct {
+		header string
+		status int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"secret", http.StatusUnauthorized},
+		{"Bearer secret", http.StatusOK},
+	} {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/audio/latest", nil)
+		require.NoError(t, err)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, tt.status, res.StatusCode, tt.header)
+		if tt.status == http.StatusUnauthorized {
+			assert.Contains(t, res.Header.Get("WWW-Authenticate"), "Bearer")
+		}
+	}
+}
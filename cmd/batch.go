@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// Most items accepted by batch_synthesize
+	maxBatchItems = 500
+	// Default and largest number of items synthesized at once
+	defaultBatchConcurrency = 4
+	maxBatchConcurrency     = 8
+)
+
+// validBatchID matches ids that are safe to use as file names
+var validBatchID = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// batchItem is one entry of the batch_synthesize `items` argument
+type batchItem struct {
+	ID    string
+	Text  string
+	Voice string
+}
+
+// parseBatchItems validates the raw `items` tool argument
+func parseBatchItems(raw any, provider string) ([]batchItem, error) {
+	entries, ok := raw.([]any)
+	if !ok {
+		return nil, errors.New("items must be an array of {id, text, voice} objects")
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("items must contain at least one item")
+	}
+	if len(entries) > maxBatchItems {
+		return nil, fmt.Errorf("too many items (%d, max %d)", len(entries), maxBatchItems)
+	}
+
+	items := make([]batchItem, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for i, entry := range entries {
+		obj, ok := entry.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("items[%d] must be an object", i)
+		}
+		id, _ := obj["id"].(string)
+		text, _ := obj["text"].(string)
+		if !validBatchID.MatchString(id) || strings.Contains(id, "..") {
+			return nil, fmt.Errorf("items[%d] id %q must be 1-128 letters, digits, '.', '_' or '-'", i, id)
+		}
+		// Ids are file names, so they must be unique even on case-insensitive file systems
+		key := strings.ToLower(id)
+		if seen[key] {
+			return nil, fmt.Errorf("items[%d] id %q is not unique", i, id)
+		}
+		seen[key] = true
+		if strings.TrimSpace(text) == "" {
+			return nil, fmt.Errorf("items[%d] must have non-empty text", i)
+		}
+		if len([]rune(text)) > maxTextLength {
+			return nil, fmt.Errorf("items[%d] text too long (max %d characters)", i, maxTextLength)
+		}
+		voice := providerSetting(obj, provider, "voice", "")
+		if name, alias, ok := lookupVoiceAlias(obj); ok {
+			// Every item is synthesized with the batch provider, an alias can't switch it
+			if alias.Provider != provider {
+				return nil, fmt.Errorf("items[%d] voice alias %q is a %s voice, not %s", i, name, alias.Provider, provider)
+			}
+			voice = alias.Voice
+		}
+		items = append(items, batchItem{ID: id, Text: text, Voice: voice})
+	}
+	return items, nil
+}
+
+// batchFileName returns the file an item's audio is written to, named after its id
+func batchFileName(id string, audio *say.Audio) string {
+	if audio.Encoding == say.EncodingMP3 {
+		return id + ".mp3"
+	}
+	return id + ".wav"
+}
+
+// batchOutput describes the file written for a batch item
+type batchOutput struct {
+	File     string
+	Bytes    int
+	Duration time.Duration
+}
+
+// synthesizeBatch synthesizes every item with at most concurrency requests in flight
+// and writes each to dir. It returns the written files and per-item errors.
+func synthesizeBatch(ctx context.Context, provider say.Provider, model string, items []batchItem, dir string, concurrency int) ([]batchOutput, []error) {
+	files := make([]batchOutput, len(items))
+	errs := make([]error, len(items))
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			audio, err := provider.Synthesize(ctx, say.Options{Text: item.Text, Voice: item.Voice, Model: model})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			name := batchFileName(item.ID, audio)
+			data := audio.Encoded()
+			if err := writeFileAtomic(ctx, filepath.Join(dir, name), bytes.NewReader(data)); err != nil {
+				errs[i] = fmt.Errorf("failed to write %s: %v", name, err)
+				return
+			}
+			files[i] = batchOutput{File: name, Bytes: len(data), Duration: audioDuration(audio)}
+		}()
+	}
+	wg.Wait()
+	return files, errs
+}
+
+// batchManifest is the JSON written to manifest_file, describing every item of a batch
+type batchManifest struct {
+	Provider string              `json:"provider"`
+	Items    []batchManifestItem `json:"items"`
+}
+
+type batchManifestItem struct {
+	ID       string `json:"id"`
+	Text     string `json:"text"`
+	Provider string `json:"provider"`
+	Voice    string `json:"voice,omitempty"`
+	// File is the absolute path of the audio, empty when the item failed
+	File       string `json:"file,omitempty"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+	Bytes      int    `json:"bytes,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// manifestArgument reads the optional manifest_file argument, a JSON file to write in an existing directory
+func manifestArgument(arguments map[string]any) (string, error) {
+	raw, ok := arguments["manifest_file"]
+	if !ok || raw == nil {
+		return "", nil
+	}
+	path, ok := raw.(string)
+	if !ok {
+		return "", errors.New("manifest_file must be a string")
+	}
+	if path == "" {
+		return "", nil
+	}
+	if info, err := os.Stat(filepath.Dir(path)); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("manifest_file directory does not exist: %s", filepath.Dir(path))
+	}
+	return path, nil
+}
+
+// writeBatchManifest atomically writes the manifest of a finished batch to path, in item order
+func writeBatchManifest(ctx context.Context, path, provider, dir string, items []batchItem, files []batchOutput, errs []error) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	manifest := batchManifest{Provider: provider, Items: make([]batchManifestItem, len(items))}
+	for i, item := range items {
+		entry := batchManifestItem{ID: item.ID, Text: item.Text, Provider: provider, Voice: cmp.Or(item.Voice, activeDefaultVoice(provider))}
+		if errs[i] != nil {
+			entry.Error = errs[i].Error()
+		} else {
+			entry.File = filepath.Join(absDir, files[i].File)
+			entry.DurationMS = files[i].Duration.Milliseconds()
+			entry.Bytes = files[i].Bytes
+		}
+		manifest.Items[i] = entry
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(ctx, path, bytes.NewReader(append(data, '\n')))
+}
+
+// handleBatchSynthesize synthesizes many texts to files in output_dir without playing them
+func handleBatchSynthesize(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Batch synthesize tool called", "request", request)
+	arguments := request.GetArguments()
+
+	providerName, _ := arguments["provider"].(string)
+	dir, _ := arguments["output_dir"].(string)
+	if dir == "" {
+		result := mcp.NewToolResultText("Error: output_dir must be a non-empty string")
+		result.IsError = true
+		return result, nil
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: output_dir does not exist: %s", dir))
+		result.IsError = true
+		return result, nil
+	}
+	manifest, err := manifestArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	items, err := parseBatchItems(arguments["items"], providerName)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	provider, err := newProvider(providerName)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	concurrency := defaultBatchConcurrency
+	if c, ok := arguments["concurrency"].(float64); ok {
+		if c >= 1 && c <= maxBatchConcurrency {
+			concurrency = int(c)
+		} else {
+			log.Warn("Concurrency out of range, using default", "provided", c, "max", maxBatchConcurrency)
+		}
+	}
+
+	log.Info("Synthesizing batch", "provider", providerName, "items", len(items), "dir", dir, "concurrency", concurrency)
+	files, errs := synthesizeBatch(ctx, provider, providerSetting(nil, providerName, "model", ""), items, dir, concurrency)
+
+	var (
+		lines   []string
+		written int
+	)
+	for i, item := range items {
+		if errs[i] != nil {
+			log.Error("Failed to synthesize batch item", "id", item.ID, "error", errs[i])
+			lines = append(lines, fmt.Sprintf("[failed] %s: %v", item.ID, errs[i]))
+			continue
+		}
+		written++
+		lines = append(lines, fmt.Sprintf("[ok] %s: %s", item.ID, files[i].File))
+	}
+	if manifest != "" && ctx.Err() == nil {
+		if err := writeBatchManifest(ctx, manifest, providerName, dir, items, files, errs); err != nil {
+			log.Error("Failed to write batch manifest", "path", manifest, "error", err)
+			lines = append(lines, fmt.Sprintf("[failed] manifest: %v", err))
+		} else {
+			lines = append(lines, fmt.Sprintf("Manifest: %s", manifest))
+		}
+	}
+
+	summary := fmt.Sprintf("Wrote %d of %d files to %s\n%s", written, len(items), dir, strings.Join(lines, "\n"))
+	if written == 0 {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			log.Info("Batch synthesis cancelled by user")
+			return mcp.NewToolResultText("Batch synthesis cancelled"), nil
+		}
+		result := mcp.NewToolResultText("Error: every item failed\n" + summary)
+		result.IsError = true
+		return result, nil
+	}
+	return mcp.NewToolResultText(summary), nil
+}
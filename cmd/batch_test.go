@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchProvider returns PCM audio, failing for the text "fail"
+type batchProvider struct{}
+
+func (batchProvider) Synthesize(ctx context.Context, opts say.Options) (*say.Audio, error) {
+	if opts.Text == "fail" {
+		return nil, errors.New("synthesis failed")
+	}
+	return pcmAudio(generateTestAudio(24000, 0.1, 440.0)), nil
+}
+
+func TestParseBatchItems(t *testing.T) {
+	items, err := parseBatchItems([]any{
+		map[string]any{"id": "intro_01", "text": "Welcome, traveler.", "voice": "nova"},
+		map[string]any{"id": "intro-02", "text": "Safe travels."},
+	}, say.ProviderOpenAI)
+	require.NoError(t, err)
+	assert.Equal(t, []batchItem{
+		{ID: "intro_01", Text: "Welcome, traveler.", Voice: "nova"},
+		{ID: "intro-02", Text: "Safe travels.", Voice: ""},
+	}, items)
+
+	tests := []struct {
+		name string
+		raw  any
+	}{
+		{"not an array", "Hello"},
+		{"empty", []any{}},
+		{"missing id", []any{map[string]any{"text": "Hello"}}},
+		{"path traversal", []any{map[string]any{"id": "../secrets", "text": "Hello"}}},
+		{"path separator", []any{map[string]any{"id": "a/b", "text": "Hello"}}},
+		{"duplicate id", []any{map[string]any{"id": "a", "text": "One"}, map[string]any{"id": "A", "text": "Two"}}},
+		{"missing text", []any{map[string]any{"id": "a"}}},
+		{"too many", make([]any, maxBatchItems+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseBatchItems(tt.raw, say.ProviderOpenAI)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestHandleBatchSynthesize(t *testing.T) {
+	mock := useMockPlayer(t)
+	prev := newProvider
+	newProvider = func(name string) (say.Provider, error) { return batchProvider{}, nil }
+	t.Cleanup(func() { newProvider = prev })
+
+	dir := t.TempDir()
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{
+		"provider":   "google",
+		"output_dir": dir,
+		"items": []any{
+			map[string]any{"id": "one", "text": "One."},
+			map[string]any{"id": "two", "text": "fail"},
+			map[string]any{"id": "three", "text": "Three."},
+		},
+		"concurrency":   2.0,
+		"manifest_file": filepath.Join(dir, "manifest.json"),
+	}
+
+	result, err := handleBatchSynthesize(context.Background(), request)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Wrote 2 of 3 files")
+	assert.Contains(t, text, "[ok] one: one.wav")
+	assert.Contains(t, text, "[failed] two: synthesis failed")
+	assert.False(t, mock.Played, "batch synthesis never plays audio")
+
+	data, err := os.ReadFile(filepath.Join(dir, "three.wav"))
+	require.NoError(t, err)
+	assert.Equal(t, "RIFF", string(data[:4]))
+	assert.NoFileExists(t, filepath.Join(dir, "two.wav"))
+
+	data, err = os.ReadFile(filepath.Join(dir, "manifest.json"))
+	require.NoError(t, err)
+	var manifest batchManifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	require.Len(t, manifest.Items, 3)
+	one := manifest.Items[0]
+	assert.Equal(t, "one", one.ID)
+	assert.Equal(t, "One.", one.Text)
+	assert.Equal(t, "google", one.Provider)
+	assert.Equal(t, say.DefaultGoogleVoice, one.Voice)
+	assert.Equal(t, filepath.Join(dir, "one.wav"), one.File)
+	assert.Equal(t, int64(100), one.DurationMS)
+	info, err := os.Stat(one.File)
+	require.NoError(t, err)
+	assert.Equal(t, int(info.Size()), one.Bytes)
+	assert.Equal(t, batchManifestItem{ID: "two", Text: "fail", Provider: "google", Voice: say.DefaultGoogleVoice, Error: "synthesis failed"}, manifest.Items[1])
+	assert.Contains(t, text, "Manifest: "+filepath.Join(dir, "manifest.json"))
+}
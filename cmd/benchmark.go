@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// benchmarkPhrase is synthesized by every provider, short enough to cost next to nothing
+	benchmarkPhrase = "The quick brown fox jumps over the lazy dog."
+	// benchmarkTimeout bounds each provider's run, a slow provider must not hold up the others' report
+	benchmarkTimeout = 30 * time.Second
+)
+
+// benchmarkProvider returns the unwrapped provider to benchmark. Swapped out in tests.
+var benchmarkProvider = configuredProvider
+
+// benchmarkResult is one provider's timings
+type benchmarkResult struct {
+	// FirstByte is 0 for providers that only return complete audio
+	FirstByte time.Duration
+	Total     time.Duration
+	Bytes     int
+}
+
+// benchmark synthesizes benchmarkPhrase with provider without playing it, timing the first
+// byte of streamed audio and the whole response. It waits for the provider's rate limit and
+// concurrency slot first, that wait is not counted.
+func benchmark(ctx context.Context, name string, provider say.Provider) (benchmarkResult, error) {
+	limits := &managedProvider{Provider: provider, name: name, bucket: providerLimiter(name), slots: providerSlots(name)}
+	if err := limits.wait(ctx); err != nil {
+		return benchmarkResult{}, err
+	}
+	defer limits.slots.release()
+
+	opts := say.Options{Text: benchmarkPhrase, Voice: activeDefaultVoice(name), Model: providerSetting(nil, name, "model", "")}
+	var (
+		body io.ReadCloser
+		err  error
+	)
+	start := time.Now()
+	switch p := provider.(type) {
+	case say.StreamingProvider:
+		body, err = p.Stream(ctx, opts)
+	case say.PCMStreamingProvider:
+		body, _, err = p.StreamPCM(ctx, opts)
+	default:
+		audio, err := provider.Synthesize(ctx, opts)
+		if err != nil {
+			return benchmarkResult{}, err
+		}
+		recordCost(ctx, name, opts.Model, opts.Text)
+		return benchmarkResult{Total: time.Since(start), Bytes: len(audio.Data)}, nil
+	}
+	if err != nil {
+		return benchmarkResult{}, err
+	}
+	defer body.Close()
+	recordCost(ctx, name, opts.Model, opts.Text)
+
+	var result benchmarkResult
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 && result.FirstByte == 0 {
+			result.FirstByte = time.Since(start)
+		}
+		result.Bytes += n
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return benchmarkResult{}, err
+		}
+	}
+	result.Total = time.Since(start)
+	return result, nil
+}
+
+// handleBenchmarkProviders times a short synthesis on every configured provider at once,
+// without playing anything. Failures are reported per provider and never fail the call.
+func handleBenchmarkProviders(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Benchmark providers tool called", "request", request)
+
+	statuses := providerStatuses()
+	results := make([]benchmarkResult, len(statuses))
+	errs := make([]error, len(statuses))
+	var wg sync.WaitGroup
+	for i, status := range statuses {
+		if !status.Ready {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, benchmarkTimeout)
+			defer cancel()
+			provider, err := benchmarkProvider(status.Name)
+			if err == nil {
+				results[i], err = benchmark(ctx, status.Name, provider)
+			}
+			if err != nil {
+				if errors.Is(err, context.DeadlineExceeded) {
+					err = fmt.Errorf("no response within %v", benchmarkTimeout)
+				}
+				log.Warn("Provider benchmark failed", "provider", status.Name, "error", err)
+				errs[i] = withProviderHint(err)
+				return
+			}
+			log.Info("Benchmarked provider", "provider", status.Name, "firstByte", results[i].FirstByte, "total", results[i].Total)
+		}()
+	}
+	wg.Wait()
+	if errors.Is(ctx.Err(), context.Canceled) {
+		log.Info("Benchmark cancelled by user")
+		return mcp.NewToolResultText("Benchmark cancelled"), nil
+	}
+
+	// Fastest first, then failures, then providers that aren't configured
+	order := make([]int, len(statuses))
+	for i := range order {
+		order[i] = i
+	}
+	rank := func(i int) int {
+		switch {
+		case !statuses[i].Ready:
+			return 2
+		case errs[i] != nil:
+			return 1
+		}
+		return 0
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Or(cmp.Compare(rank(a), rank(b)), cmp.Compare(results[a].Total, results[b].Total))
+	})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Synthesized %q (%d characters), fastest first:\n", benchmarkPhrase, len(benchmarkPhrase))
+	for _, i := range order {
+		status, result := statuses[i], results[i]
+		switch {
+		case !status.Ready:
+			fmt.Fprintf(&sb, "- %s: skipped, %s\n", status.Name, status.Detail)
+		case errs[i] != nil:
+			fmt.Fprintf(&sb, "✗ %s: %v\n", status.Name, errs[i])
+		case result.FirstByte > 0:
+			fmt.Fprintf(&sb, "✓ %s: first byte %v, total %v, %d bytes\n", status.Name, result.FirstByte.Round(time.Millisecond), result.Total.Round(time.Millisecond), result.Bytes)
+		default:
+			fmt.Fprintf(&sb, "✓ %s: total %v (not streamed), %d bytes\n", status.Name, result.Total.Round(time.Millisecond), result.Bytes)
+		}
+	}
+	return mcp.NewToolResultText(strings.TrimSpace(sb.String())), nil
+}
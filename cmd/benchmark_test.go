@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamingBenchmarkProvider streams a few bytes of audio
+type streamingBenchmarkProvider struct {
+	fakeProvider
+}
+
+func (p *streamingBenchmarkProvider) Stream(ctx context.Context, opts say.Options) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("ID3 audio")), nil
+}
+
+func TestHandleBenchmarkProviders(t *testing.T) {
+	mock := useMockPlayer(t)
+	resetConcurrencyLimits(t)
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	t.Setenv("ELEVENLABS_API_KEY", "xi-test")
+	t.Setenv("GOOGLE_AI_API_KEY", "gm-test")
+	useConfig(t, &Config{})
+
+	elevenLabs := &fakeProvider{}
+	prev := benchmarkProvider
+	benchmarkProvider = func(name string) (say.Provider, error) {
+		switch name {
+		case say.ProviderOpenAI:
+			return &streamingBenchmarkProvider{}, nil
+		case say.ProviderElevenLabs:
+			return elevenLabs, nil
+		}
+		return nil, errors.New("connection refused")
+	}
+	t.Cleanup(func() { benchmarkProvider = prev })
+
+	result, err := handleBenchmarkProviders(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.False(t, result.IsError, "a failed provider does not fail the call")
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, `Synthesized "The quick brown fox jumps over the lazy dog."`)
+	assert.Regexp(t, `✓ openai: first byte \S+, total \S+, 9 bytes`, text)
+	assert.Regexp(t, `✓ elevenlabs: total \S+ \(not streamed\), \d+ bytes`, text)
+	assert.Contains(t, text, "✗ google: connection refused")
+	assert.Less(t, strings.Index(text, "elevenlabs"), strings.Index(text, "google"), "failures come after successes")
+	assert.Equal(t, []string{benchmarkPhrase}, elevenLabs.texts)
+	assert.False(t, mock.Played, "benchmarks never play audio")
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+)
+
+// bufferSetting reads a duration in milliseconds from env, invalid values fall back to def
+func bufferSetting(env string, def time.Duration, min int) time.Duration {
+	value := getenv(env)
+	if value == "" {
+		return def
+	}
+	ms, err := strconv.Atoi(value)
+	if err != nil || ms < min {
+		log.Warn("Invalid buffer size, using default", "env", env, "value", value, "default", def)
+		return def
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// sampleRateSetting reads the speaker rate in Hz from MCP_SAY_SAMPLE_RATE, invalid values fall back to the default
+func sampleRateSetting() beep.SampleRate {
+	value := getenv("MCP_SAY_SAMPLE_RATE")
+	if value == "" {
+		return say.DefaultSpeakerSampleRate
+	}
+	hz, err := strconv.Atoi(value)
+	if err != nil || beep.SampleRate(hz) < say.MinSpeakerSampleRate || beep.SampleRate(hz) > say.MaxSpeakerSampleRate {
+		log.Warn("Invalid sample rate, using default", "env", "MCP_SAY_SAMPLE_RATE", "value", value, "default", say.DefaultSpeakerSampleRate)
+		return say.DefaultSpeakerSampleRate
+	}
+	return beep.SampleRate(hz)
+}
+
+// speakerPlayer returns the speaker player sized by MCP_SAY_BUFFER_MS and MCP_SAY_PREBUFFER_MS,
+// opened at MCP_SAY_SAMPLE_RATE
+func speakerPlayer() *say.SpeakerPlayer {
+	player := &say.SpeakerPlayer{
+		Buffer:     bufferSetting("MCP_SAY_BUFFER_MS", say.DefaultSpeakerBuffer, 1),
+		Prebuffer:  bufferSetting("MCP_SAY_PREBUFFER_MS", say.DefaultPrebuffer, 0),
+		SampleRate: sampleRateSetting(),
+	}
+	if player.Prebuffer == 0 {
+		// 0 turns prebuffering off, which the player spells as a negative duration
+		player.Prebuffer = -1
+	}
+	return player
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpeakerPlayer(t *testing.T) {
+	t.Setenv("MCP_SAY_BUFFER_MS", "")
+	t.Setenv("MCP_SAY_PREBUFFER_MS", "")
+	t.Setenv("MCP_SAY_SAMPLE_RATE", "")
+	assert.Equal(t, &say.SpeakerPlayer{Buffer: say.DefaultSpeakerBuffer, Prebuffer: say.DefaultPrebuffer, SampleRate: say.DefaultSpeakerSampleRate}, speakerPlayer())
+
+	t.Setenv("MCP_SAY_BUFFER_MS", "250")
+	t.Setenv("MCP_SAY_PREBUFFER_MS", "0")
+	t.Setenv("MCP_SAY_SAMPLE_RATE", "48000")
+	assert.Equal(t, &say.SpeakerPlayer{Buffer: 250 * time.Millisecond, Prebuffer: -1, SampleRate: 48000}, speakerPlayer())
+
+	// The speaker buffer can't be empty, invalid values keep the defaults
+	t.Setenv("MCP_SAY_BUFFER_MS", "0")
+	t.Setenv("MCP_SAY_PREBUFFER_MS", "soon")
+	t.Setenv("MCP_SAY_SAMPLE_RATE", "1000")
+	assert.Equal(t, &say.SpeakerPlayer{Buffer: say.DefaultSpeakerBuffer, Prebuffer: say.DefaultPrebuffer, SampleRate: say.DefaultSpeakerSampleRate}, speakerPlayer())
+}
@@ -19,8 +19,14 @@ func WithCancellation(handler ToolHandlerFunc) func(ctx context.Context, request
 		// Try to extract request ID from JSON-RPC context or generate one
 		requestID := extractOrGenerateRequestID(ctx, request)
 
-		// Create cancellable context
-		cancellableCtx, cancel := context.WithCancel(ctx)
+		// Create cancellable context, bounded by the configured call timeout
+		var cancellableCtx context.Context
+		var cancel context.CancelFunc
+		if timeout := callTimeout(); timeout > 0 {
+			cancellableCtx, cancel = context.WithTimeout(ctx, timeout)
+		} else {
+			cancellableCtx, cancel = context.WithCancel(ctx)
+		}
 		defer cancel() // Ensure cleanup
 
 		// Register for cancellation
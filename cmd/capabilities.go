@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Features reported by the capabilities tool
+const (
+	featureSpeed        = "speed"
+	featureSSML         = "ssml"
+	featureTimestamps   = "timestamps"
+	featureStreaming    = "streaming"
+	featureInstructions = "instructions"
+	featureMultiSpeaker = "multi_speaker"
+)
+
+// capability says whether a provider supports a feature and how to use it
+type capability struct {
+	Supported bool `json:"supported"`
+	// Argument is the tool argument that turns the feature on, if it takes one
+	Argument string `json:"argument,omitempty"`
+	Note     string `json:"note,omitempty"`
+}
+
+// providerCapabilities lists what one provider's tool can do
+type providerCapabilities struct {
+	Provider string                `json:"provider"`
+	Tool     string                `json:"tool"`
+	Features map[string]capability `json:"features"`
+}
+
+// capabilityTable describes each provider's features as the handlers implement them.
+// Update it together with the handlers, the tests check it against the providers.
+var capabilityTable = []providerCapabilities{
+	{
+		Provider: say.ProviderSay,
+		Tool:     "say_tts",
+		Features: map[string]capability{
+			featureSpeed:        {Supported: true, Argument: "rate", Note: "words per minute"},
+			featureSSML:         {Supported: true, Note: "via speak_ssml, <break> becomes a [[slnc]] command and other tags are stripped"},
+			featureTimestamps:   {Supported: false},
+			featureStreaming:    {Supported: false, Note: "the say command plays the whole utterance"},
+			featureInstructions: {Supported: false},
+			featureMultiSpeaker: {Supported: false},
+		},
+	},
+	{
+		Provider: say.ProviderElevenLabs,
+		Tool:     "elevenlabs_tts",
+		Features: map[string]capability{
+			featureSpeed:        {Supported: false},
+			featureSSML:         {Supported: true, Note: "via speak_ssml, <break> is native up to 3s and other tags are stripped"},
+			featureTimestamps:   {Supported: true, Argument: "vtt_output", Note: "WebVTT captions from the provider's own word alignment"},
+			featureStreaming:    {Supported: true, Argument: "optimize_streaming_latency", Note: "audio plays as it arrives, the argument trades quality for a faster start"},
+			featureInstructions: {Supported: false},
+			featureMultiSpeaker: {Supported: true, Argument: "speakers", Note: "uses the text-to-dialogue endpoint"},
+		},
+	},
+	{
+		Provider: say.ProviderGoogle,
+		Tool:     "google_tts",
+		Features: map[string]capability{
+			featureSpeed:        {Supported: true, Argument: "speaking_rate", Note: "a prompt directive for Gemini TTS, an audio setting with --google-grpc"},
+			featureSSML:         {Supported: true, Note: "via speak_ssml, <break> is rendered as inserted silence and other tags are stripped"},
+			featureTimestamps:   {Supported: false},
+			featureStreaming:    {Supported: false, Note: "the clip plays once fully synthesized"},
+			featureInstructions: {Supported: false},
+			featureMultiSpeaker: {Supported: true, Argument: "speakers", Note: "not with --google-grpc"},
+		},
+	},
+	{
+		Provider: say.ProviderOpenAI,
+		Tool:     "openai_tts",
+		Features: map[string]capability{
+			featureSpeed:        {Supported: true, Argument: "speed", Note: "0.25 to 4.0"},
+			featureSSML:         {Supported: true, Note: "via speak_ssml, <break> is rendered as inserted silence and other tags are stripped"},
+			featureTimestamps:   {Supported: true, Argument: "align", Note: "approximate, transcribed with whisper-1 in a second billed request"},
+			featureStreaming:    {Supported: true, Argument: "stream", Note: "audio plays as it arrives, the argument streams raw PCM for the fastest start"},
+			featureInstructions: {Supported: true, Argument: "instructions", Note: "gpt-4o-mini-tts only, tts-1 models ignore them"},
+			featureMultiSpeaker: {Supported: false},
+		},
+	},
+}
+
+// handleCapabilities returns the capability table as JSON
+func handleCapabilities(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Capabilities tool called", "request", request)
+	data, err := json.MarshalIndent(struct {
+		Providers []providerCapabilities `json:"providers"`
+	}{capabilityTable}, "", "  ")
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
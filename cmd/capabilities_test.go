@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilityTable(t *testing.T) {
+	features := []string{featureSpeed, featureSSML, featureTimestamps, featureStreaming, featureInstructions, featureMultiSpeaker}
+	var providers []string
+	for _, row := range capabilityTable {
+		providers = append(providers, row.Provider)
+		assert.Equal(t, row.Tool, mustToolFor(t, row.Provider))
+		for _, feature := range features {
+			_, ok := row.Features[feature]
+			assert.True(t, ok, "%s is missing %s", row.Provider, feature)
+		}
+		assert.Len(t, row.Features, len(features), row.Provider)
+
+		// Streaming must match what the provider implements
+		provider, err := configuredProvider(row.Provider)
+		require.NoError(t, err)
+		_, streams := provider.(say.StreamingProvider)
+		assert.Equal(t, streams, row.Features[featureStreaming].Supported, "%s streaming", row.Provider)
+	}
+	assert.ElementsMatch(t, []string{say.ProviderSay, say.ProviderElevenLabs, say.ProviderGoogle, say.ProviderOpenAI}, providers)
+}
+
+// mustToolFor returns the tool the status report lists for provider
+func mustToolFor(t *testing.T, provider string) string {
+	t.Helper()
+	for _, status := range providerStatuses() {
+		if status.Name == provider {
+			return status.Tool
+		}
+	}
+	t.Fatalf("no status for provider %s", provider)
+	return ""
+}
+
+func TestHandleCapabilities(t *testing.T) {
+	result, err := handleCapabilities(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var matrix struct {
+		Providers []providerCapabilities `json:"providers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &matrix))
+	require.Len(t, matrix.Providers, len(capabilityTable))
+	for _, row := range matrix.Providers {
+		if row.Provider == say.ProviderElevenLabs {
+			assert.Equal(t, capability{Supported: true, Argument: "vtt_output", Note: "WebVTT captions from the provider's own word alignment"}, row.Features[featureTimestamps])
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+)
+
+const (
+	// Longest accepted sentence_pause_ms value
+	maxSentencePause = 5 * time.Second
+	// Longest accepted crossfade_ms value
+	maxCrossfade = time.Second
+	// Longest chunk synthesized in one request, in characters, under OpenAI's 4096 input limit
+	maxChunkLength = 4000
+)
+
+// chunking configures sentence by sentence synthesis, the zero value synthesizes text in one request
+type chunking struct {
+	// pause is the silence between sentences
+	pause time.Duration
+	// crossfade overlaps sentences that have no pause between them
+	crossfade time.Duration
+}
+
+func (c chunking) enabled() bool {
+	return c.pause > 0 || c.crossfade > 0
+}
+
+// chunkingArgument reads the optional sentence_pause_ms and crossfade_ms tool arguments
+func chunkingArgument(arguments map[string]any) chunking {
+	return chunking{pause: sentencePauseArgument(arguments), crossfade: crossfadeArgument(arguments)}
+}
+
+// splitSentences splits text into sentences on terminal punctuation followed by
+// whitespace, and on line breaks. Chinese and Japanese terminators (。！？) end a sentence
+// without whitespace, since those languages don't put spaces between sentences, unless
+// a closing quote follows them.
+// Closing quotes and brackets stay with their sentence.
+// Sentences longer than maxChunkLength are split further, see splitLongSentence.
+func splitSentences(text string) []string {
+	var (
+		sentences []string
+		current   strings.Builder
+	)
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			sentences = append(sentences, splitLongSentence(s, maxChunkLength)...)
+		}
+		current.Reset()
+	}
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '\n' {
+			flush()
+			continue
+		}
+		current.WriteRune(r)
+		if !isSentenceTerminator(r) {
+			continue
+		}
+		// Absorb repeated terminators and closing punctuation ("Really?!", "He said "hi."", 「はい。」)
+		for i+1 < len(runes) && (isSentenceTerminator(runes[i+1]) || isClosingPunct(runes[i+1])) {
+			i++
+			current.WriteRune(runes[i])
+		}
+		// A quote closing after 。 is usually part of a longer sentence, 「はい。」と言った。
+		if i+1 == len(runes) || unicode.IsSpace(runes[i+1]) || isFullWidthTerminator(runes[i]) {
+			flush()
+		}
+	}
+	flush()
+	return sentences
+}
+
+// splitLongSentence breaks a sentence longer than limit characters at the last space
+// that fits, or after the last Chinese or Japanese comma (、，；：) for text written
+// without spaces. A run with neither within limit, e.g. a long URL or token, is cut at
+// exactly limit characters, so no chunk is ever over the limit.
+func splitLongSentence(sentence string, limit int) []string {
+	runes := []rune(sentence)
+	var chunks []string
+	for len(runes) > limit {
+		cut := limit
+		for i := limit; i > 0; i-- {
+			if unicode.IsSpace(runes[i]) {
+				cut = i
+				break
+			}
+			if isFullWidthPause(runes[i-1]) {
+				cut = i
+				break
+			}
+		}
+		chunks = append(chunks, strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace))
+		for cut < len(runes) && unicode.IsSpace(runes[cut]) {
+			cut++
+		}
+		runes = runes[cut:]
+	}
+	if len(runes) > 0 {
+		chunks = append(chunks, string(runes))
+	}
+	return chunks
+}
+
+func isSentenceTerminator(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || isFullWidthTerminator(r)
+}
+
+// isFullWidthTerminator reports whether r ends a Chinese or Japanese sentence
+func isFullWidthTerminator(r rune) bool {
+	return r == '。' || r == '！' || r == '？' || r == '．' || r == '｡'
+}
+
+// isFullWidthPause reports whether r separates clauses in Chinese or Japanese text
+func isFullWidthPause(r rune) bool {
+	return r == '、' || r == '，' || r == '；' || r == '：' || r == '､'
+}
+
+func isClosingPunct(r rune) bool {
+	switch r {
+	case '"', '\'', ')', ']', '”', '’', '」', '』', '）', '】', '〕', '〉', '》', '］':
+		return true
+	}
+	return false
+}
+
+// sentenceSegments splits text into sentences separated by the chunking's pause or
+// crossfade. Each carries its neighbours as context so the seams sound natural.
+func sentenceSegments(text string, chunks chunking) []say.Segment {
+	sentences := splitSentences(text)
+	segments := make([]say.Segment, 0, len(sentences))
+	for i, sentence := range sentences {
+		seg := say.Segment{Text: sentence}
+		if i > 0 {
+			seg.PreviousText = sentences[i-1]
+		}
+		if i < len(sentences)-1 {
+			seg.Pause = chunks.pause
+			seg.Crossfade = chunks.crossfade
+			seg.NextText = sentences[i+1]
+		}
+		segments = append(segments, seg)
+	}
+	return segments
+}
+
+// sentencePauseArgument reads the optional sentence_pause_ms tool argument.
+// Out of range values are ignored, leaving the feature off.
+func sentencePauseArgument(arguments map[string]any) time.Duration {
+	ms, ok := arguments["sentence_pause_ms"].(float64)
+	if !ok || ms == 0 {
+		return 0
+	}
+	pause := time.Duration(ms * float64(time.Millisecond))
+	if pause < 0 || pause > maxSentencePause {
+		log.Warn("Sentence pause out of range, ignoring", "provided", ms, "max", maxSentencePause.Milliseconds())
+		return 0
+	}
+	return pause
+}
+
+// crossfadeArgument reads the optional crossfade_ms tool argument.
+// Out of range values are ignored, leaving hard cuts between clips.
+func crossfadeArgument(arguments map[string]any) time.Duration {
+	ms, ok := arguments["crossfade_ms"].(float64)
+	if !ok || ms == 0 {
+		return 0
+	}
+	crossfade := time.Duration(ms * float64(time.Millisecond))
+	if crossfade < 0 || crossfade > maxCrossfade {
+		log.Warn("Crossfade out of range, ignoring", "provided", ms, "max", maxCrossfade.Milliseconds())
+		return 0
+	}
+	return crossfade
+}
+
+// speakText speaks opts.Text with provider, synthesizing each sentence separately
+// and joining them with silence or a crossfade when chunking is enabled
+func speakText(ctx context.Context, provider say.Provider, opts say.Options, chunks chunking) error {
+	opts.Player = player()
+	if !chunks.enabled() {
+		return withAudioHint(say.SpeakWith(ctx, provider, opts))
+	}
+	log.Debug("Synthesizing audio per sentence", "pause", chunks.pause, "crossfade", chunks.crossfade)
+	streamer, format, err := say.RenderSegments(ctx, sentenceSegments(opts.Text, chunks), say.ProviderSegments(provider, opts))
+	if err != nil {
+		return err
+	}
+	return playStream(ctx, say.WithVolume(streamer, opts.Volume), format)
+}
+
+// playStream plays decoded samples with the active audio player
+func playStream(ctx context.Context, streamer beep.Streamer, format beep.Format) error {
+	return withAudioHint(player().PlayStream(ctx, streamer, format))
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{"single sentence", "Hello world", []string{"Hello world"}},
+		{"terminators", "Hello there. How are you? Great!", []string{"Hello there.", "How are you?", "Great!"}},
+		{"repeated terminators", "Really?! Yes... Okay.", []string{"Really?!", "Yes...", "Okay."}},
+		{"closing quotes", `He said "stop." Then left.`, []string{`He said "stop."`, "Then left."}},
+		{"no split inside numbers", "Version 1.5 is out. Try it.", []string{"Version 1.5 is out.", "Try it."}},
+		{"line breaks", "First line\nSecond line", []string{"First line", "Second line"}},
+		{"empty", "   ", nil},
+		{"japanese", "今日は晴れです。散歩に行きましょう！どこへ行きますか？", []string{"今日は晴れです。", "散歩に行きましょう！", "どこへ行きますか？"}},
+		{"chinese", "我们明天见。你好吗？我很好！", []string{"我们明天见。", "你好吗？", "我很好！"}},
+		{"japanese quotes", "彼は「はい。」と言った。それから帰った。", []string{"彼は「はい。」と言った。", "それから帰った。"}},
+		{"mixed scripts", "The meeting is at 3. 会議は三時です。See you there.", []string{"The meeting is at 3.", "会議は三時です。", "See you there."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, splitSentences(tt.text))
+		})
+	}
+}
+
+func TestSplitLongSentence(t *testing.T) {
+	assert.Equal(t, []string{"one two", "three", "four"}, splitLongSentence("one two three  four", 8))
+	assert.Equal(t, []string{"abcd", "efgh", "ij k"}, splitLongSentence("abcdefghij k", 4))
+	assert.Equal(t, []string{"héllo"}, splitLongSentence("héllo", 5))
+
+	// A giant URL or token with no spaces must still come out in chunks within the limit
+	token := "https://example.com/" + strings.Repeat("x", 20000)
+	sentences := splitSentences("Open this link.\n" + token + "\nThanks.")
+	require.Greater(t, len(sentences), 3)
+	assert.Equal(t, "Open this link.", sentences[0])
+	assert.Equal(t, "Thanks.", sentences[len(sentences)-1])
+	for _, s := range sentences {
+		assert.LessOrEqual(t, utf8.RuneCountInString(s), maxChunkLength)
+	}
+	assert.Equal(t, token, strings.Join(sentences[1:len(sentences)-1], ""))
+}
+
+func TestSplitLongSentenceCJK(t *testing.T) {
+	// Clauses break after the comma that fits, there are no spaces to break at
+	assert.Equal(t, []string{"今日は、", "晴れです"}, splitLongSentence("今日は、晴れです", 5))
+	assert.Equal(t, []string{"我们，", "明天见"}, splitLongSentence("我们，明天见", 4))
+	assert.Equal(t, []string{"あいう", "えお"}, splitLongSentence("あいうえお", 3))
+
+	// A long Japanese paragraph without terminators still comes out within the limit
+	clause := strings.Repeat("日本語の文章", 50) + "、"
+	paragraph := strings.Repeat(clause, 30)
+	sentences := splitSentences(paragraph)
+	require.Greater(t, len(sentences), 1)
+	for _, s := range sentences {
+		assert.LessOrEqual(t, utf8.RuneCountInString(s), maxChunkLength)
+		assert.True(t, strings.HasSuffix(s, "、"), "breaks after a comma")
+	}
+	assert.Equal(t, paragraph, strings.Join(sentences, ""))
+
+	// And a Chinese paragraph of sentences is split on its terminators
+	chinese := strings.Repeat("这是一个很长的中文句子。", 500)
+	sentences = splitSentences(chinese)
+	assert.Len(t, sentences, 500)
+	assert.Equal(t, "这是一个很长的中文句子。", sentences[0])
+}
+
+func TestSentenceSegments(t *testing.T) {
+	segments := sentenceSegments("One. Two. Three.", chunking{pause: 300 * time.Millisecond})
+	assert.Equal(t, []say.Segment{
+		{Text: "One.", Pause: 300 * time.Millisecond, NextText: "Two."},
+		{Text: "Two.", Pause: 300 * time.Millisecond, PreviousText: "One.", NextText: "Three."},
+		{Text: "Three.", PreviousText: "Two."},
+	}, segments)
+
+	segments = sentenceSegments("One. Two.", chunking{crossfade: 20 * time.Millisecond})
+	assert.Equal(t, []say.Segment{
+		{Text: "One.", Crossfade: 20 * time.Millisecond, NextText: "Two."},
+		{Text: "Two.", PreviousText: "One."},
+	}, segments)
+}
+
+func TestSentencePauseArgument(t *testing.T) {
+	assert.Equal(t, time.Duration(0), sentencePauseArgument(map[string]any{}))
+	assert.Equal(t, 250*time.Millisecond, sentencePauseArgument(map[string]any{"sentence_pause_ms": 250.0}))
+	assert.Equal(t, time.Duration(0), sentencePauseArgument(map[string]any{"sentence_pause_ms": -10.0}))
+	assert.Equal(t, time.Duration(0), sentencePauseArgument(map[string]any{"sentence_pause_ms": 60000.0}))
+}
+
+func TestChunkingArgument(t *testing.T) {
+	assert.False(t, chunkingArgument(map[string]any{}).enabled())
+	assert.Equal(t, chunking{crossfade: 30 * time.Millisecond}, chunkingArgument(map[string]any{"crossfade_ms": 30.0}))
+	assert.Equal(t, chunking{pause: 100 * time.Millisecond}, chunkingArgument(map[string]any{"sentence_pause_ms": 100.0, "crossfade_ms": 5000.0}))
+	assert.True(t, chunking{crossfade: time.Millisecond}.enabled())
+}
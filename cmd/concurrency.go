@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+)
+
+// defaultProviderConcurrency bounds the synthesis requests in flight per provider
+// unless overridden with MCP_SAY_<PROVIDER>_CONCURRENCY
+var defaultProviderConcurrency = map[string]int{
+	say.ProviderOpenAI:     4,
+	say.ProviderGoogle:     2,
+	say.ProviderElevenLabs: 2,
+	say.ProviderSay:        2,
+}
+
+// Clips played at the same time unless overridden with MCP_SAY_PLAYBACK_CONCURRENCY
+const defaultPlaybackConcurrency = 1
+
+// semaphore bounds concurrent work, a nil semaphore is unlimited
+type semaphore chan struct{}
+
+func newSemaphore(n int) semaphore {
+	if n <= 0 {
+		return nil
+	}
+	return make(semaphore, n)
+}
+
+// acquire blocks until a slot is free or ctx is cancelled
+func (s semaphore) acquire(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a slot taken by acquire
+func (s semaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
+
+// concurrencySetting reads a concurrency limit from env, where 0 means unlimited
+func concurrencySetting(env string, def int) int {
+	value := getenv(env)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Warn("Invalid concurrency limit, using default", "env", env, "value", value, "default", def)
+		return def
+	}
+	return n
+}
+
+// concurrencyEnv is the environment variable that sets a provider's concurrent synthesis requests
+func concurrencyEnv(provider string) string {
+	return fmt.Sprintf("MCP_SAY_%s_CONCURRENCY", strings.ToUpper(provider))
+}
+
+var (
+	limitsMu       sync.Mutex
+	synthesisSlots = map[string]semaphore{}
+	// playbackSlots is created on first use, after the config is loaded
+	playbackSlots     semaphore
+	playbackSlotsInit bool
+)
+
+// providerSlots returns the shared synthesis semaphore for a provider
+func providerSlots(provider string) semaphore {
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+	if slots, ok := synthesisSlots[provider]; ok {
+		return slots
+	}
+	n := concurrencySetting(concurrencyEnv(provider), defaultProviderConcurrency[provider])
+	log.Debug("Limiting concurrent synthesis", "provider", provider, "max", n)
+	synthesisSlots[provider] = newSemaphore(n)
+	return synthesisSlots[provider]
+}
+
+// playbackLimit returns the semaphore shared by everything that plays audio
+func playbackLimit() semaphore {
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+	if !playbackSlotsInit {
+		playbackSlots = newSemaphore(concurrencySetting("MCP_SAY_PLAYBACK_CONCURRENCY", defaultPlaybackConcurrency))
+		playbackSlotsInit = true
+	}
+	return playbackSlots
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/gopxl/beep/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// peakCounter records the most calls that were in flight at once
+type peakCounter struct {
+	current, peak atomic.Int32
+}
+
+func (c *peakCounter) enter() {
+	n := c.current.Add(1)
+	for {
+		peak := c.peak.Load()
+		if n <= peak || c.peak.CompareAndSwap(peak, n) {
+			return
+		}
+	}
+}
+
+func (c *peakCounter) leave() {
+	c.current.Add(-1)
+}
+
+// slowProvider takes a while to synthesize, like a network request
+type slowProvider struct {
+	counter peakCounter
+}
+
+func (p *slowProvider) Synthesize(ctx context.Context, opts say.Options) (*say.Audio, error) {
+	p.counter.enter()
+	defer p.counter.leave()
+	time.Sleep(30 * time.Millisecond)
+	return pcmAudio(generateTestAudio(24000, 0.01, 440.0)), nil
+}
+
+// slowPlayer takes a while to play, like the speaker
+type slowPlayer struct {
+	counter peakCounter
+}
+
+func (p *slowPlayer) Play(ctx context.Context, audio *say.Audio) error {
+	p.counter.enter()
+	defer p.counter.leave()
+	time.Sleep(20 * time.Millisecond)
+	return nil
+}
+
+func (p *slowPlayer) PlayStream(ctx context.Context, streamer beep.Streamer, format beep.Format) error {
+	return p.Play(ctx, nil)
+}
+
+func resetConcurrencyLimits(t *testing.T) {
+	reset := func() {
+		limitsMu.Lock()
+		synthesisSlots = map[string]semaphore{}
+		playbackSlots, playbackSlotsInit = nil, false
+		limitsMu.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestSynthesisOverlapsWhilePlaybackSerializes(t *testing.T) {
+	t.Setenv("MCP_SAY_OPENAI_RPS", "0")
+	t.Setenv("MCP_SAY_OPENAI_CONCURRENCY", "3")
+	resetConcurrencyLimits(t)
+	rateLimitersMu.Lock()
+	rateLimiters = map[string]*tokenBucket{}
+	rateLimitersMu.Unlock()
+
+	provider := &slowProvider{}
+	sink := &slowPlayer{}
+	prev := audioPlayer
+	audioPlayer = sink
+	t.Cleanup(func() { audioPlayer = prev })
+
+	managed := wrapProvider(say.ProviderOpenAI, provider)
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			audio, err := managed.Synthesize(context.Background(), say.Options{Text: "Hello"})
+			require.NoError(t, err)
+			require.NoError(t, player().Play(context.Background(), audio))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(3), provider.counter.peak.Load(), "synthesis runs up to the provider limit in parallel")
+	assert.Equal(t, int32(1), sink.counter.peak.Load(), "playback is serialized")
+}
+
+func TestPlaybackConcurrencyUnlimited(t *testing.T) {
+	t.Setenv("MCP_SAY_PLAYBACK_CONCURRENCY", "0")
+	resetConcurrencyLimits(t)
+	assert.Nil(t, playbackLimit())
+
+	sink := &slowPlayer{}
+	prev := audioPlayer
+	audioPlayer = sink
+	t.Cleanup(func() { audioPlayer = prev })
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, player().Play(context.Background(), &say.Audio{}))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(3), sink.counter.peak.Load())
+}
+
+func TestQueuedPlaybackIsCancellable(t *testing.T) {
+	t.Setenv("MCP_SAY_PLAYBACK_CONCURRENCY", "1")
+	resetConcurrencyLimits(t)
+	slots := playbackLimit()
+	require.NoError(t, slots.acquire(context.Background()))
+	defer slots.release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := player().Play(ctx, &say.Audio{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestConcurrencySetting(t *testing.T) {
+	assert.Equal(t, 4, concurrencySetting("MCP_SAY_TEST_CONCURRENCY", 4))
+	t.Setenv("MCP_SAY_TEST_CONCURRENCY", "-1")
+	assert.Equal(t, 4, concurrencySetting("MCP_SAY_TEST_CONCURRENCY", 4))
+	t.Setenv("MCP_SAY_TEST_CONCURRENCY", "0")
+	assert.Equal(t, 0, concurrencySetting("MCP_SAY_TEST_CONCURRENCY", 4))
+}
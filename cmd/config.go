@@ -0,0 +1,388 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// apiKeyEnv is the environment variable holding each cloud provider's API key
+var apiKeyEnv = map[string]string{
+	say.ProviderOpenAI:     "OPENAI_API_KEY",
+	say.ProviderGoogle:     "GOOGLE_AI_API_KEY",
+	say.ProviderElevenLabs: "ELEVENLABS_API_KEY",
+}
+
+// ProviderConfig holds the config file settings for a single provider
+type ProviderConfig struct {
+	APIKey        string   `yaml:"api_key"`
+	Voice         string   `yaml:"voice"`
+	Model         string   `yaml:"model"`
+	RPS           *float64 `yaml:"rps"`
+	USDPer1MChars *float64 `yaml:"usd_per_1m_chars"`
+	Concurrency   *int     `yaml:"concurrency"`
+	// Organization and Project scope OpenAI requests for org-scoped keys (openai only)
+	Organization string `yaml:"organization"`
+	Project      string `yaml:"project"`
+	// ExtraHeaders are added to this provider's requests, over the top level extra_headers
+	ExtraHeaders map[string]string `yaml:"extra_headers"`
+}
+
+// PolicyRule is one provider_policy entry, see MCP_SAY_PROVIDER_POLICY
+type PolicyRule struct {
+	Provider string `yaml:"provider"`
+	// UnderChars matches texts shorter than this many characters, omit it on the last rule
+	UnderChars int `yaml:"under_chars"`
+}
+
+// Config holds the settings loaded from the --config file. Every setting has an
+// environment variable equivalent, and a set environment variable always wins.
+type Config struct {
+	Providers map[string]ProviderConfig `yaml:"providers"`
+	// Timeout caps how long a single tool call may run, e.g. "2m"
+	Timeout                string `yaml:"timeout"`
+	SuppressSpeakingOutput bool   `yaml:"suppress_speaking_output"`
+	ShowCost               bool   `yaml:"show_cost"`
+	NoAudio                bool   `yaml:"no_audio"`
+	Interrupt              bool   `yaml:"interrupt"`
+	// DedupeMS skips repeating the same text, voice and provider within this many milliseconds
+	DedupeMS *int `yaml:"dedupe_ms"`
+	// HistorySize is how many utterances history and replay keep, 0 turns the history off
+	HistorySize *int `yaml:"history_size"`
+	// Muted starts the server muted, see the mute and unmute tools
+	Muted bool `yaml:"muted"`
+	// IdleTimeout releases the audio device after this long without tool calls, e.g. "30m"
+	IdleTimeout string `yaml:"idle_timeout"`
+	// IdleExit exits instead of only releasing the audio device once idle
+	IdleExit bool `yaml:"idle_exit"`
+	// TextFallback returns the text instead of failing when there is no audio backend
+	TextFallback bool `yaml:"text_fallback"`
+	// GoogleGRPC uses the Cloud Text-to-Speech gRPC API with Application Default Credentials
+	GoogleGRPC bool `yaml:"google_grpc"`
+	// GoogleLongAudio sends long google_tts text to the Cloud Text-to-Speech long audio API
+	GoogleLongAudio bool `yaml:"google_long_audio"`
+	// ReplacementsFile is a JSON or CSV file of text replacements applied before synthesis
+	ReplacementsFile string `yaml:"replacements_file"`
+	// PlaybackConcurrency is how many clips may play at once, 0 for unlimited
+	PlaybackConcurrency *int `yaml:"playback_concurrency"`
+	// BufferMS is the speaker buffer and PrebufferMS the audio decoded before playback starts
+	BufferMS    *int `yaml:"buffer_ms"`
+	PrebufferMS *int `yaml:"prebuffer_ms"`
+	// SampleRate is the rate in Hz the speaker is opened at, every stream is resampled to it
+	SampleRate *int `yaml:"sample_rate"`
+	// AudioAddr serves synthesized audio over HTTP, AuthToken is required as a bearer token when set
+	AudioAddr string `yaml:"audio_addr"`
+	AuthToken string `yaml:"auth_token"`
+	// Proxy routes provider requests through this proxy instead of HTTP_PROXY/HTTPS_PROXY
+	Proxy string `yaml:"proxy"`
+	// UserAgent replaces the mcp-say/<version> User-Agent of provider requests
+	UserAgent string `yaml:"user_agent"`
+	// ExtraHeaders are added to every provider request, e.g. a team id an API gateway requires
+	ExtraHeaders map[string]string `yaml:"extra_headers"`
+	// VoiceAliases map logical voice names to a provider voice, see MCP_SAY_VOICE_ALIASES
+	VoiceAliases map[string]voiceAlias `yaml:"voice_aliases"`
+	// ProviderPolicy enables the speak tool, which picks a provider by text length
+	ProviderPolicy []PolicyRule `yaml:"provider_policy"`
+	// VoicesTTL is how long the ElevenLabs voices list is cached, e.g. "10m"
+	VoicesTTL string `yaml:"voices_ttl"`
+	// ResultVerbosity is "quiet", "normal" or "verbose"
+	ResultVerbosity string `yaml:"result_verbosity"`
+	// ErrorMode is "result" or "error", see MCP_SAY_ERROR_MODE
+	ErrorMode string `yaml:"error_mode"`
+	// ErrorSound is played when a tool call fails, see MCP_SAY_ERROR_SOUND
+	ErrorSound string `yaml:"error_sound"`
+	// Duck lowers other audio while speech plays, see MCP_SAY_DUCK
+	Duck bool `yaml:"duck"`
+	// WebhookURL receives a POST when a call's playback ends, see MCP_SAY_WEBHOOK_URL
+	WebhookURL string `yaml:"webhook_url"`
+
+	// env maps environment variable names to their config file values
+	env map[string]string
+}
+
+// Settings loaded from the config file, empty unless --config is used
+var config = &Config{}
+
+// loadConfig reads a YAML config file, rejecting unknown keys so typos don't go unnoticed
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %v", err)
+	}
+
+	var c Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&c); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %v", path, err)
+	}
+
+	c.env = make(map[string]string)
+	for name, p := range c.Providers {
+		switch name {
+		case say.ProviderOpenAI, say.ProviderGoogle, say.ProviderElevenLabs, say.ProviderSay:
+		default:
+			return nil, fmt.Errorf("config %s: unknown provider %q", path, name)
+		}
+		if p.APIKey != "" {
+			env, ok := apiKeyEnv[name]
+			if !ok {
+				return nil, fmt.Errorf("config %s: provider %q does not use an API key", path, name)
+			}
+			c.env[env] = p.APIKey
+		}
+		if (p.Organization != "" || p.Project != "") && name != say.ProviderOpenAI {
+			return nil, fmt.Errorf("config %s: provider %q does not use an organization or project", path, name)
+		}
+		c.setEnv("OPENAI_ORG_ID", p.Organization)
+		c.setEnv("OPENAI_PROJECT_ID", p.Project)
+		c.setEnv(settingEnv(name, "voice"), p.Voice)
+		c.setEnv(settingEnv(name, "model"), p.Model)
+		if p.RPS != nil {
+			c.env[rateLimitEnv(name)] = strconv.FormatFloat(*p.RPS, 'f', -1, 64)
+		}
+		if p.USDPer1MChars != nil {
+			c.env[costEnv(name)] = strconv.FormatFloat(*p.USDPer1MChars, 'f', -1, 64)
+		}
+		if p.Concurrency != nil {
+			c.env[concurrencyEnv(name)] = strconv.Itoa(*p.Concurrency)
+		}
+		if len(p.ExtraHeaders) > 0 {
+			if !slices.Contains(extraHeaderProviders, name) {
+				return nil, fmt.Errorf("config %s: provider %q does not send requests, extra_headers don't apply", path, name)
+			}
+			value, err := extraHeadersValue(p.ExtraHeaders)
+			if err != nil {
+				return nil, fmt.Errorf("config %s: invalid %s extra_headers: %v", path, name, err)
+			}
+			c.env[settingEnv(name, "extra_headers")] = value
+		}
+	}
+	if c.Timeout != "" {
+		if _, err := time.ParseDuration(c.Timeout); err != nil {
+			return nil, fmt.Errorf("config %s: invalid timeout %q: %v", path, c.Timeout, err)
+		}
+		c.env["MCP_SAY_TIMEOUT"] = c.Timeout
+	}
+	if c.VoicesTTL != "" {
+		if _, err := time.ParseDuration(c.VoicesTTL); err != nil {
+			return nil, fmt.Errorf("config %s: invalid voices_ttl %q: %v", path, c.VoicesTTL, err)
+		}
+		c.env["MCP_SAY_VOICES_TTL"] = c.VoicesTTL
+	}
+	if c.IdleTimeout != "" {
+		if d, err := time.ParseDuration(c.IdleTimeout); err != nil || d < 0 {
+			return nil, fmt.Errorf("config %s: invalid idle_timeout %q", path, c.IdleTimeout)
+		}
+		c.env["MCP_SAY_IDLE_TIMEOUT"] = c.IdleTimeout
+	}
+	if c.IdleExit {
+		c.env["MCP_SAY_IDLE_EXIT"] = "true"
+	}
+	if c.SuppressSpeakingOutput {
+		c.env["MCP_TTS_SUPPRESS_SPEAKING_OUTPUT"] = "true"
+	}
+	if c.ShowCost {
+		c.env["MCP_SAY_SHOW_COST"] = "true"
+	}
+	if c.NoAudio {
+		c.env["MCP_SAY_NO_AUDIO"] = "true"
+	}
+	if c.Interrupt {
+		c.env["MCP_SAY_INTERRUPT"] = "true"
+	}
+	if c.Muted {
+		c.env["MCP_SAY_MUTED"] = "true"
+	}
+	if c.TextFallback {
+		c.env["MCP_SAY_TEXT_FALLBACK"] = "true"
+	}
+	if c.GoogleGRPC {
+		c.env["MCP_SAY_GOOGLE_GRPC"] = "true"
+	}
+	if c.GoogleLongAudio {
+		c.env["MCP_SAY_GOOGLE_LONG_AUDIO"] = "true"
+	}
+	c.setEnv("MCP_SAY_REPLACEMENTS_FILE", c.ReplacementsFile)
+	c.setEnv("MCP_SAY_AUDIO_ADDR", c.AudioAddr)
+	c.setEnv("MCP_SAY_AUTH_TOKEN", c.AuthToken)
+	c.setEnv("MCP_SAY_PROXY", c.Proxy)
+	c.setEnv("MCP_SAY_USER_AGENT", c.UserAgent)
+	if len(c.ExtraHeaders) > 0 {
+		value, err := extraHeadersValue(c.ExtraHeaders)
+		if err != nil {
+			return nil, fmt.Errorf("config %s: invalid extra_headers: %v", path, err)
+		}
+		c.env[extraHeadersEnv] = value
+	}
+	if c.DedupeMS != nil {
+		c.env["MCP_SAY_DEDUPE_MS"] = strconv.Itoa(*c.DedupeMS)
+	}
+	if c.HistorySize != nil {
+		if *c.HistorySize < 0 {
+			return nil, fmt.Errorf("config %s: history_size must not be negative, got %d", path, *c.HistorySize)
+		}
+		c.env["MCP_SAY_HISTORY_SIZE"] = strconv.Itoa(*c.HistorySize)
+	}
+	if c.PlaybackConcurrency != nil {
+		c.env["MCP_SAY_PLAYBACK_CONCURRENCY"] = strconv.Itoa(*c.PlaybackConcurrency)
+	}
+	if c.BufferMS != nil {
+		c.env["MCP_SAY_BUFFER_MS"] = strconv.Itoa(*c.BufferMS)
+	}
+	if c.PrebufferMS != nil {
+		c.env["MCP_SAY_PREBUFFER_MS"] = strconv.Itoa(*c.PrebufferMS)
+	}
+	if c.SampleRate != nil {
+		if rate := beep.SampleRate(*c.SampleRate); rate < say.MinSpeakerSampleRate || rate > say.MaxSpeakerSampleRate {
+			return nil, fmt.Errorf("config %s: sample_rate must be between %d and %d Hz, got %d", path, say.MinSpeakerSampleRate, say.MaxSpeakerSampleRate, *c.SampleRate)
+		}
+		c.env["MCP_SAY_SAMPLE_RATE"] = strconv.Itoa(*c.SampleRate)
+	}
+	if len(c.VoiceAliases) > 0 {
+		data, err := json.Marshal(c.VoiceAliases)
+		if err != nil {
+			return nil, fmt.Errorf("config %s: invalid voice_aliases: %v", path, err)
+		}
+		if _, err := parseVoiceAliases(string(data)); err != nil {
+			return nil, fmt.Errorf("config %s: invalid voice_aliases: %v", path, err)
+		}
+		c.env["MCP_SAY_VOICE_ALIASES"] = string(data)
+	}
+	if len(c.ProviderPolicy) > 0 {
+		var rules []string
+		for _, rule := range c.ProviderPolicy {
+			if rule.UnderChars != 0 {
+				rules = append(rules, fmt.Sprintf("%s:%d", rule.Provider, rule.UnderChars))
+			} else {
+				rules = append(rules, rule.Provider)
+			}
+		}
+		policy := strings.Join(rules, ",")
+		if _, err := parseProviderPolicy(policy); err != nil {
+			return nil, fmt.Errorf("config %s: invalid provider_policy: %v", path, err)
+		}
+		c.env["MCP_SAY_PROVIDER_POLICY"] = policy
+	}
+	switch c.ResultVerbosity {
+	case "", verbosityQuiet, verbosityNormal, verbosityVerbose:
+		c.setEnv("MCP_SAY_RESULT_VERBOSITY", c.ResultVerbosity)
+	default:
+		return nil, fmt.Errorf("config %s: invalid result_verbosity %q", path, c.ResultVerbosity)
+	}
+	if _, err := parseErrorMode(c.ErrorMode); err != nil {
+		return nil, fmt.Errorf("config %s: invalid error_mode: %v", path, err)
+	}
+	c.setEnv("MCP_SAY_ERROR_MODE", c.ErrorMode)
+	c.setEnv("MCP_SAY_ERROR_SOUND", c.ErrorSound)
+	if c.Duck {
+		c.env["MCP_SAY_DUCK"] = "true"
+	}
+	if c.WebhookURL != "" {
+		if _, err := parseWebhookURL(c.WebhookURL); err != nil {
+			return nil, fmt.Errorf("config %s: invalid webhook_url: %v", path, err)
+		}
+	}
+	c.setEnv("MCP_SAY_WEBHOOK_URL", c.WebhookURL)
+	return &c, nil
+}
+
+// extraHeadersValue validates config file headers and encodes them as MCP_SAY_EXTRA_HEADERS JSON
+func extraHeadersValue(headers map[string]string) (string, error) {
+	if err := say.ValidateExtraHeaders(headers); err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(headers)
+	return string(data), err
+}
+
+func (c *Config) setEnv(name, value string) {
+	if value != "" {
+		c.env[name] = value
+	}
+}
+
+// getenv returns the first of the named environment variables that is set, preferring
+// API keys read from _FILE variables and falling back to the config file values in the same order
+func getenv(names ...string) string {
+	for _, name := range names {
+		if value := secrets[name]; value != "" {
+			return value
+		}
+	}
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	for _, name := range names {
+		if value := config.env[name]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// providerAPIKey returns a cloud provider's API key from the environment or config file
+func providerAPIKey(provider string) string {
+	switch provider {
+	case say.ProviderGoogle:
+		return getenv("GOOGLE_AI_API_KEY", "GEMINI_API_KEY")
+	case say.ProviderElevenLabs:
+		// Requests made with the first rotation key are spread over all of them
+		if keys := elevenLabsKeys(); len(keys) > 0 {
+			return keys[0]
+		}
+	}
+	return getenv(apiKeyEnv[provider])
+}
+
+// configuredProvider returns the named provider using API keys from the environment or config file
+func configuredProvider(name string) (say.Provider, error) {
+	switch name {
+	case say.ProviderOpenAI:
+		account := openAIAccount()
+		return &say.OpenAI{APIKey: account.APIKey, Organization: account.Organization, Project: account.Project}, nil
+	case say.ProviderGoogle:
+		return googleProvider(say.GoogleDefaultPitch, nil), nil
+	case say.ProviderElevenLabs:
+		return say.NewElevenLabs(providerAPIKey(name)), nil
+	default:
+		return say.NewProvider(name)
+	}
+}
+
+// elevenLabsKeys returns the keys listed in ELEVENLABS_API_KEYS, comma separated
+func elevenLabsKeys() []string {
+	var keys []string
+	for key := range strings.SplitSeq(getenv("ELEVENLABS_API_KEYS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// callTimeout returns the per tool call timeout from MCP_SAY_TIMEOUT, or 0 for none
+func callTimeout() time.Duration {
+	value := getenv("MCP_SAY_TIMEOUT")
+	if value == "" {
+		return 0
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		log.Warn("Invalid timeout, ignoring", "env", "MCP_SAY_TIMEOUT", "value", value)
+		return 0
+	}
+	return timeout
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func useConfig(t *testing.T, c *Config) {
+	t.Helper()
+	orig := config
+	config = c
+	t.Cleanup(func() { config = orig })
+}
+
+func TestLoadConfig(t *testing.T) {
+	c, err := loadConfig(writeConfig(t, `
+providers:
+  openai:
+    api_key: sk-config
+    organization: org-config
+    voice: nova
+    rps: 0
+  google:
+    model: gemini-2.5-pro-preview-tts
+    usd_per_1m_chars: 12.5
+timeout: 90s
+show_cost: true
+history_size: 0
+provider_policy:
+  - provider: say
+    under_chars: 100
+  - provider: openai
+voice_aliases:
+  narrator:
+    provider: google
+    voice: Charon
+`))
+	require.NoError(t, err)
+	useConfig(t, c)
+
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("OPENAI_ORG_ID", "")
+	t.Setenv("OPENAI_PROJECT_ID", "proj_env")
+	t.Setenv("GOOGLE_AI_API_KEY", "")
+	t.Setenv("GEMINI_API_KEY", "")
+	t.Setenv(settingEnv(say.ProviderOpenAI, "voice"), "")
+	t.Setenv(settingEnv(say.ProviderGoogle, "model"), "")
+	t.Setenv(rateLimitEnv(say.ProviderOpenAI), "")
+	t.Setenv(costEnv(say.ProviderGoogle), "")
+	t.Setenv("MCP_SAY_TIMEOUT", "")
+	t.Setenv("MCP_SAY_SHOW_COST", "")
+	t.Setenv("MCP_SAY_PROVIDER_POLICY", "")
+	t.Setenv("MCP_SAY_VOICE_ALIASES", "")
+	t.Setenv("MCP_SAY_HISTORY_SIZE", "")
+
+	assert.Equal(t, "sk-config", providerAPIKey(say.ProviderOpenAI))
+	assert.Empty(t, providerAPIKey(say.ProviderGoogle))
+	assert.Equal(t, say.OpenAIAccount{APIKey: "sk-config", Organization: "org-config", Project: "proj_env"}, openAIAccount())
+	assert.Equal(t, "nova", providerSetting(nil, say.ProviderOpenAI, "voice", say.DefaultOpenAIVoice))
+	assert.Equal(t, "gemini-2.5-pro-preview-tts", providerSetting(nil, say.ProviderGoogle, "model", say.DefaultGoogleModel))
+	assert.Equal(t, "0", getenv(rateLimitEnv(say.ProviderOpenAI)))
+	assert.Equal(t, 12.5, costPerMillionChars(say.ProviderGoogle, ""))
+	assert.Equal(t, 90*time.Second, callTimeout())
+	assert.Equal(t, "true", getenv("MCP_SAY_SHOW_COST"))
+	assert.Equal(t, "say:100,openai", getenv("MCP_SAY_PROVIDER_POLICY"))
+	assert.JSONEq(t, `{"narrator": {"provider": "google", "voice": "Charon"}}`, getenv("MCP_SAY_VOICE_ALIASES"))
+	assert.Equal(t, -1, (&utteranceHistory{}).capacity(), "history_size 0 turns the history off")
+
+	// Environment variables win over the config file
+	t.Setenv("OPENAI_API_KEY", "sk-env")
+	t.Setenv(settingEnv(say.ProviderOpenAI, "voice"), "alloy")
+	t.Setenv("MCP_SAY_TIMEOUT", "10s")
+	assert.Equal(t, "sk-env", providerAPIKey(say.ProviderOpenAI))
+	assert.Equal(t, "alloy", providerSetting(nil, say.ProviderOpenAI, "voice", say.DefaultOpenAIVoice))
+	assert.Equal(t, 10*time.Second, callTimeout())
+
+	// Arguments still win over both
+	assert.Equal(t, "echo", providerSetting(map[string]any{"voice": "echo"}, say.ProviderOpenAI, "voice", say.DefaultOpenAIVoice))
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{"unknown field", "providers:\n  openai:\n    voise: nova\n", "field voise not found"},
+		{"unknown provider", "providers:\n  polly:\n    voice: Joanna\n", `unknown provider "polly"`},
+		{"say api key", "providers:\n  say:\n    api_key: nope\n", "does not use an API key"},
+		{"google organization", "providers:\n  google:\n    organization: org-1\n", "does not use an organization or project"},
+		{"bad timeout", "timeout: soon\n", "invalid timeout"},
+		{"alias without voice", "voice_aliases:\n  narrator:\n    provider: google\n", "invalid voice_aliases"},
+		{"policy without catch-all", "provider_policy:\n  - provider: say\n    under_chars: 100\n", "invalid provider_policy"},
+		{"authorization header", "extra_headers:\n  authorization: Bearer x\n", "Authorization is set by the provider client"},
+		{"say extra headers", "providers:\n  say:\n    extra_headers:\n      X-Team-Id: t1\n", "extra_headers don't apply"},
+		{"bad error mode", "error_mode: panic\n", "invalid error_mode"},
+		{"negative history size", "history_size: -1\n", "history_size must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadConfig(writeConfig(t, tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+
+	_, err := loadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read config")
+}
+
+func TestElevenLabsKeys(t *testing.T) {
+	t.Setenv("ELEVENLABS_API_KEY", "el-single")
+	t.Setenv("ELEVENLABS_API_KEYS", "")
+	assert.Empty(t, elevenLabsKeys())
+	assert.Equal(t, "el-single", providerAPIKey(say.ProviderElevenLabs))
+
+	t.Setenv("ELEVENLABS_API_KEYS", " el-1, el-2,,el-3 ")
+	assert.Equal(t, []string{"el-1", "el-2", "el-3"}, elevenLabsKeys())
+	assert.Equal(t, "el-1", providerAPIKey(say.ProviderElevenLabs))
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultCostPerMillionChars is the approximate USD price of synthesizing one million
+// characters, keyed by provider or "provider/model" when a model is priced differently.
+// Override per provider with MCP_SAY_<PROVIDER>_USD_PER_1M_CHARS.
+var defaultCostPerMillionChars = map[string]float64{
+	say.ProviderOpenAI:               15, // ~$0.015 per minute of audio
+	say.ProviderOpenAI + "/tts-1":    15,
+	say.ProviderOpenAI + "/tts-1-hd": 30,
+	say.ProviderGoogle:               16, // $10 per 1M audio tokens at 25 tokens per second
+	say.ProviderElevenLabs:           300,
+	say.ProviderSay:                  0,
+}
+
+// costEnv is the environment variable that overrides a provider's price
+func costEnv(provider string) string {
+	return fmt.Sprintf("MCP_SAY_%s_USD_PER_1M_CHARS", strings.ToUpper(provider))
+}
+
+// costPerMillionChars returns the price for a provider and model
+func costPerMillionChars(provider, model string) float64 {
+	if value := getenv(costEnv(provider)); value != "" {
+		if price, err := strconv.ParseFloat(value, 64); err == nil && price >= 0 {
+			return price
+		}
+		log.Warn("Invalid price, using default", "env", costEnv(provider), "value", value)
+	}
+	if price, ok := defaultCostPerMillionChars[provider+"/"+model]; ok {
+		return price
+	}
+	return defaultCostPerMillionChars[provider]
+}
+
+// estimateCost returns the approximate USD cost of synthesizing text
+func estimateCost(provider, model, text string) float64 {
+	return float64(utf8.RuneCountInString(text)) * costPerMillionChars(provider, model) / 1e6
+}
+
+// providerCost accumulates usage for one provider
+type providerCost struct {
+	Requests   int
+	Characters int
+	USD        float64
+}
+
+func (c *providerCost) add(chars int, usd float64) {
+	c.Requests++
+	c.Characters += chars
+	c.USD += usd
+}
+
+// costTracker accumulates estimated costs per provider for the session
+type costTracker struct {
+	mu        sync.Mutex
+	since     time.Time
+	providers map[string]*providerCost
+}
+
+func newCostTracker() *costTracker {
+	return &costTracker{since: time.Now(), providers: make(map[string]*providerCost)}
+}
+
+// Global session cost totals
+var sessionCosts = newCostTracker()
+
+func (t *costTracker) add(provider string, chars int, usd float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	total, ok := t.providers[provider]
+	if !ok {
+		total = &providerCost{}
+		t.providers[provider] = total
+	}
+	total.add(chars, usd)
+}
+
+func (t *costTracker) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.since = time.Now()
+	t.providers = make(map[string]*providerCost)
+}
+
+// report renders the totals per provider, most expensive first
+func (t *costTracker) report() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.providers) == 0 {
+		return fmt.Sprintf("No TTS requests since %s", t.since.Format(time.RFC3339))
+	}
+	names := make([]string, 0, len(t.providers))
+	for name := range t.providers {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		if d := t.providers[b].USD - t.providers[a].USD; d != 0 {
+			if d > 0 {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a, b)
+	})
+
+	var (
+		sb    strings.Builder
+		total providerCost
+	)
+	fmt.Fprintf(&sb, "Estimated TTS cost since %s:\n", t.since.Format(time.RFC3339))
+	for _, name := range names {
+		c := t.providers[name]
+		fmt.Fprintf(&sb, "• %s: $%.4f (%d requests, %d characters)\n", name, c.USD, c.Requests, c.Characters)
+		total.Requests += c.Requests
+		total.Characters += c.Characters
+		total.USD += c.USD
+	}
+	fmt.Fprintf(&sb, "Total: $%.4f (%d requests, %d characters)", total.USD, total.Requests, total.Characters)
+	return sb.String()
+}
+
+type callCostKey struct{}
+
+// withCallCost returns a context that collects the cost of a single tool call
+func withCallCost(ctx context.Context) (context.Context, *costTracker) {
+	call := newCostTracker()
+	return context.WithValue(ctx, callCostKey{}, call), call
+}
+
+// recordCost adds a synthesis request to the session totals and the current call, if any
+func recordCost(ctx context.Context, provider, model, text string) {
+	chars := utf8.RuneCountInString(text)
+	usd := estimateCost(provider, model, text)
+	log.Debug("Recorded TTS cost", "provider", provider, "characters", chars, "usd", usd)
+	sessionCosts.add(provider, chars, usd)
+	if call, ok := ctx.Value(callCostKey{}).(*costTracker); ok {
+		call.add(provider, chars, usd)
+	}
+}
+
+// costLine summarizes a single call's cost, or returns "" if it made no requests
+func (t *costTracker) costLine() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var total providerCost
+	var names []string
+	for name, c := range t.providers {
+		names = append(names, name)
+		total.Characters += c.Characters
+		total.USD += c.USD
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	slices.Sort(names)
+	return fmt.Sprintf("Estimated cost: $%.4f (%d characters via %s)", total.USD, total.Characters, strings.Join(names, ", "))
+}
+
+// WithCostReport wraps a tool handler to append the call's estimated cost to its result
+// when --show-cost is enabled
+func WithCostReport(handler ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, call := withCallCost(ctx)
+		result, err := handler(ctx, request)
+		if showCost && err == nil && result != nil {
+			if line := call.costLine(); line != "" {
+				result.Content = append(result.Content, mcp.NewTextContent(line))
+			}
+		}
+		return result, err
+	}
+}
+
+// handleCostStats reports the estimated session cost per provider
+func handleCostStats(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Cost stats tool called", "request", request)
+	report := sessionCosts.report()
+	if reset, _ := request.GetArguments()["reset"].(bool); reset {
+		sessionCosts.reset()
+		report += "\nTotals have been reset"
+	}
+	return mcp.NewToolResultText(report), nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateCost(t *testing.T) {
+	text := strings.Repeat("a", 1000)
+	assert.InDelta(t, 0.015, estimateCost(say.ProviderOpenAI, "tts-1", text), 1e-9)
+	assert.InDelta(t, 0.030, estimateCost(say.ProviderOpenAI, "tts-1-hd", text), 1e-9)
+	assert.InDelta(t, 0.015, estimateCost(say.ProviderOpenAI, "", text), 1e-9)
+	assert.Zero(t, estimateCost(say.ProviderSay, "", text))
+
+	t.Setenv("MCP_SAY_ELEVENLABS_USD_PER_1M_CHARS", "165")
+	assert.InDelta(t, 0.165, estimateCost(say.ProviderElevenLabs, "", text), 1e-9)
+}
+
+func TestCostTracker(t *testing.T) {
+	tracker := newCostTracker()
+	assert.Contains(t, tracker.report(), "No TTS requests")
+
+	tracker.add(say.ProviderOpenAI, 100, 0.0015)
+	tracker.add(say.ProviderElevenLabs, 100, 0.03)
+	tracker.add(say.ProviderOpenAI, 200, 0.003)
+
+	report := tracker.report()
+	assert.Contains(t, report, "• elevenlabs: $0.0300 (1 requests, 100 characters)\n• openai: $0.0045 (2 requests, 300 characters)")
+	assert.Contains(t, report, "Total: $0.0345 (3 requests, 400 characters)")
+
+	tracker.reset()
+	assert.Contains(t, tracker.report(), "No TTS requests")
+}
+
+func TestWithCostReport(t *testing.T) {
+	prev := showCost
+	showCost = true
+	t.Cleanup(func() { showCost = prev })
+
+	handler := WithCostReport(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		recordCost(ctx, say.ProviderOpenAI, "tts-1-hd", "Hello world")
+		return mcp.NewToolResultText("Speaking: Hello world"), nil
+	})
+
+	result, err := handler(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.Len(t, result.Content, 2)
+	assert.Equal(t, "Estimated cost: $0.0003 (11 characters via openai)", result.Content[1].(mcp.TextContent).Text)
+}
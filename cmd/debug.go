@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// debugTools are the tools debug_request can build a provider request for
+var debugTools = map[string]ToolHandlerFunc{
+	"openai_tts":     WithVoiceAlias(say.ProviderOpenAI, handleOpenAITTS),
+	"google_tts":     WithVoiceAlias(say.ProviderGoogle, handleGoogleTTS),
+	"elevenlabs_tts": WithVoiceAlias(say.ProviderElevenLabs, handleElevenLabsTTS),
+	"sound_effect":   handleSoundEffect,
+}
+
+// formatCapturedRequest writes a request like an HTTP message, with an indented JSON body
+func formatCapturedRequest(sb *strings.Builder, req say.CapturedRequest) {
+	fmt.Fprintf(sb, "%s %s\n", req.Method, req.URL)
+	for _, name := range slices.Sorted(maps.Keys(req.Header)) {
+		for _, value := range req.Header[name] {
+			fmt.Fprintf(sb, "%s: %s\n", name, value)
+		}
+	}
+	if len(req.Body) == 0 {
+		return
+	}
+	sb.WriteString("\n")
+	var body bytes.Buffer
+	if json.Indent(&body, req.Body, "", "  ") == nil {
+		sb.Write(body.Bytes())
+	} else {
+		sb.Write(req.Body)
+	}
+	sb.WriteString("\n")
+}
+
+// handleDebugRequest runs a TTS tool in a dry run and returns the provider request it
+// would send, with credentials redacted. Nothing is sent, played or saved.
+func handleDebugRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Debug request tool called", "request", request)
+	arguments := request.GetArguments()
+	tool, _ := arguments["tool"].(string)
+	handler, ok := debugTools[tool]
+	if !ok {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: tool must be one of %s", strings.Join(slices.Sorted(maps.Keys(debugTools)), ", ")))
+		result.IsError = true
+		return result, nil
+	}
+	toolArguments, _ := arguments["arguments"].(map[string]any)
+	toolArguments = maps.Clone(toolArguments)
+	if toolArguments == nil {
+		toolArguments = map[string]any{}
+	}
+	// Never touch the speaker or the filesystem, the call stops at the first request anyway
+	delete(toolArguments, "output_file")
+	toolArguments["return_audio"] = true
+
+	ctx, captured := say.WithDryRun(ctx)
+	call := mcp.CallToolRequest{}
+	call.Params.Name = tool
+	call.Params.Arguments = toolArguments
+	result, err := handler(ctx, call)
+	requests := captured()
+	if len(requests) == 0 {
+		if err != nil || (result != nil && result.IsError) {
+			return result, err
+		}
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %s made no provider request", tool))
+		result.IsError = true
+		return result, nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Request %s would send (not sent, credentials redacted):\n\n", tool)
+	for i, req := range requests {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		formatCapturedRequest(&sb, req)
+	}
+	return mcp.NewToolResultText(strings.TrimSpace(sb.String())), nil
+}
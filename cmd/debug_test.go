@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleDebugRequest(t *testing.T) {
+	mock := useMockPlayer(t)
+	t.Setenv("OPENAI_API_KEY", "sk-secret")
+	t.Setenv("ELEVENLABS_API_KEY", "xi-secret")
+
+	call := func(tool string, arguments map[string]any) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"tool": tool, "arguments": arguments}
+		result, err := handleDebugRequest(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := call("openai_tts", map[string]any{"text": "Hello", "voice": "nova", "model": "tts-1"})
+	require.False(t, result.IsError, result.Content)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "POST https://api.openai.com/v1/audio/speech\n")
+	assert.Contains(t, text, "Authorization: REDACTED\n")
+	assert.Contains(t, text, `"voice": "nova"`)
+	assert.Contains(t, text, `"input": "Hello"`)
+	assert.NotContains(t, text, "sk-secret")
+
+	result = call("elevenlabs_tts", map[string]any{"text": "Hello", "voice": "voice-id", "output_file": "/nonexistent/out.mp3"})
+	require.False(t, result.IsError, result.Content)
+	text = result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "/v1/text-to-speech/voice-id/stream")
+	assert.Contains(t, text, "Xi-Api-Key: REDACTED\n")
+	assert.NotContains(t, text, "xi-secret")
+	assert.False(t, mock.Played)
+
+	t.Setenv("GOOGLE_AI_API_KEY", "ai-secret")
+	result = call("google_tts", map[string]any{"text": "Hello", "temperature": 0.5, "seed": 42.0})
+	require.False(t, result.IsError, result.Content)
+	text = result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, `"temperature": 0.5`)
+	assert.Contains(t, text, `"seed": 42`)
+	assert.NotContains(t, text, "ai-secret")
+
+	// Argument errors come back as the tool would return them
+	result = call("openai_tts", map[string]any{})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "text")
+
+	result = call("say_tts", map[string]any{"text": "Hello"})
+	assert.True(t, result.IsError)
+	assert.Equal(t, "Error: tool must be one of elevenlabs_tts, google_tts, openai_tts, sound_effect", result.Content[0].(mcp.TextContent).Text)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// dedupeKey identifies an utterance for duplicate detection
+type dedupeKey struct {
+	provider string
+	voice    string
+	text     string
+}
+
+// dedupeTracker remembers when recent utterances started, so a repeat can be skipped
+type dedupeTracker struct {
+	mu     sync.Mutex
+	recent map[dedupeKey]time.Time
+}
+
+// Utterances spoken within the dedupe window
+var spokenRecently = &dedupeTracker{recent: make(map[dedupeKey]time.Time)}
+
+// dedupeWindow returns MCP_SAY_DEDUPE_MS as a duration, 0 when duplicates are not skipped
+func dedupeWindow() time.Duration {
+	value := getenv("MCP_SAY_DEDUPE_MS")
+	if value == "" {
+		return 0
+	}
+	ms, err := strconv.Atoi(value)
+	if err != nil || ms < 0 {
+		log.Warn("Invalid dedupe window, duplicates are not skipped", "env", "MCP_SAY_DEDUPE_MS", "value", value)
+		return 0
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// claim records key as starting now and returns that time. ok is false when the same
+// utterance started within window, entries older than that are dropped.
+func (t *dedupeTracker) claim(key dedupeKey, window time.Duration) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	for k, at := range t.recent {
+		if now.Sub(at) >= window {
+			delete(t.recent, k)
+		}
+	}
+	if _, ok := t.recent[key]; ok {
+		return time.Time{}, false
+	}
+	t.recent[key] = now
+	return now, true
+}
+
+// release forgets the claim made at at, so a failed utterance can be retried right away
+func (t *dedupeTracker) release(key dedupeKey, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.recent[key].Equal(at) {
+		delete(t.recent, key)
+	}
+}
+
+// WithDedupe skips a call that would speak the same text with the same provider and voice
+// as a call started within MCP_SAY_DEDUPE_MS, e.g. an agent retrying a call that worked.
+// provider is the tool's own provider, "" reads it from the provider argument.
+// Calls that save or return the audio, or write captions, are never skipped.
+func WithDedupe(provider string, handler ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		window := dedupeWindow()
+		arguments := request.GetArguments()
+		file, _ := arguments["output_file"].(string)
+		inline, _ := arguments["return_audio"].(bool)
+		captions, _ := arguments["vtt_output"].(string)
+		if window <= 0 || file != "" || inline || captions != "" {
+			return handler(ctx, request)
+		}
+		text, err := textArgument(arguments)
+		if ssml, ok := arguments["ssml"].(string); ok {
+			text, err = ssml, nil
+		}
+		if err != nil || text == "" {
+			return handler(ctx, request)
+		}
+		name := provider
+		if name == "" {
+			name, _ = arguments["provider"].(string)
+		}
+		key := dedupeKey{provider: name, voice: providerSetting(arguments, name, "voice", ""), text: text}
+
+		at, ok := spokenRecently.claim(key, window)
+		if !ok {
+			log.Info("Skipped duplicate speech", "provider", key.provider, "voice", key.voice, "window", window)
+			return mcp.NewToolResultText("Skipped duplicate: the same text was spoken within the last " + window.String()), nil
+		}
+		result, err := handler(ctx, request)
+		if err != nil || result == nil || result.IsError || ctx.Err() != nil {
+			spokenRecently.release(key, at)
+		}
+		return result, err
+	}
+}
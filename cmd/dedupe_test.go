@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDedupe(t *testing.T) {
+	prev := spokenRecently
+	spokenRecently = &dedupeTracker{recent: make(map[dedupeKey]time.Time)}
+	t.Cleanup(func() { spokenRecently = prev })
+	t.Setenv("MCP_SAY_DEDUPE_MS", "200")
+
+	var calls int
+	fail := false
+	handler := WithDedupe("openai", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		if fail {
+			result := mcp.NewToolResultText("Error: provider failed")
+			result.IsError = true
+			return result, nil
+		}
+		return mcp.NewToolResultText("Speaking"), nil
+	})
+	call := func(arguments map[string]any) string {
+		result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: arguments}})
+		require.NoError(t, err)
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	assert.Equal(t, "Speaking", call(map[string]any{"text": "Hello", "voice": "nova"}))
+	assert.Equal(t, "Skipped duplicate: the same text was spoken within the last 200ms", call(map[string]any{"text": "Hello", "voice": "nova"}))
+	assert.Equal(t, 1, calls)
+
+	// Another voice, other text or saving the audio is not a duplicate
+	call(map[string]any{"text": "Hello", "voice": "alloy"})
+	call(map[string]any{"text": "Hello again", "voice": "nova"})
+	call(map[string]any{"text": "Hello", "voice": "nova", "return_audio": true})
+	assert.Equal(t, 4, calls)
+
+	// A failed call can be retried right away
+	fail = true
+	call(map[string]any{"text": "Oops", "voice": "nova"})
+	fail = false
+	assert.Equal(t, "Speaking", call(map[string]any{"text": "Oops", "voice": "nova"}))
+
+	// After the window the text is spoken again
+	time.Sleep(250 * time.Millisecond)
+	assert.Equal(t, "Speaking", call(map[string]any{"text": "Hello", "voice": "nova"}))
+
+	// Off by default
+	t.Setenv("MCP_SAY_DEDUPE_MS", "")
+	assert.Equal(t, "Speaking", call(map[string]any{"text": "Hello", "voice": "nova"}))
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+
+	"github.com/blacktop/mcp-tts/say"
+)
+
+// defaultVoices are the fallback voices per provider set with --default-voice
+var defaultVoices map[string]string
+
+// settingEnv is the environment variable holding a provider's default for a setting,
+// e.g. MCP_SAY_OPENAI_VOICE
+func settingEnv(provider, setting string) string {
+	return fmt.Sprintf("MCP_SAY_%s_%s", strings.ToUpper(provider), strings.ToUpper(setting))
+}
+
+// providerSetting resolves a provider setting with the precedence
+// explicit argument > --default-voice > MCP_SAY_<PROVIDER>_<SETTING> > built-in fallback
+func providerSetting(arguments map[string]any, provider, setting, fallback string) string {
+	if value, ok := arguments[setting].(string); ok && value != "" {
+		return value
+	}
+	if value := defaultVoices[provider]; setting == "voice" && value != "" {
+		return value
+	}
+	if value := getenv(settingEnv(provider, setting)); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// validateDefaultVoices checks the --default-voice entries before the server starts
+func validateDefaultVoices() error {
+	for provider, voice := range defaultVoices {
+		if voice == "" {
+			return fmt.Errorf("--default-voice %s: voice must not be empty", provider)
+		}
+		switch provider {
+		case say.ProviderOpenAI, say.ProviderElevenLabs:
+		case say.ProviderGoogle:
+			if _, err := say.ParseGoogleVoice(voice); err != nil {
+				return fmt.Errorf("--default-voice %s: %v", provider, err)
+			}
+		case say.ProviderSay:
+			if !say.ValidSayVoice(voice) {
+				return fmt.Errorf("--default-voice %s: voice contains invalid characters: %s", provider, voice)
+			}
+		default:
+			return fmt.Errorf("--default-voice: unknown provider %q", provider)
+		}
+	}
+	return nil
+}
+
+// activeDefaultVoice is the voice a provider uses when a call omits voice, for tool descriptions
+func activeDefaultVoice(provider string) string {
+	switch provider {
+	case say.ProviderOpenAI:
+		return providerSetting(nil, provider, "voice", say.DefaultOpenAIVoice)
+	case say.ProviderGoogle:
+		return providerSetting(nil, provider, "voice", say.DefaultGoogleVoice)
+	case say.ProviderElevenLabs:
+		return providerSetting(nil, provider, "voice", cmp.Or(getenv("ELEVENLABS_VOICE_ID"), say.DefaultElevenLabsVoiceID))
+	default:
+		return providerSetting(nil, provider, "voice", "")
+	}
+}
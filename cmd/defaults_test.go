@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderSettingPrecedence(t *testing.T) {
+	tests := []struct {
+		provider string
+		setting  string
+		builtin  string
+	}{
+		{say.ProviderOpenAI, "voice", say.DefaultOpenAIVoice},
+		{say.ProviderOpenAI, "model", say.DefaultOpenAIModel},
+		{say.ProviderGoogle, "voice", say.DefaultGoogleVoice},
+		{say.ProviderGoogle, "model", say.DefaultGoogleModel},
+		{say.ProviderElevenLabs, "voice", ""},
+		{say.ProviderElevenLabs, "model", ""},
+		{say.ProviderSay, "voice", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider+"/"+tt.setting, func(t *testing.T) {
+			env := settingEnv(tt.provider, tt.setting)
+
+			t.Setenv(env, "")
+			assert.Equal(t, tt.builtin, providerSetting(map[string]any{}, tt.provider, tt.setting, tt.builtin), "built-in default")
+
+			t.Setenv(env, "from-env")
+			assert.Equal(t, "from-env", providerSetting(map[string]any{}, tt.provider, tt.setting, tt.builtin), "env overrides built-in")
+			assert.Equal(t, "from-env", providerSetting(map[string]any{tt.setting: ""}, tt.provider, tt.setting, tt.builtin), "empty argument is omitted")
+
+			arguments := map[string]any{tt.setting: "from-arg"}
+			assert.Equal(t, "from-arg", providerSetting(arguments, tt.provider, tt.setting, tt.builtin), "argument overrides env")
+		})
+	}
+}
+
+func TestSettingEnv(t *testing.T) {
+	assert.Equal(t, "MCP_SAY_OPENAI_VOICE", settingEnv(say.ProviderOpenAI, "voice"))
+	assert.Equal(t, "MCP_SAY_ELEVENLABS_MODEL", settingEnv(say.ProviderElevenLabs, "model"))
+}
+
+func TestDefaultVoiceFlag(t *testing.T) {
+	t.Cleanup(func() { defaultVoices = nil })
+	t.Setenv(settingEnv(say.ProviderOpenAI, "voice"), "from-env")
+	t.Setenv("ELEVENLABS_VOICE_ID", "")
+
+	defaultVoices = map[string]string{say.ProviderOpenAI: "nova"}
+	require.NoError(t, validateDefaultVoices())
+	assert.Equal(t, "nova", providerSetting(map[string]any{}, say.ProviderOpenAI, "voice", say.DefaultOpenAIVoice), "flag overrides env")
+	assert.Equal(t, "shimmer", providerSetting(map[string]any{"voice": "shimmer"}, say.ProviderOpenAI, "voice", say.DefaultOpenAIVoice), "argument overrides flag")
+	assert.Equal(t, say.DefaultOpenAIModel, providerSetting(map[string]any{}, say.ProviderOpenAI, "model", say.DefaultOpenAIModel), "only voices are affected")
+
+	assert.Equal(t, "nova", activeDefaultVoice(say.ProviderOpenAI))
+	assert.Equal(t, say.DefaultGoogleVoice, activeDefaultVoice(say.ProviderGoogle))
+	assert.Equal(t, say.DefaultElevenLabsVoiceID, activeDefaultVoice(say.ProviderElevenLabs))
+	assert.Empty(t, activeDefaultVoice(say.ProviderSay))
+
+	for _, invalid := range []map[string]string{
+		{"azure": "en-US-JennyNeural"},
+		{say.ProviderGoogle: "not a voice!"},
+		{say.ProviderSay: "Alex; rm -rf"},
+		{say.ProviderOpenAI: ""},
+	} {
+		defaultVoices = invalid
+		assert.Error(t, validateDefaultVoices(), "%v", invalid)
+	}
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// voiceDescription is what describe_voice knows about a voice. Fields the provider
+// doesn't publish for the voice are left out.
+type voiceDescription struct {
+	Provider string `json:"provider"`
+	Voice    string `json:"voice"`
+	Name     string `json:"name,omitempty"`
+	Gender   string `json:"gender,omitempty"`
+	Age      string `json:"age,omitempty"`
+	Accent   string `json:"accent,omitempty"`
+	// Locale is the BCP-47 locale the voice is pinned to, empty when it follows the text
+	Locale      string            `json:"locale,omitempty"`
+	Style       string            `json:"style,omitempty"`
+	UseCase     string            `json:"use_case,omitempty"`
+	Category    string            `json:"category,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	PreviewURL  string            `json:"preview_url,omitempty"`
+	Note        string            `json:"note,omitempty"`
+}
+
+// tableVoice is an entry of the embedded OpenAI and Gemini voice tables
+type tableVoice struct {
+	Gender string
+	Style  string
+	Note   string
+}
+
+// openAIVoiceTable describes the OpenAI voices, the API publishes no metadata for them
+var openAIVoiceTable = map[string]tableVoice{
+	"alloy":   {Style: "balanced"},
+	"ash":     {},
+	"ballad":  {Note: "gpt-4o-mini-tts only"},
+	"coral":   {Style: "warm and natural"},
+	"echo":    {Style: "warm and engaging"},
+	"fable":   {Style: "expressive, storytelling"},
+	"nova":    {Style: "bright and articulate"},
+	"onyx":    {Style: "deep and resonant"},
+	"sage":    {},
+	"shimmer": {Style: "smooth and pleasant"},
+	"verse":   {Note: "gpt-4o-mini-tts only"},
+}
+
+// geminiVoiceTable describes the Gemini TTS voices with Google's style for each and the
+// gender of the matching Chirp 3 HD voice
+var geminiVoiceTable = map[string]tableVoice{
+	"Achernar":      {Gender: "female", Style: "soft"},
+	"Achird":        {Gender: "male", Style: "friendly"},
+	"Algenib":       {Gender: "male", Style: "gravelly"},
+	"Algieba":       {Gender: "male", Style: "smooth"},
+	"Alnilam":       {Gender: "male", Style: "firm"},
+	"Aoede":         {Gender: "female", Style: "breezy"},
+	"Autonoe":       {Gender: "female", Style: "bright"},
+	"Callirrhoe":    {Gender: "female", Style: "easy-going"},
+	"Charon":        {Gender: "male", Style: "informative"},
+	"Despina":       {Gender: "female", Style: "smooth"},
+	"Enceladus":     {Gender: "male", Style: "breathy"},
+	"Erinome":       {Gender: "female", Style: "clear"},
+	"Fenrir":        {Gender: "male", Style: "excitable"},
+	"Gacrux":        {Gender: "female", Style: "mature"},
+	"Iapetus":       {Gender: "male", Style: "clear"},
+	"Kore":          {Gender: "female", Style: "firm"},
+	"Laomedeia":     {Gender: "female", Style: "upbeat"},
+	"Leda":          {Gender: "female", Style: "youthful"},
+	"Orus":          {Gender: "male", Style: "firm"},
+	"Puck":          {Gender: "male", Style: "upbeat"},
+	"Pulcherrima":   {Gender: "female", Style: "forward"},
+	"Rasalgethi":    {Gender: "male", Style: "informative"},
+	"Sadachbia":     {Gender: "male", Style: "lively"},
+	"Sadaltager":    {Gender: "male", Style: "knowledgeable"},
+	"Schedar":       {Gender: "male", Style: "even"},
+	"Sulafat":       {Gender: "female", Style: "warm"},
+	"Umbriel":       {Gender: "male", Style: "easy-going"},
+	"Vindemiatrix":  {Gender: "female", Style: "gentle"},
+	"Zephyr":        {Gender: "female", Style: "bright"},
+	"Zubenelgenubi": {Gender: "male", Style: "casual"},
+}
+
+// lookupTableVoice finds voice in table ignoring case, returning its canonical name
+func lookupTableVoice(table map[string]tableVoice, voice string) (string, tableVoice, bool) {
+	for name, entry := range table {
+		if strings.EqualFold(name, voice) {
+			return name, entry, true
+		}
+	}
+	return "", tableVoice{}, false
+}
+
+func describeOpenAIVoice(voice string) (voiceDescription, error) {
+	name, entry, ok := lookupTableVoice(openAIVoiceTable, voice)
+	if !ok {
+		return voiceDescription{}, fmt.Errorf("unknown OpenAI voice %q, use one of: %s", voice, strings.Join(slices.Sorted(maps.Keys(openAIVoiceTable)), ", "))
+	}
+	return voiceDescription{
+		Provider: say.ProviderOpenAI,
+		Voice:    name,
+		Name:     name,
+		Style:    entry.Style,
+		Note:     entry.Note,
+	}, nil
+}
+
+func describeGoogleVoice(voice string) (voiceDescription, error) {
+	parsed, err := say.ParseGoogleVoice(voice)
+	if err != nil {
+		return voiceDescription{}, err
+	}
+	name, entry, ok := lookupTableVoice(geminiVoiceTable, parsed.Name)
+	if !ok {
+		return voiceDescription{}, fmt.Errorf("unknown Gemini voice %q, use one of: %s", parsed.Name, strings.Join(slices.Sorted(maps.Keys(geminiVoiceTable)), ", "))
+	}
+	description := voiceDescription{
+		Provider: say.ProviderGoogle,
+		Voice:    voice,
+		Name:     name,
+		Gender:   entry.Gender,
+		Locale:   parsed.LanguageCode,
+		Style:    entry.Style,
+	}
+	if parsed.LanguageCode == "" {
+		description.Note = "speaks the language of the text, add a locale prefix like en-US-" + name + " to pin it"
+	}
+	return description, nil
+}
+
+// describeElevenLabsVoice looks voice up by ID or name in the cached voices list,
+// fetching it when the cache is empty or expired
+func describeElevenLabsVoice(ctx context.Context, voice string) (voiceDescription, string, error) {
+	voices, warning, err := elevenLabsVoices.get(ctx, false)
+	if err != nil {
+		return voiceDescription{}, "", withProviderHint(err)
+	}
+	i := slices.IndexFunc(voices, func(v say.ElevenLabsVoice) bool { return v.ID == voice })
+	if i < 0 {
+		i = slices.IndexFunc(voices, func(v say.ElevenLabsVoice) bool { return strings.EqualFold(v.Name, voice) })
+	}
+	if i < 0 {
+		return voiceDescription{}, "", fmt.Errorf("ElevenLabs voice %q is not on the account, run list_voices for the IDs or refresh_voices after adding a voice", voice)
+	}
+	v := voices[i]
+	labels := maps.Clone(v.Labels)
+	take := func(keys ...string) string {
+		for _, key := range keys {
+			if value, ok := labels[key]; ok {
+				delete(labels, key)
+				return value
+			}
+		}
+		return ""
+	}
+	description := voiceDescription{
+		Provider:    say.ProviderElevenLabs,
+		Voice:       v.ID,
+		Name:        v.Name,
+		Gender:      take("gender"),
+		Age:         take("age"),
+		Accent:      take("accent"),
+		UseCase:     take("use_case", "use case"),
+		Category:    v.Category,
+		Description: v.Description,
+		PreviewURL:  v.PreviewURL,
+	}
+	if d := take("description"); description.Description == "" {
+		description.Description = d
+	}
+	if len(labels) > 0 {
+		description.Labels = labels
+	}
+	return description, warning, nil
+}
+
+// handleDescribeVoice returns a voice's metadata as JSON: the ElevenLabs labels from the
+// voices list, or the embedded tables for OpenAI and Gemini voices
+func handleDescribeVoice(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Describe voice tool called", "request", request)
+
+	provider := request.GetString("provider", "")
+	voice := request.GetString("voice", "")
+	if voice == "" {
+		voice = activeDefaultVoice(provider)
+	}
+
+	var (
+		description voiceDescription
+		warning     string
+		err         error
+	)
+	switch provider {
+	case say.ProviderOpenAI:
+		description, err = describeOpenAIVoice(voice)
+	case say.ProviderGoogle:
+		description, err = describeGoogleVoice(voice)
+	case say.ProviderElevenLabs:
+		description, warning, err = describeElevenLabsVoice(ctx, voice)
+	default:
+		err = fmt.Errorf("unknown provider %q (use google, openai or elevenlabs)", provider)
+	}
+	if err == nil {
+		var data []byte
+		if data, err = json.MarshalIndent(description, "", "  "); err == nil {
+			text := string(data)
+			if warning != "" {
+				text = warning + "\n" + text
+			}
+			return mcp.NewToolResultText(text), nil
+		}
+	}
+	log.Error("Failed to describe voice", "provider", provider, "voice", voice, "error", err)
+	result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+	result.IsError = true
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// describeVoice calls describe_voice and decodes a successful result
+func describeVoice(t *testing.T, arguments map[string]any) voiceDescription {
+	t.Helper()
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = arguments
+	result, err := WithVoiceAlias("", handleDescribeVoice)(context.Background(), request)
+	require.NoError(t, err)
+	text := result.Content[0].(mcp.TextContent).Text
+	require.False(t, result.IsError, text)
+	var description voiceDescription
+	require.NoError(t, json.Unmarshal([]byte(text), &description))
+	return description
+}
+
+func TestDescribeVoiceElevenLabs(t *testing.T) {
+	var fetches int
+	useVoiceCache(t, func(ctx context.Context) ([]say.ElevenLabsVoice, error) {
+		fetches++
+		return []say.ElevenLabsVoice{{
+			ID:       "v1",
+			Name:     "Rachel",
+			Category: "premade",
+			Labels:   map[string]string{"accent": "american", "age": "young", "gender": "female", "use case": "narration", "description": "calm"},
+		}}, nil
+	})
+
+	description := describeVoice(t, map[string]any{"provider": "elevenlabs", "voice": "v1"})
+	assert.Equal(t, voiceDescription{
+		Provider:    say.ProviderElevenLabs,
+		Voice:       "v1",
+		Name:        "Rachel",
+		Gender:      "female",
+		Age:         "young",
+		Accent:      "american",
+		UseCase:     "narration",
+		Category:    "premade",
+		Description: "calm",
+	}, description)
+
+	description = describeVoice(t, map[string]any{"provider": "elevenlabs", "voice": "rachel"})
+	assert.Equal(t, "v1", description.Voice, "looked up by name")
+	assert.Equal(t, 1, fetches, "served from the voices cache")
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"provider": "elevenlabs", "voice": "missing"}
+	result, err := handleDescribeVoice(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "refresh_voices")
+}
+
+func TestDescribeVoiceTables(t *testing.T) {
+	description := describeVoice(t, map[string]any{"provider": "openai", "voice": "Nova"})
+	assert.Equal(t, voiceDescription{Provider: say.ProviderOpenAI, Voice: "nova", Name: "nova", Style: "bright and articulate"}, description)
+
+	description = describeVoice(t, map[string]any{"provider": "google", "voice": "en-GB-Puck"})
+	assert.Equal(t, "Puck", description.Name)
+	assert.Equal(t, "male", description.Gender)
+	assert.Equal(t, "en-GB", description.Locale)
+	assert.Equal(t, "upbeat", description.Style)
+	assert.Empty(t, description.Note)
+
+	description = describeVoice(t, map[string]any{"provider": "google"})
+	assert.Equal(t, say.DefaultGoogleVoice, description.Name, "defaults to the provider's voice")
+	assert.Contains(t, description.Note, "language of the text")
+
+	useVoiceAliases(t, `{"narrator": {"provider": "google", "voice": "Charon"}}`)
+	description = describeVoice(t, map[string]any{"provider": "openai", "voice": "narrator"})
+	assert.Equal(t, say.ProviderGoogle, description.Provider, "an alias switches provider")
+	assert.Equal(t, "informative", description.Style)
+
+	for _, arguments := range []map[string]any{
+		{"provider": "openai", "voice": "bogus"},
+		{"provider": "google", "voice": "Bogus"},
+		{"provider": "google", "voice": "en-US-Standard-A"},
+	} {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = arguments
+		result, err := handleDescribeVoice(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, arguments)
+	}
+}
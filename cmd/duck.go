@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+)
+
+const (
+	// duckPercent is the share of their volume ducked players keep while speech plays
+	duckPercent = 25
+	// duckTimeout bounds each duck and restore, osascript can hang on an unresponsive app
+	duckTimeout = 5 * time.Second
+)
+
+// duckRestoreDelay keeps other audio ducked between playbacks that follow each other
+// closely, e.g. the sentences of a stream, instead of letting music swell in between
+var duckRestoreDelay = 500 * time.Millisecond
+
+// ducker lowers other audio while any playback is active and restores it once all have
+// ended, whether they completed, failed or were stopped
+type ducker struct {
+	duck func(ctx context.Context, percent int) (func(ctx context.Context) error, error)
+
+	mu      sync.Mutex
+	active  int
+	restore func(ctx context.Context) error // nil while nothing is ducked
+	timer   *time.Timer
+}
+
+// Set from MCP_SAY_DUCK, nil while ducking is off
+var audioDucker *ducker
+
+// loadDucking reads MCP_SAY_DUCK, ducking is a no-op with a warning where it isn't implemented
+func loadDucking() {
+	audioDucker = nil
+	if v := getenv("MCP_SAY_DUCK"); v != "1" && v != "true" {
+		return
+	}
+	if !say.DuckingSupported() {
+		log.Warn("Ignoring MCP_SAY_DUCK", "error", say.ErrDuckingUnsupported)
+		return
+	}
+	audioDucker = &ducker{duck: say.DuckAudio}
+	log.Info("Ducking other audio during speech", "percent", duckPercent)
+}
+
+// begin ducks other audio for a playback that is starting, unless it already is
+func (d *ducker) begin() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.active++
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	if d.restore != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), duckTimeout)
+	defer cancel()
+	restore, err := d.duck(ctx, duckPercent)
+	if err != nil {
+		log.Warn("Failed to duck other audio", "error", err)
+	}
+	d.restore = restore
+}
+
+// end restores other audio shortly after the last active playback ends
+func (d *ducker) end() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.active--
+	if d.active > 0 || d.restore == nil {
+		return
+	}
+	d.timer = time.AfterFunc(duckRestoreDelay, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if d.active == 0 {
+			d.restoreNow()
+		}
+	})
+}
+
+// close restores other audio right away, for shutdown
+func (d *ducker) close() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	d.restoreNow()
+}
+
+// restoreNow undoes the duck, d.mu must be held
+func (d *ducker) restoreNow() {
+	if d.restore == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), duckTimeout)
+	defer cancel()
+	if err := d.restore(ctx); err != nil {
+		log.Warn("Failed to restore other audio", "error", err)
+	}
+	d.restore = nil
+	d.timer = nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDuck counts ducks and restores
+type fakeDuck struct {
+	mu       sync.Mutex
+	ducks    int
+	restores int
+}
+
+func (f *fakeDuck) duck(ctx context.Context, percent int) (func(ctx context.Context) error, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ducks++
+	return func(ctx context.Context) error {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.restores++
+		return nil
+	}, nil
+}
+
+func (f *fakeDuck) counts() (int, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.ducks, f.restores
+}
+
+func TestDucker(t *testing.T) {
+	prev := duckRestoreDelay
+	duckRestoreDelay = 20 * time.Millisecond
+	t.Cleanup(func() { duckRestoreDelay = prev })
+
+	fake := &fakeDuck{}
+	d := &ducker{duck: fake.duck}
+
+	// Overlapping playbacks duck once and restore once after the last one ends
+	d.begin()
+	d.begin()
+	d.end()
+	time.Sleep(50 * time.Millisecond)
+	ducks, restores := fake.counts()
+	assert.Equal(t, 1, ducks)
+	assert.Equal(t, 0, restores, "a playback is still active")
+	d.end()
+	assert.Eventually(t, func() bool { _, r := fake.counts(); return r == 1 }, time.Second, 5*time.Millisecond)
+
+	// A playback starting within the delay keeps audio ducked
+	d.begin()
+	d.end()
+	d.begin()
+	time.Sleep(50 * time.Millisecond)
+	ducks, restores = fake.counts()
+	assert.Equal(t, 2, ducks)
+	assert.Equal(t, 1, restores)
+
+	// close restores right away
+	d.close()
+	ducks, restores = fake.counts()
+	assert.Equal(t, 2, ducks)
+	assert.Equal(t, 2, restores)
+	d.end()
+	d.close()
+	_, restores = fake.counts()
+	assert.Equal(t, 2, restores, "nothing left to restore")
+
+	// A nil ducker is off
+	var off *ducker
+	off.begin()
+	off.end()
+	off.close()
+}
+
+func TestLoadDucking(t *testing.T) {
+	t.Cleanup(func() { audioDucker = nil })
+
+	t.Setenv("MCP_SAY_DUCK", "")
+	loadDucking()
+	assert.Nil(t, audioDucker)
+
+	t.Setenv("MCP_SAY_DUCK", "true")
+	loadDucking()
+	if runtime.GOOS == "darwin" {
+		assert.NotNil(t, audioDucker)
+	} else {
+		assert.Nil(t, audioDucker, "unsupported platforms ignore MCP_SAY_DUCK")
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	earconSampleRate = beep.SampleRate(24000)
+	earconVolume     = 0.3
+	earconNote       = 120 * time.Millisecond
+)
+
+// earcon returns short notes played back-to-back
+func earcon(frequencies ...float64) (beep.Streamer, beep.Format) {
+	notes := make([]beep.Streamer, 0, len(frequencies))
+	for _, f := range frequencies {
+		notes = append(notes, say.Tone(earconSampleRate, f, earconNote, earconVolume))
+	}
+	return beep.Seq(notes...), beep.Format{SampleRate: earconSampleRate, NumChannels: 1, Precision: 2}
+}
+
+// readyEarcon is a rising pair of notes played once the server is ready
+func readyEarcon() (beep.Streamer, beep.Format) {
+	return earcon(660, 880)
+}
+
+// completionEarcon is a single note played after each utterance
+func completionEarcon() (beep.Streamer, beep.Format) {
+	return earcon(880)
+}
+
+// errorEarcon is a falling pair of low notes played when a tool call fails
+func errorEarcon() (beep.Streamer, beep.Format) {
+	return earcon(440, 294)
+}
+
+// playReadyTone plays the ready earcon, failures are only logged
+func playReadyTone() {
+	if noAudio {
+		return
+	}
+	streamer, format := readyEarcon()
+	if err := player().PlayStream(context.Background(), streamer, format); err != nil {
+		log.Warn("Failed to play ready tone", "error", err)
+	}
+}
+
+// WithCompletionTone plays the completion earcon after a tool call has spoken
+// successfully. Calls that were cancelled, failed or saved their audio stay silent.
+func WithCompletionTone(handler ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := handler(ctx, request)
+		if !completionTone || noAudio || err != nil || result == nil || result.IsError || ctx.Err() != nil {
+			return result, err
+		}
+		if out, _ := outputArgument(request.GetArguments()); out.enabled() {
+			return result, err
+		}
+		streamer, format := completionEarcon()
+		if err := player().PlayStream(ctx, streamer, format); err != nil {
+			log.Warn("Failed to play completion tone", "error", err)
+		}
+		return result, nil
+	}
+}
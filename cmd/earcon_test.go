@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEarcons(t *testing.T) {
+	mock := useMockPlayer(t)
+	streamer, format := readyEarcon()
+	require.NoError(t, mock.PlayStream(context.Background(), streamer, format))
+	// Two 120ms notes at 24kHz
+	assert.Equal(t, 2*2880, mock.PlayedSamples)
+}
+
+func TestWithCompletionTone(t *testing.T) {
+	prev := completionTone
+	completionTone = true
+	t.Cleanup(func() { completionTone = prev })
+
+	ok := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("Speech completed"), nil
+	}
+	failed := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result := mcp.NewToolResultText("Error: boom")
+		result.IsError = true
+		return result, nil
+	}
+	request := func(arguments map[string]any) mcp.CallToolRequest {
+		return mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: arguments}}
+	}
+
+	tests := []struct {
+		name      string
+		handler   ToolHandlerFunc
+		arguments map[string]any
+		played    bool
+	}{
+		{"spoken", ok, map[string]any{"text": "Hi"}, true},
+		{"failed", failed, map[string]any{"text": "Hi"}, false},
+		{"saved to file", ok, map[string]any{"text": "Hi", "return_audio": true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := useMockPlayer(t)
+			_, err := WithCompletionTone(tt.handler)(context.Background(), request(tt.arguments))
+			require.NoError(t, err)
+			assert.Equal(t, tt.played, mock.Played)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type spokenTextKey struct{}
+
+// spokenText collects the text a tool call sent to providers, after text replacements
+type spokenText struct {
+	mu    sync.Mutex
+	texts []string
+}
+
+// withSpokenText returns a context that collects the text sent to providers
+func withSpokenText(ctx context.Context) (context.Context, *spokenText) {
+	spoken := &spokenText{}
+	return context.WithValue(ctx, spokenTextKey{}, spoken), spoken
+}
+
+// recordSpokenText adds text sent to a provider to the call's spoken text, if it is collected
+func recordSpokenText(ctx context.Context, text string) {
+	if spoken, ok := ctx.Value(spokenTextKey{}).(*spokenText); ok {
+		spoken.add(text)
+	}
+}
+
+func (s *spokenText) add(text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.texts = append(s.texts, text)
+}
+
+// String joins the texts in the order they were sent
+func (s *spokenText) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return strings.Join(s.texts, " ")
+}
+
+// WithEchoText reads the optional echo_text argument and adds the text that was actually
+// sent to the providers, after text replacements, to a successful result as its own block
+func WithEchoText(handler ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if echo, _ := request.GetArguments()["echo_text"].(bool); !echo {
+			return handler(ctx, request)
+		}
+		ctx, spoken := withSpokenText(ctx)
+		result, err := handler(ctx, request)
+		if err != nil || result == nil || result.IsError || ctx.Err() != nil {
+			return result, err
+		}
+		if text := spoken.String(); text != "" {
+			result.Content = append(result.Content, mcp.NewTextContent("Spoken text: "+text))
+		}
+		return result, nil
+	}
+}
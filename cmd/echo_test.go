@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEchoText(t *testing.T) {
+	useMockPlayer(t)
+	r, err := loadReplacements(writeReplacements(t, "rules.json", `[{"find": "k8s", "replace": "Kubernetes"}]`))
+	require.NoError(t, err)
+	textReplacements = r
+	t.Cleanup(func() { textReplacements = nil })
+	prev := newProvider
+	newProvider = func(name string) (say.Provider, error) {
+		return wrapProvider(name, &fakeProvider{}), nil
+	}
+	t.Cleanup(func() { newProvider = prev })
+
+	call := func(arguments map[string]any) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = arguments
+		result, err := WithEchoText(handleSpeakSequence)(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError, result.Content)
+		return result
+	}
+	segments := []any{
+		map[string]any{"text": "Deploying k8s.", "provider": "openai"},
+		map[string]any{"text": " Done. ", "provider": "google"},
+	}
+
+	result := call(map[string]any{"segments": segments, "echo_text": true})
+	require.Len(t, result.Content, 2)
+	assert.Equal(t, "Spoken text: Deploying Kubernetes. Done.", result.Content[1].(mcp.TextContent).Text)
+
+	result = call(map[string]any{"segments": segments})
+	assert.Len(t, result.Content, 1)
+}
@@ -1,18 +1,290 @@
 package cmd
 
-type SynthesisOptions struct {
-	Stability       float64 `json:"stability,omitempty"`
-	SimilarityBoost float64 `json:"similarity_boost,omitempty"`
-	Style           float64 `json:"style,omitempty"`
-	UseSpeakerBoost bool    `json:"use_speaker_boost,omitempty"`
-	// Speed           float64 `json:"speed,omitempty"`
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// validDictionaryID matches ElevenLabs pronunciation dictionary and version ids
+var validDictionaryID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// parsePronunciationDictionary validates the raw `pronunciation_dictionary` tool argument
+func parsePronunciationDictionary(raw any) (say.PronunciationDictionary, error) {
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return say.PronunciationDictionary{}, errors.New("pronunciation_dictionary must be an object with id and version")
+	}
+	id, _ := obj["id"].(string)
+	version, _ := obj["version"].(string)
+	if !validDictionaryID.MatchString(id) {
+		return say.PronunciationDictionary{}, fmt.Errorf("pronunciation_dictionary has an invalid id %q", id)
+	}
+	if version != "" && !validDictionaryID.MatchString(version) {
+		return say.PronunciationDictionary{}, fmt.Errorf("pronunciation_dictionary has an invalid version %q", version)
+	}
+	return say.PronunciationDictionary{ID: id, VersionID: version}, nil
+}
+
+// streamingLatencyArgument reads the optional optimize_streaming_latency tool argument
+func streamingLatencyArgument(arguments map[string]any) (int, error) {
+	raw, ok := arguments["optimize_streaming_latency"]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+	level, ok := raw.(float64)
+	if !ok || level != math.Trunc(level) || level < 0 || level > say.MaxStreamingLatencyOptimization {
+		return 0, fmt.Errorf("optimize_streaming_latency must be an integer from 0 to %d", say.MaxStreamingLatencyOptimization)
+	}
+	return int(level), nil
+}
+
+// elevenLabsSeedArgument reads the optional seed tool argument, nil when not given
+func elevenLabsSeedArgument(arguments map[string]any) (*uint32, error) {
+	raw, ok := arguments["seed"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	seed, ok := raw.(float64)
+	if !ok || seed != math.Trunc(seed) || seed < 0 || seed > math.MaxUint32 {
+		return nil, fmt.Errorf("seed must be an integer from 0 to %d", uint32(math.MaxUint32))
+	}
+	v := uint32(seed)
+	return &v, nil
+}
+
+// elevenLabsContextArgument reads the optional previous_text and next_text tool arguments
+func elevenLabsContextArgument(arguments map[string]any) (previous, next string, err error) {
+	for name, value := range map[string]*string{"previous_text": &previous, "next_text": &next} {
+		raw, ok := arguments[name]
+		if !ok || raw == nil {
+			continue
+		}
+		if *value, ok = raw.(string); !ok {
+			return "", "", fmt.Errorf("%s must be a string", name)
+		}
+	}
+	if err := say.ValidateElevenLabsContext(previous, next); err != nil {
+		return "", "", err
+	}
+	return previous, next, nil
+}
+
+// bitrateArgument reads the optional bitrate tool argument, 0 when not given
+func bitrateArgument(arguments map[string]any) (int, error) {
+	raw, ok := arguments["bitrate"]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+	bitrate, ok := raw.(float64)
+	if !ok || !slices.Contains(say.ElevenLabsBitrates, int(bitrate)) || bitrate != math.Trunc(bitrate) {
+		return 0, fmt.Errorf("bitrate must be one of %v kbps", say.ElevenLabsBitrates)
+	}
+	return int(bitrate), nil
+}
+
+// captionsArgument reads the optional vtt_output tool argument, the directory must exist
+func captionsArgument(arguments map[string]any) (string, error) {
+	raw, ok := arguments["vtt_output"]
+	if !ok || raw == nil {
+		return "", nil
+	}
+	path, ok := raw.(string)
+	if !ok {
+		return "", errors.New("vtt_output must be a string")
+	}
+	if path != "" {
+		info, err := os.Stat(filepath.Dir(path))
+		if err != nil || !info.IsDir() {
+			return "", fmt.Errorf("vtt_output directory does not exist: %s", filepath.Dir(path))
+		}
+	}
+	return path, nil
 }
 
-type ElevenLabsParams struct {
-	Text          string           `json:"text"`
-	ModelID       string           `json:"model_id,omitempty"`
-	LanguageCode  string           `json:"language_code,omitempty"`
-	PreviousText  string           `json:"previous_text,omitempty"`
-	NextText      string           `json:"next_text,omitempty"`
-	VoiceSettings SynthesisOptions `json:"voice_settings,omitempty"`
+// handleElevenLabsTTS synthesizes text with ElevenLabs and streams it to the speaker
+func handleElevenLabsTTS(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("ElevenLabs tool called", "request", request)
+	arguments := request.GetArguments()
+	text, err := textArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	out, err := outputArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	captions, err := captionsArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	latency, err := streamingLatencyArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	seed, err := elevenLabsSeedArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	bitrate, err := bitrateArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	extra, err := extraArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	previousText, nextText, err := elevenLabsContextArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	elevenLabs := say.NewElevenLabs(providerAPIKey(say.ProviderElevenLabs))
+	elevenLabs.OptimizeStreamingLatency = latency
+	elevenLabs.Seed = seed
+	elevenLabs.Bitrate = bitrate
+	elevenLabs.Extra = extra
+	if raw, ok := arguments["pronunciation_dictionary"]; ok && raw != nil {
+		dictionary, err := parsePronunciationDictionary(raw)
+		if err != nil {
+			result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+			result.IsError = true
+			return result, nil
+		}
+		elevenLabs.PronunciationDictionaries = []say.PronunciationDictionary{dictionary}
+	}
+
+	// Empty values fall back to ELEVENLABS_VOICE_ID/ELEVENLABS_MODEL_ID and then the built-in defaults
+	opts := say.Options{
+		Text:  text,
+		Voice: providerSetting(arguments, say.ProviderElevenLabs, "voice", ""),
+		Model: providerSetting(arguments, say.ProviderElevenLabs, "model", ""),
+
+		PreviousText: previousText,
+		NextText:     nextText,
+	}
+	chunks := chunkingArgument(arguments)
+	if rawSpeakers, ok := arguments["speakers"]; ok && rawSpeakers != nil {
+		if captions != "" {
+			result := mcp.NewToolResultText("Error: vtt_output is not supported with speakers, the dialogue endpoint returns no word timestamps")
+			result.IsError = true
+			return result, nil
+		}
+		if previousText != "" || nextText != "" {
+			result := mcp.NewToolResultText("Error: previous_text and next_text are not supported with speakers")
+			result.IsError = true
+			return result, nil
+		}
+		speakers, err := parseSpeakers(rawSpeakers)
+		voices := make(map[string]string, len(speakers))
+		for _, sp := range speakers {
+			voices[sp.Name] = sp.Voice
+		}
+		if err == nil {
+			err = say.ValidateElevenLabsDialogue(text, voices)
+		}
+		if err != nil {
+			result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+			result.IsError = true
+			return result, nil
+		}
+		elevenLabs.Voices = voices
+		// The transcript goes to the dialogue endpoint whole, and the speech model defaults don't apply to it
+		chunks = chunking{}
+		opts.Voice = "multi-speaker"
+		opts.Model, _ = arguments["model"].(string)
+	}
+
+	if found, ok := elevenLabsVoices.known(opts.Voice); ok && !found && len(elevenLabs.Voices) == 0 && opts.Voice != "" {
+		// Only checked against a list already cached, shared library voices may be missing from it
+		log.Warn("Voice is not in the cached ElevenLabs voices list", "voice", opts.Voice)
+	}
+	log.Info("Speaking text via ElevenLabs", "text", text, "voice", opts.Voice)
+	var words []say.WordTiming
+	if captions != "" {
+		elevenLabs.WordTimings = func(w []say.WordTiming) { words = w }
+	}
+	provider := wrapProvider(say.ProviderElevenLabs, elevenLabs)
+	var captionsNote string
+	switch {
+	case captions != "":
+		// Word timestamps come with the whole clip, so synthesize it in one request and
+		// write the captions before playing
+		var audio *say.Audio
+		audio, err = renderText(ctx, provider, opts, chunking{})
+		if err == nil {
+			err = writeFileAtomic(ctx, captions, strings.NewReader(say.FormatWebVTT(words)))
+		}
+		if err != nil {
+			log.Error("ElevenLabs TTS failed", "error", err)
+			result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+			result.IsError = true
+			return result, nil
+		}
+		log.Info("Wrote captions", "path", captions, "words", len(words))
+		captionsNote = fmt.Sprintf("Wrote captions to %s", captions)
+		if out.enabled() {
+			return withNote(deliverAudio(ctx, out, audio, synthesizedMessage(text)), captionsNote), nil
+		}
+		err = withAudioHint(player().Play(ctx, audio))
+	case out.enabled():
+		audio, err := renderText(ctx, provider, opts, chunks)
+		if err != nil {
+			log.Error("ElevenLabs TTS failed", "error", err)
+			result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+			result.IsError = true
+			return result, nil
+		}
+		return deliverAudio(ctx, out, audio, synthesizedMessage(text)), nil
+	default:
+		err = speakText(ctx, provider, opts, chunks)
+	}
+
+	if errors.Is(err, context.Canceled) {
+		log.Info("Audio playback cancelled by user")
+		return mcp.NewToolResultText("Audio playback cancelled"), nil
+	}
+	if err != nil {
+		log.Error("ElevenLabs TTS failed", "error", err)
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	log.Debug("Finished speaking")
+	if suppressSpeakingOutput {
+		return withNote(mcp.NewToolResultText("Speech completed"), captionsNote), nil
+	}
+	return withNote(mcp.NewToolResultText(fmt.Sprintf("Speaking: %s", text)), captionsNote), nil
 }
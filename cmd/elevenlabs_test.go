@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePronunciationDictionary(t *testing.T) {
+	dictionary, err := parsePronunciationDictionary(map[string]any{"id": "5xM3yVvZQKV0EfqQpLrJ", "version": "aF0iZcfXH3hBnMdQx4Wj"})
+	require.NoError(t, err)
+	assert.Equal(t, say.PronunciationDictionary{ID: "5xM3yVvZQKV0EfqQpLrJ", VersionID: "aF0iZcfXH3hBnMdQx4Wj"}, dictionary)
+
+	dictionary, err = parsePronunciationDictionary(map[string]any{"id": "5xM3yVvZQKV0EfqQpLrJ"})
+	require.NoError(t, err)
+	assert.Empty(t, dictionary.VersionID, "version defaults to latest")
+
+	tests := []struct {
+		name string
+		raw  any
+	}{
+		{"not an object", "5xM3yVvZQKV0EfqQpLrJ"},
+		{"missing id", map[string]any{"version": "aF0iZcfXH3hBnMdQx4Wj"}},
+		{"invalid id", map[string]any{"id": "../voices"}},
+		{"invalid version", map[string]any{"id": "5xM3yVvZQKV0EfqQpLrJ", "version": "v 1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parsePronunciationDictionary(tt.raw)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestStreamingLatencyArgument(t *testing.T) {
+	level, err := streamingLatencyArgument(map[string]any{})
+	require.NoError(t, err)
+	assert.Zero(t, level, "defaults to best quality")
+
+	level, err = streamingLatencyArgument(map[string]any{"optimize_streaming_latency": 3.0})
+	require.NoError(t, err)
+	assert.Equal(t, 3, level)
+
+	for _, raw := range []any{-1.0, 5.0, 2.5, "2"} {
+		_, err := streamingLatencyArgument(map[string]any{"optimize_streaming_latency": raw})
+		assert.Error(t, err, "%v", raw)
+	}
+}
+
+func TestElevenLabsSeedArgument(t *testing.T) {
+	seed, err := elevenLabsSeedArgument(map[string]any{})
+	require.NoError(t, err)
+	assert.Nil(t, seed)
+
+	seed, err = elevenLabsSeedArgument(map[string]any{"seed": 42.0})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(42), *seed)
+
+	for _, raw := range []any{-1.0, 4294967296.0, 1.5, "42"} {
+		_, err := elevenLabsSeedArgument(map[string]any{"seed": raw})
+		assert.Error(t, err, "%v", raw)
+	}
+}
+
+func TestElevenLabsContextArgument(t *testing.T) {
+	previous, next, err := elevenLabsContextArgument(map[string]any{})
+	require.NoError(t, err)
+	assert.Empty(t, previous)
+	assert.Empty(t, next)
+
+	previous, next, err = elevenLabsContextArgument(map[string]any{"previous_text": "Chapter one ended.", "next_text": "Chapter three begins."})
+	require.NoError(t, err)
+	assert.Equal(t, "Chapter one ended.", previous)
+	assert.Equal(t, "Chapter three begins.", next)
+
+	_, _, err = elevenLabsContextArgument(map[string]any{"previous_text": 42.0})
+	assert.EqualError(t, err, "previous_text must be a string")
+	_, _, err = elevenLabsContextArgument(map[string]any{"next_text": strings.Repeat("é", say.MaxElevenLabsContextLength+1)})
+	assert.ErrorContains(t, err, "next_text is too long")
+}
+
+func TestBitrateArgument(t *testing.T) {
+	bitrate, err := bitrateArgument(map[string]any{})
+	require.NoError(t, err)
+	assert.Zero(t, bitrate, "defaults to the provider's bitrate")
+
+	bitrate, err = bitrateArgument(map[string]any{"bitrate": 64.0})
+	require.NoError(t, err)
+	assert.Equal(t, 64, bitrate)
+
+	for _, raw := range []any{0.0, 100.0, 64.5, 320.0, "64"} {
+		_, err := bitrateArgument(map[string]any{"bitrate": raw})
+		assert.Error(t, err, "%v", raw)
+	}
+}
+
+func TestElevenLabsArgumentErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler ToolHandlerFunc
+		args    map[string]any
+		errMsg  string
+	}{
+		{"speaker without voice", handleElevenLabsTTS, map[string]any{
+			"text":     "Joe: Hi\nJane: Hello",
+			"speakers": []any{map[string]any{"name": "Joe", "voice": "voice-joe"}},
+		}, "no voice mapping for speaker(s): Jane"},
+		{"captions with speakers", handleElevenLabsTTS, map[string]any{
+			"text":       "Joe: Hi",
+			"speakers":   []any{map[string]any{"name": "Joe", "voice": "voice-joe"}},
+			"vtt_output": "captions.vtt",
+		}, "vtt_output is not supported with speakers"},
+		{"context with speakers", handleElevenLabsTTS, map[string]any{
+			"text":          "Joe: Hi",
+			"speakers":      []any{map[string]any{"name": "Joe", "voice": "voice-joe"}},
+			"previous_text": "Earlier.",
+		}, "previous_text and next_text are not supported with speakers"},
+		{"unsupported bitrate", handleElevenLabsTTS, map[string]any{"text": "Hi", "bitrate": float64(256)}, "bitrate must be one of [32 64 96 128 192] kbps"},
+		{"captions directory missing", handleElevenLabsTTS, map[string]any{"text": "Hi", "vtt_output": "/does/not/exist/captions.vtt"}, "vtt_output directory does not exist"},
+		{"empty prompt", handleSoundEffect, map[string]any{"prompt": ""}, "prompt must not be empty"},
+		{"duration too long", handleSoundEffect, map[string]any{"prompt": "rain", "duration": float64(60)}, "duration must be between"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: tt.args}})
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errMsg)
+		})
+	}
+}
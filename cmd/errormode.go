@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Error modes, set with MCP_SAY_ERROR_MODE
+const (
+	// errorModeResult reports failures as tool results with isError set
+	errorModeResult = "result"
+	// errorModeError reports failures as JSON-RPC errors, for clients that ignore isError
+	errorModeError = "error"
+)
+
+// How tool failures are reported, errorModeResult unless MCP_SAY_ERROR_MODE says otherwise
+var errorMode = errorModeResult
+
+// parseErrorMode validates an MCP_SAY_ERROR_MODE value, empty means errorModeResult
+func parseErrorMode(value string) (string, error) {
+	switch value {
+	case "":
+		return errorModeResult, nil
+	case errorModeResult, errorModeError:
+		return value, nil
+	default:
+		return "", fmt.Errorf("unknown error mode %q (use result or error)", value)
+	}
+}
+
+// resultError turns an error result into a Go error carrying its text
+func resultError(result *mcp.CallToolResult) error {
+	var lines []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			lines = append(lines, text.Text)
+		}
+	}
+	message := strings.TrimPrefix(strings.Join(lines, "\n"), "Error: ")
+	if message == "" {
+		message = "tool call failed"
+	}
+	return errors.New(message)
+}
+
+// withErrorMode is a server middleware that returns error results as Go errors in error
+// mode, so every tool reports failures the same way whichever handler produced them
+func withErrorMode(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if err == nil && errorMode == errorModeError && result != nil && result.IsError {
+			return nil, resultError(result)
+		}
+		return result, err
+	}
+}
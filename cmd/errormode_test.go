@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrorMode(t *testing.T) {
+	mode, err := parseErrorMode("")
+	require.NoError(t, err)
+	assert.Equal(t, errorModeResult, mode)
+	mode, err = parseErrorMode("error")
+	require.NoError(t, err)
+	assert.Equal(t, errorModeError, mode)
+	_, err = parseErrorMode("Error")
+	assert.Error(t, err)
+}
+
+func TestWithErrorMode(t *testing.T) {
+	failing := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result := mcp.NewToolResultText("Error: OPENAI_API_KEY is not set")
+		result.IsError = true
+		return result, nil
+	}
+	succeeding := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("Speaking: hi"), nil
+	}
+	prev := errorMode
+	t.Cleanup(func() { errorMode = prev })
+
+	errorMode = errorModeResult
+	result, err := withErrorMode(failing)(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	errorMode = errorModeError
+	result, err = withErrorMode(failing)(context.Background(), mcp.CallToolRequest{})
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "OPENAI_API_KEY is not set")
+
+	result, err = withErrorMode(succeeding)(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "Speaking: hi", result.Content[0].(mcp.TextContent).Text)
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// errorSoundTone is the MCP_SAY_ERROR_SOUND value for the generated error earcon
+const errorSoundTone = "tone"
+
+// errorSound is played when a tool call fails
+type errorSound struct {
+	// file is the configured audio, nil for the generated earcon
+	file *say.Audio
+}
+
+// Set from MCP_SAY_ERROR_SOUND, nil while failures are silent
+var failureSound *errorSound
+
+// parseErrorSound reads an MCP_SAY_ERROR_SOUND value: "tone" (or 1/true) for the error
+// earcon, otherwise the path of an MP3, WAV or FLAC file, read once here. Empty is off.
+func parseErrorSound(value string) (*errorSound, error) {
+	switch value {
+	case "":
+		return nil, nil
+	case errorSoundTone, "1", "true":
+		return &errorSound{}, nil
+	}
+	audio, err := say.ReadAudioFile(value)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := audio.Decode(); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %v", value, err)
+	}
+	return &errorSound{file: audio}, nil
+}
+
+// play plays the sound, failures are only logged
+func (s *errorSound) play(ctx context.Context) {
+	var err error
+	if s.file != nil {
+		err = player().Play(ctx, s.file)
+	} else {
+		streamer, format := errorEarcon()
+		err = player().PlayStream(ctx, streamer, format)
+	}
+	if err != nil {
+		log.Warn("Failed to play error sound", "error", err)
+	}
+}
+
+// withErrorSound is a server middleware that plays the error sound after any tool call
+// fails, so a user not watching the transcript hears it. It stays silent while muted,
+// with --no-audio and for calls that were cancelled.
+func withErrorSound(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		failed := err != nil || (result != nil && result.IsError)
+		if failureSound == nil || !failed || noAudio || muted.Load() || ctx.Err() != nil {
+			return result, err
+		}
+		log.Debug("Playing error sound", "tool", request.Params.Name)
+		failureSound.play(ctx)
+		return result, err
+	}
+}
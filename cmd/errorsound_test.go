@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrorSound(t *testing.T) {
+	sound, err := parseErrorSound("")
+	require.NoError(t, err)
+	assert.Nil(t, sound)
+
+	for _, value := range []string{"tone", "1", "true"} {
+		sound, err = parseErrorSound(value)
+		require.NoError(t, err, value)
+		assert.Nil(t, sound.file, value)
+	}
+
+	path := filepath.Join(t.TempDir(), "error.wav")
+	tone := say.ToneAudio(say.ToneParams{SampleRate: 24000, Waveform: say.WaveformSine, Frequency: 220, Duration: 50 * time.Millisecond, Volume: 0.2})
+	require.NoError(t, os.WriteFile(path, tone.Encoded(), 0o644))
+	sound, err = parseErrorSound(path)
+	require.NoError(t, err)
+	require.NotNil(t, sound.file)
+	assert.Equal(t, say.EncodingWAV, sound.file.Encoding)
+
+	_, err = parseErrorSound(filepath.Join(t.TempDir(), "missing.wav"))
+	assert.Error(t, err)
+	bad := filepath.Join(t.TempDir(), "bad.mp3")
+	require.NoError(t, os.WriteFile(bad, []byte("not audio"), 0o644))
+	_, err = parseErrorSound(bad)
+	assert.Error(t, err)
+}
+
+func TestWithErrorSound(t *testing.T) {
+	mock := useMockPlayer(t)
+	prev := failureSound
+	t.Cleanup(func() { failureSound = prev; muted.Store(false) })
+
+	failing := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result := mcp.NewToolResultText("Error: OPENAI_API_KEY is not set")
+		result.IsError = true
+		return result, nil
+	}
+	erroring := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("failed")
+	}
+	succeeding := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("Speaking: hi"), nil
+	}
+	call := func(handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)) bool {
+		t.Helper()
+		mock.Played = false
+		result, err := withErrorSound(handler)(context.Background(), mcp.CallToolRequest{})
+		if err == nil {
+			require.NotNil(t, result)
+		}
+		return mock.Played
+	}
+
+	failureSound = nil
+	assert.False(t, call(failing), "off by default")
+
+	failureSound = &errorSound{}
+	assert.True(t, call(failing))
+	assert.True(t, call(erroring))
+	assert.False(t, call(succeeding))
+
+	muted.Store(true)
+	assert.False(t, call(failing), "muted")
+	muted.Store(false)
+
+	noAudio = true
+	assert.False(t, call(failing), "no audio")
+	noAudio = false
+}
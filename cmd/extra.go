@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/blacktop/mcp-tts/say"
+)
+
+// extraArgument reads the optional extra tool argument, raw request body fields merged into
+// the provider request for API parameters the tool doesn't expose. nil when not given.
+func extraArgument(arguments map[string]any) (map[string]any, error) {
+	raw, ok := arguments["extra"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	extra, ok := raw.(map[string]any)
+	if !ok {
+		return nil, errors.New("extra must be an object of request body fields")
+	}
+	if err := say.ValidateExtra(extra); err != nil {
+		return nil, err
+	}
+	return extra, nil
+}
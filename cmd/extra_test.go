@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtraArgument(t *testing.T) {
+	useMockPlayer(t)
+	t.Setenv("OPENAI_API_KEY", "sk-secret")
+	t.Setenv("ELEVENLABS_API_KEY", "xi-secret")
+	t.Setenv("GOOGLE_AI_API_KEY", "ai-secret")
+
+	extra, err := extraArgument(map[string]any{})
+	require.NoError(t, err)
+	assert.Nil(t, extra)
+	_, err = extraArgument(map[string]any{"extra": "stream_format=audio"})
+	assert.ErrorContains(t, err, "must be an object")
+	_, err = extraArgument(map[string]any{"extra": map[string]any{"a.b": 1.0}})
+	assert.Error(t, err)
+
+	request := func(tool string, arguments map[string]any) string {
+		t.Helper()
+		call := mcp.CallToolRequest{}
+		call.Params.Arguments = map[string]any{"tool": tool, "arguments": arguments}
+		result, err := handleDebugRequest(t.Context(), call)
+		require.NoError(t, err)
+		require.False(t, result.IsError, result.Content)
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	text := request("openai_tts", map[string]any{"text": "Hello", "extra": map[string]any{"stream_format": "audio", "input": "Goodbye"}})
+	assert.Contains(t, text, `"stream_format": "audio"`)
+	assert.Contains(t, text, `"input": "Hello"`)
+	assert.NotContains(t, text, "Goodbye")
+
+	text = request("elevenlabs_tts", map[string]any{"text": "Hello", "extra": map[string]any{"apply_text_normalization": "off"}})
+	assert.Contains(t, text, `"apply_text_normalization": "off"`)
+
+	text = request("google_tts", map[string]any{"text": "Hello", "extra": map[string]any{"cachedContent": "cachedContents/abc"}})
+	assert.Contains(t, text, `"cachedContent": "cachedContents/abc"`)
+
+	call := mcp.CallToolRequest{}
+	call.Params.Arguments = map[string]any{"text": "Hello", "model": "gpt-4o-audio-preview", "extra": map[string]any{"stream_format": "audio"}}
+	result, err := handleOpenAITTS(t.Context(), call)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
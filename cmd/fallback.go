@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// textFallbackMarker starts every result returned instead of speech in text fallback mode
+const textFallbackMarker = "[would speak]"
+
+// Set by MCP_SAY_TEXT_FALLBACK, lets speech tools answer with their text when there is no audio backend
+var textFallback bool
+
+// providerReady reports whether a provider has the keys or binary it needs, without
+// making network calls. An empty name is ready when any provider is.
+func providerReady(name string) (bool, string) {
+	for _, status := range providerStatuses() {
+		if name == "" && status.Ready {
+			return true, ""
+		}
+		if status.Name == name {
+			return status.Ready, status.Detail
+		}
+	}
+	if name == "" {
+		return false, "no provider is configured"
+	}
+	// Unknown providers are left to the handler to report
+	return true, ""
+}
+
+// fallbackText returns the text a speech tool call would speak, false when its
+// arguments are invalid and the handler should report that
+func fallbackText(request mcp.CallToolRequest) (string, bool) {
+	arguments := request.GetArguments()
+	if ssml, ok := arguments["ssml"].(string); ok {
+		segments, err := parseSSML(ssml)
+		if err != nil {
+			return "", false
+		}
+		var plain []string
+		for _, seg := range segments {
+			if seg.Text != "" {
+				plain = append(plain, seg.Text)
+			}
+		}
+		return strings.Join(plain, " "), len(plain) > 0
+	}
+	text, err := textArgument(arguments)
+	if err != nil || text == "" {
+		return "", false
+	}
+	return applyReplacements(text), true
+}
+
+// textFallbackResult is the result of a call answered with its text instead of speech
+func textFallbackResult(text, reason string) *mcp.CallToolResult {
+	log.Info("No audio backend, returning the text instead of speaking it", "reason", reason)
+	return mcp.NewToolResultText(textFallbackMarker + " " + text)
+}
+
+// WithTextFallback answers a speech tool call with its text, prefixed with textFallbackMarker,
+// instead of failing when MCP_SAY_TEXT_FALLBACK is set and there is no audio backend: the
+// provider isn't configured, playback is disabled, or no audio device can be opened.
+// Calls that save or return the audio still fail, they need real audio.
+func WithTextFallback(provider string, handler ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !textFallback {
+			return handler(ctx, request)
+		}
+		text, ok := fallbackText(request)
+		if !ok {
+			return handler(ctx, request)
+		}
+		arguments := request.GetArguments()
+		out, err := outputArgument(arguments)
+		if err != nil && !errors.Is(err, errNoAudio) {
+			return handler(ctx, request)
+		}
+		if out.enabled() {
+			return handler(ctx, request)
+		}
+
+		name := provider
+		if name == "" {
+			if value, ok := arguments["provider"].(string); ok {
+				name = value
+			} else if request.Params.Name == "speak" {
+				name, _ = speakPolicy.choose(text)
+			}
+		}
+		if ready, detail := providerReady(name); !ready {
+			return textFallbackResult(text, detail), nil
+		}
+		if noAudio {
+			return textFallbackResult(text, "audio playback is disabled (--no-audio)"), nil
+		}
+
+		result, err := handler(ctx, request)
+		if err == nil && result != nil && result.IsError && strings.Contains(resultError(result).Error(), say.ErrNoAudioDevice.Error()) {
+			return textFallbackResult(text, resultError(result).Error()), nil
+		}
+		return result, err
+	}
+}
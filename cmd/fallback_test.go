@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTextFallback(t *testing.T) {
+	var calls int
+	var failure error
+	handler := WithTextFallback(say.ProviderOpenAI, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		if failure != nil {
+			result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", failure))
+			result.IsError = true
+			return result, nil
+		}
+		return mcp.NewToolResultText("Speaking: hello"), nil
+	})
+	call := func(arguments map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = arguments
+		result, err := handler(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+	prev := textFallback
+	t.Cleanup(func() { textFallback = prev })
+	t.Setenv("OPENAI_API_KEY", "")
+
+	textFallback = false
+	call(map[string]any{"text": "hello"})
+	assert.Equal(t, 1, calls, "the handler runs when the mode is off")
+
+	textFallback = true
+	result := call(map[string]any{"text": "hello"})
+	assert.Equal(t, 1, calls, "an unconfigured provider is never called")
+	assert.False(t, result.IsError)
+	assert.Equal(t, "[would speak] hello", result.Content[0].(mcp.TextContent).Text)
+
+	call(map[string]any{"text": "hello", "return_audio": true})
+	assert.Equal(t, 2, calls, "calls asking for the audio still need a provider")
+
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	failure = fmt.Errorf("%w: no device", say.ErrNoAudioDevice)
+	result = call(map[string]any{"text": "hello"})
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, "[would speak] hello", result.Content[0].(mcp.TextContent).Text)
+
+	failure = fmt.Errorf("invalid voice")
+	result = call(map[string]any{"text": "hello"})
+	assert.True(t, result.IsError, "other errors are reported as usual")
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// parseSpeakers validates the raw `speakers` tool argument of the dialogue capable tools
+func parseSpeakers(raw any) ([]say.GoogleSpeaker, error) {
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("speakers must be an array of {name, voice} objects")
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("speakers must contain at least one {name, voice} object")
+	}
+
+	speakers := make([]say.GoogleSpeaker, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	for i, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("speakers[%d] must be an object with name and voice", i)
+		}
+		name, _ := obj["name"].(string)
+		voice, _ := obj["voice"].(string)
+		name = strings.TrimSpace(name)
+		voice = strings.TrimSpace(voice)
+		if name == "" || voice == "" {
+			return nil, fmt.Errorf("speakers[%d] must have a non-empty name and voice", i)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("speaker %q is mapped more than once", name)
+		}
+		seen[name] = true
+		speakers = append(speakers, say.GoogleSpeaker{Name: name, Voice: voice})
+	}
+	return speakers, nil
+}
+
+// googleProvider returns the Gemini TTS provider, over gRPC with Application Default
+// Credentials when --google-grpc is set and over REST with the API key otherwise
+func googleProvider(pitch float64, speakers []say.GoogleSpeaker) say.Provider {
+	if googleGRPC {
+		return &say.GoogleCloud{Pitch: pitch}
+	}
+	return &say.Google{APIKey: providerAPIKey(say.ProviderGoogle), Pitch: pitch, Speakers: speakers}
+}
+
+// googleGenerationArguments reads the optional temperature and seed arguments, nil when not given
+func googleGenerationArguments(arguments map[string]any) (*float32, *int32, error) {
+	var (
+		temperature *float32
+		seed        *int32
+	)
+	if raw, ok := arguments["temperature"]; ok && raw != nil {
+		t, ok := raw.(float64)
+		if !ok || t < say.GoogleMinTemperature || t > say.GoogleMaxTemperature {
+			return nil, nil, fmt.Errorf("temperature must be a number from %g to %g", say.GoogleMinTemperature, say.GoogleMaxTemperature)
+		}
+		v := float32(t)
+		temperature = &v
+	}
+	if raw, ok := arguments["seed"]; ok && raw != nil {
+		s, ok := raw.(float64)
+		if !ok || s != math.Trunc(s) || s < math.MinInt32 || s > math.MaxInt32 {
+			return nil, nil, fmt.Errorf("seed must be a whole number from %d to %d", math.MinInt32, math.MaxInt32)
+		}
+		v := int32(s)
+		seed = &v
+	}
+	if googleGRPC && (temperature != nil || seed != nil) {
+		return nil, nil, fmt.Errorf("temperature and seed are not supported with --google-grpc")
+	}
+	return temperature, seed, nil
+}
+
+// handleGoogleTTS synthesizes text with Gemini TTS and plays it
+func handleGoogleTTS(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Google TTS tool called", "request", request)
+	arguments := request.GetArguments()
+	text, err := textArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	out, err := outputArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	if text == "" {
+		result := mcp.NewToolResultText("Error: Empty text provided")
+		result.IsError = true
+		return result, nil
+	}
+	sampleRate, err := sampleRateArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	// Get configuration from arguments
+	voice := providerSetting(arguments, say.ProviderGoogle, "voice", say.DefaultGoogleVoice)
+	model := providerSetting(arguments, say.ProviderGoogle, "model", say.DefaultGoogleModel)
+	if _, err := say.ParseGoogleVoice(voice); err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	speakingRate := say.GoogleDefaultSpeakingRate
+	if r, ok := arguments["speaking_rate"].(float64); ok {
+		if r >= say.GoogleMinSpeakingRate && r <= say.GoogleMaxSpeakingRate {
+			speakingRate = r
+		} else {
+			log.Warn("Speaking rate out of range, using default", "provided", r, "default", say.GoogleDefaultSpeakingRate)
+		}
+	}
+
+	pitch := say.GoogleDefaultPitch
+	if p, ok := arguments["pitch"].(float64); ok {
+		if p >= say.GoogleMinPitch && p <= say.GoogleMaxPitch {
+			pitch = p
+		} else {
+			log.Warn("Pitch out of range, using default", "provided", p, "default", say.GoogleDefaultPitch)
+		}
+	}
+
+	temperature, seed, err := googleGenerationArguments(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	extra, err := extraArgument(arguments)
+	if err == nil && extra != nil && googleGRPC {
+		err = fmt.Errorf("extra is not supported with --google-grpc")
+	}
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	bucket, _ := arguments["gcs_bucket"].(string)
+	longAudio := useGoogleLongAudio(text)
+	switch {
+	case bucket != "" && !googleLongAudio:
+		err = fmt.Errorf("gcs_bucket is only used with --google-long-audio")
+	case longAudio && arguments["speakers"] != nil:
+		err = fmt.Errorf("multi-speaker dialogue is not supported with long audio, keep the text under %d bytes", say.GoogleSyncTextLimit)
+	case longAudio && (temperature != nil || seed != nil || extra != nil):
+		err = fmt.Errorf("temperature, seed and extra are not supported with long audio")
+	}
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	provider := googleProvider(pitch, nil)
+	opts := say.Options{
+		Text:  text,
+		Voice: voice,
+		Model: model,
+		Speed: speakingRate,
+	}
+	if rawSpeakers, ok := arguments["speakers"]; ok && rawSpeakers != nil {
+		speakers, err := parseSpeakers(rawSpeakers)
+		if err == nil {
+			err = say.ValidateGoogleDialogue(text, speakers)
+		}
+		if err == nil && googleGRPC {
+			err = fmt.Errorf("multi-speaker dialogue is not supported with --google-grpc")
+		}
+		if err != nil {
+			result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+			result.IsError = true
+			return result, nil
+		}
+		provider = googleProvider(pitch, speakers)
+		voice = "multi-speaker"
+	}
+	if google, ok := provider.(*say.Google); ok {
+		google.Temperature, google.Seed = temperature, seed
+		google.Extra = extra
+	}
+
+	log.Debug("Generating TTS audio",
+		"model", model,
+		"voice", voice,
+		"speakingRate", speakingRate,
+		"pitch", pitch,
+		"text", text,
+	)
+
+	log.Info("Speaking via Google TTS", "text", text, "voice", voice, "model", model)
+	if longAudio || out.enabled() || sampleRate != 0 {
+		var audio *say.Audio
+		if longAudio {
+			audio, err = synthesizeGoogleLongAudio(ctx, say.GoogleSpeechParams{
+				Text:         text,
+				Voice:        voice,
+				Model:        model,
+				SpeakingRate: speakingRate,
+				Pitch:        pitch,
+			}, bucket)
+		} else {
+			audio, err = renderText(ctx, wrapProvider(say.ProviderGoogle, provider), opts, chunking{})
+		}
+		if err == nil && sampleRate != 0 {
+			audio, err = resampleAudio(audio, sampleRate)
+		}
+		if err != nil {
+			log.Error("Google TTS failed", "error", err)
+			result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+			result.IsError = true
+			return result, nil
+		}
+		if out.enabled() {
+			return deliverAudio(ctx, out, audio, synthesizedMessage(text)), nil
+		}
+		err = withAudioHint(player().Play(ctx, audio))
+	} else {
+		err = speakText(ctx, wrapProvider(say.ProviderGoogle, provider), opts, chunking{})
+	}
+
+	if errors.Is(err, context.Canceled) {
+		log.Info("Google TTS audio playback cancelled by user")
+		return mcp.NewToolResultText("Google TTS audio playback cancelled"), nil
+	}
+	if err != nil {
+		log.Error("Google TTS failed", "error", err)
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	log.Debug("Google TTS audio playback completed normally")
+	if suppressSpeakingOutput {
+		return mcp.NewToolResultText("Speech completed"), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Speaking: %s (via Google TTS with voice %s)", text, voice)), nil
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGoogleSpeakers(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  any
+	}{
+		{"not an array", "Joe=Kore"},
+		{"empty array", []any{}},
+		{"missing voice", []any{map[string]any{"name": "Joe"}}},
+		{"duplicate name", []any{
+			map[string]any{"name": "Joe", "voice": "Kore"},
+			map[string]any{"name": "Joe", "voice": "Puck"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSpeakers(tt.raw)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGoogleGenerationArguments(t *testing.T) {
+	temperature, seed, err := googleGenerationArguments(map[string]any{})
+	require.NoError(t, err)
+	assert.Nil(t, temperature)
+	assert.Nil(t, seed)
+
+	temperature, seed, err = googleGenerationArguments(map[string]any{"temperature": 0.0, "seed": -7.0})
+	require.NoError(t, err)
+	assert.Equal(t, float32(0), *temperature)
+	assert.Equal(t, int32(-7), *seed)
+
+	tests := []struct {
+		name      string
+		arguments map[string]any
+	}{
+		{"temperature too high", map[string]any{"temperature": 2.5}},
+		{"temperature not a number", map[string]any{"temperature": "hot"}},
+		{"fractional seed", map[string]any{"seed": 1.5}},
+		{"seed out of range", map[string]any{"seed": 1e10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := googleGenerationArguments(tt.arguments)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGoogleProvider(t *testing.T) {
+	prev := googleGRPC
+	t.Cleanup(func() { googleGRPC = prev })
+
+	googleGRPC = false
+	assert.IsType(t, &say.Google{}, googleProvider(2, nil))
+
+	googleGRPC = true
+	assert.Equal(t, &say.GoogleCloud{Pitch: 2}, googleProvider(2, nil))
+
+	result, err := handleGoogleTTS(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Arguments: map[string]any{
+			"text":     "Joe: Hi\nJane: Hello",
+			"speakers": []any{map[string]any{"name": "Joe", "voice": "Kore"}, map[string]any{"name": "Jane", "voice": "Puck"}},
+		},
+	}})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "not supported with --google-grpc")
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+)
+
+// extraHeadersEnv holds headers added to every provider request, e.g. for an API gateway.
+// MCP_SAY_<PROVIDER>_EXTRA_HEADERS adds headers for one provider.
+const extraHeadersEnv = "MCP_SAY_EXTRA_HEADERS"
+
+// extraHeaderProviders are the providers that send HTTP or gRPC requests
+var extraHeaderProviders = []string{say.ProviderOpenAI, say.ProviderGoogle, say.ProviderElevenLabs}
+
+// parseExtraHeaders parses a JSON object of header name to value
+func parseExtraHeaders(value string) (map[string]string, error) {
+	var headers map[string]string
+	if err := json.Unmarshal([]byte(value), &headers); err != nil {
+		return nil, fmt.Errorf("must be a JSON object of header name to value: %v", err)
+	}
+	if err := say.ValidateExtraHeaders(headers); err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
+// loadExtraHeaders applies MCP_SAY_EXTRA_HEADERS and the per provider variants
+func loadExtraHeaders() error {
+	for _, provider := range append([]string{""}, extraHeaderProviders...) {
+		env := extraHeadersEnv
+		if provider != "" {
+			env = settingEnv(provider, "extra_headers")
+		}
+		value := getenv(env)
+		if value == "" {
+			continue
+		}
+		headers, err := parseExtraHeaders(value)
+		if err == nil {
+			err = say.SetExtraHeaders(provider, headers)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", env, err)
+		}
+		// Only the names are logged, values may be tokens
+		log.Info("Adding headers to provider requests", "provider", cmp.Or(provider, "all"), "headers", slices.Sorted(maps.Keys(headers)))
+	}
+	return nil
+}
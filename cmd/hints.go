@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/openai/openai-go"
+	"google.golang.org/genai"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// providerErrorHint classifies a provider API error by its status and body and returns
+// what the user can do about it, or "" for errors without a known fix
+func providerErrorHint(err error) string {
+	var elevenLabsErr *say.ElevenLabsError
+	if errors.As(err, &elevenLabsErr) {
+		return elevenLabsHint(elevenLabsErr.StatusCode, elevenLabsErr.Body)
+	}
+	var openaiErr *openai.Error
+	if errors.As(err, &openaiErr) {
+		return openAIHint(openaiErr.StatusCode, openaiErr.Code)
+	}
+	var googleErr genai.APIError
+	if errors.As(err, &googleErr) {
+		return googleHint(googleErr.Code, googleErr.Message)
+	}
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.Unauthenticated, codes.PermissionDenied:
+			return "Check GOOGLE_APPLICATION_CREDENTIALS or run `gcloud auth application-default login`, and that the Cloud Text-to-Speech API is enabled for the project"
+		case codes.ResourceExhausted:
+			return "Google rate limited this request or the project's quota is used up, retry shortly or check the quota in the Cloud console"
+		}
+	}
+	return ""
+}
+
+func elevenLabsHint(code int, body string) string {
+	switch {
+	case strings.Contains(body, "quota_exceeded"):
+		return "The ElevenLabs character quota is used up, check the plan's usage at elevenlabs.io"
+	case strings.Contains(body, "voice_not_found"):
+		return "Run list_voices to see the voice IDs available to this API key"
+	case strings.Contains(body, "model_not_found"):
+		return "Check the model ID, e.g. eleven_multilingual_v2 or eleven_turbo_v2_5"
+	case code == http.StatusUnauthorized:
+		return "Check ELEVENLABS_API_KEY, the key was rejected"
+	case code == http.StatusTooManyRequests:
+		return "ElevenLabs rate limited this request (too many concurrent requests), retry shortly or lower MCP_SAY_ELEVENLABS_CONCURRENCY"
+	}
+	return ""
+}
+
+func openAIHint(code int, errCode string) string {
+	switch {
+	case errCode == "insufficient_quota":
+		return "The OpenAI quota is used up, check the plan and billing details of the account"
+	case code == http.StatusUnauthorized:
+		return "Check OPENAI_API_KEY, and OPENAI_ORG_ID or OPENAI_PROJECT_ID for org- or project-scoped keys"
+	case code == http.StatusTooManyRequests:
+		return "OpenAI rate limited this request, retry shortly or lower MCP_SAY_OPENAI_RPS"
+	case code == http.StatusNotFound || errCode == "model_not_found":
+		return "Check the model name, e.g. gpt-4o-mini-tts, and that the key has access to it"
+	}
+	return ""
+}
+
+func googleHint(code int, message string) string {
+	switch {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden || strings.Contains(message, "API key not valid"):
+		return "Check GOOGLE_AI_API_KEY or GEMINI_API_KEY, the key was rejected"
+	case code == http.StatusTooManyRequests:
+		return "Google rate limited this request or the quota is used up, retry shortly or lower MCP_SAY_GOOGLE_RPS"
+	case code == http.StatusNotFound:
+		return "Check the model name, e.g. gemini-2.5-flash-preview-tts"
+	}
+	return ""
+}
+
+// withProviderHint appends what to do about a provider error, when it is a known one
+func withProviderHint(err error) error {
+	if hint := providerErrorHint(err); hint != "" {
+		return fmt.Errorf("%w. %s", err, hint)
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genai"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestProviderErrorHint(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"elevenlabs 401", &say.ElevenLabsError{StatusCode: 401, Body: `{"detail":{"status":"invalid_api_key"}}`}, "Check ELEVENLABS_API_KEY"},
+		{"elevenlabs quota", &say.ElevenLabsError{StatusCode: 401, Body: `{"detail":{"status":"quota_exceeded"}}`}, "character quota is used up"},
+		{"elevenlabs voice", &say.ElevenLabsError{StatusCode: 400, Body: `{"detail":{"status":"voice_not_found"}}`}, "Run list_voices"},
+		{"elevenlabs 429", &say.ElevenLabsError{StatusCode: 429}, "ElevenLabs rate limited"},
+		{"openai 401", fmt.Errorf("failed to generate TTS audio: %w", &openai.Error{StatusCode: 401}), "Check OPENAI_API_KEY"},
+		{"openai quota", &openai.Error{StatusCode: 429, Code: "insufficient_quota"}, "OpenAI quota is used up"},
+		{"openai 429", &openai.Error{StatusCode: 429, Code: "rate_limit_exceeded"}, "OpenAI rate limited"},
+		{"google key", fmt.Errorf("failed to generate TTS audio: %w", genai.APIError{Code: 400, Message: "API key not valid. Please pass a valid API key."}), "Check GOOGLE_AI_API_KEY"},
+		{"google 429", genai.APIError{Code: 429}, "Google rate limited"},
+		{"google grpc", fmt.Errorf("failed to generate TTS audio: %w", status.Error(codes.PermissionDenied, "denied")), "GOOGLE_APPLICATION_CREDENTIALS"},
+		{"elevenlabs 500", &say.ElevenLabsError{StatusCode: 500}, ""},
+		{"other", errors.New("connection refused"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hint := providerErrorHint(tt.err)
+			if tt.want == "" {
+				assert.Empty(t, hint)
+				assert.Same(t, tt.err, withProviderHint(tt.err))
+				return
+			}
+			assert.Contains(t, hint, tt.want)
+			err := withProviderHint(tt.err)
+			assert.Equal(t, tt.err, errors.Unwrap(err))
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/google/uuid"
+	"github.com/gopxl/beep/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultHistorySize is how many utterances the history keeps, with their audio
+	defaultHistorySize = 50
+	// defaultHistoryLimit is how many utterances the history tool lists by default
+	defaultHistoryLimit = 20
+)
+
+// utterance is one synthesis request kept for the history and replay tools
+type utterance struct {
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"`
+	Provider string    `json:"provider"`
+	Voice    string    `json:"voice,omitempty"`
+	Model    string    `json:"model,omitempty"`
+	// Text is what was sent to the provider, after text replacements
+	Text string `json:"text"`
+	// Duration is the length of the audio, empty when it can't be replayed
+	Duration   string `json:"duration,omitempty"`
+	Replayable bool   `json:"replayable"`
+
+	// audio is nil when a stream was not read to the end
+	audio *say.Audio
+}
+
+// utteranceHistory is a fixed-size ring buffer of recent utterances
+type utteranceHistory struct {
+	mu      sync.Mutex
+	entries []*utterance
+	next    int // slot the next utterance is written to once entries is full
+	size    int // 0 until the first use reads MCP_SAY_HISTORY_SIZE, -1 when disabled
+}
+
+// Utterances synthesized this session
+var history = &utteranceHistory{}
+
+// capacity returns the history size, read from MCP_SAY_HISTORY_SIZE on first use
+func (h *utteranceHistory) capacity() int {
+	if h.size != 0 {
+		return h.size
+	}
+	h.size = defaultHistorySize
+	if value := getenv("MCP_SAY_HISTORY_SIZE"); value != "" {
+		n, err := strconv.Atoi(value)
+		switch {
+		case err != nil || n < 0:
+			log.Warn("Invalid history size, using default", "env", "MCP_SAY_HISTORY_SIZE", "value", value, "default", defaultHistorySize)
+		case n == 0:
+			h.size = -1 // history disabled
+		default:
+			h.size = n
+		}
+	}
+	return h.size
+}
+
+func (h *utteranceHistory) add(u *utterance) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	size := h.capacity()
+	if size < 0 {
+		return
+	}
+	if len(h.entries) < size {
+		h.entries = append(h.entries, u)
+		return
+	}
+	h.entries[h.next] = u
+	h.next = (h.next + 1) % size
+}
+
+// recent returns up to limit utterances, newest first
+func (h *utteranceHistory) recent(limit int) []*utterance {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var out []*utterance
+	for i := range h.entries {
+		if len(out) == limit {
+			break
+		}
+		out = append(out, h.newest(i))
+	}
+	return out
+}
+
+// newest returns the utterance i entries back from the newest, h.mu must be held
+func (h *utteranceHistory) newest(i int) *utterance {
+	// The newest entry is just before next, walk backwards from it
+	return h.entries[(h.next-1-i+2*len(h.entries))%len(h.entries)]
+}
+
+// latest returns the most recent utterance that can be replayed
+func (h *utteranceHistory) latest() (*utterance, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := range h.entries {
+		if u := h.newest(i); u.audio != nil {
+			return u, true
+		}
+	}
+	return nil, false
+}
+
+func (h *utteranceHistory) get(id string) (*utterance, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, u := range h.entries {
+		if u.ID == id {
+			return u, true
+		}
+	}
+	return nil, false
+}
+
+// recordUtterance adds a synthesis to the history, audio is nil when it can't be replayed
+func recordUtterance(ctx context.Context, provider string, opts say.Options, audio *say.Audio) {
+	u := &utterance{
+		ID:         uuid.NewString(),
+		Time:       time.Now(),
+		Provider:   provider,
+		Voice:      opts.Voice,
+		Model:      opts.Model,
+		Text:       opts.Text,
+		Replayable: audio != nil,
+		audio:      audio,
+	}
+	log.Debug("Recorded utterance", "id", u.ID, "provider", provider, "replayable", u.Replayable)
+	history.add(u)
+	notePlayedUtterance(ctx, u)
+}
+
+// recordingReader keeps a copy of a streamed response and adds it to the history
+// once the stream ends. A stream closed early is recorded without audio.
+type recordingReader struct {
+	io.ReadCloser
+	ctx      context.Context
+	buf      bytes.Buffer
+	provider string
+	opts     say.Options
+	// rate is set for raw PCM streams, 0 for encoded ones
+	rate     beep.SampleRate
+	recorded bool
+}
+
+func (r *recordingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.buf.Write(p[:n])
+	if err == io.EOF && !r.recorded {
+		r.recorded = true
+		recordUtterance(r.ctx, r.provider, r.opts, r.audio())
+	}
+	return n, err
+}
+
+func (r *recordingReader) Close() error {
+	if !r.recorded {
+		r.recorded = true
+		recordUtterance(r.ctx, r.provider, r.opts, nil)
+	}
+	return r.ReadCloser.Close()
+}
+
+func (r *recordingReader) audio() *say.Audio {
+	data := r.buf.Bytes()
+	if r.rate != 0 {
+		return &say.Audio{Data: data, Encoding: say.EncodingPCM, SampleRate: r.rate}
+	}
+	encoding, ok := say.SniffEncoding(data)
+	if !ok {
+		// Streaming providers send MP3 unless told otherwise
+		encoding = say.EncodingMP3
+	}
+	return &say.Audio{Data: data, Encoding: encoding}
+}
+
+// audioDuration returns the length of audio, or 0 when it can't be decoded
+func audioDuration(audio *say.Audio) time.Duration {
+	streamer, format, err := audio.Decode()
+	if err != nil {
+		return 0
+	}
+	return format.SampleRate.D(streamer.Len())
+}
+
+// handleHistory lists recent utterances as JSON, newest first
+func handleHistory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("History tool called", "request", request)
+	limit := defaultHistoryLimit
+	if raw, ok := request.GetArguments()["limit"]; ok && raw != nil {
+		l, ok := raw.(float64)
+		if !ok || l < 1 || l != float64(int(l)) {
+			result := mcp.NewToolResultText("Error: limit must be a positive integer")
+			result.IsError = true
+			return result, nil
+		}
+		limit = int(l)
+	}
+
+	entries := []utterance{}
+	for _, u := range history.recent(limit) {
+		entry := *u
+		if u.audio != nil {
+			entry.Duration = audioDuration(u.audio).Round(time.Millisecond).String()
+		}
+		entries = append(entries, entry)
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// handleReplay plays a past utterance's audio again without synthesizing it
+func handleReplay(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Replay tool called", "request", request)
+	arguments := request.GetArguments()
+	id, _ := arguments["id"].(string)
+	u, ok := history.get(id)
+	if !ok {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: no utterance with id %q in the history", id))
+		result.IsError = true
+		return result, nil
+	}
+	if u.audio == nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: utterance %s has no audio to replay, its stream was not played to the end", id))
+		result.IsError = true
+		return result, nil
+	}
+	out, err := outputArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	if out.enabled() {
+		return deliverAudio(ctx, out, u.audio, fmt.Sprintf("Replayed: %s", u.Text)), nil
+	}
+
+	log.Info("Replaying utterance", "id", id, "provider", u.Provider)
+	if err := withAudioHint(player().Play(ctx, u.audio)); err != nil {
+		if errors.Is(err, context.Canceled) {
+			log.Info("Replay cancelled by user")
+			return mcp.NewToolResultText("Replay cancelled"), nil
+		}
+		log.Error("Failed to replay utterance", "id", id, "error", err)
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Replayed: %s (via %s)", u.Text, u.Provider)), nil
+}
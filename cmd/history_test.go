@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func useHistory(t *testing.T, size int) *utteranceHistory {
+	prev := history
+	history = &utteranceHistory{size: size}
+	t.Cleanup(func() { history = prev })
+	return history
+}
+
+func TestUtteranceHistoryRing(t *testing.T) {
+	h := useHistory(t, 3)
+	for i := range 5 {
+		h.add(&utterance{ID: fmt.Sprint(i)})
+	}
+	var ids []string
+	for _, u := range h.recent(10) {
+		ids = append(ids, u.ID)
+	}
+	assert.Equal(t, []string{"4", "3", "2"}, ids, "the oldest utterances are dropped")
+	assert.Len(t, h.recent(2), 2)
+	_, ok := h.get("1")
+	assert.False(t, ok)
+
+	disabled := &utteranceHistory{size: -1}
+	disabled.add(&utterance{ID: "x"})
+	assert.Empty(t, disabled.recent(10))
+}
+
+func TestUtteranceHistoryLatest(t *testing.T) {
+	h := useHistory(t, 3)
+	_, ok := h.latest()
+	assert.False(t, ok)
+
+	h.add(&utterance{ID: "a", audio: &say.Audio{}})
+	h.add(&utterance{ID: "b"})
+	u, ok := h.latest()
+	require.True(t, ok)
+	assert.Equal(t, "a", u.ID, "entries without audio are skipped")
+
+	// The audio server reads the latest entry while tool calls add to the history
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 100 {
+			h.add(&utterance{ID: fmt.Sprint(i), audio: &say.Audio{}})
+		}
+	}()
+	for range 100 {
+		h.latest()
+	}
+	<-done
+	u, ok = h.latest()
+	require.True(t, ok)
+	assert.Equal(t, "99", u.ID)
+}
+
+func TestRecordingReader(t *testing.T) {
+	useHistory(t, 10)
+	opts := say.Options{Text: "Hello", Voice: "nova"}
+
+	// A stream read to the end is replayable
+	r := &recordingReader{ctx: context.Background(), ReadCloser: io.NopCloser(strings.NewReader("ID3 mp3 data")), provider: say.ProviderOpenAI, opts: opts}
+	_, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	// A stream closed early is recorded without audio
+	r = &recordingReader{ctx: context.Background(), ReadCloser: io.NopCloser(strings.NewReader("partial")), provider: say.ProviderOpenAI, opts: opts}
+	require.NoError(t, r.Close())
+
+	entries := history.recent(10)
+	require.Len(t, entries, 2)
+	assert.False(t, entries[0].Replayable)
+	assert.True(t, entries[1].Replayable)
+	assert.Equal(t, "ID3 mp3 data", string(entries[1].audio.Data))
+	assert.Equal(t, say.EncodingMP3, entries[1].audio.Encoding)
+	assert.Equal(t, "nova", entries[1].Voice)
+}
+
+func TestHistoryAndReplay(t *testing.T) {
+	useHistory(t, 10)
+	mock := useMockPlayer(t)
+
+	err := speakText(context.Background(), wrapProvider(say.ProviderGoogle, &fakeProvider{}), say.Options{Text: "Hello", Voice: "Kore"}, chunking{})
+	require.NoError(t, err)
+	mock.PlayedAudio = nil
+
+	result, err := handleHistory(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	var entries []utterance
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &entries))
+	require.Len(t, entries, 1)
+	assert.Equal(t, say.ProviderGoogle, entries[0].Provider)
+	assert.Equal(t, "Hello", entries[0].Text)
+	assert.Equal(t, "100ms", entries[0].Duration)
+	assert.True(t, entries[0].Replayable)
+
+	result, err = handleReplay(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Arguments: map[string]any{"id": entries[0].ID},
+	}})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Len(t, mock.PlayedAudio, 4800, "the kept audio is played without synthesizing again")
+
+	result, err = handleReplay(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Arguments: map[string]any{"id": "missing"},
+	}})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, err = handleHistory(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Arguments: map[string]any{"limit": 0.5},
+	}})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
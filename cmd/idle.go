@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var (
+	// How long without tool calls before the speaker is released, 0 to keep it open
+	idleTimeout time.Duration
+	// Flag to exit instead of only releasing the speaker once idle
+	idleExit bool
+	// Tracks tool calls for the idle timeout, nil when it is disabled
+	idle *idleWatcher
+)
+
+// idleWatcher runs onIdle once no tool call has been in flight for timeout. onIdle runs
+// under the watcher's lock, so a call starting meanwhile waits for it rather than being
+// cut off by --idle-exit or having the device suspended under it. When onIdle reports
+// that it is still busy, the countdown starts over.
+type idleWatcher struct {
+	timeout time.Duration
+	onIdle  func() bool
+
+	mu     sync.Mutex
+	active int
+	timer  *time.Timer
+}
+
+// newIdleWatcher starts counting idle time right away
+func newIdleWatcher(timeout time.Duration, onIdle func() bool) *idleWatcher {
+	w := &idleWatcher{timeout: timeout, onIdle: onIdle}
+	w.timer = time.AfterFunc(timeout, w.fire)
+	return w
+}
+
+// begin marks a tool call in flight, which is never idle time
+func (w *idleWatcher) begin() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.active++
+	w.timer.Stop()
+}
+
+// end restarts the idle countdown once the last call in flight returns
+func (w *idleWatcher) end() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.active--
+	if w.active == 0 {
+		w.timer.Reset(w.timeout)
+	}
+}
+
+func (w *idleWatcher) fire() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.active == 0 && !w.onIdle() {
+		w.timer.Reset(w.timeout)
+	}
+}
+
+// withIdleTimer is a server middleware that keeps the idle timeout from expiring during tool calls
+func withIdleTimer(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if idle == nil {
+			return next(ctx, request)
+		}
+		idle.begin()
+		defer idle.end()
+		return next(ctx, request)
+	}
+}
+
+// onIdle releases the audio device, or exits with --idle-exit. It reports false, waiting
+// another timeout, while audio a call left playing in the background, e.g. the sentences
+// of a say_stream_append stream, is still queued or playing. The device is suspended
+// rather than closed: beep's speaker can't be initialized again once its driver context
+// exists, and that context can't be closed (hajimehoshi/oto#149), so a closed speaker
+// could never play again. Suspending stops the device and the next playback resumes it.
+func onIdle() bool {
+	if n := playbacks.count(); n > 0 || say.SpeakerPlaying() {
+		log.Debug("No tool calls, but audio is still playing", "playback", n)
+		return false
+	}
+	if idleExit {
+		log.Info("No tool calls, exiting", "idle", idleTimeout)
+		os.Exit(0)
+	}
+	suspended, err := say.SuspendSpeaker()
+	if err != nil {
+		log.Warn("Failed to release the audio device", "error", err)
+		return true
+	}
+	if suspended {
+		log.Info("No tool calls, released the audio device until the next playback", "idle", idleTimeout)
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdleWatcher(t *testing.T) {
+	var fired atomic.Int32
+	prev := idle
+	t.Cleanup(func() { idle = prev })
+	idle = newIdleWatcher(30*time.Millisecond, func() bool { fired.Add(1); return true })
+
+	// A call running longer than the timeout keeps the watcher from firing
+	withIdleTimer(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		time.Sleep(60 * time.Millisecond)
+		return mcp.NewToolResultText("ok"), nil
+	})(context.Background(), mcp.CallToolRequest{})
+	assert.Zero(t, fired.Load())
+
+	assert.Eventually(t, func() bool { return fired.Load() == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestIdleWatcherCallDuringOnIdle(t *testing.T) {
+	release := make(chan struct{})
+	entered := make(chan struct{})
+	w := newIdleWatcher(time.Millisecond, func() bool {
+		close(entered)
+		<-release
+		return true
+	})
+	<-entered
+
+	// A call arriving while onIdle decides to suspend or exit waits for it to finish
+	begun := make(chan struct{})
+	go func() {
+		w.begin()
+		close(begun)
+	}()
+	select {
+	case <-begun:
+		t.Fatal("call began while onIdle was running")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-begun
+}
+
+func TestIdleWatcherBusy(t *testing.T) {
+	var fired atomic.Int32
+	newIdleWatcher(5*time.Millisecond, func() bool {
+		// Busy the first two times, e.g. a stream still playing in the background
+		return fired.Add(1) > 2
+	})
+	assert.Eventually(t, func() bool { return fired.Load() == 3 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(3), fired.Load(), "idle once it was done")
+}
+
+func TestOnIdleWaitsForPlayback(t *testing.T) {
+	prev := idleExit
+	t.Cleanup(func() { idleExit = prev })
+	idleExit = true
+
+	// With background playback active it must neither exit nor suspend
+	_, _, done := playbacks.start(context.Background())
+	defer done()
+	assert.False(t, onIdle())
+}
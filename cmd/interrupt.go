@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// errInterrupted cancels playback preempted by a newer interrupting call.
+// It wraps context.Canceled so the preempted call reports a cancellation.
+var errInterrupted = fmt.Errorf("%w: interrupted by new speech", context.Canceled)
+
+type interruptKey struct{}
+
+// playbackTracker tracks the calls currently playing audio so they can be interrupted
+type playbackTracker struct {
+	mu     sync.Mutex
+	nextID int
+	active map[int]*playback
+}
+
+// playback is a call's audio, waiting for a playback slot or playing. Guarded by playbackTracker.mu.
+type playback struct {
+	cancel context.CancelCauseFunc
+	source *playbackSource
+	// started is zero while the playback waits for a slot
+	started time.Time
+	// counter counts the samples played so far, nil when the position is unknown
+	counter *countingStreamer
+	format  beep.Format
+	// total is the length of the audio, 0 when unknown, e.g. for streams
+	total time.Duration
+}
+
+// Audio currently playing in this process
+var playbacks = &playbackTracker{active: make(map[int]*playback)}
+
+// start registers a playback, the returned done func must be called when it ends
+func (t *playbackTracker) start(ctx context.Context) (context.Context, *playback, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	source, _ := ctx.Value(playbackSourceKey{}).(*playbackSource)
+	p := &playback{cancel: cancel, source: source}
+	t.mu.Lock()
+	id := t.nextID
+	t.nextID++
+	t.active[id] = p
+	t.mu.Unlock()
+	return ctx, p, func() {
+		t.mu.Lock()
+		delete(t.active, id)
+		t.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// playing marks a playback as started, for audio whose position can't be counted
+func (t *playbackTracker) playing(p *playback) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p.started = time.Now()
+}
+
+// playingStream marks a playback as started and returns streamer counting the samples played
+func (t *playbackTracker) playingStream(p *playback, streamer beep.Streamer, format beep.Format) beep.Streamer {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p.started = time.Now()
+	if s, ok := streamer.(interface{ Len() int }); ok && s.Len() > 0 {
+		p.total = format.SampleRate.D(s.Len())
+	}
+	p.counter = &countingStreamer{Streamer: streamer}
+	p.format = format
+	return p.counter
+}
+
+// count returns how many playbacks are active, queued or playing
+func (t *playbackTracker) count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.active)
+}
+
+// interrupt stops every active playback and returns how many were stopped
+func (t *playbackTracker) interrupt() int {
+	return t.stop(errInterrupted)
+}
+
+// stop cancels every active playback with cause and returns how many were stopped
+func (t *playbackTracker) stop(cause error) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := len(t.active)
+	for id, p := range t.active {
+		p.cancel(cause)
+		delete(t.active, id)
+	}
+	return n
+}
+
+// interruptDefault reports whether MCP_SAY_INTERRUPT makes every call interrupt
+func interruptDefault() bool {
+	v := getenv("MCP_SAY_INTERRUPT")
+	return v == "1" || v == "true"
+}
+
+// WithInterrupt reads the per-call interrupt argument, falling back to MCP_SAY_INTERRUPT.
+// Interrupting calls stop whatever is playing right before their own audio starts.
+func WithInterrupt(handler ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		interrupt := interruptDefault()
+		if v, ok := request.GetArguments()["interrupt"].(bool); ok {
+			interrupt = v
+		}
+		ctx = context.WithValue(ctx, playbackSourceKey{}, &playbackSource{tool: request.Params.Name})
+		return handler(context.WithValue(ctx, interruptKey{}, interrupt), request)
+	}
+}
+
+// beginPlayback interrupts other playback if the call asked for it, tracks this one
+// and waits for a free playback slot. Calls waiting for a slot can be interrupted too.
+// Other audio is ducked from then until the returned done func is called.
+func beginPlayback(ctx context.Context) (context.Context, *playback, func(), error) {
+	if interrupt, _ := ctx.Value(interruptKey{}).(bool); interrupt {
+		if n := playbacks.interrupt(); n > 0 {
+			log.Info("Interrupted current speech", "playbacks", n)
+		}
+	}
+	ctx, p, done := playbacks.start(ctx)
+	slots := playbackLimit()
+	if err := slots.acquire(ctx); err != nil {
+		done()
+		return ctx, nil, nil, playbackErr(ctx, err)
+	}
+	audioDucker.begin()
+	return ctx, p, func() {
+		audioDucker.end()
+		slots.release()
+		done()
+	}, nil
+}
+
+// playbackErr reports an interrupted playback as errInterrupted
+func playbackErr(ctx context.Context, err error) error {
+	if err != nil && context.Cause(ctx) == errInterrupted {
+		return errInterrupted
+	}
+	return err
+}
+
+// trackedPlayer registers every playback with playbacks so it can be interrupted
+type trackedPlayer struct {
+	say.AudioPlayer
+}
+
+// player returns the active audio player with interrupt tracking
+func player() say.AudioPlayer {
+	return trackedPlayer{audioPlayer}
+}
+
+func (p trackedPlayer) Play(ctx context.Context, audio *say.Audio) error {
+	if mutedPlayback(ctx) {
+		return nil
+	}
+	_, progress := ctx.Value(progressKey{}).(*progressReporter)
+	_, capped := ctx.Value(durationCapKey{}).(*durationCap)
+	_, webhook := ctx.Value(playedUtterancesKey{}).(*playedUtterances)
+	if progress || capped || webhook {
+		// Progress, the duration cap and the webhook's duration are measured on decoded samples
+		streamer, format, err := audio.Decode()
+		if err != nil {
+			return err
+		}
+		return p.PlayStream(ctx, streamer, format)
+	}
+	ctx, current, done, err := beginPlayback(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	playbacks.playing(current)
+	return playbackErr(ctx, p.AudioPlayer.Play(ctx, audio))
+}
+
+func (p trackedPlayer) PlayStream(ctx context.Context, streamer beep.Streamer, format beep.Format) error {
+	if mutedPlayback(ctx) {
+		return nil
+	}
+	ctx, current, done, err := beginPlayback(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	if c, ok := ctx.Value(durationCapKey{}).(*durationCap); ok {
+		var stopCap func()
+		streamer, stopCap = c.take(streamer, format)
+		defer stopCap()
+	}
+	streamer, stop := trackProgress(ctx, streamer, format)
+	defer stop()
+	streamer, counted := countPlayedSamples(ctx, streamer, format)
+	defer counted()
+	streamer = playbacks.playingStream(current, streamer, format)
+	return playbackErr(ctx, p.AudioPlayer.PlayStream(ctx, streamer, format))
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterruptStopsCurrentPlayback(t *testing.T) {
+	prev := audioPlayer
+	audioPlayer = &MockAudioPlayer{Duration: 5 * time.Second}
+	t.Cleanup(func() { audioPlayer = prev })
+
+	audio := pcmAudio(generateTestAudio(24000, 0.1, 440.0))
+	first := make(chan error, 1)
+	go func() {
+		first <- player().Play(context.Background(), audio)
+	}()
+	require.Eventually(t, func() bool {
+		playbacks.mu.Lock()
+		defer playbacks.mu.Unlock()
+		return len(playbacks.active) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	// A second call without interrupt would overlap, with interrupt it preempts
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), interruptKey{}, true), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, player().Play(ctx, audio), context.DeadlineExceeded)
+
+	select {
+	case err := <-first:
+		assert.ErrorIs(t, err, errInterrupted)
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("first playback was not interrupted")
+	}
+	assert.Equal(t, 0, playbacks.interrupt())
+}
+
+func TestInterruptDefault(t *testing.T) {
+	t.Setenv("MCP_SAY_INTERRUPT", "")
+	assert.False(t, interruptDefault())
+	t.Setenv("MCP_SAY_INTERRUPT", "1")
+	assert.True(t, interruptDefault())
+}
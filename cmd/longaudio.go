@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+)
+
+// googleLongAudio sends google_tts text over the synchronous limit to the Cloud
+// Text-to-Speech long audio API, set with --google-long-audio
+var googleLongAudio bool
+
+// useGoogleLongAudio reports whether text is too long for a synchronous request and
+// long audio is enabled
+func useGoogleLongAudio(text string) bool {
+	return googleLongAudio && len(text) > say.GoogleSyncTextLimit
+}
+
+// longAudioOutput returns the gs:// URI long audio is written to: a new object named
+// after now in bucket, which is a bucket name or a gs://bucket/folder URI
+func longAudioOutput(bucket string, now time.Time) (string, error) {
+	if bucket == "" {
+		return "", fmt.Errorf("gcs_bucket is required for text over %d bytes with --google-long-audio, Google writes long audio to Cloud Storage", say.GoogleSyncTextLimit)
+	}
+	if !strings.HasPrefix(bucket, "gs://") {
+		bucket = "gs://" + bucket
+	}
+	name, folder, err := say.ParseGCSURI(bucket)
+	if err != nil {
+		return "", fmt.Errorf("invalid gcs_bucket: %v", err)
+	}
+	if folder = strings.Trim(folder, "/"); folder != "" {
+		folder += "/"
+	}
+	return fmt.Sprintf("gs://%s/%smcp-say-%s.wav", name, folder, now.UTC().Format("20060102T150405.000000000")), nil
+}
+
+// synthesizeGoogleLongAudio synthesizes text with the long audio API, reporting the
+// operation's progress to clients that asked for it
+func synthesizeGoogleLongAudio(ctx context.Context, params say.GoogleSpeechParams, bucket string) (*say.Audio, error) {
+	output, err := longAudioOutput(bucket, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	long := say.GoogleLongAudioParams{
+		GoogleSpeechParams: params,
+		Output:             output,
+		Project:            getenv("GOOGLE_CLOUD_PROJECT"),
+	}
+	if reporter, ok := ctx.Value(progressKey{}).(*progressReporter); ok {
+		long.Progress = reporter.percent
+	}
+	log.Info("Synthesizing via Google long audio", "bytes", len(params.Text), "voice", params.Voice, "model", params.Model, "output", output)
+	audio, err := say.SynthesizeGoogleLongAudio(ctx, long)
+	if err != nil {
+		return nil, err
+	}
+	recordCost(ctx, say.ProviderGoogle, params.Model, params.Text)
+	return audio, nil
+}
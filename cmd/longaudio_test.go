@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLongAudioOutput(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 30, 45, 123, time.UTC)
+	for bucket, expected := range map[string]string{
+		"tts-out":                  "gs://tts-out/mcp-say-20250701T123045.000000123.wav",
+		"gs://tts-out":             "gs://tts-out/mcp-say-20250701T123045.000000123.wav",
+		"gs://tts-out/books/":      "gs://tts-out/books/mcp-say-20250701T123045.000000123.wav",
+		"gs://tts-out/books/vol1/": "gs://tts-out/books/vol1/mcp-say-20250701T123045.000000123.wav",
+	} {
+		output, err := longAudioOutput(bucket, now)
+		require.NoError(t, err, bucket)
+		assert.Equal(t, expected, output, bucket)
+	}
+	_, err := longAudioOutput("", now)
+	assert.ErrorContains(t, err, "gcs_bucket is required")
+	_, err = longAudioOutput("gs://", now)
+	assert.Error(t, err)
+}
+
+func TestGoogleTTSLongAudio(t *testing.T) {
+	mock := useMockPlayer(t)
+	useTestMode(t)
+	t.Setenv("GOOGLE_AI_API_KEY", "ai-test")
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "my-project")
+	prev := googleLongAudio
+	googleLongAudio = true
+	t.Cleanup(func() { googleLongAudio = prev })
+
+	long := strings.Repeat("Once upon a time. ", 300)
+	require.Greater(t, len(long), say.GoogleSyncTextLimit)
+
+	call := func(arguments map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = arguments
+		result, err := handleGoogleTTS(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := call(map[string]any{"text": long})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "gcs_bucket is required")
+
+	result = call(map[string]any{"text": long, "gcs_bucket": "tts-out", "seed": 7.0})
+	assert.True(t, result.IsError, "seed is a Gemini API setting")
+
+	result = call(map[string]any{"text": long, "gcs_bucket": "tts-out"})
+	assert.False(t, result.IsError, result.Content[0].(mcp.TextContent).Text)
+	assert.True(t, mock.Played)
+
+	// Short text takes the usual path, the bucket is ignored
+	result = call(map[string]any{"text": "Hello", "gcs_bucket": "tts-out"})
+	assert.False(t, result.IsError)
+
+	googleLongAudio = false
+	result = call(map[string]any{"text": "Hello", "gcs_bucket": "tts-out"})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "--google-long-audio")
+}
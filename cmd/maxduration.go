@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type durationCapKey struct{}
+
+// durationCap stops a tool call's playback once it has played for limit. A call can
+// play several streams (e.g. chunked text), the cap counts across all of them.
+type durationCap struct {
+	limit time.Duration
+
+	mu        sync.Mutex
+	played    time.Duration
+	total     time.Duration // length of the audio offered for playback, -1 when unknown
+	truncated bool
+}
+
+// take limits streamer to what is left of the cap. The returned stop func must be
+// called once playback ends to account for what was played.
+func (c *durationCap) take(streamer beep.Streamer, format beep.Format) (beep.Streamer, func()) {
+	c.mu.Lock()
+	remaining := format.SampleRate.N(c.limit - c.played)
+	if c.total >= 0 {
+		if s, ok := streamer.(interface{ Len() int }); ok && s.Len() > 0 {
+			c.total += format.SampleRate.D(s.Len())
+		} else {
+			c.total = -1
+		}
+	}
+	c.mu.Unlock()
+
+	counter := &countingStreamer{Streamer: beep.Take(max(remaining, 0), streamer)}
+	return counter, func() {
+		played := int(counter.samples.Load())
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.played += format.SampleRate.D(played)
+		if played >= remaining {
+			if !c.truncated {
+				log.Info("Stopped playback at max duration", "max", c.limit)
+			}
+			c.truncated = true
+		}
+	}
+}
+
+// summary describes how much audio was played, empty when nothing was
+func (c *durationCap) summary() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.truncated {
+		if c.played == 0 {
+			return ""
+		}
+		return fmt.Sprintf("Played %v of audio", c.played.Round(time.Millisecond))
+	}
+	line := fmt.Sprintf("Stopped at max_duration_ms after %v of audio", c.played.Round(time.Millisecond))
+	if c.total > c.played {
+		line += fmt.Sprintf(" (of %v)", c.total.Round(time.Millisecond))
+	}
+	return line
+}
+
+// WithMaxDuration reads the optional max_duration_ms argument and stops the call's
+// playback once it is reached, adding how much was played to the result
+func WithMaxDuration(handler ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		raw, ok := request.GetArguments()["max_duration_ms"]
+		if !ok || raw == nil {
+			return handler(ctx, request)
+		}
+		ms, ok := raw.(float64)
+		if !ok || ms <= 0 {
+			result := mcp.NewToolResultText("Error: max_duration_ms must be a positive number of milliseconds")
+			result.IsError = true
+			return result, nil
+		}
+		c := &durationCap{limit: time.Duration(ms * float64(time.Millisecond))}
+		result, err := handler(context.WithValue(ctx, durationCapKey{}, c), request)
+		if err == nil && result != nil && !result.IsError {
+			if line := c.summary(); line != "" {
+				result.Content = append(result.Content, mcp.NewTextContent(line))
+			}
+		}
+		return result, err
+	}
+}
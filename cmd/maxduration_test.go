@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMaxDuration(t *testing.T) {
+	// Each clip is 100ms of 24kHz audio
+	speakTimes := func(n int) ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			for range n {
+				if err := speakText(ctx, &fakeProvider{}, say.Options{Text: "Hello"}, chunking{}); err != nil {
+					return nil, err
+				}
+			}
+			return mcp.NewToolResultText("Speech completed"), nil
+		}
+	}
+	call := func(handler ToolHandlerFunc, arguments map[string]any) *mcp.CallToolResult {
+		result, err := WithMaxDuration(handler)(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: arguments}})
+		require.NoError(t, err)
+		return result
+	}
+	lastLine := func(result *mcp.CallToolResult) string {
+		return result.Content[len(result.Content)-1].(mcp.TextContent).Text
+	}
+
+	t.Run("truncated", func(t *testing.T) {
+		mock := useMockPlayer(t)
+		result := call(speakTimes(1), map[string]any{"max_duration_ms": 50.0})
+		assert.Equal(t, 1200, mock.PlayedSamples)
+		assert.Equal(t, "Stopped at max_duration_ms after 50ms of audio (of 100ms)", lastLine(result))
+	})
+
+	t.Run("across streams", func(t *testing.T) {
+		mock := useMockPlayer(t)
+		result := call(speakTimes(3), map[string]any{"max_duration_ms": 150.0})
+		assert.Equal(t, 3600, mock.PlayedSamples)
+		assert.Equal(t, "Stopped at max_duration_ms after 150ms of audio (of 300ms)", lastLine(result))
+	})
+
+	t.Run("under the cap", func(t *testing.T) {
+		mock := useMockPlayer(t)
+		result := call(speakTimes(1), map[string]any{"max_duration_ms": 1000.0})
+		assert.Equal(t, 2400, mock.PlayedSamples)
+		assert.Equal(t, "Played 100ms of audio", lastLine(result))
+	})
+
+	t.Run("no cap", func(t *testing.T) {
+		mock := useMockPlayer(t)
+		result := call(speakTimes(1), map[string]any{})
+		// Encoded audio goes to the player as is
+		assert.Len(t, mock.PlayedAudio, 2*2400)
+		assert.Len(t, result.Content, 1)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		result := call(speakTimes(1), map[string]any{"max_duration_ms": -5.0})
+		assert.True(t, result.IsError)
+		assert.Contains(t, lastLine(result), "max_duration_ms must be a positive number")
+	})
+}
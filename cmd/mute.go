@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// mutedNote is added to the result of a call whose playback was skipped while muted
+const mutedNote = "Output is muted, nothing was played"
+
+// Set by the mute and unmute tools and MCP_SAY_MUTED, consulted before every playback
+var muted atomic.Bool
+
+// mutedPlayback reports whether playback should be skipped because output is muted,
+// marking the call so its result says so
+func mutedPlayback(ctx context.Context) bool {
+	if !muted.Load() {
+		return false
+	}
+	if stats, ok := ctx.Value(callStatsKey{}).(*callStats); ok {
+		stats.mu.Lock()
+		stats.muted = true
+		stats.mu.Unlock()
+	}
+	log.Debug("Skipped playback, output is muted")
+	return true
+}
+
+// handleMute silences playback until unmute, stopping whatever is playing now
+func handleMute(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Mute tool called", "request", request)
+	muted.Store(true)
+	n := playbacks.interrupt()
+	log.Info("Output muted", "stopped", n)
+	return mcp.NewToolResultText("Output muted. TTS tools still synthesize and save audio, but nothing plays until unmute"), nil
+}
+
+// handleUnmute resumes playback
+func handleUnmute(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Unmute tool called", "request", request)
+	muted.Store(false)
+	log.Info("Output unmuted")
+	return mcp.NewToolResultText("Output unmuted"), nil
+}
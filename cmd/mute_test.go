@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMute(t *testing.T) {
+	mock := useMockPlayer(t)
+	t.Cleanup(func() { muted.Store(false) })
+	tone := WithResultFormat(handleTone)
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"frequency": 440.0, "duration_ms": 100.0}
+
+	_, err := handleMute(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	result, err := tone(context.Background(), request)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	require.Len(t, result.Content, 2)
+	assert.Equal(t, mutedNote, result.Content[1].(mcp.TextContent).Text)
+	assert.False(t, mock.Played)
+
+	status, err := handleStatus(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.Contains(t, status.Content[0].(mcp.TextContent).Text, "Audio playback: muted")
+
+	_, err = handleUnmute(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	result, err = tone(context.Background(), request)
+	require.NoError(t, err)
+	assert.Len(t, result.Content, 1)
+	assert.True(t, mock.Played)
+}
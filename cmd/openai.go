@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// handleOpenAITTS synthesizes text with OpenAI TTS and plays it
+func handleOpenAITTS(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("OpenAI TTS tool called", "request", request)
+	arguments := request.GetArguments()
+	text, err := textArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	out, err := outputArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	if text == "" {
+		result := mcp.NewToolResultText("Error: Empty text provided")
+		result.IsError = true
+		return result, nil
+	}
+
+	// Get configuration from arguments
+	voice := providerSetting(arguments, say.ProviderOpenAI, "voice", say.DefaultOpenAIVoice)
+	model := providerSetting(arguments, say.ProviderOpenAI, "model", say.DefaultOpenAIModel)
+
+	speed := 1.0
+	if s, ok := arguments["speed"].(float64); ok {
+		if s >= 0.25 && s <= 4.0 {
+			speed = s
+		} else {
+			log.Warn("Speed out of range, using default", "provided", s, "default", 1.0)
+		}
+	}
+
+	// Get voice instructions from arguments or environment variable
+	instructions := ""
+	var ignoredNote string
+	if inst, ok := arguments["instructions"].(string); ok && inst != "" {
+		instructions = inst
+	} else {
+		// Fallback to environment variable
+		instructions = getenv("OPENAI_TTS_INSTRUCTIONS")
+	}
+	if instructions != "" && !say.OpenAISupportsInstructions(model) {
+		log.Warn("Model does not support instructions, ignoring them", "model", model)
+		if inst, _ := arguments["instructions"].(string); inst != "" {
+			ignoredNote = fmt.Sprintf("Note: instructions were ignored, %s does not support them. Use %s for tone control", model, say.DefaultOpenAIModel)
+		}
+		instructions = ""
+	}
+
+	extra, err := extraArgument(arguments)
+	if err == nil && extra != nil && say.IsOpenAIAudioModel(model) {
+		err = fmt.Errorf("extra is not supported with %s, it only applies to the speech endpoint", model)
+	}
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	// Basic validation for instructions length (OpenAI has reasonable limits)
+	if len(instructions) > 1000 {
+		log.Warn("Instructions are very long, may exceed API limits", "length", len(instructions))
+	}
+
+	logFields := []any{
+		"model", model,
+		"voice", voice,
+		"speed", speed,
+		"text", text,
+	}
+	if instructions != "" {
+		logFields = append(logFields, "instructions", instructions)
+	}
+	log.Info("Speaking text via OpenAI TTS", logFields...)
+	account := openAIAccount()
+	var provider say.Provider = &say.OpenAI{APIKey: account.APIKey, Organization: account.Organization, Project: account.Project, Instructions: instructions, Extra: extra}
+	if say.IsOpenAIAudioModel(model) {
+		// Audio chat models stream PCM through the chat completions API instead
+		provider = &say.OpenAIAudio{APIKey: account.APIKey, Organization: account.Organization, Project: account.Project, Instructions: instructions}
+	}
+	opts := say.Options{
+		Text:  text,
+		Voice: voice,
+		Model: model,
+		Speed: speed,
+	}
+	chunks := chunkingArgument(arguments)
+	align, _ := arguments["align"].(bool)
+	stream, _ := arguments["stream"].(bool)
+
+	var alignment func() ([]say.WordTiming, error)
+	switch {
+	case align:
+		// Alignment needs the whole clip, so synthesize it first and transcribe while it plays
+		audio, err := renderText(ctx, wrapProvider(say.ProviderOpenAI, provider), opts, chunks)
+		if err != nil {
+			log.Error("OpenAI TTS failed", "error", err)
+			result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+			result.IsError = true
+			return result, nil
+		}
+		alignment = alignAsync(ctx, audio)
+		if out.enabled() {
+			return withNote(withAlignment(deliverAudio(ctx, out, audio, synthesizedMessage(text)), alignment), ignoredNote), nil
+		}
+		err = withAudioHint(player().Play(ctx, audio))
+	case out.enabled():
+		audio, err := renderText(ctx, wrapProvider(say.ProviderOpenAI, provider), opts, chunks)
+		if err != nil {
+			log.Error("OpenAI TTS failed", "error", err)
+			result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+			result.IsError = true
+			return result, nil
+		}
+		return withNote(deliverAudio(ctx, out, audio, synthesizedMessage(text)), ignoredNote), nil
+	default:
+		if openAI, ok := provider.(*say.OpenAI); ok && stream {
+			// Raw PCM plays as it arrives, saved and returned audio stays MP3
+			provider = (*say.OpenAIPCM)(openAI)
+		}
+		err = speakText(ctx, wrapProvider(say.ProviderOpenAI, provider), opts, chunks)
+	}
+
+	if errors.Is(err, context.Canceled) {
+		log.Info("OpenAI TTS audio playback cancelled by user")
+		return mcp.NewToolResultText("OpenAI TTS audio playback cancelled"), nil
+	}
+	if err != nil {
+		log.Error("OpenAI TTS failed", "error", err)
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	log.Debug("OpenAI TTS audio playback completed normally")
+	if suppressSpeakingOutput {
+		return withNote(withAlignment(mcp.NewToolResultText("Speech completed"), alignment), ignoredNote), nil
+	}
+	return withNote(withAlignment(mcp.NewToolResultText(fmt.Sprintf("Speaking: %s (via OpenAI TTS with voice %s)", text, voice)), alignment), ignoredNote), nil
+}
+
+// withNote appends note to a successful result, if there is one
+func withNote(result *mcp.CallToolResult, note string) *mcp.CallToolResult {
+	if note != "" && !result.IsError {
+		result.Content = append(result.Content, mcp.NewTextContent(note))
+	}
+	return result
+}
+
+// openAIAccount returns the OpenAI API key, organization and project from the environment or config file
+func openAIAccount() say.OpenAIAccount {
+	return say.OpenAIAccount{
+		APIKey:       providerAPIKey(say.ProviderOpenAI),
+		Organization: getenv("OPENAI_ORG_ID"),
+		Project:      getenv("OPENAI_PROJECT_ID"),
+	}
+}
+
+// alignAsync starts transcribing audio for word timestamps and returns a func waiting for them
+func alignAsync(ctx context.Context, audio *say.Audio) func() ([]say.WordTiming, error) {
+	type alignResult struct {
+		words []say.WordTiming
+		err   error
+	}
+	done := make(chan alignResult, 1)
+	go func() {
+		words, err := say.AlignOpenAI(ctx, openAIAccount(), audio)
+		done <- alignResult{words, err}
+	}()
+	return func() ([]say.WordTiming, error) {
+		r := <-done
+		return r.words, r.err
+	}
+}
+
+// withAlignment appends the word timestamps as JSON to a successful result.
+// A failed alignment is reported but doesn't fail the call, the speech already played.
+func withAlignment(result *mcp.CallToolResult, alignment func() ([]say.WordTiming, error)) *mcp.CallToolResult {
+	if alignment == nil || result.IsError {
+		return result
+	}
+	words, err := alignment()
+	if err != nil {
+		log.Warn("Failed to align OpenAI TTS audio", "error", err)
+		result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf("Alignment failed: %v", err)))
+		return result
+	}
+	data, err := json.Marshal(struct {
+		Words []say.WordTiming `json:"words"`
+	}{words})
+	if err != nil {
+		log.Warn("Failed to encode alignment", "error", err)
+		return result
+	}
+	result.Content = append(result.Content, mcp.NewTextContent(string(data)))
+	return result
+}
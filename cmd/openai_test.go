@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAlignment(t *testing.T) {
+	t.Run("appends word timings", func(t *testing.T) {
+		alignment := func() ([]say.WordTiming, error) {
+			return []say.WordTiming{{Word: "Hello", Start: 0, End: 0.42}, {Word: "world", Start: 0.42, End: 0.9}}, nil
+		}
+		result := withAlignment(mcp.NewToolResultText("Speech completed"), alignment)
+		require.Len(t, result.Content, 2)
+		assert.JSONEq(t, `{"words":[{"word":"Hello","start":0,"end":0.42},{"word":"world","start":0.42,"end":0.9}]}`,
+			result.Content[1].(mcp.TextContent).Text)
+	})
+
+	t.Run("failure is not fatal", func(t *testing.T) {
+		alignment := func() ([]say.WordTiming, error) { return nil, errors.New("quota exceeded") }
+		result := withAlignment(mcp.NewToolResultText("Speech completed"), alignment)
+		assert.False(t, result.IsError)
+		require.Len(t, result.Content, 2)
+		assert.Contains(t, result.Content[1].(mcp.TextContent).Text, "Alignment failed: quota exceeded")
+	})
+
+	t.Run("no alignment requested", func(t *testing.T) {
+		result := withAlignment(mcp.NewToolResultText("Speech completed"), nil)
+		assert.Len(t, result.Content, 1)
+	})
+}
+
+func TestOpenAIIgnoredInstructions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Write([]byte("ID3"))
+	}))
+	defer server.Close()
+	t.Setenv("OPENAI_BASE_URL", server.URL)
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+
+	call := func(arguments map[string]any) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = arguments
+		result, err := handleOpenAITTS(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		return result
+	}
+
+	result := call(map[string]any{"text": "Hello", "model": "tts-1", "instructions": "Whisper", "return_audio": true})
+	last := result.Content[len(result.Content)-1].(mcp.TextContent).Text
+	assert.Equal(t, "Note: instructions were ignored, tts-1 does not support them. Use gpt-4o-mini-tts for tone control", last)
+
+	// Instructions from the environment are dropped without a note
+	t.Setenv("OPENAI_TTS_INSTRUCTIONS", "Whisper")
+	result = call(map[string]any{"text": "Hello", "model": "tts-1", "return_audio": true})
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			assert.NotContains(t, text.Text, "instructions were ignored")
+		}
+	}
+}
+
+func TestOpenAIStreamPCM(t *testing.T) {
+	var formats []any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		formats = append(formats, body["response_format"])
+		w.Write(make([]byte, 4800)) // 100ms of 24kHz 16-bit silence
+	}))
+	defer server.Close()
+	t.Setenv("OPENAI_BASE_URL", server.URL)
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	mock := useMockPlayer(t)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"text": "Hello", "stream": true}
+	result, err := handleOpenAITTS(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, []any{"pcm"}, formats)
+	assert.Equal(t, 2400, mock.PlayedSamples)
+}
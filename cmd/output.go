@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// noAudioHint is appended to playback errors when there is no usable audio device
+const noAudioHint = "Set output_file to save the audio to a file or return_audio to get it inline instead"
+
+// audioOutput is where a tool call sends its audio instead of the speaker
+type audioOutput struct {
+	// File is the path to write the audio to (output_file)
+	File string
+	// Inline embeds the audio in the tool result (return_audio)
+	Inline bool
+}
+
+// enabled reports whether the audio should be delivered instead of played
+func (o audioOutput) enabled() bool {
+	return o.File != "" || o.Inline
+}
+
+// errNoAudio is returned in headless mode when a call asks for neither output mode
+var errNoAudio = errors.New("audio playback is disabled on this server (--no-audio). Set output_file to save the audio to a file or return_audio to get it inline")
+
+// outputArgument reads the optional output_file and return_audio tool arguments.
+// In headless mode one of them is required, so nothing is synthesized that can't be delivered.
+func outputArgument(arguments map[string]any) (audioOutput, error) {
+	var out audioOutput
+	if raw, ok := arguments["output_file"]; ok && raw != nil {
+		path, ok := raw.(string)
+		if !ok {
+			return out, errors.New("output_file must be a string")
+		}
+		if path != "" {
+			info, err := os.Stat(filepath.Dir(path))
+			if err != nil || !info.IsDir() {
+				return out, fmt.Errorf("output_file directory does not exist: %s", filepath.Dir(path))
+			}
+			out.File = path
+		}
+	}
+	if raw, ok := arguments["return_audio"]; ok && raw != nil {
+		inline, ok := raw.(bool)
+		if !ok {
+			return out, errors.New("return_audio must be a boolean")
+		}
+		out.Inline = inline
+	}
+	if noAudio && !out.enabled() {
+		return out, errNoAudio
+	}
+	return out, nil
+}
+
+// withAudioHint points the user at the non-playback modes when there is no audio device
+func withAudioHint(err error) error {
+	if errors.Is(err, say.ErrNoAudioDevice) {
+		return fmt.Errorf("%w. %s", err, noAudioHint)
+	}
+	return err
+}
+
+// renderText synthesizes opts.Text without playing it, joining sentences with
+// silence or a crossfade when chunking is enabled
+func renderText(ctx context.Context, provider say.Provider, opts say.Options, chunks chunking) (*say.Audio, error) {
+	if opts.Text == "" {
+		return nil, errors.New("empty text provided")
+	}
+	if !chunks.enabled() {
+		return provider.Synthesize(ctx, opts)
+	}
+	streamer, format, err := say.RenderSegments(ctx, sentenceSegments(opts.Text, chunks), say.ProviderSegments(provider, opts))
+	if err != nil {
+		return nil, err
+	}
+	return captureStream(streamer, format), nil
+}
+
+// captureStream drains decoded samples into 16-bit mono PCM audio
+func captureStream(streamer beep.Streamer, format beep.Format) *say.Audio {
+	var buf bytes.Buffer
+	samples := make([][2]float64, 512)
+	for {
+		n, ok := streamer.Stream(samples)
+		for _, s := range samples[:n] {
+			mono := math.Max(-1, math.Min(1, (s[0]+s[1])/2))
+			binary.Write(&buf, binary.LittleEndian, int16(mono*math.MaxInt16))
+		}
+		if !ok {
+			break
+		}
+	}
+	return &say.Audio{Data: buf.Bytes(), Encoding: say.EncodingPCM, SampleRate: format.SampleRate}
+}
+
+// Output sample rates accepted by the sample_rate argument
+var supportedSampleRates = []int{8000, 16000, 22050, 24000, 44100, 48000}
+
+// sampleRateArgument reads the optional sample_rate tool argument, 0 keeps the provider's rate
+func sampleRateArgument(arguments map[string]any) (beep.SampleRate, error) {
+	raw, ok := arguments["sample_rate"]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+	if rate, ok := raw.(float64); ok && slices.Contains(supportedSampleRates, int(rate)) && rate == math.Trunc(rate) {
+		return beep.SampleRate(rate), nil
+	}
+	return 0, fmt.Errorf("sample_rate must be one of %v", supportedSampleRates)
+}
+
+// resampleAudio converts audio to 16-bit mono PCM at rate, audio already at rate is returned as is
+func resampleAudio(audio *say.Audio, rate beep.SampleRate) (*say.Audio, error) {
+	streamer, format, err := audio.Decode()
+	if err != nil {
+		return nil, err
+	}
+	if format.SampleRate == rate && audio.Encoding == say.EncodingPCM {
+		return audio, nil
+	}
+	log.Debug("Resampling audio", "from", format.SampleRate, "to", rate)
+	return captureStream(beep.Resample(4, format.SampleRate, rate, streamer), beep.Format{SampleRate: rate, NumChannels: 1, Precision: 2}), nil
+}
+
+// deliverAudio writes audio to the requested outputs and returns the tool result
+func deliverAudio(ctx context.Context, out audioOutput, audio *say.Audio, summary string) *mcp.CallToolResult {
+	data := audio.Encoded()
+	result := &mcp.CallToolResult{}
+	if out.File != "" {
+		if err := writeFileAtomic(ctx, out.File, bytes.NewReader(data)); err != nil {
+			log.Error("Failed to write audio file", "path", out.File, "error", err)
+			result := mcp.NewToolResultText(fmt.Sprintf("Error: failed to write output_file: %v", err))
+			result.IsError = true
+			return result
+		}
+		log.Info("Saved audio", "path", out.File, "bytes", len(data))
+		summary += fmt.Sprintf("\nSaved %s audio to %s (%d bytes)", audio.MIMEType(), out.File, len(data))
+	}
+	result.Content = append(result.Content, mcp.NewTextContent(summary))
+	if out.Inline {
+		result.Content = append(result.Content, mcp.NewAudioContent(base64.StdEncoding.EncodeToString(data), audio.MIMEType()))
+	}
+	return result
+}
+
+// synthesizedMessage is the result text for speech that was rendered but not played
+func synthesizedMessage(text string) string {
+	if suppressSpeakingOutput {
+		return "Speech synthesized"
+	}
+	return fmt.Sprintf("Synthesized: %s", text)
+}
+
+// writeFileAtomic writes r to a temp file next to path and renames it into place once
+// complete, so readers never see a half-written file. The temp file is removed on
+// failure, including when ctx is cancelled before the rename.
+func writeFileAtomic(ctx context.Context, path string, r io.Reader) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = io.Copy(tmp, ctxReader{ctx, r}); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(0o644); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ctxReader stops reading once ctx is cancelled
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/gopxl/beep/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputArgument(t *testing.T) {
+	dir := t.TempDir()
+
+	out, err := outputArgument(map[string]any{})
+	require.NoError(t, err)
+	assert.False(t, out.enabled())
+
+	path := filepath.Join(dir, "speech.wav")
+	out, err = outputArgument(map[string]any{"output_file": path, "return_audio": true})
+	require.NoError(t, err)
+	assert.Equal(t, audioOutput{File: path, Inline: true}, out)
+
+	_, err = outputArgument(map[string]any{"output_file": filepath.Join(dir, "missing", "speech.wav")})
+	assert.ErrorContains(t, err, "directory does not exist")
+
+	_, err = outputArgument(map[string]any{"return_audio": "yes"})
+	assert.ErrorContains(t, err, "return_audio must be a boolean")
+}
+
+func TestRenderTextAndDeliver(t *testing.T) {
+	provider := &fakeProvider{}
+	audio, err := renderText(context.Background(), provider, say.Options{Text: "One. Two."}, chunking{pause: 200 * time.Millisecond})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"One.", "Two."}, provider.texts)
+	assert.Equal(t, say.EncodingPCM, audio.Encoding)
+	// Two 100ms sentences joined by a 200ms pause at 24kHz, 2 bytes per sample
+	assert.Len(t, audio.Data, (2400+4800+2400)*2)
+
+	path := filepath.Join(t.TempDir(), "speech.wav")
+	result := deliverAudio(context.Background(), audioOutput{File: path, Inline: true}, audio, "Synthesized: One. Two.")
+	require.False(t, result.IsError)
+	require.Len(t, result.Content, 2)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Saved audio/wav audio to "+path)
+
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "RIFF", string(written[:4]))
+
+	inline := result.Content[1].(mcp.AudioContent)
+	assert.Equal(t, "audio/wav", inline.MIMEType)
+	decoded, err := base64.StdEncoding.DecodeString(inline.Data)
+	require.NoError(t, err)
+	assert.Equal(t, written, decoded)
+}
+
+func TestResampleAudio(t *testing.T) {
+	rate, err := sampleRateArgument(map[string]any{})
+	require.NoError(t, err)
+	assert.Zero(t, rate)
+	rate, err = sampleRateArgument(map[string]any{"sample_rate": float64(16000)})
+	require.NoError(t, err)
+	assert.Equal(t, beep.SampleRate(16000), rate)
+	for _, bad := range []any{float64(11025), 16000.5, "16000"} {
+		_, err = sampleRateArgument(map[string]any{"sample_rate": bad})
+		assert.ErrorContains(t, err, "sample_rate must be one of")
+	}
+
+	audio, err := renderText(context.Background(), &fakeProvider{}, say.Options{Text: "Hello"}, chunking{})
+	require.NoError(t, err)
+	same, err := resampleAudio(audio, audio.SampleRate)
+	require.NoError(t, err)
+	assert.Same(t, audio, same)
+
+	// 100ms at 24kHz becomes 100ms at 8kHz, give or take the resampler's edge samples
+	resampled, err := resampleAudio(audio, 8000)
+	require.NoError(t, err)
+	assert.Equal(t, beep.SampleRate(8000), resampled.SampleRate)
+	assert.InDelta(t, 800*2, len(resampled.Data), 16)
+	encoded := resampled.Encoded()
+	assert.Equal(t, uint32(8000), binary.LittleEndian.Uint32(encoded[24:28]))
+}
+
+func TestNoAudioPlayerSuggestsOutputModes(t *testing.T) {
+	prev := audioPlayer
+	audioPlayer = say.NoAudioPlayer{}
+	t.Cleanup(func() { audioPlayer = prev })
+
+	err := speakText(context.Background(), &fakeProvider{}, say.Options{Text: "Hello"}, chunking{})
+	require.ErrorIs(t, err, say.ErrNoAudioDevice)
+	assert.Contains(t, err.Error(), "output_file")
+}
+
+func TestOutputArgumentNoAudio(t *testing.T) {
+	prev := noAudio
+	noAudio = true
+	t.Cleanup(func() { noAudio = prev })
+
+	_, err := outputArgument(map[string]any{"text": "Hello"})
+	assert.ErrorIs(t, err, errNoAudio)
+
+	out, err := outputArgument(map[string]any{"return_audio": true})
+	require.NoError(t, err)
+	assert.True(t, out.Inline)
+
+	result, err := handleOpenAITTS(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Arguments: map[string]any{"text": "Hello"},
+	}})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "--no-audio")
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "speech.mp3")
+
+	require.NoError(t, writeFileAtomic(ctx, path, bytes.NewReader([]byte("complete audio"))))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "complete audio", string(data))
+
+	// A write failing half way leaves the previous file untouched and no temp files behind
+	failing := io.MultiReader(bytes.NewReader([]byte("partial")), iotest.ErrReader(errors.New("connection reset")))
+	assert.ErrorContains(t, writeFileAtomic(ctx, path, failing), "connection reset")
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "complete audio", string(data))
+
+	missing := filepath.Join(dir, "new.mp3")
+	failing = io.MultiReader(bytes.NewReader([]byte("partial")), iotest.ErrReader(errors.New("connection reset")))
+	assert.Error(t, writeFileAtomic(ctx, missing, failing))
+	assert.NoFileExists(t, missing)
+
+	// A cancelled call never replaces the file
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, writeFileAtomic(cancelled, path, bytes.NewReader([]byte("new audio"))), context.Canceled)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "complete audio", string(data))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temp files are removed")
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// handlePlayAudio plays base64 audio passed inline as a data URI or with its mime type
+func handlePlayAudio(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// The request holds the whole clip, log only its size
+	arguments := request.GetArguments()
+	data, ok := arguments["data"].(string)
+	if !ok || data == "" {
+		result := mcp.NewToolResultText("Error: data must be a non-empty base64 string or data URI")
+		result.IsError = true
+		return result, nil
+	}
+	mimeType, _ := arguments["mime_type"].(string)
+	log.Debug("Play audio tool called", "bytes", len(data), "mimeType", mimeType)
+
+	audio, err := say.DecodeAudioData(data, mimeType)
+	if err == nil {
+		// Decode up front so corrupt audio fails before the speaker is opened
+		_, _, err = audio.Decode()
+	}
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	log.Info("Playing inline audio", "encoding", audio.Encoding, "bytes", len(audio.Data))
+	if err := withAudioHint(player().Play(ctx, audio)); err != nil {
+		if errors.Is(err, context.Canceled) {
+			log.Info("Audio playback cancelled by user")
+			return mcp.NewToolResultText("Audio playback cancelled"), nil
+		}
+		log.Error("Failed to play inline audio", "error", err)
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Played %d bytes of %s audio", len(audio.Data), audio.Encoding)), nil
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlePlayAudio(t *testing.T) {
+	wavData := pcmAudio(generateTestAudio(24000, 0.1, 880.0)).Encoded()
+	encoded := base64.StdEncoding.EncodeToString(wavData)
+
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		errMsg    string
+	}{
+		{"data uri", map[string]any{"data": "data:audio/wav;base64," + encoded}, ""},
+		{"raw base64 with mime type", map[string]any{"data": encoded, "mime_type": "audio/x-wav"}, ""},
+		{"raw base64 detected", map[string]any{"data": encoded}, ""},
+		{"missing data", map[string]any{}, "data must be a non-empty"},
+		{"unsupported mime type", map[string]any{"data": "data:audio/ogg;base64," + encoded}, `unsupported mime type "audio/ogg"`},
+		{"mismatched mime type", map[string]any{"data": "data:audio/wav;base64," + encoded, "mime_type": "audio/mpeg"}, "does not match"},
+		{"not base64", map[string]any{"data": "data:audio/wav;base64,!!!"}, "not valid base64"},
+		{"corrupt audio", map[string]any{"data": base64.StdEncoding.EncodeToString([]byte("not flac")), "mime_type": "audio/flac"}, "failed to decode FLAC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := useMockPlayer(t)
+			result, err := handlePlayAudio(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: tt.arguments}})
+			require.NoError(t, err)
+			if tt.errMsg == "" {
+				assert.False(t, result.IsError, result.Content)
+				assert.Equal(t, wavData, mock.PlayedAudio)
+				return
+			}
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errMsg)
+			assert.False(t, mock.Played)
+		})
+	}
+}
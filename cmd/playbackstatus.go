@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type playbackSourceKey struct{}
+
+// playbackSource is what a tool call is speaking, filled in as its providers synthesize
+type playbackSource struct {
+	tool string
+
+	mu        sync.Mutex
+	providers []string
+	voices    []string
+}
+
+// noteSpeaking records the provider and voice a call synthesized with, for playback_status
+func noteSpeaking(ctx context.Context, provider, voice string) {
+	source, ok := ctx.Value(playbackSourceKey{}).(*playbackSource)
+	if !ok {
+		return
+	}
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	if !slices.Contains(source.providers, provider) {
+		source.providers = append(source.providers, provider)
+	}
+	if voice != "" && !slices.Contains(source.voices, voice) {
+		source.voices = append(source.voices, voice)
+	}
+}
+
+// playbackState is a playback as reported by playback_status
+type playbackState struct {
+	Tool     string `json:"tool,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	Voice    string `json:"voice,omitempty"`
+	// Playing is false while the playback waits for a free slot
+	Playing bool `json:"playing"`
+	// PositionMS is how much has been played, omitted when it can't be measured
+	PositionMS *int64 `json:"position_ms,omitempty"`
+	// DurationMS is the length of the audio, omitted when unknown, e.g. for streams
+	DurationMS int64 `json:"duration_ms,omitempty"`
+	// ElapsedMS is the time since playback started
+	ElapsedMS int64 `json:"elapsed_ms,omitempty"`
+}
+
+// snapshot returns the state of every tracked playback, playing ones first, oldest first
+func (t *playbackTracker) snapshot() []playbackState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var playing, queued []playbackState
+	for _, id := range slices.Sorted(maps.Keys(t.active)) {
+		p := t.active[id]
+		state := playbackState{Playing: !p.started.IsZero()}
+		if p.source != nil {
+			p.source.mu.Lock()
+			state.Tool = p.source.tool
+			state.Provider = strings.Join(p.source.providers, ",")
+			state.Voice = strings.Join(p.source.voices, ",")
+			p.source.mu.Unlock()
+		}
+		if !state.Playing {
+			queued = append(queued, state)
+			continue
+		}
+		state.ElapsedMS = time.Since(p.started).Milliseconds()
+		if p.counter != nil {
+			position := p.format.SampleRate.D(int(p.counter.samples.Load())).Milliseconds()
+			state.PositionMS = &position
+		}
+		state.DurationMS = p.total.Milliseconds()
+		playing = append(playing, state)
+	}
+	return append(playing, queued...)
+}
+
+// handlePlaybackStatus reports what is playing and waiting to play right now
+func handlePlaybackStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Playback status tool called", "request", request)
+	states := playbacks.snapshot()
+	status := struct {
+		Playing  bool            `json:"playing"`
+		Muted    bool            `json:"muted"`
+		Queued   int             `json:"queued"`
+		Playback []playbackState `json:"playback"`
+	}{Muted: muted.Load(), Playback: states}
+	if status.Playback == nil {
+		status.Playback = []playbackState{}
+	}
+	for _, state := range states {
+		if state.Playing {
+			status.Playing = true
+		} else {
+			status.Queued++
+		}
+	}
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
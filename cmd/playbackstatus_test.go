@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/gopxl/beep/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// holdingPlayer plays the first 100ms of a stream, then holds until cancelled
+type holdingPlayer struct{}
+
+func (holdingPlayer) Play(ctx context.Context, audio *say.Audio) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (holdingPlayer) PlayStream(ctx context.Context, streamer beep.Streamer, format beep.Format) error {
+	streamer.Stream(make([][2]float64, format.SampleRate.N(100*time.Millisecond)))
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestHandlePlaybackStatus(t *testing.T) {
+	resetConcurrencyLimits(t)
+	t.Setenv("MCP_SAY_PLAYBACK_CONCURRENCY", "1")
+	prev := audioPlayer
+	audioPlayer = holdingPlayer{}
+	t.Cleanup(func() { audioPlayer = prev })
+
+	status := func() map[string]any {
+		t.Helper()
+		result, err := handlePlaybackStatus(context.Background(), mcp.CallToolRequest{})
+		require.NoError(t, err)
+		var status map[string]any
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &status))
+		return status
+	}
+	assert.Equal(t, map[string]any{"playing": false, "muted": false, "queued": 0.0, "playback": []any{}}, status())
+
+	streamer, format, err := pcmAudio(generateTestAudio(24000, 1, 440.0)).Decode()
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), playbackSourceKey{}, &playbackSource{tool: "openai_tts"}))
+	defer cancel()
+	noteSpeaking(ctx, say.ProviderOpenAI, "nova")
+	done := make(chan error, 2)
+	go func() { done <- player().PlayStream(ctx, streamer, format) }()
+	require.Eventually(t, func() bool { return status()["playing"] == true }, time.Second, 5*time.Millisecond)
+	go func() { done <- player().Play(ctx, &say.Audio{}) }()
+	require.Eventually(t, func() bool { return status()["queued"] == 1.0 }, time.Second, 5*time.Millisecond)
+
+	playback := status()["playback"].([]any)
+	require.Len(t, playback, 2)
+	current := playback[0].(map[string]any)
+	assert.Equal(t, "openai_tts", current["tool"])
+	assert.Equal(t, "openai", current["provider"])
+	assert.Equal(t, "nova", current["voice"])
+	assert.Equal(t, 100.0, current["position_ms"])
+	assert.Equal(t, 1000.0, current["duration_ms"])
+	assert.Equal(t, false, playback[1].(map[string]any)["playing"])
+
+	cancel()
+	<-done
+	<-done
+	assert.Equal(t, false, status()["playing"])
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider returns generated PCM audio instead of calling a TTS API
+type fakeProvider struct {
+	texts []string
+}
+
+func (p *fakeProvider) Synthesize(ctx context.Context, opts say.Options) (*say.Audio, error) {
+	p.texts = append(p.texts, opts.Text)
+	return pcmAudio(generateTestAudio(24000, 0.1, 440.0)), nil
+}
+
+func useMockPlayer(t *testing.T) *MockAudioPlayer {
+	mock := &MockAudioPlayer{}
+	prev := audioPlayer
+	audioPlayer = mock
+	t.Cleanup(func() { audioPlayer = prev })
+	return mock
+}
+
+func TestSpeakTextUsesAudioPlayer(t *testing.T) {
+	t.Run("whole text", func(t *testing.T) {
+		mock := useMockPlayer(t)
+		provider := &fakeProvider{}
+
+		err := speakText(context.Background(), provider, say.Options{Text: "One. Two."}, chunking{})
+		require.NoError(t, err)
+		assert.True(t, mock.Played)
+		assert.Equal(t, []string{"One. Two."}, provider.texts)
+		assert.Len(t, mock.PlayedAudio, 4800)
+	})
+
+	t.Run("sentence pauses", func(t *testing.T) {
+		mock := useMockPlayer(t)
+		provider := &fakeProvider{}
+
+		err := speakText(context.Background(), provider, say.Options{Text: "One. Two."}, chunking{pause: 200 * time.Millisecond})
+		require.NoError(t, err)
+		assert.True(t, mock.Played)
+		assert.Equal(t, []string{"One.", "Two."}, provider.texts)
+		// Two 100ms sentences joined by a 200ms pause at 24kHz
+		assert.Equal(t, 2400+4800+2400, mock.PlayedSamples)
+	})
+
+	t.Run("volume decodes to a stream", func(t *testing.T) {
+		mock := useMockPlayer(t)
+
+		err := speakText(context.Background(), &fakeProvider{}, say.Options{Text: "Hello", Volume: 0.5}, chunking{})
+		require.NoError(t, err)
+		assert.Nil(t, mock.PlayedAudio)
+		assert.Equal(t, 2400, mock.PlayedSamples)
+	})
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// handlePlayFile plays a local MP3, WAV or FLAC file through the speaker
+func handlePlayFile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Play file tool called", "request", request)
+	path, ok := request.GetArguments()["path"].(string)
+	if !ok || path == "" {
+		result := mcp.NewToolResultText("Error: path must be a non-empty string")
+		result.IsError = true
+		return result, nil
+	}
+
+	audio, err := say.ReadAudioFile(path)
+	if err == nil {
+		// Decode up front so a corrupt file fails before the speaker is opened
+		_, _, err = audio.Decode()
+	}
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	log.Info("Playing audio file", "path", path, "encoding", audio.Encoding)
+	if err := player().Play(ctx, audio); err != nil {
+		if errors.Is(err, context.Canceled) {
+			log.Info("Audio file playback cancelled by user")
+			return mcp.NewToolResultText("Audio file playback cancelled"), nil
+		}
+		log.Error("Failed to play audio file", "path", path, "error", err)
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Played %s", path)), nil
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func playFileRequest(path string) mcp.CallToolRequest {
+	return mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"path": path}}}
+}
+
+func TestHandlePlayFile(t *testing.T) {
+	dir := t.TempDir()
+	wavPath := filepath.Join(dir, "chime.WAV")
+	wavData := pcmAudio(generateTestAudio(24000, 0.1, 880.0)).Encoded()
+	require.NoError(t, os.WriteFile(wavPath, wavData, 0o644))
+
+	t.Run("plays wav", func(t *testing.T) {
+		mock := useMockPlayer(t)
+		result, err := handlePlayFile(context.Background(), playFileRequest(wavPath))
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+		assert.True(t, mock.Played)
+		assert.Equal(t, wavData, mock.PlayedAudio)
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		errMsg string
+	}{
+		{"unsupported format", filepath.Join(dir, "clip.ogg"), `unsupported audio format ".ogg"`},
+		{"missing file", filepath.Join(dir, "missing.mp3"), "failed to read audio file"},
+		{"corrupt file", writeTempFile(t, dir, "bad.flac", "not flac"), "failed to decode FLAC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := useMockPlayer(t)
+			result, err := handlePlayFile(context.Background(), playFileRequest(tt.path))
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errMsg)
+			assert.False(t, mock.Played)
+		})
+	}
+}
+
+func writeTempFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestHandlePlayFileCancelledMidPlayback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "long.wav")
+	require.NoError(t, os.WriteFile(path, pcmAudio(generateTestAudio(24000, 0.1, 440.0)).Encoded(), 0o644))
+	mock := useMockPlayer(t)
+	mock.Duration = 10 * time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	result, err := handlePlayFile(ctx, playFileRequest(path))
+	require.NoError(t, err)
+
+	assert.Less(t, time.Since(start), time.Second, "playback stops promptly")
+	assert.True(t, mock.Played)
+	assert.False(t, result.IsError)
+	assert.Equal(t, "Audio file playback cancelled", result.Content[0].(mcp.TextContent).Text)
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// policyRule sends texts shorter than UnderChars characters to Provider, 0 matches any length
+type policyRule struct {
+	Provider   string
+	UnderChars int
+}
+
+// providerPolicy picks the speak tool's provider by text length, rules are tried in order
+type providerPolicy []policyRule
+
+// Policy loaded from MCP_SAY_PROVIDER_POLICY, nil when the speak tool is disabled
+var speakPolicy providerPolicy
+
+// parseProviderPolicy parses a policy like "say:100,openai", a provider with a character
+// threshold per rule and a last rule without one that catches everything else
+func parseProviderPolicy(value string) (providerPolicy, error) {
+	var policy providerPolicy
+	for i, field := range strings.Split(value, ",") {
+		name, limit, hasLimit := strings.Cut(strings.TrimSpace(field), ":")
+		rule := policyRule{Provider: strings.TrimSpace(name)}
+		switch rule.Provider {
+		case say.ProviderOpenAI, say.ProviderGoogle, say.ProviderElevenLabs, say.ProviderSay:
+		default:
+			return nil, fmt.Errorf("rule %d: unknown provider %q (use google, openai, elevenlabs or say)", i+1, rule.Provider)
+		}
+		if hasLimit {
+			n, err := strconv.Atoi(strings.TrimSpace(limit))
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("rule %d: character threshold must be a positive integer, got %q", i+1, limit)
+			}
+			if len(policy) > 0 && n <= policy[len(policy)-1].UnderChars {
+				return nil, fmt.Errorf("rule %d: thresholds must increase, %d follows %d", i+1, n, policy[len(policy)-1].UnderChars)
+			}
+			rule.UnderChars = n
+		}
+		if len(policy) > 0 && policy[len(policy)-1].UnderChars == 0 {
+			return nil, fmt.Errorf("rule %d: only the last rule may omit the character threshold", i)
+		}
+		policy = append(policy, rule)
+	}
+	if policy[len(policy)-1].UnderChars != 0 {
+		return nil, fmt.Errorf("the last rule must omit the character threshold to catch longer texts, e.g. %q", value+",openai")
+	}
+	return policy, nil
+}
+
+// String formats the policy in MCP_SAY_PROVIDER_POLICY syntax
+func (p providerPolicy) String() string {
+	rules := make([]string, len(p))
+	for i, rule := range p {
+		rules[i] = rule.Provider
+		if rule.UnderChars > 0 {
+			rules[i] += ":" + strconv.Itoa(rule.UnderChars)
+		}
+	}
+	return strings.Join(rules, ",")
+}
+
+// describe explains the policy in words for the tool description
+func (p providerPolicy) describe() string {
+	var parts []string
+	for _, rule := range p {
+		if rule.UnderChars > 0 {
+			parts = append(parts, fmt.Sprintf("%s under %d characters", rule.Provider, rule.UnderChars))
+		} else if len(parts) > 0 {
+			parts = append(parts, fmt.Sprintf("%s otherwise", rule.Provider))
+		} else {
+			parts = append(parts, rule.Provider)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// choose returns the provider for text and why it was picked
+func (p providerPolicy) choose(text string) (string, string) {
+	n := utf8.RuneCountInString(text)
+	for _, rule := range p {
+		if rule.UnderChars == 0 {
+			return rule.Provider, fmt.Sprintf("%d characters, no shorter threshold matched", n)
+		}
+		if n < rule.UnderChars {
+			return rule.Provider, fmt.Sprintf("%d characters, under %d", n, rule.UnderChars)
+		}
+	}
+	// parseProviderPolicy guarantees a catch-all last rule
+	return p[len(p)-1].Provider, ""
+}
+
+// handleSpeak speaks text with the provider the policy picks for its length
+func handleSpeak(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Speak tool called", "request", request)
+	arguments := request.GetArguments()
+	text, err := textArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	out, err := outputArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	name, reason := speakPolicy.choose(text)
+	log.Info("Selected provider by text length", "provider", name, "reason", reason, "policy", speakPolicy)
+	provider, err := newProvider(name)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	opts := say.Options{
+		Text:  text,
+		Voice: providerSetting(nil, name, "voice", ""),
+		Model: providerSetting(nil, name, "model", ""),
+	}
+
+	if out.enabled() {
+		audio, err := renderText(ctx, provider, opts, chunking{})
+		if err != nil {
+			log.Error("Speech synthesis failed", "provider", name, "error", err)
+			result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+			result.IsError = true
+			return result, nil
+		}
+		return deliverAudio(ctx, out, audio, synthesizedMessage(text)), nil
+	}
+
+	err = speakText(ctx, provider, opts, chunking{})
+	if errors.Is(err, context.Canceled) {
+		log.Info("Speech playback cancelled by user")
+		return mcp.NewToolResultText("Speech playback cancelled"), nil
+	}
+	if err != nil {
+		log.Error("Speech synthesis failed", "provider", name, "error", err)
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	if suppressSpeakingOutput {
+		return mcp.NewToolResultText("Speech completed"), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Speaking: %s (via %s, %s)", text, name, reason)), nil
+}
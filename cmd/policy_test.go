@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProviderPolicy(t *testing.T) {
+	policy, err := parseProviderPolicy("say:100, google:1000 ,openai")
+	require.NoError(t, err)
+	assert.Equal(t, providerPolicy{{"say", 100}, {"google", 1000}, {"openai", 0}}, policy)
+	assert.Equal(t, "say:100,google:1000,openai", policy.String())
+	assert.Equal(t, "say under 100 characters, google under 1000 characters, openai otherwise", policy.describe())
+
+	policy, err = parseProviderPolicy("elevenlabs")
+	require.NoError(t, err)
+	assert.Equal(t, "elevenlabs", policy.describe())
+
+	for value, errMsg := range map[string]string{
+		"say:100":                  "last rule must omit",
+		"say:100,polly":            `unknown provider "polly"`,
+		"say:0,openai":             "positive integer",
+		"say:abc,openai":           "positive integer",
+		"say:100,google:50,openai": "thresholds must increase",
+		"say,openai":               "only the last rule",
+		"":                         "unknown provider",
+	} {
+		_, err := parseProviderPolicy(value)
+		require.Error(t, err, value)
+		assert.Contains(t, err.Error(), errMsg, value)
+	}
+}
+
+func TestProviderPolicyChoose(t *testing.T) {
+	policy, err := parseProviderPolicy("say:10,openai")
+	require.NoError(t, err)
+
+	name, reason := policy.choose("Done!")
+	assert.Equal(t, "say", name)
+	assert.Equal(t, "5 characters, under 10", reason)
+
+	// Characters, not bytes, are counted
+	name, _ = policy.choose("héllo wörld")
+	assert.Equal(t, "openai", name)
+	name, _ = policy.choose("ééééééééé")
+	assert.Equal(t, "say", name)
+
+	name, reason = policy.choose("The build finished successfully.")
+	assert.Equal(t, "openai", name)
+	assert.Equal(t, "32 characters, no shorter threshold matched", reason)
+}
+
+func TestHandleSpeak(t *testing.T) {
+	mock := useMockPlayer(t)
+	policy, err := parseProviderPolicy("say:20,openai")
+	require.NoError(t, err)
+	prevPolicy := speakPolicy
+	speakPolicy = policy
+	t.Cleanup(func() { speakPolicy = prevPolicy })
+
+	var picked []string
+	prev := newProvider
+	newProvider = func(name string) (say.Provider, error) {
+		picked = append(picked, name)
+		return &fakeProvider{}, nil
+	}
+	t.Cleanup(func() { newProvider = prev })
+
+	for _, text := range []string{"Tests passed.", "All 42 integration tests passed on the first try."} {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"text": text}
+		result, err := handleSpeak(context.Background(), request)
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Speaking: "+text)
+	}
+	assert.Equal(t, []string{"say", "openai"}, picked)
+	assert.True(t, mock.Played)
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// previewText is spoken by every previewed voice so they can be compared
+	previewText = "The quick brown fox jumps over the lazy dog."
+	// Most voices accepted by one preview_voice call
+	maxPreviewVoices = 10
+	// previewPause separates the previews of consecutive voices
+	previewPause = 700 * time.Millisecond
+)
+
+// previewVoice is one voice to preview, resolved from an alias when one is named
+type previewVoice struct {
+	Name     string
+	Provider string
+	Voice    string
+	Model    string
+}
+
+// label names the voice in announcements and results
+func (v previewVoice) label() string {
+	if v.Voice == "" {
+		return v.Provider + " default voice"
+	}
+	return v.Provider + "/" + v.Voice
+}
+
+// previewVoicesArgument reads the voice and voices arguments, resolving voice aliases
+func previewVoicesArgument(arguments map[string]any) ([]previewVoice, error) {
+	provider, _ := arguments["provider"].(string)
+	switch provider {
+	case say.ProviderOpenAI, say.ProviderGoogle, say.ProviderElevenLabs, say.ProviderSay:
+	default:
+		return nil, fmt.Errorf("unknown provider %q (use google, openai, elevenlabs or say)", provider)
+	}
+
+	var names []string
+	if voice, ok := arguments["voice"].(string); ok && voice != "" {
+		names = append(names, voice)
+	}
+	if raw, ok := arguments["voices"]; ok && raw != nil {
+		items, ok := raw.([]any)
+		if !ok {
+			return nil, errors.New("voices must be an array of voice names")
+		}
+		for i, item := range items {
+			name, ok := item.(string)
+			if !ok || strings.TrimSpace(name) == "" {
+				return nil, fmt.Errorf("voices[%d] must be a non-empty string", i)
+			}
+			names = append(names, name)
+		}
+	}
+	if len(names) > maxPreviewVoices {
+		return nil, fmt.Errorf("too many voices (%d, max %d)", len(names), maxPreviewVoices)
+	}
+	if len(names) == 0 {
+		return []previewVoice{{Provider: provider, Voice: providerSetting(nil, provider, "voice", "")}}, nil
+	}
+
+	voices := make([]previewVoice, 0, len(names))
+	for _, name := range names {
+		v := previewVoice{Name: name, Provider: provider, Voice: name}
+		if _, alias, ok := lookupVoiceAlias(map[string]any{"voice": name}); ok {
+			v.Provider, v.Voice, v.Model = alias.Provider, alias.Voice, alias.Model
+		}
+		voices = append(voices, v)
+	}
+	return voices, nil
+}
+
+// announceVoice speaks the voice's name with the native say command. It is best effort,
+// the preview goes on without it where say is unavailable.
+func announceVoice(ctx context.Context, v previewVoice) {
+	name := v.Name
+	if name == "" {
+		name = v.label()
+	}
+	provider, err := newProvider(say.ProviderSay)
+	if err == nil {
+		err = speakText(ctx, provider, say.Options{Text: "Voice: " + name}, chunking{})
+	}
+	if err != nil && ctx.Err() == nil {
+		log.Warn("Could not announce voice, previewing without it", "voice", name, "error", err)
+	}
+}
+
+// handlePreviewVoice speaks a fixed sentence with one or more voices so they can be compared
+func handlePreviewVoice(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Preview voice tool called", "request", request)
+	arguments := request.GetArguments()
+	voices, err := previewVoicesArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	announce := len(voices) > 1
+	if raw, ok := arguments["announce"].(bool); ok {
+		announce = raw
+	}
+
+	var (
+		lines  []string
+		spoken int
+	)
+	for i, v := range voices {
+		if i > 0 {
+			format := beep.Format{SampleRate: say.DefaultSpeakerSampleRate, NumChannels: 1, Precision: 2}
+			if err := playStream(ctx, beep.Silence(format.SampleRate.N(previewPause)), format); err != nil && ctx.Err() == nil {
+				log.Warn("Could not play pause between previews", "error", err)
+			}
+		}
+		if announce {
+			announceVoice(ctx, v)
+		}
+		provider, err := newProvider(v.Provider)
+		if err == nil {
+			log.Info("Previewing voice", "provider", v.Provider, "voice", v.Voice)
+			err = speakText(ctx, provider, say.Options{
+				Text:  previewText,
+				Voice: v.Voice,
+				Model: providerSetting(map[string]any{"model": v.Model}, v.Provider, "model", ""),
+			}, chunking{})
+		}
+		if errors.Is(err, context.Canceled) {
+			log.Info("Voice preview cancelled by user")
+			return mcp.NewToolResultText("Voice preview cancelled"), nil
+		}
+		if err != nil {
+			log.Error("Failed to preview voice", "provider", v.Provider, "voice", v.Voice, "error", err)
+			lines = append(lines, fmt.Sprintf("%d. [failed] %s: %v", i+1, v.label(), err))
+			continue
+		}
+		spoken++
+		lines = append(lines, fmt.Sprintf("%d. [ok] %s", i+1, v.label()))
+	}
+
+	if spoken == 0 {
+		result := mcp.NewToolResultText("Error: every voice failed\n" + strings.Join(lines, "\n"))
+		result.IsError = true
+		return result, nil
+	}
+	summary := fmt.Sprintf("Previewed %d of %d voices with %q", spoken, len(voices), previewText)
+	return mcp.NewToolResultText(summary + "\n" + strings.Join(lines, "\n")), nil
+}
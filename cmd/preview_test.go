@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewVoicesArgument(t *testing.T) {
+	prev := voiceAliases
+	voiceAliases = map[string]voiceAlias{"narrator": {Provider: say.ProviderElevenLabs, Voice: "Rachel"}}
+	t.Cleanup(func() { voiceAliases = prev })
+
+	voices, err := previewVoicesArgument(map[string]any{"provider": "openai", "voice": "nova", "voices": []any{"echo", "Narrator"}})
+	require.NoError(t, err)
+	assert.Equal(t, []previewVoice{
+		{Name: "nova", Provider: "openai", Voice: "nova"},
+		{Name: "echo", Provider: "openai", Voice: "echo"},
+		{Name: "Narrator", Provider: "elevenlabs", Voice: "Rachel"},
+	}, voices)
+
+	voices, err = previewVoicesArgument(map[string]any{"provider": "openai"})
+	require.NoError(t, err)
+	assert.Equal(t, []previewVoice{{Provider: "openai"}}, voices)
+
+	tests := []struct {
+		name      string
+		arguments map[string]any
+	}{
+		{"unknown provider", map[string]any{"provider": "polly", "voice": "Joanna"}},
+		{"voices not an array", map[string]any{"provider": "openai", "voices": "nova"}},
+		{"empty voice", map[string]any{"provider": "openai", "voices": []any{""}}},
+		{"too many", map[string]any{"provider": "openai", "voices": []any{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := previewVoicesArgument(tt.arguments)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestHandlePreviewVoice(t *testing.T) {
+	mock := useMockPlayer(t)
+	providers := map[string]*fakeProvider{}
+	prev := newProvider
+	newProvider = func(name string) (say.Provider, error) {
+		if name == say.ProviderGoogle {
+			return nil, errors.New("GOOGLE_AI_API_KEY is not set")
+		}
+		if providers[name] == nil {
+			providers[name] = &fakeProvider{}
+		}
+		return providers[name], nil
+	}
+	t.Cleanup(func() { newProvider = prev })
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"provider": "openai", "voices": []any{"nova", "echo"}}
+	result, err := handlePreviewVoice(context.Background(), request)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Previewed 2 of 2 voices")
+	assert.Contains(t, text, "2. [ok] openai/echo")
+	assert.Equal(t, []string{previewText, previewText}, providers[say.ProviderOpenAI].texts)
+	assert.Equal(t, []string{"Voice: nova", "Voice: echo"}, providers[say.ProviderSay].texts)
+	assert.True(t, mock.Played)
+
+	t.Run("single voice is not announced", func(t *testing.T) {
+		delete(providers, say.ProviderSay)
+		request.Params.Arguments = map[string]any{"provider": "openai", "voice": "nova"}
+		result, err := handlePreviewVoice(context.Background(), request)
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+		assert.Nil(t, providers[say.ProviderSay])
+	})
+
+	t.Run("every voice failed", func(t *testing.T) {
+		request.Params.Arguments = map[string]any{"provider": "google", "voices": []any{"Puck"}, "announce": false}
+		result, err := handlePreviewVoice(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "1. [failed] google/Puck: GOOGLE_AI_API_KEY is not set")
+	})
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// How often playback progress is sent to clients that asked for it
+var progressInterval = time.Second
+
+type progressKey struct{}
+
+// progressReporter sends playback progress for a single tool call. A call can
+// play several streams (e.g. chunked text), progress keeps counting across them.
+type progressReporter struct {
+	token mcp.ProgressToken
+	send  func(params map[string]any) error
+
+	mu     sync.Mutex
+	played float64 // seconds played by finished streams
+	last   float64
+}
+
+// notify sends a progress notification, progress never goes backwards
+func (r *progressReporter) notify(position, total float64) {
+	r.mu.Lock()
+	progress := r.played + position
+	if progress < r.last {
+		r.mu.Unlock()
+		return
+	}
+	r.last = progress
+	params := map[string]any{
+		"progressToken": r.token,
+		"progress":      progress,
+		"message":       fmt.Sprintf("Played %.0fs", progress),
+	}
+	if total > 0 {
+		params["total"] = r.played + total
+		params["message"] = fmt.Sprintf("Played %.0fs of %.0fs", progress, r.played+total)
+	}
+	r.mu.Unlock()
+
+	if err := r.send(params); err != nil {
+		log.Debug("Failed to send progress notification", "error", err)
+	}
+}
+
+// sentence reports a sentence spoken by a command that plays text itself, where the
+// audio position is unknown and progress counts sentences instead of seconds
+func (r *progressReporter) sentence(done, total int) {
+	params := map[string]any{
+		"progressToken": r.token,
+		"progress":      done,
+		"total":         total,
+		"message":       fmt.Sprintf("Spoke sentence %d of %d", done, total),
+	}
+	if err := r.send(params); err != nil {
+		log.Debug("Failed to send progress notification", "error", err)
+	}
+}
+
+// percent reports the progress of a synthesis that runs before anything plays, e.g. a
+// Google long audio operation
+func (r *progressReporter) percent(done float64) {
+	params := map[string]any{
+		"progressToken": r.token,
+		"progress":      done,
+		"total":         100,
+		"message":       fmt.Sprintf("Synthesized %.0f%%", done),
+	}
+	if err := r.send(params); err != nil {
+		log.Debug("Failed to send progress notification", "error", err)
+	}
+}
+
+// finish adds a finished stream's duration to the call's progress
+func (r *progressReporter) finish(position float64) {
+	r.mu.Lock()
+	r.played += position
+	r.mu.Unlock()
+}
+
+// WithProgress enables playback progress notifications when the client sent a
+// progress token with the request. Without one, playback is not tracked at all.
+func WithProgress(handler ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+			return handler(ctx, request)
+		}
+		srv := server.ServerFromContext(ctx)
+		if srv == nil {
+			return handler(ctx, request)
+		}
+		reporter := &progressReporter{
+			token: request.Params.Meta.ProgressToken,
+			send: func(params map[string]any) error {
+				return srv.SendNotificationToClient(ctx, "notifications/progress", params)
+			},
+		}
+		return handler(context.WithValue(ctx, progressKey{}, reporter), request)
+	}
+}
+
+// countingStreamer counts the samples taken from a streamer
+type countingStreamer struct {
+	beep.Streamer
+	samples atomic.Int64
+}
+
+func (s *countingStreamer) Stream(samples [][2]float64) (int, bool) {
+	n, ok := s.Streamer.Stream(samples)
+	s.samples.Add(int64(n))
+	return n, ok
+}
+
+// Len returns the length of the counted streamer, 0 when it is unknown
+func (s *countingStreamer) Len() int {
+	if l, ok := s.Streamer.(interface{ Len() int }); ok {
+		return l.Len()
+	}
+	return 0
+}
+
+// trackProgress reports the position of streamer every progressInterval while it
+// plays. The returned stop func must be called once playback ends.
+func trackProgress(ctx context.Context, streamer beep.Streamer, format beep.Format) (beep.Streamer, func()) {
+	reporter, ok := ctx.Value(progressKey{}).(*progressReporter)
+	if !ok {
+		return streamer, func() {}
+	}
+
+	var total float64
+	if s, ok := streamer.(interface{ Len() int }); ok && s.Len() > 0 {
+		total = format.SampleRate.D(s.Len()).Seconds()
+	}
+	counter := &countingStreamer{Streamer: streamer}
+	position := func() float64 {
+		return format.SampleRate.D(int(counter.samples.Load())).Seconds()
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				reporter.notify(position(), total)
+			}
+		}
+	}()
+
+	return counter, func() {
+		close(done)
+		wg.Wait()
+		reporter.notify(position(), total)
+		reporter.finish(position())
+	}
+}
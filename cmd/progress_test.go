@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/gopxl/beep/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackProgress(t *testing.T) {
+	var mu sync.Mutex
+	var sent []map[string]any
+	reporter := &progressReporter{
+		token: "tok",
+		send: func(params map[string]any) error {
+			mu.Lock()
+			sent = append(sent, params)
+			mu.Unlock()
+			return nil
+		},
+	}
+	ctx := context.WithValue(context.Background(), progressKey{}, reporter)
+	format := beep.Format{SampleRate: 8000, NumChannels: 1, Precision: 2}
+
+	// Two one-second streams played back-to-back by the same call
+	for range 2 {
+		streamer, stop := trackProgress(ctx, say.NewPCMStream(make([]byte, 16000), format.SampleRate), format)
+		buf := make([][2]float64, 512)
+		for {
+			if _, ok := streamer.Stream(buf); !ok {
+				break
+			}
+		}
+		stop()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, sent, 2)
+	assert.Equal(t, "tok", sent[0]["progressToken"])
+	assert.InDelta(t, 1.0, sent[0]["progress"], 1e-9)
+	assert.InDelta(t, 1.0, sent[0]["total"], 1e-9)
+	assert.InDelta(t, 2.0, sent[1]["progress"], 1e-9)
+	assert.InDelta(t, 2.0, sent[1]["total"], 1e-9)
+	assert.Equal(t, "Played 2s of 2s", sent[1]["message"])
+}
+
+func TestTrackProgressWithoutToken(t *testing.T) {
+	stream := say.NewPCMStream(make([]byte, 16), 8000)
+	streamer, stop := trackProgress(context.Background(), stream, beep.Format{SampleRate: 8000})
+	defer stop()
+	assert.Same(t, stream, streamer)
+}
+
+func TestProgressReporterNeverGoesBackwards(t *testing.T) {
+	var progress []float64
+	reporter := &progressReporter{send: func(params map[string]any) error {
+		progress = append(progress, params["progress"].(float64))
+		return nil
+	}}
+	reporter.notify(2, 0)
+	reporter.notify(1, 0)
+	reporter.notify(3, 0)
+	assert.Equal(t, []float64{2, 3}, progress)
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+)
+
+// wrapProvider applies text replacements, the provider's local rate limit, cost metering,
+// call stats and the utterance history to each request, and adds hints to known API errors
+func wrapProvider(name string, provider say.Provider) say.Provider {
+	managed := &managedProvider{Provider: provider, name: name, bucket: providerLimiter(name), slots: providerSlots(name)}
+	if _, ok := provider.(say.StreamingProvider); ok {
+		return &managedStreamingProvider{managed}
+	}
+	if _, ok := provider.(say.PCMStreamingProvider); ok {
+		return &managedPCMStreamingProvider{managed}
+	}
+	return managed
+}
+
+type managedProvider struct {
+	say.Provider
+	name string
+	// bucket is nil when the provider is unlimited
+	bucket *tokenBucket
+	// slots bounds concurrent requests, nil when unlimited
+	slots semaphore
+}
+
+// wait blocks until a concurrency slot is free and the rate limiter allows another
+// request. On success the slot must be given back with p.slots.release().
+func (p *managedProvider) wait(ctx context.Context) error {
+	if err := p.slots.acquire(ctx); err != nil {
+		return err
+	}
+	if p.bucket == nil {
+		return nil
+	}
+	if err := p.bucket.Wait(ctx); err != nil {
+		p.slots.release()
+		if errors.Is(err, errRateLimited) {
+			log.Warn("Request rate limited locally", "provider", p.name)
+			return fmt.Errorf("%w: %s allows %g requests per second, try again shortly or raise %s",
+				err, p.name, p.bucket.rate, rateLimitEnv(p.name))
+		}
+		return err
+	}
+	return nil
+}
+
+func (p *managedProvider) Synthesize(ctx context.Context, opts say.Options) (*say.Audio, error) {
+	if err := p.wait(ctx); err != nil {
+		return nil, err
+	}
+	defer p.slots.release()
+	opts.Text = applyReplacements(opts.Text)
+	start := time.Now()
+	audio, err := p.Provider.Synthesize(ctx, opts)
+	if err == nil {
+		recordCost(ctx, p.name, opts.Model, opts.Text)
+		recordSpokenText(ctx, opts.Text)
+		noteSpeaking(ctx, p.name, opts.Voice)
+		recordResponse(ctx, time.Since(start), len(audio.Data))
+		recordUtterance(ctx, p.name, opts, audio)
+	}
+	return audio, withProviderHint(err)
+}
+
+type managedStreamingProvider struct {
+	*managedProvider
+}
+
+func (p *managedStreamingProvider) Stream(ctx context.Context, opts say.Options) (io.ReadCloser, error) {
+	if err := p.wait(ctx); err != nil {
+		return nil, err
+	}
+	// Streams free their slot once the response starts, playback may queue for a while
+	defer p.slots.release()
+	opts.Text = applyReplacements(opts.Text)
+	start := time.Now()
+	body, err := p.Provider.(say.StreamingProvider).Stream(ctx, opts)
+	if err != nil {
+		return nil, withProviderHint(err)
+	}
+	recordCost(ctx, p.name, opts.Model, opts.Text)
+	recordSpokenText(ctx, opts.Text)
+	noteSpeaking(ctx, p.name, opts.Voice)
+	recordResponse(ctx, time.Since(start), 0)
+	return &countingReader{ReadCloser: &recordingReader{ReadCloser: body, ctx: ctx, provider: p.name, opts: opts}, ctx: ctx}, nil
+}
+
+type managedPCMStreamingProvider struct {
+	*managedProvider
+}
+
+func (p *managedPCMStreamingProvider) StreamPCM(ctx context.Context, opts say.Options) (io.ReadCloser, beep.SampleRate, error) {
+	if err := p.wait(ctx); err != nil {
+		return nil, 0, err
+	}
+	defer p.slots.release()
+	opts.Text = applyReplacements(opts.Text)
+	start := time.Now()
+	body, rate, err := p.Provider.(say.PCMStreamingProvider).StreamPCM(ctx, opts)
+	if err != nil {
+		return nil, 0, withProviderHint(err)
+	}
+	recordCost(ctx, p.name, opts.Model, opts.Text)
+	recordSpokenText(ctx, opts.Text)
+	noteSpeaking(ctx, p.name, opts.Voice)
+	recordResponse(ctx, time.Since(start), 0)
+	return &countingReader{ReadCloser: &recordingReader{ReadCloser: body, ctx: ctx, provider: p.name, opts: opts, rate: rate}, ctx: ctx}, rate, nil
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+)
+
+const (
+	// Longest a request waits for a rate limit token before giving up
+	maxRateLimitWait = 30 * time.Second
+)
+
+// defaultProviderRPS are the request rates allowed per provider unless overridden
+// with MCP_SAY_<PROVIDER>_RPS. The local say command is never limited.
+var defaultProviderRPS = map[string]float64{
+	say.ProviderOpenAI:     5,
+	say.ProviderGoogle:     2,
+	say.ProviderElevenLabs: 2,
+}
+
+var errRateLimited = errors.New("rate limited locally")
+
+// tokenBucket is a token bucket rate limiter refilled at rate tokens per second
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func newTokenBucket(rps float64) *tokenBucket {
+	burst := math.Max(1, math.Ceil(rps))
+	return &tokenBucket{
+		rate:   rps,
+		burst:  burst,
+		tokens: burst,
+		now:    time.Now,
+	}
+}
+
+// reserve takes a token and returns how long to wait before using it
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token that won't be used
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+// Wait blocks until a token is available. It returns errRateLimited when the wait
+// would outlast ctx or maxRateLimitWait, and ctx.Err() if ctx is cancelled first.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	wait := b.reserve()
+	if wait == 0 {
+		return nil
+	}
+	deadline := b.now().Add(maxRateLimitWait)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if b.now().Add(wait).After(deadline) {
+		b.cancel()
+		return errRateLimited
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
+
+var (
+	rateLimitersMu sync.Mutex
+	rateLimiters   = map[string]*tokenBucket{}
+)
+
+// rateLimitEnv is the environment variable that sets a provider's requests per second
+func rateLimitEnv(provider string) string {
+	return fmt.Sprintf("MCP_SAY_%s_RPS", strings.ToUpper(provider))
+}
+
+// providerLimiter returns the shared limiter for a provider, or nil if it is unlimited
+func providerLimiter(provider string) *tokenBucket {
+	rateLimitersMu.Lock()
+	defer rateLimitersMu.Unlock()
+
+	if bucket, ok := rateLimiters[provider]; ok {
+		return bucket
+	}
+
+	rps, limited := defaultProviderRPS[provider]
+	if value := getenv(rateLimitEnv(provider)); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 {
+			log.Warn("Invalid rate limit, using default", "env", rateLimitEnv(provider), "value", value, "default", rps)
+		} else {
+			rps, limited = parsed, true
+		}
+	}
+
+	var bucket *tokenBucket
+	if limited && rps > 0 {
+		log.Debug("Rate limiting provider", "provider", provider, "rps", rps)
+		bucket = newTokenBucket(rps)
+	}
+	rateLimiters[provider] = bucket
+	return bucket
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucketReserve(t *testing.T) {
+	now := time.Unix(0, 0)
+	bucket := newTokenBucket(2)
+	bucket.now = func() time.Time { return now }
+
+	// Burst of 2, then one token every 500ms
+	assert.Zero(t, bucket.reserve())
+	assert.Zero(t, bucket.reserve())
+	assert.Equal(t, 500*time.Millisecond, bucket.reserve())
+
+	now = now.Add(time.Second)
+	assert.Zero(t, bucket.reserve())
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	bucket := newTokenBucket(1)
+	require.NoError(t, bucket.Wait(context.Background()))
+
+	t.Run("deadline too soon", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, bucket.Wait(ctx), errRateLimited)
+	})
+
+	t.Run("cancelled while waiting", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+		assert.ErrorIs(t, bucket.Wait(ctx), context.Canceled)
+	})
+}
+
+func TestLimitProvider(t *testing.T) {
+	t.Setenv("MCP_SAY_OPENAI_RPS", "0")
+	t.Setenv("MCP_SAY_ELEVENLABS_RPS", "1")
+	resetLimiters := func() {
+		rateLimitersMu.Lock()
+		rateLimiters = map[string]*tokenBucket{}
+		rateLimitersMu.Unlock()
+	}
+	resetLimiters()
+	t.Cleanup(resetLimiters)
+
+	assert.Nil(t, providerLimiter(say.ProviderOpenAI), "0 disables the limiter")
+
+	limited := wrapProvider(say.ProviderElevenLabs, say.NewElevenLabs(""))
+	_, ok := limited.(say.StreamingProvider)
+	assert.True(t, ok, "streaming providers keep streaming")
+
+	// Drain the single token, the next request can't get one before the deadline
+	require.NoError(t, providerLimiter(say.ProviderElevenLabs).Wait(context.Background()))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := limited.Synthesize(ctx, say.Options{Text: "Hello"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rate limited locally")
+	assert.Contains(t, err.Error(), "MCP_SAY_ELEVENLABS_RPS")
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// replacementRule is one find→replace entry of the MCP_SAY_REPLACEMENTS_FILE
+type replacementRule struct {
+	Find    string `json:"find"`
+	Replace string `json:"replace"`
+	// CaseSensitive makes Find match only with the same case
+	CaseSensitive bool `json:"case_sensitive"`
+	// Regex treats Find as a regular expression and allows $1 style references in Replace
+	Regex bool `json:"regex"`
+}
+
+// replacement is a compiled replacementRule
+type replacement struct {
+	pattern *regexp.Regexp
+	replace string
+	regex   bool
+}
+
+// Text replacements applied before synthesis, loaded once at startup
+var textReplacements []replacement
+
+// loadReplacements reads a JSON or CSV replacements file, chosen by extension.
+//
+// JSON is an array of {"find", "replace", "case_sensitive", "regex"} objects.
+// CSV rows are find,replace[,flags] where flags is a space separated list of
+// "case" and "regex". Lines starting with # are comments.
+func loadReplacements(path string) ([]replacement, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replacements: %v", err)
+	}
+
+	var rules []replacementRule
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&rules); err != nil {
+			return nil, fmt.Errorf("failed to parse replacements %s: %v", path, err)
+		}
+	case ".csv":
+		if rules, err = parseReplacementsCSV(data); err != nil {
+			return nil, fmt.Errorf("failed to parse replacements %s: %v", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("replacements file %s must be .json or .csv", path)
+	}
+
+	replacements := make([]replacement, 0, len(rules))
+	for i, rule := range rules {
+		r, err := compileReplacement(rule)
+		if err != nil {
+			return nil, fmt.Errorf("replacements %s: rule %d: %v", path, i+1, err)
+		}
+		replacements = append(replacements, r)
+	}
+	return replacements, nil
+}
+
+func parseReplacementsCSV(data []byte) ([]replacementRule, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]replacementRule, 0, len(records))
+	for i, record := range records {
+		if len(record) < 2 || len(record) > 3 {
+			return nil, fmt.Errorf("line %d: expected find,replace[,flags]", i+1)
+		}
+		rule := replacementRule{Find: record[0], Replace: record[1]}
+		if len(record) == 3 {
+			for _, flag := range strings.Fields(record[2]) {
+				switch flag {
+				case "case":
+					rule.CaseSensitive = true
+				case "regex":
+					rule.Regex = true
+				default:
+					return nil, fmt.Errorf("line %d: unknown flag %q (use case or regex)", i+1, flag)
+				}
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// compileReplacement builds the pattern of a rule. Plain rules only match whole
+// words: "API" matches in "the API." but not in "APIs".
+func compileReplacement(rule replacementRule) (replacement, error) {
+	if rule.Find == "" {
+		return replacement{}, fmt.Errorf("find must not be empty")
+	}
+	expr := rule.Find
+	if !rule.Regex {
+		expr = regexp.QuoteMeta(rule.Find)
+		if first, _ := utf8.DecodeRuneInString(rule.Find); isWordRune(first) {
+			expr = `\b` + expr
+		}
+		if last, _ := utf8.DecodeLastRuneInString(rule.Find); isWordRune(last) {
+			expr += `\b`
+		}
+	}
+	if !rule.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return replacement{}, fmt.Errorf("invalid pattern %q: %v", rule.Find, err)
+	}
+	return replacement{pattern: pattern, replace: rule.Replace, regex: rule.Regex}, nil
+}
+
+// isWordRune matches the characters \b treats as word characters
+func isWordRune(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
+
+// applyReplacements runs text through every replacement in file order
+func applyReplacements(text string) string {
+	for _, r := range textReplacements {
+		if r.regex {
+			text = r.pattern.ReplaceAllString(text, r.replace)
+		} else {
+			text = r.pattern.ReplaceAllLiteralString(text, r.replace)
+		}
+	}
+	return text
+}
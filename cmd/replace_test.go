@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeReplacements(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadReplacementsJSON(t *testing.T) {
+	path := writeReplacements(t, "rules.json", `[
+		{"find": "k8s", "replace": "Kubernetes"},
+		{"find": "SRE", "replace": "site reliability engineering", "case_sensitive": true},
+		{"find": "v(\\d+)\\.(\\d+)", "replace": "version $1 point $2", "regex": true},
+		{"find": "C++", "replace": "C plus plus"}
+	]`)
+	r, err := loadReplacements(path)
+	require.NoError(t, err)
+	textReplacements = r
+	t.Cleanup(func() { textReplacements = nil })
+
+	assert.Equal(t, "Deploy Kubernetes v2 with Kubernetes.", applyReplacements("Deploy K8S v2 with k8s."))
+	assert.Equal(t, "k8sctl stays", applyReplacements("k8sctl stays"))
+	assert.Equal(t, "Ask site reliability engineering, not sre", applyReplacements("Ask SRE, not sre"))
+	assert.Equal(t, "Released version 1 point 24", applyReplacements("Released v1.24"))
+	assert.Equal(t, "I write C plus plus daily", applyReplacements("I write C++ daily"))
+}
+
+func TestLoadReplacementsCSV(t *testing.T) {
+	path := writeReplacements(t, "rules.csv", `# find,replace,flags
+API,A P I,case
+"e\.g\.",for example,regex
+`)
+	r, err := loadReplacements(path)
+	require.NoError(t, err)
+	textReplacements = r
+	t.Cleanup(func() { textReplacements = nil })
+
+	assert.Equal(t, "Use tools, for example curl", applyReplacements("Use tools, e.g. curl"))
+	assert.Equal(t, "The A P I, not the api", applyReplacements("The API, not the api"))
+}
+
+func TestLoadReplacementsErrors(t *testing.T) {
+	_, err := loadReplacements(writeReplacements(t, "rules.txt", "a,b"))
+	assert.ErrorContains(t, err, "must be .json or .csv")
+
+	_, err = loadReplacements(writeReplacements(t, "rules.json", `[{"find": "a", "with": "b"}]`))
+	assert.ErrorContains(t, err, "unknown field")
+
+	_, err = loadReplacements(writeReplacements(t, "rules.json", `[{"find": "(", "regex": true}]`))
+	assert.ErrorContains(t, err, "rule 1: invalid pattern")
+
+	_, err = loadReplacements(writeReplacements(t, "rules.csv", "a,b,loud\n"))
+	assert.ErrorContains(t, err, `unknown flag "loud"`)
+
+	_, err = loadReplacements(writeReplacements(t, "rules.csv", ",b\n"))
+	assert.ErrorContains(t, err, "find must not be empty")
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Result verbosity levels, set with MCP_SAY_RESULT_VERBOSITY or the per-call quiet argument
+const (
+	// verbosityQuiet returns only "ok" and how long the call took
+	verbosityQuiet = "quiet"
+	// verbosityNormal returns the handler's result unchanged
+	verbosityNormal = "normal"
+	// verbosityVerbose adds provider latency and audio size
+	verbosityVerbose = "verbose"
+)
+
+// resultVerbosity returns the verbosity for a call, quiet: true wins over MCP_SAY_RESULT_VERBOSITY
+func resultVerbosity(arguments map[string]any) string {
+	if quiet, _ := arguments["quiet"].(bool); quiet {
+		return verbosityQuiet
+	}
+	switch v := getenv("MCP_SAY_RESULT_VERBOSITY"); v {
+	case "":
+		return verbosityNormal
+	case verbosityQuiet, verbosityNormal, verbosityVerbose:
+		return v
+	default:
+		log.Warn("Invalid result verbosity, using normal", "env", "MCP_SAY_RESULT_VERBOSITY", "value", v)
+		return verbosityNormal
+	}
+}
+
+// callStats collects provider latency and audio size for a single tool call
+type callStats struct {
+	mu       sync.Mutex
+	requests int
+	// latency is the slowest time to a provider's first response
+	latency time.Duration
+	bytes   int64
+	// muted is set when playback was skipped because output is muted
+	muted bool
+}
+
+type callStatsKey struct{}
+
+// withCallStats returns a context that collects the stats of a single tool call
+func withCallStats(ctx context.Context) (context.Context, *callStats) {
+	stats := &callStats{}
+	return context.WithValue(ctx, callStatsKey{}, stats), stats
+}
+
+// recordResponse adds a provider response to the current call's stats, if any
+func recordResponse(ctx context.Context, latency time.Duration, bytes int) {
+	if stats, ok := ctx.Value(callStatsKey{}).(*callStats); ok {
+		stats.mu.Lock()
+		stats.requests++
+		stats.latency = max(stats.latency, latency)
+		stats.bytes += int64(bytes)
+		stats.mu.Unlock()
+	}
+}
+
+// countingReader adds the bytes read from a streamed response to the call's stats
+type countingReader struct {
+	io.ReadCloser
+	ctx context.Context
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if stats, ok := r.ctx.Value(callStatsKey{}).(*callStats); ok && n > 0 {
+		stats.mu.Lock()
+		stats.bytes += int64(n)
+		stats.mu.Unlock()
+	}
+	return n, err
+}
+
+// detailsLine summarizes the call for verbose results
+func (s *callStats) detailsLine(elapsed time.Duration) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.requests == 0 {
+		return fmt.Sprintf("Details: took %s", elapsed.Round(time.Millisecond))
+	}
+	return fmt.Sprintf("Details: took %s, %d provider request(s), first response after %s, %d bytes of audio",
+		elapsed.Round(time.Millisecond), s.requests, s.latency.Round(time.Millisecond), s.bytes)
+}
+
+// WithResultFormat wraps a tool handler to shorten or extend its result text
+// according to the call's verbosity. Errors and cancellations are left untouched.
+func WithResultFormat(handler ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		verbosity := resultVerbosity(request.GetArguments())
+		ctx, stats := withCallStats(ctx)
+		start := time.Now()
+		result, err := handler(ctx, request)
+		if err != nil || result == nil || result.IsError || ctx.Err() != nil || len(result.Content) == 0 {
+			return result, err
+		}
+		elapsed := time.Since(start)
+
+		stats.mu.Lock()
+		skipped := stats.muted
+		stats.mu.Unlock()
+		if skipped {
+			result.Content = append(result.Content, mcp.NewTextContent(mutedNote))
+		}
+
+		switch verbosity {
+		case verbosityQuiet:
+			if _, ok := result.Content[0].(mcp.TextContent); ok {
+				result.Content[0] = mcp.NewTextContent(fmt.Sprintf("ok (%s)", elapsed.Round(100*time.Millisecond)))
+			}
+		case verbosityVerbose:
+			result.Content = append(result.Content, mcp.NewTextContent(stats.detailsLine(elapsed)))
+		}
+		return result, nil
+	}
+}
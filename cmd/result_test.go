@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultVerbosity(t *testing.T) {
+	assert.Equal(t, verbosityNormal, resultVerbosity(nil))
+	assert.Equal(t, verbosityQuiet, resultVerbosity(map[string]any{"quiet": true}))
+
+	t.Setenv("MCP_SAY_RESULT_VERBOSITY", "verbose")
+	assert.Equal(t, verbosityVerbose, resultVerbosity(map[string]any{"quiet": false}))
+	assert.Equal(t, verbosityQuiet, resultVerbosity(map[string]any{"quiet": true}))
+
+	t.Setenv("MCP_SAY_RESULT_VERBOSITY", "loud")
+	assert.Equal(t, verbosityNormal, resultVerbosity(nil))
+}
+
+func TestWithResultFormat(t *testing.T) {
+	handler := WithResultFormat(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		recordResponse(ctx, 250*time.Millisecond, 1024)
+		return &mcp.CallToolResult{Content: []mcp.Content{
+			mcp.NewTextContent("Speaking: hello"),
+			mcp.NewAudioContent("AAAA", "audio/mpeg"),
+		}}, nil
+	})
+	call := func(arguments map[string]any) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = arguments
+		result, err := handler(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := call(nil)
+	require.Len(t, result.Content, 2)
+	assert.Equal(t, "Speaking: hello", result.Content[0].(mcp.TextContent).Text)
+
+	result = call(map[string]any{"quiet": true})
+	require.Len(t, result.Content, 2)
+	assert.Regexp(t, `^ok \(.*s\)$`, result.Content[0].(mcp.TextContent).Text)
+	assert.IsType(t, mcp.AudioContent{}, result.Content[1])
+
+	t.Setenv("MCP_SAY_RESULT_VERBOSITY", "verbose")
+	result = call(nil)
+	require.Len(t, result.Content, 3)
+	details := result.Content[2].(mcp.TextContent).Text
+	assert.Contains(t, details, "1 provider request(s)")
+	assert.Contains(t, details, "first response after 250ms")
+	assert.Contains(t, details, "1024 bytes of audio")
+}
+
+func TestWithResultFormatKeepsErrors(t *testing.T) {
+	handler := WithResultFormat(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("Error: boom"), nil
+	})
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"quiet": true}
+	result, err := handler(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, "Error: boom", result.Content[0].(mcp.TextContent).Text)
+}
@@ -22,32 +22,27 @@ THE SOFTWARE.
 package cmd
 
 import (
-	"bytes"
+	"cmp"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"maps"
+	"math"
+	"net/url"
 	"os"
 	"os/exec"
 	"runtime"
+	"slices"
 	"strconv"
 	"time"
 
+	"github.com/blacktop/mcp-tts/say"
 	"github.com/caarlos0/ctrlc"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
-	"github.com/gopxl/beep/v2"
-	"github.com/gopxl/beep/v2/mp3"
-	"github.com/gopxl/beep/v2/speaker"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
-	"github.com/openai/openai-go"
-	"github.com/openai/openai-go/option"
 	"github.com/spf13/cobra"
-	"golang.org/x/sync/errgroup"
-	"google.golang.org/genai"
 )
 
 var (
@@ -59,6 +54,25 @@ var (
 	cancellationManager *CancellationManager
 	// Flag to suppress "Speaking:" output
 	suppressSpeakingOutput bool
+	// Flag to append the estimated cost to results
+	showCost bool
+	// Path to the YAML config file
+	configPath string
+	// Flag to never touch the audio device
+	noAudio bool
+	// Flag to call Google over gRPC with Application Default Credentials
+	googleGRPC bool
+	// Address of the HTTP endpoint serving synthesized audio, empty when disabled
+	audioAddr string
+	// Flags to play earcons when the server is ready and after each utterance
+	readyTone      bool
+	completionTone bool
+	// Flag to play a test tone, report on the audio pipeline and exit
+	selfTest bool
+	// Flag to log the format, duration and peak of raw PCM clips before playback
+	pcmDiagnostics bool
+	// Plays the audio synthesized by the TTS tools
+	audioPlayer say.AudioPlayer = say.DefaultPlayer
 )
 
 func init() {
@@ -78,11 +92,20 @@ func init() {
 	// Define CLI flags
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose debug logging")
 	rootCmd.PersistentFlags().BoolVar(&suppressSpeakingOutput, "suppress-speaking-output", false, "Suppress 'Speaking:' text output")
-	
-	// Check environment variable for suppressing output
-	if os.Getenv("MCP_TTS_SUPPRESS_SPEAKING_OUTPUT") == "true" {
-		suppressSpeakingOutput = true
-	}
+	rootCmd.PersistentFlags().BoolVar(&showCost, "show-cost", false, "Append the estimated cost to each result")
+
+	rootCmd.PersistentFlags().BoolVar(&noAudio, "no-audio", false, "Headless mode: never open the audio device, tools must use output_file or return_audio")
+	rootCmd.PersistentFlags().BoolVar(&googleGRPC, "google-grpc", false, "Use the Google Cloud Text-to-Speech gRPC API with Application Default Credentials instead of the API key")
+	rootCmd.PersistentFlags().BoolVar(&googleLongAudio, "google-long-audio", false, "Send google_tts text over 5000 bytes to the Cloud Text-to-Speech long audio API with Application Default Credentials, which writes to a gcs_bucket")
+	rootCmd.PersistentFlags().BoolVar(&readyTone, "ready-tone", false, "Play a short tone when the server is ready")
+	rootCmd.PersistentFlags().BoolVar(&completionTone, "completion-tone", false, "Play a short tone after each utterance")
+	rootCmd.PersistentFlags().BoolVar(&pcmDiagnostics, "pcm-diagnostics", false, "Log the sample rate, channels, length, duration and peak of raw PCM audio before playback (needs --verbose)")
+	rootCmd.PersistentFlags().BoolVar(&selfTest, "selftest", false, "Play a short test tone, print audio diagnostics and exit with pass or fail")
+	rootCmd.PersistentFlags().StringToStringVar(&defaultVoices, "default-voice", nil, "Fallback voice per provider when a call omits voice, e.g. openai=nova,google=Puck")
+	rootCmd.PersistentFlags().StringVar(&audioAddr, "audio-addr", "", "Serve synthesized audio over HTTP on this address, e.g. 127.0.0.1:8765 (other than loopback requires MCP_SAY_AUTH_TOKEN)")
+	rootCmd.PersistentFlags().DurationVar(&idleTimeout, "idle-timeout", 0, "Release the audio device after this long without tool calls, e.g. 30m, reopening it on the next playback (default: never)")
+	rootCmd.PersistentFlags().BoolVar(&idleExit, "idle-exit", false, "Exit instead of only releasing the audio device once --idle-timeout expires")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Path to a YAML config file (env vars override its settings)")
 }
 
 // rootCmd represents the base command when called without any subcommands
@@ -107,6 +130,33 @@ Designed to be used with the MCP (Model Context Protocol).`,
 		if verbose {
 			log.SetLevel(log.DebugLevel)
 		}
+		if pcmDiagnostics {
+			if !verbose {
+				log.Warn("--pcm-diagnostics logs at debug level, add --verbose to see it")
+			}
+			say.SetPCMDiagnostics(true)
+		}
+
+		if err := loadSettings(); err != nil {
+			return err
+		}
+		if noAudio {
+			log.Info("Audio playback disabled, the speaker will not be initialized")
+			audioPlayer = say.NoAudioPlayer{}
+		} else {
+			audioPlayer = speakerPlayer()
+		}
+		if selfTest {
+			if noAudio {
+				return errors.New("--selftest plays audio, it can't be combined with --no-audio")
+			}
+			p := speakerPlayer()
+			prebuffer := p.Prebuffer
+			// Without prebuffering, every sample counted was taken by the device itself
+			p.Prebuffer = -1
+			cmd.SilenceUsage = true
+			return runSelfTest(cmd.Context(), p, p.Buffer, prebuffer, cmd.OutOrStdout())
+		}
 
 		// Initialize cancellation manager
 		cancellationManager = NewCancellationManager()
@@ -118,728 +168,1021 @@ Designed to be used with the MCP (Model Context Protocol).`,
 			}
 		}()
 
-		// Create a new MCP server
-		s := server.NewMCPServer(
-			"Say TTS Service",
-			Version,
-			server.WithPromptCapabilities(true),
-			server.WithToolCapabilities(true),
-			server.WithLogging(),
-		)
-
-		s.AddPrompt(mcp.NewPrompt("say",
-			mcp.WithPromptDescription("Speaks the provided text out loud using the macOS text-to-speech engine"),
-			mcp.WithArgument("text",
-				mcp.RequiredArgument(),
-				mcp.ArgumentDescription("The text to be spoken"),
-			),
-			mcp.WithArgument("rate",
-				mcp.ArgumentDescription("The rate at which the text is spoken (words per minute)"),
-			),
-			mcp.WithArgument("voice",
-				mcp.ArgumentDescription("The voice to use for speech"),
-			),
-		), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-			text := request.Params.Arguments["text"]
-			if text == "" {
-				return nil, fmt.Errorf("text is required")
-			}
-
-			args := []string{}
+		s := newServer()
+		if idleTimeout > 0 {
+			idle = newIdleWatcher(idleTimeout, onIdle)
+			log.Info("Idle timeout enabled", "timeout", idleTimeout, "exit", idleExit)
+		}
 
-			// Add rate if provided
-			if rate := request.Params.Arguments["rate"]; rate != "" {
-				rateInt, _ := strconv.Atoi(rate)
-				args = append(args, "--rate", fmt.Sprintf("%d", rateInt))
-			} else {
-				args = append(args, "--rate", "200") // Default rate
-			}
+		logProviderStatus()
 
-			// Add voice if provided
-			if voice := request.Params.Arguments["voice"]; voice != "" {
-				args = append(args, "--voice", voice)
+		if audioAddr == "" {
+			audioAddr = getenv("MCP_SAY_AUDIO_ADDR")
+		}
+		if audioAddr != "" {
+			audioServer, err := startAudioServer(audioAddr, getenv("MCP_SAY_AUTH_TOKEN"))
+			if err != nil {
+				return err
 			}
+			defer audioServer.Close()
+		}
 
-			args = append(args, text)
+		log.Info("Starting MCP server", "name", "Say TTS Service", "version", Version)
+		if readyTone {
+			go playReadyTone()
+		}
+		// Start the server using stdin/stdout
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		defer audioDucker.close()
 
-			// Execute the say command
-			sayCmd := exec.Command("/usr/bin/say", args...)
-			if err := sayCmd.Start(); err != nil {
-				return nil, fmt.Errorf("failed to start say command: %v", err)
+		ignoreBrokenPipe()
+		if err := ctrlc.Default.Run(ctx, func() error {
+			if err := serveStdio(ctx, s, os.Stdin, os.Stdout); err != nil {
+				return fmt.Errorf("failed to serve MCP: %v", err)
 			}
-
-			var content string
-			if suppressSpeakingOutput {
-				content = "Speech completed"
+			return nil
+		}); err != nil {
+			if errors.As(err, &ctrlc.ErrorCtrlC{}) {
+				log.Warn("Exiting...")
+				audioDucker.close()
+				os.Exit(0)
 			} else {
-				content = fmt.Sprintf("Speaking: %s", text)
+				return fmt.Errorf("failed while serving MCP: %v", err)
 			}
-			
-			return mcp.NewGetPromptResult(
-				"Speaking text",
-				[]mcp.PromptMessage{
-					mcp.NewPromptMessage(
-						mcp.RoleUser,
-						mcp.NewTextContent(content),
-					),
-				},
-			), nil
-		})
-
-		if runtime.GOOS == "darwin" {
-			// Add the "say_tts" tool
-			sayTool := mcp.NewTool("say_tts",
-				mcp.WithDescription("Speaks the provided text out loud using the macOS text-to-speech engine"),
-				mcp.WithString("text",
-					mcp.Required(),
-					mcp.Description("The text to be spoken"),
-				),
-				mcp.WithNumber("rate",
-					mcp.Description("The rate at which the text is spoken (words per minute)"),
-				),
-				mcp.WithString("voice",
-					mcp.Description("The voice to use for speech"),
-				),
-			)
-
-			// Add the say tool handler
-			s.AddTool(sayTool, WithCancellation(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-				log.Debug("Say tool called", "request", request)
-				arguments := request.GetArguments()
-				text, ok := arguments["text"].(string)
-				if !ok {
-					result := mcp.NewToolResultText("Error: text must be a string")
-					result.IsError = true
-					return result, nil
-				}
-
-				args := []string{}
-
-				// Add rate if provided
-				if rate, ok := arguments["rate"].(float64); ok {
-					args = append(args, "--rate", fmt.Sprintf("%d", int(rate)))
-				} else {
-					args = append(args, "--rate", "200") // Default rate
-				}
-
-				// Add voice if provided and validate it
-				if voice, ok := arguments["voice"].(string); ok && voice != "" {
-					// Simple validation to prevent command injection
-					// Only allow alphanumeric characters, spaces, and some common punctuation
-					for _, r := range voice {
-						if !((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == ' ' || r == '(' || r == ')') {
-							result := mcp.NewToolResultText(fmt.Sprintf("Error: Voice contains invalid characters: %s", voice))
-							result.IsError = true
-							return result, nil
-						}
-					}
-					args = append(args, "--voice", voice)
-				}
-
-				// Text is passed as a separate argument, not through shell, which provides some safety
-				// but we'll still do basic validation
-				if text == "" {
-					result := mcp.NewToolResultText("Error: Empty text provided")
-					result.IsError = true
-					return result, nil
-				}
-
-				// Check for potentially dangerous shell metacharacters
-				// Note: exec.Command with separate arguments is already safe from command injection,
-				// but we're adding this check as an additional safeguard
-				dangerousChars := []rune{';', '&', '|', '<', '>', '`', '$', '(', ')', '{', '}', '[', ']', '\\', '\'', '"', '\n', '\r'}
-				for _, char := range dangerousChars {
-					if bytes.ContainsRune([]byte(text), char) {
-						log.Warn("Potentially dangerous character in text input",
-							"char", string(char),
-							"text", text)
-					}
-				}
-
-				// Add the text as the last argument
-				args = append(args, text)
-
-				log.Debug("Executing say command", "args", args)
-				// Execute the say command with context for cancellation
-				sayCmd := exec.CommandContext(ctx, "/usr/bin/say", args...)
-				if err := sayCmd.Start(); err != nil {
-					log.Error("Failed to start say command", "error", err)
-					result := mcp.NewToolResultText(fmt.Sprintf("Error: Failed to start say command: %v", err))
-					result.IsError = true
-					return result, nil
-				}
-
-				// Wait for command completion or cancellation in a goroutine
-				done := make(chan error, 1)
-				go func() {
-					done <- sayCmd.Wait()
-				}()
-
-				select {
-				case err := <-done:
-					if err != nil {
-						if ctx.Err() == context.Canceled {
-							log.Info("Say command cancelled by user")
-							return mcp.NewToolResultText("Say command cancelled"), nil
-						}
-						log.Error("Say command failed", "error", err)
-						result := mcp.NewToolResultText(fmt.Sprintf("Error: Say command failed: %v", err))
-						result.IsError = true
-						return result, nil
-					}
-					log.Info("Speaking text completed", "text", text)
-					if suppressSpeakingOutput {
-						return mcp.NewToolResultText("Speech completed"), nil
-					}
-					return mcp.NewToolResultText(fmt.Sprintf("Speaking: %s", text)), nil
-				case <-ctx.Done():
-					log.Info("Say command cancelled by user")
-					// The CommandContext will handle killing the process
-					return mcp.NewToolResultText("Say command cancelled"), nil
-				}
-			}))
 		}
+		return nil
+	},
+}
 
-		elevenLabsTool := mcp.NewTool("elevenlabs_tts",
-			mcp.WithDescription("Uses the ElevenLabs API to generate speech from text"),
-			mcp.WithString("text",
-				mcp.Required(),
-				mcp.Description("The text to be spoken"),
-			),
-		)
+// loadSettings reads the config file and the MCP_SAY_* settings shared by every command
+func loadSettings() error {
+	if err := loadTestMode(); err != nil {
+		return err
+	}
+	if configPath == "" {
+		configPath = os.Getenv("MCP_SAY_CONFIG")
+	}
+	if configPath != "" {
+		c, err := loadConfig(configPath)
+		if err != nil {
+			return err
+		}
+		config = c
+		log.Info("Loaded config", "path", configPath)
+	}
+	if err := validateDefaultVoices(); err != nil {
+		return err
+	}
+	if err := loadSecretFiles(); err != nil {
+		return err
+	}
+	if keys := elevenLabsKeys(); len(keys) > 1 {
+		say.SetElevenLabsKeys(keys)
+		log.Info("Rotating ElevenLabs API keys", "keys", len(keys))
+	}
 
-		s.AddTool(elevenLabsTool, WithCancellation(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			log.Debug("ElevenLabs tool called", "request", request)
-			arguments := request.GetArguments()
-			text, ok := arguments["text"].(string)
-			if !ok {
-				result := mcp.NewToolResultText("Error: text must be a string")
-				result.IsError = true
-				return result, nil
-			}
+	// Check environment variables and config for suppressing output
+	if getenv("MCP_TTS_SUPPRESS_SPEAKING_OUTPUT") == "true" {
+		suppressSpeakingOutput = true
+	}
+	if getenv("MCP_SAY_SHOW_COST") == "true" {
+		showCost = true
+	}
 
-			voiceID := os.Getenv("ELEVENLABS_VOICE_ID")
-			if voiceID == "" {
-				voiceID = "1SM7GgM6IMuvQlz2BwM3"
-				log.Debug("Voice not specified, using default", "voiceID", voiceID)
-			}
+	if path := getenv("MCP_SAY_REPLACEMENTS_FILE"); path != "" {
+		r, err := loadReplacements(path)
+		if err != nil {
+			return err
+		}
+		textReplacements = r
+		log.Info("Loaded text replacements", "path", path, "rules", len(r))
+	}
 
-			modelID := os.Getenv("ELEVENLABS_MODEL_ID")
-			if modelID == "" {
-				modelID = "eleven_multilingual_v2" // eleven_turbo_v2_5 is also available
-				log.Debug("Model not specified, using default", "modelID", modelID)
-			}
+	if v := getenv("MCP_SAY_NO_AUDIO"); v == "1" || v == "true" {
+		noAudio = true
+	}
+	if value := getenv("MCP_SAY_PROXY"); value != "" {
+		if err := say.SetProxy(value); err != nil {
+			return fmt.Errorf("invalid MCP_SAY_PROXY: %v", err)
+		}
+		proxy, _ := url.Parse(value)
+		log.Info("Sending provider requests through proxy", "proxy", proxy.Redacted())
+	}
+	if err := loadExtraHeaders(); err != nil {
+		return err
+	}
+	if err := say.SetUserAgent(cmp.Or(getenv("MCP_SAY_USER_AGENT"), "mcp-say/"+cmp.Or(Version, "dev"))); err != nil {
+		return fmt.Errorf("invalid MCP_SAY_USER_AGENT: %v", err)
+	}
+	if value := getenv("MCP_SAY_IDLE_TIMEOUT"); value != "" && idleTimeout == 0 {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid MCP_SAY_IDLE_TIMEOUT %q, use a duration like 30m", value)
+		}
+		idleTimeout = d
+	}
+	if v := getenv("MCP_SAY_IDLE_EXIT"); v == "1" || v == "true" {
+		idleExit = true
+	}
+	if v := getenv("MCP_SAY_TEXT_FALLBACK"); v == "1" || v == "true" {
+		textFallback = true
+		log.Info("Text fallback enabled, speech tools return their text when there is no audio backend")
+	}
+	if v := getenv("MCP_SAY_MUTED"); v == "1" || v == "true" {
+		muted.Store(true)
+		log.Info("Starting muted, call unmute to resume playback")
+	}
+	mode, err := parseErrorMode(getenv("MCP_SAY_ERROR_MODE"))
+	if err != nil {
+		return fmt.Errorf("invalid MCP_SAY_ERROR_MODE: %v", err)
+	}
+	errorMode = mode
+	sound, err := parseErrorSound(getenv("MCP_SAY_ERROR_SOUND"))
+	if err != nil {
+		return fmt.Errorf("invalid MCP_SAY_ERROR_SOUND: %v", err)
+	}
+	failureSound = sound
+	loadDucking()
+	if value := getenv("MCP_SAY_WEBHOOK_URL"); value != "" {
+		u, err := parseWebhookURL(value)
+		if err != nil {
+			return fmt.Errorf("invalid MCP_SAY_WEBHOOK_URL: %v", err)
+		}
+		webhookURL = u
+		log.Info("Sending playback webhooks", "url", u)
+	}
+	if v := getenv("MCP_SAY_GOOGLE_GRPC"); v == "1" || v == "true" {
+		googleGRPC = true
+	}
+	if v := getenv("MCP_SAY_GOOGLE_LONG_AUDIO"); v == "1" || v == "true" {
+		googleLongAudio = true
+	}
+	if value := getenv("MCP_SAY_VOICE_ALIASES"); value != "" {
+		aliases, err := parseVoiceAliases(value)
+		if err != nil {
+			return fmt.Errorf("invalid MCP_SAY_VOICE_ALIASES: %v", err)
+		}
+		voiceAliases = aliases
+		log.Info("Loaded voice aliases", "aliases", voiceAliasNames())
+	}
+	if value := getenv("MCP_SAY_PROVIDER_POLICY"); value != "" {
+		policy, err := parseProviderPolicy(value)
+		if err != nil {
+			return fmt.Errorf("invalid MCP_SAY_PROVIDER_POLICY: %v", err)
+		}
+		speakPolicy = policy
+		log.Info("Loaded provider policy", "policy", policy)
+	}
+	return nil
+}
 
-			apiKey := os.Getenv("ELEVENLABS_API_KEY")
-			if apiKey == "" {
-				log.Error("ELEVENLABS_API_KEY not set")
-				result := mcp.NewToolResultText("Error: ELEVENLABS_API_KEY is not set")
-				result.IsError = true
-				return result, nil
-			}
+// newServer creates the MCP server with every prompt and tool registered
+func newServer() *server.MCPServer {
+	// Create a new MCP server
+	s := server.NewMCPServer(
+		"Say TTS Service",
+		Version,
+		server.WithPromptCapabilities(true),
+		server.WithToolCapabilities(true),
+		server.WithLogging(),
+		server.WithToolHandlerMiddleware(withErrorMode),
+		server.WithToolHandlerMiddleware(withErrorSound),
+		server.WithToolHandlerMiddleware(withIdleTimer),
+	)
+
+	s.AddPrompt(mcp.NewPrompt("say",
+		mcp.WithPromptDescription("Speaks the provided text out loud using the macOS text-to-speech engine"),
+		mcp.WithArgument("text",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("The text to be spoken"),
+		),
+		mcp.WithArgument("rate",
+			mcp.ArgumentDescription("The rate at which the text is spoken (words per minute)"),
+		),
+		mcp.WithArgument("voice",
+			mcp.ArgumentDescription("The voice to use for speech"),
+		),
+	), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		text := request.Params.Arguments["text"]
+		if text == "" {
+			return nil, fmt.Errorf("text is required")
+		}
 
-			pipeReader, pipeWriter := io.Pipe()
-
-			// Channel to signal when HTTP response status has been validated
-			statusValidated := make(chan error, 1)
-			// Channel to signal when audio playback is complete
-			audioComplete := make(chan error, 1)
-
-			g, ctx := errgroup.WithContext(ctx)
-
-			g.Go(func() error {
-				defer pipeWriter.Close()
-
-				url := fmt.Sprintf("https://api.elevenlabs.io/v1/text-to-speech/%s/stream", voiceID)
-
-				params := ElevenLabsParams{
-					Text:    text,
-					ModelID: modelID,
-					VoiceSettings: SynthesisOptions{
-						Stability:       0.60,
-						SimilarityBoost: 0.75,
-						Style:           0.50,
-						UseSpeakerBoost: false,
-					},
-				}
-
-				b, err := json.Marshal(params)
-				if err != nil {
-					log.Error("Failed to marshal request body", "error", err)
-					statusValidated <- fmt.Errorf("failed to marshal request body: %v", err)
-					return fmt.Errorf("failed to marshal request body: %v", err)
-				}
-
-				log.Debug("Making ElevenLabs API request",
-					"url", url,
-					"voice", voiceID,
-					"model", modelID,
-					"text", text,
-					"params", params,
-				)
-
-				req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(b))
-				if err != nil {
-					log.Error("Failed to create request", "error", err)
-					statusValidated <- fmt.Errorf("failed to create request: %v", err)
-					return fmt.Errorf("failed to create request: %v", err)
-				}
-
-				req.Header.Set("xi-api-key", apiKey)
-				req.Header.Set("Content-Type", "application/json")
-				req.Header.Set("accept", "audio/mpeg")
-
-				safeLog("Sending HTTP request", req)
-				res, err := http.DefaultClient.Do(req)
-				if err != nil {
-					log.Error("Failed to send request", "error", err)
-					statusValidated <- fmt.Errorf("failed to send request: %v", err)
-					return fmt.Errorf("failed to send request: %v", err)
-				}
-				defer res.Body.Close()
-
-				if res.StatusCode != http.StatusOK {
-					log.Error("Request failed", "status", res.Status, "statusCode", res.StatusCode)
-					// Read the error response body for more details
-					body, readErr := io.ReadAll(res.Body)
-					errMsg := fmt.Errorf("ElevenLabs API error: status %d %s", res.StatusCode, res.Status)
-					if readErr == nil && len(body) > 0 {
-						log.Error("Error response body", "body", string(body))
-						errMsg = fmt.Errorf("ElevenLabs API error (status %d): %s", res.StatusCode, string(body))
-					}
-					statusValidated <- errMsg
-					return errMsg
-				}
-
-				// HTTP status is OK, signal success and proceed with streaming
-				statusValidated <- nil
-
-				log.Debug("Copying response body to pipe")
-				bytesWritten, err := io.Copy(pipeWriter, res.Body)
-				log.Debug("Response body copied", "bytes", bytesWritten)
-				return err
-			})
-
-			// Wait for HTTP status validation before proceeding to decode
-			select {
-			case err := <-statusValidated:
-				if err != nil {
-					log.Error("HTTP request failed", "error", err)
-					result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
-					result.IsError = true
-					return result, nil
-				}
-				log.Debug("HTTP status validated successfully, proceeding to decode")
-			case <-ctx.Done():
-				log.Error("Context cancelled while waiting for HTTP status validation")
-				result := mcp.NewToolResultText("Error: Request cancelled")
-				result.IsError = true
-				return result, nil
-			}
+		args := []string{}
 
-			// Start audio playback in a separate goroutine with cancellation support
-			g.Go(func() error {
-				log.Debug("Decoding MP3 stream")
-				streamer, format, err := mp3.Decode(pipeReader)
-				if err != nil {
-					log.Error("Failed to decode response", "error", err)
-					audioComplete <- fmt.Errorf("failed to decode response: %v", err)
-					return fmt.Errorf("failed to decode response: %v", err)
-				}
-				defer streamer.Close()
-
-				log.Debug("Initializing speaker", "sampleRate", format.SampleRate)
-				speaker.Init(format.SampleRate, format.SampleRate.N(time.Second/10))
-				done := make(chan bool, 1)
-
-				// Play audio with callback
-				speaker.Play(beep.Seq(streamer, beep.Callback(func() {
-					done <- true
-				})))
-
-				log.Info("Speaking text via ElevenLabs", "text", text)
-
-				// Wait for either completion or cancellation
-				select {
-				case <-done:
-					log.Debug("Audio playback completed normally")
-					audioComplete <- nil
-					return nil
-				case <-ctx.Done():
-					log.Debug("Context cancelled, stopping audio playback")
-					// Clear all audio from speaker to stop playback immediately
-					speaker.Clear()
-					audioComplete <- ctx.Err()
-					return ctx.Err()
-				}
-			})
-
-			// Wait for audio completion or cancellation
-			select {
-			case err := <-audioComplete:
-				if err != nil && err != context.Canceled {
-					log.Error("Audio playback failed", "error", err)
-					result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
-					result.IsError = true
-					return result, nil
-				}
-				if err == context.Canceled {
-					log.Info("Audio playback cancelled by user")
-					return mcp.NewToolResultText("Audio playback cancelled"), nil
-				}
-			case <-ctx.Done():
-				log.Info("Request cancelled, stopping all operations")
-				speaker.Clear()
-				return mcp.NewToolResultText("Request cancelled"), nil
-			}
+		// Add rate if provided
+		if rate := request.Params.Arguments["rate"]; rate != "" {
+			rateInt, _ := strconv.Atoi(rate)
+			args = append(args, "--rate", fmt.Sprintf("%d", rateInt))
+		} else {
+			args = append(args, "--rate", "200") // Default rate
+		}
 
-			log.Debug("Finished speaking")
+		// Add voice if provided
+		if voice := request.Params.Arguments["voice"]; voice != "" {
+			args = append(args, "--voice", voice)
+		}
 
-			// Check for any errors that occurred during streaming
-			if err := g.Wait(); err != nil && err != context.Canceled {
-				log.Error("Error occurred during streaming", "error", err)
-				result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
-				result.IsError = true
-				return result, nil
-			}
+		args = append(args, text)
 
-			if suppressSpeakingOutput {
-				return mcp.NewToolResultText("Speech completed"), nil
-			}
-			return mcp.NewToolResultText(fmt.Sprintf("Speaking: %s", text)), nil
-		}))
+		// Execute the say command
+		sayCmd := exec.Command(sayBinary, args...)
+		if err := sayCmd.Start(); err != nil {
+			return nil, fmt.Errorf("failed to start say command: %v", err)
+		}
+
+		var content string
+		if suppressSpeakingOutput {
+			content = "Speech completed"
+		} else {
+			content = fmt.Sprintf("Speaking: %s", text)
+		}
 
-		// Add Google TTS tool
-		googleTTSTool := mcp.NewTool("google_tts",
-			mcp.WithDescription("Uses Google's dedicated Text-to-Speech API with Gemini TTS models"),
+		return mcp.NewGetPromptResult(
+			"Speaking text",
+			[]mcp.PromptMessage{
+				mcp.NewPromptMessage(
+					mcp.RoleUser,
+					mcp.NewTextContent(content),
+				),
+			},
+		), nil
+	})
+
+	if runtime.GOOS == "darwin" {
+		// Add the "say_tts" tool
+		sayTool := mcp.NewTool("say_tts",
+			mcp.WithDescription("Speaks the provided text out loud using the macOS text-to-speech engine"),
 			mcp.WithString("text",
-				mcp.Required(),
-				mcp.Description("The text message to convert to speech"),
+				mcp.Description("The text to be spoken"),
+			),
+			mcp.WithString("text_file",
+				mcp.Description("Path to a UTF-8 text file to speak instead of text"),
+			),
+			mcp.WithNumber("rate",
+				mcp.Description(fmt.Sprintf("Speaking rate in words per minute, %d-%d (default: 200). Around 150 is relaxed, 175-200 conversational, 250 and up fast", say.MinSayRate, say.MaxSayRate)),
+				mcp.Min(say.MinSayRate),
+				mcp.Max(say.MaxSayRate),
+				wholeNumber(),
 			),
 			mcp.WithString("voice",
-				mcp.Description("Voice name: Zephyr, Puck, Charon, Kore, Fenrir, Aoede, Leda, Orus, etc. (default: Kore)"),
+				mcp.Description(fmt.Sprintf("The voice to use for speech (default: %s)", cmp.Or(activeDefaultVoice(say.ProviderSay), "the system voice"))),
+			),
+			mcp.WithString("output_file",
+				mcp.Description("Path to write the audio to instead of playing it (WAV)"),
+			),
+			mcp.WithBoolean("return_audio",
+				mcp.Description("Return the audio inline in the result instead of playing it (default: false)"),
 			),
-			mcp.WithString("model",
-				mcp.Description("TTS model: gemini-2.5-flash-preview-tts, gemini-2.5-pro-preview-tts (default: gemini-2.5-flash-preview-tts)"),
+			mcp.WithBoolean("echo_text",
+				mcp.Description("Add the exact text sent to the provider, after text replacements, to the result as its own block, e.g. for a transcript display (default: false)"),
+			),
+			mcp.WithBoolean("interrupt",
+				mcp.Description("Stop whatever is currently playing before this speech starts (default: MCP_SAY_INTERRUPT or false)"),
+			),
+			mcp.WithBoolean("quiet",
+				mcp.Description("Return only 'ok' and the call duration (default: MCP_SAY_RESULT_VERBOSITY or normal)"),
 			),
 		)
 
-		s.AddTool(googleTTSTool, WithCancellation(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			log.Debug("Google TTS tool called", "request", request)
-			arguments := request.GetArguments()
-			text, ok := arguments["text"].(string)
-			if !ok {
-				result := mcp.NewToolResultText("Error: text must be a string")
-				result.IsError = true
-				return result, nil
-			}
-
-			if text == "" {
-				result := mcp.NewToolResultText("Error: Empty text provided")
-				result.IsError = true
-				return result, nil
-			}
-
-			// Get configuration from arguments
-			voice := "Kore"
-			if v, ok := arguments["voice"].(string); ok && v != "" {
-				voice = v
-			}
-
-			model := "gemini-2.5-flash-preview-tts"
-			if m, ok := arguments["model"].(string); ok && m != "" {
-				model = m
-			}
-
-			// Get API key from environment
-			apiKey := os.Getenv("GOOGLE_AI_API_KEY")
-			if apiKey == "" {
-				apiKey = os.Getenv("GEMINI_API_KEY")
-			}
-			if apiKey == "" {
-				log.Error("GOOGLE_AI_API_KEY or GEMINI_API_KEY not set")
-				result := mcp.NewToolResultText("Error: GOOGLE_AI_API_KEY or GEMINI_API_KEY is not set")
-				result.IsError = true
-				return result, nil
-			}
-
-			// Create Google AI client
-			client, err := genai.NewClient(ctx, &genai.ClientConfig{
-				APIKey:  apiKey,
-				Backend: genai.BackendGeminiAPI,
-			})
-			if err != nil {
-				log.Error("Failed to create Google AI client", "error", err)
-				result := mcp.NewToolResultText(fmt.Sprintf("Error: Failed to create client: %v", err))
-				result.IsError = true
-				return result, nil
-			}
-
-			log.Debug("Generating TTS audio",
-				"model", model,
-				"voice", voice,
-				"text", text,
-			)
-
-			// Generate TTS audio using the dedicated TTS models
-			content := []*genai.Content{
-				genai.NewContentFromText(text, genai.RoleUser),
-			}
+		// Add the say tool handler
+		s.AddTool(sayTool, WithCancellation(WithTextFallback(say.ProviderSay, WithDedupe(say.ProviderSay, WithResultFormat(WithWebhook(say.ProviderSay, WithCompletionTone(WithProgress(WithInterrupt(WithEchoText(WithVoiceAlias(say.ProviderSay, handleSayTTS)))))))))))
+	}
 
-			response, err := client.Models.GenerateContent(ctx, model, content, &genai.GenerateContentConfig{
-				ResponseModalities: []string{"AUDIO"},
-				SpeechConfig: &genai.SpeechConfig{
-					VoiceConfig: &genai.VoiceConfig{
-						PrebuiltVoiceConfig: &genai.PrebuiltVoiceConfig{
-							VoiceName: voice,
-						},
-					},
+	elevenLabsTool := mcp.NewTool("elevenlabs_tts",
+		mcp.WithDescription("Uses the ElevenLabs API to generate speech from text"),
+		mcp.WithString("text",
+			mcp.Description("The text to be spoken"),
+		),
+		mcp.WithString("text_file",
+			mcp.Description("Path to a UTF-8 text file to speak instead of text"),
+		),
+		mcp.WithString("voice",
+			mcp.Description(fmt.Sprintf("ElevenLabs voice ID (default: %s)", activeDefaultVoice(say.ProviderElevenLabs))),
+		),
+		mcp.WithString("model",
+			mcp.Description("ElevenLabs model ID, e.g. eleven_multilingual_v2, eleven_turbo_v2_5 (default: eleven_multilingual_v2)"),
+		),
+		mcp.WithObject("pronunciation_dictionary",
+			mcp.Description("ElevenLabs pronunciation dictionary to apply, as {id, version}. Omitting version uses the latest"),
+			mcp.Properties(map[string]any{
+				"id":      map[string]any{"type": "string", "description": "Pronunciation dictionary ID"},
+				"version": map[string]any{"type": "string", "description": "Dictionary version ID (default: latest)"},
+			}),
+		),
+		mcp.WithArray("speakers",
+			mcp.Description("Multi-speaker dialogue: array of {name, voice} objects with ElevenLabs voice IDs. When set, text must be formatted as one 'Name: line' per line and is sent to the text-to-dialogue endpoint (model default: eleven_v3)"),
+			mcp.MinItems(1),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name":  map[string]any{"type": "string", "description": "Speaker name as used in the transcript"},
+					"voice": map[string]any{"type": "string", "description": "ElevenLabs voice ID for this speaker"},
 				},
-			})
-			if err != nil {
-				log.Error("Failed to generate TTS audio", "error", err)
-				result := mcp.NewToolResultText(fmt.Sprintf("Error: Failed to generate TTS audio: %v", err))
-				result.IsError = true
-				return result, nil
-			}
-
-			// Extract audio data from response
-			if len(response.Candidates) == 0 || len(response.Candidates[0].Content.Parts) == 0 {
-				log.Error("No audio data in TTS response")
-				result := mcp.NewToolResultText("Error: No audio data received from Google TTS")
-				result.IsError = true
-				return result, nil
-			}
-
-			part := response.Candidates[0].Content.Parts[0]
-			if part.InlineData == nil {
-				log.Error("No inline data in TTS response")
-				result := mcp.NewToolResultText("Error: No audio data received from Google TTS")
-				result.IsError = true
-				return result, nil
-			}
-
-			audioData := part.InlineData.Data
-			log.Info("Playing TTS audio via beep speaker", "bytes", len(audioData))
-
-			// Create PCM stream for beep (Google TTS returns 24kHz PCM)
-			pcmStream := &PCMStream{
-				data:       audioData,
-				sampleRate: beep.SampleRate(24000), // 24kHz sample rate from Google TTS
-				position:   0,
-			}
-
-			// Initialize speaker with the sample rate
-			speaker.Init(pcmStream.sampleRate, pcmStream.sampleRate.N(time.Second/10))
-
-			// Play the audio with cancellation support
-			done := make(chan bool)
-			speaker.Play(beep.Seq(pcmStream, beep.Callback(func() {
-				done <- true
-			})))
-
-			log.Info("Speaking via Google TTS", "text", text, "voice", voice, "model", model)
-
-			// Wait for either playback completion or cancellation
-			select {
-			case <-done:
-				log.Debug("Google TTS audio playback completed normally")
-				if suppressSpeakingOutput {
-					return mcp.NewToolResultText("Speech completed"), nil
-				}
-				return mcp.NewToolResultText(fmt.Sprintf("Speaking: %s (via Google TTS with voice %s)", text, voice)), nil
-			case <-ctx.Done():
-				log.Debug("Context cancelled, stopping Google TTS audio playback")
-				speaker.Clear()
-				log.Info("Google TTS audio playback cancelled by user")
-				return mcp.NewToolResultText("Google TTS audio playback cancelled"), nil
-			}
-		}))
-
-		// Add OpenAI TTS tool
-		openaiTTSTool := mcp.NewTool("openai_tts",
-			mcp.WithDescription("Uses OpenAI's Text-to-Speech API to generate speech from text"),
+				"required": []string{"name", "voice"},
+			}),
+		),
+		mcp.WithNumber("optimize_streaming_latency",
+			mcp.Description("Trade audio quality for a faster start, 0-4 (default: 0, best quality). 1-2 suit interactive replies with little quality loss; 3-4 start fastest but also skip text normalization, so numbers and dates may be misread"),
+			mcp.Min(0),
+			mcp.Max(say.MaxStreamingLatencyOptimization),
+			wholeNumber(),
+		),
+		mcp.WithNumber("seed",
+			mcp.Description("Integer from 0 to 4294967295. Repeating a request with the same text, voice, model and seed gives largely the same audio, e.g. for stable narration with cached output. ElevenLabs does not guarantee fully identical output (default: random)"),
+			mcp.Min(0),
+			mcp.Max(math.MaxUint32),
+			wholeNumber(),
+		),
+		mcp.WithNumber("sentence_pause_ms",
+			mcp.Description("Silence to insert between sentences in milliseconds, up to 5000 (default: 0, off). Each sentence is synthesized separately when set"),
+			milliseconds(maxSentencePause),
+		),
+		mcp.WithNumber("crossfade_ms",
+			mcp.Description("Synthesize each sentence separately and overlap adjacent ones by this many milliseconds, fading one out as the next fades in, up to 1000. Ignored with sentence_pause_ms (default: 0, off)"),
+			milliseconds(maxCrossfade),
+		),
+		mcp.WithString("previous_text",
+			mcp.Description(fmt.Sprintf("Text spoken just before this one, e.g. the previous paragraph of a narration split across calls. Not spoken, it only keeps the prosody continuous. Up to %d characters. Not supported with speakers", say.MaxElevenLabsContextLength)),
+			mcp.MaxLength(say.MaxElevenLabsContextLength),
+		),
+		mcp.WithString("next_text",
+			mcp.Description(fmt.Sprintf("Text spoken just after this one, see previous_text. Up to %d characters", say.MaxElevenLabsContextLength)),
+			mcp.MaxLength(say.MaxElevenLabsContextLength),
+		),
+		mcp.WithObject("extra",
+			mcp.Description("Raw ElevenLabs request fields for API parameters this tool doesn't expose, merged into the request body, e.g. {\"apply_text_normalization\": \"off\"}. Fields the tool already sets take precedence"),
+		),
+		mcp.WithString("output_file",
+			mcp.Description("Path to write the audio to instead of playing it (MP3 or WAV depending on the provider)"),
+		),
+		mcp.WithNumber("bitrate",
+			mcp.Description(fmt.Sprintf("MP3 bitrate in kbps, one of %v (default: %d). Lower bitrates make smaller files, e.g. 64 is plenty for archived narration. 192 needs a Creator plan or higher", say.ElevenLabsBitrates, say.DefaultElevenLabsBitrate)),
+			numberEnum(say.ElevenLabsBitrates...),
+		),
+		mcp.WithBoolean("return_audio",
+			mcp.Description("Return the audio inline in the result instead of playing it (default: false)"),
+		),
+		mcp.WithString("vtt_output",
+			mcp.Description("Path to write WebVTT captions to, timed from ElevenLabs' word timestamps. The text is synthesized in one request, so sentence_pause_ms and crossfade_ms are ignored. Not supported with speakers"),
+		),
+		mcp.WithNumber("max_duration_ms",
+			mcp.Description("Stop playback after this many milliseconds, however long the audio is, e.g. 10000 for notifications (default: no limit)"),
+			mcp.Min(1),
+		),
+		mcp.WithBoolean("echo_text",
+			mcp.Description("Add the exact text sent to the provider, after text replacements, to the result as its own block, e.g. for a transcript display (default: false)"),
+		),
+		mcp.WithBoolean("interrupt",
+			mcp.Description("Stop whatever is currently playing before this speech starts (default: MCP_SAY_INTERRUPT or false)"),
+		),
+		mcp.WithBoolean("quiet",
+			mcp.Description("Return only 'ok' and the call duration (default: MCP_SAY_RESULT_VERBOSITY or normal)"),
+		),
+	)
+
+	s.AddTool(elevenLabsTool, WithCancellation(WithTextFallback(say.ProviderElevenLabs, WithDedupe(say.ProviderElevenLabs, WithCostReport(WithResultFormat(WithWebhook(say.ProviderElevenLabs, WithCompletionTone(WithProgress(WithInterrupt(WithEchoText(WithMaxDuration(WithVoiceAlias(say.ProviderElevenLabs, handleElevenLabsTTS)))))))))))))
+
+	// Add ElevenLabs sound effect tool
+	soundEffectTool := mcp.NewTool("sound_effect",
+		mcp.WithDescription("Generates a sound effect from a description with the ElevenLabs sound generation API and plays it"),
+		mcp.WithString("prompt",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("Description of the sound, e.g. 'a door creaking open in an old house' (up to %d characters)", say.MaxSoundEffectPromptLength)),
+			mcp.MinLength(1),
+			mcp.MaxLength(say.MaxSoundEffectPromptLength),
+		),
+		mcp.WithNumber("duration",
+			mcp.Description(fmt.Sprintf("Length in seconds, from %g to %g (default: chosen by ElevenLabs to fit the prompt)", say.MinSoundEffectDuration, say.MaxSoundEffectDuration)),
+			mcp.Min(say.MinSoundEffectDuration),
+			mcp.Max(say.MaxSoundEffectDuration),
+		),
+		mcp.WithString("output_file",
+			mcp.Description("Path to write the MP3 to instead of playing it"),
+		),
+		mcp.WithBoolean("return_audio",
+			mcp.Description("Return the audio inline in the result instead of playing it (default: false)"),
+		),
+		mcp.WithNumber("max_duration_ms",
+			mcp.Description("Stop playback after this many milliseconds, however long the audio is, e.g. 10000 for notifications (default: no limit)"),
+			mcp.Min(1),
+		),
+		mcp.WithBoolean("interrupt",
+			mcp.Description("Stop whatever is currently playing before the sound starts (default: MCP_SAY_INTERRUPT or false)"),
+		),
+		mcp.WithBoolean("quiet",
+			mcp.Description("Return only 'ok' and the call duration (default: MCP_SAY_RESULT_VERBOSITY or normal)"),
+		),
+	)
+
+	s.AddTool(soundEffectTool, WithCancellation(WithCostReport(WithResultFormat(WithProgress(WithInterrupt(WithMaxDuration(handleSoundEffect)))))))
+
+	// Add Google TTS tool
+	googleTTSTool := mcp.NewTool("google_tts",
+		mcp.WithDescription("Uses Google's dedicated Text-to-Speech API with Gemini TTS models"),
+		mcp.WithString("text",
+			mcp.Description("The text message to convert to speech"),
+		),
+		mcp.WithString("text_file",
+			mcp.Description("Path to a UTF-8 text file to speak instead of text"),
+		),
+		mcp.WithString("voice",
+			mcp.Description(fmt.Sprintf("Voice name: Zephyr, Puck, Charon, Kore, Fenrir, Aoede, Leda, Orus, etc. (default: %s). A locale prefix like en-US-Kore also sets the language", activeDefaultVoice(say.ProviderGoogle))),
+		),
+		mcp.WithString("model",
+			mcp.Description("TTS model: gemini-2.5-flash-preview-tts, gemini-2.5-pro-preview-tts (default: gemini-2.5-flash-preview-tts)"),
+		),
+		mcp.WithNumber("speaking_rate",
+			mcp.Description("Speaking rate from 0.25 to 4.0 (default: 1.0)"),
+			mcp.Min(say.GoogleMinSpeakingRate),
+			mcp.Max(say.GoogleMaxSpeakingRate),
+		),
+		mcp.WithNumber("pitch",
+			mcp.Description("Pitch adjustment in semitones from -20.0 to 20.0 (default: 0.0)"),
+			mcp.Min(say.GoogleMinPitch),
+			mcp.Max(say.GoogleMaxPitch),
+		),
+		mcp.WithArray("speakers",
+			mcp.Description("Multi-speaker dialogue: array of exactly two {name, voice} objects. When set, text must be formatted as one 'Name: line' per line, every name must have a voice and both speakers must speak"),
+			mcp.MinItems(say.GoogleDialogueSpeakers),
+			mcp.MaxItems(say.GoogleDialogueSpeakers),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name":  map[string]any{"type": "string", "description": "Speaker name as used in the transcript"},
+					"voice": map[string]any{"type": "string", "description": "Voice name for this speaker"},
+				},
+				"required": []string{"name", "voice"},
+			}),
+		),
+		mcp.WithNumber("temperature",
+			mcp.Description("Sampling temperature from 0.0 to 2.0. Higher values give a more varied, expressive delivery, lower values a flatter, more consistent one (default: the model's). Not with --google-grpc"),
+			mcp.Min(say.GoogleMinTemperature),
+			mcp.Max(say.GoogleMaxTemperature),
+		),
+		mcp.WithNumber("seed",
+			mcp.Description("Fixed random seed, a whole number. The same text, voice, settings and seed give (nearly) the same audio, for caching and tests (default: random). Not with --google-grpc"),
+			mcp.Min(math.MinInt32),
+			mcp.Max(math.MaxInt32),
+			wholeNumber(),
+		),
+		mcp.WithNumber("sample_rate",
+			mcp.Description("Resample the audio to this rate in Hz before playing or saving it: 8000, 16000, 22050, 24000, 44100 or 48000 (default: 24000, the model's native rate)"),
+			numberEnum(supportedSampleRates...),
+		),
+		mcp.WithObject("extra",
+			mcp.Description("Raw Gemini generateContent request fields for API parameters this tool doesn't expose, merged into the top level of the request body, e.g. safetySettings. Fields the tool already sets take precedence. Not with --google-grpc"),
+		),
+		mcp.WithString("gcs_bucket",
+			mcp.Description("Cloud Storage bucket, e.g. my-bucket or gs://my-bucket/folder, Google writes long audio to. Required for text over 5000 bytes with --google-long-audio, the WAV file is left in the bucket"),
+		),
+		mcp.WithString("output_file",
+			mcp.Description("Path to write the audio to instead of playing it (MP3 or WAV depending on the provider)"),
+		),
+		mcp.WithBoolean("return_audio",
+			mcp.Description("Return the audio inline in the result instead of playing it (default: false)"),
+		),
+		mcp.WithNumber("max_duration_ms",
+			mcp.Description("Stop playback after this many milliseconds, however long the audio is, e.g. 10000 for notifications (default: no limit)"),
+			mcp.Min(1),
+		),
+		mcp.WithBoolean("echo_text",
+			mcp.Description("Add the exact text sent to the provider, after text replacements, to the result as its own block, e.g. for a transcript display (default: false)"),
+		),
+		mcp.WithBoolean("interrupt",
+			mcp.Description("Stop whatever is currently playing before this speech starts (default: MCP_SAY_INTERRUPT or false)"),
+		),
+		mcp.WithBoolean("quiet",
+			mcp.Description("Return only 'ok' and the call duration (default: MCP_SAY_RESULT_VERBOSITY or normal)"),
+		),
+	)
+
+	s.AddTool(googleTTSTool, WithCancellation(WithTextFallback(say.ProviderGoogle, WithDedupe(say.ProviderGoogle, WithCostReport(WithResultFormat(WithWebhook(say.ProviderGoogle, WithCompletionTone(WithProgress(WithInterrupt(WithEchoText(WithMaxDuration(WithVoiceAlias(say.ProviderGoogle, handleGoogleTTS)))))))))))))
+
+	// Add OpenAI TTS tool
+	openaiTTSTool := mcp.NewTool("openai_tts",
+		mcp.WithDescription("Uses OpenAI's Text-to-Speech API to generate speech from text"),
+		mcp.WithString("text",
+			mcp.Description("The text to be spoken"),
+		),
+		mcp.WithString("text_file",
+			mcp.Description("Path to a UTF-8 text file to speak instead of text"),
+		),
+		mcp.WithString("voice",
+			mcp.Description(fmt.Sprintf("Voice to use: coral, alloy, echo, fable, onyx, nova, shimmer (default: %s)", activeDefaultVoice(say.ProviderOpenAI))),
+		),
+		mcp.WithString("model",
+			mcp.Description("TTS model: gpt-4o-mini-tts, tts-1, tts-1-hd, or an audio chat model like gpt-4o-audio-preview (default: gpt-4o-mini-tts)"),
+		),
+		mcp.WithNumber("speed",
+			mcp.Description("Speed of speech from 0.25 to 4.0 (default: 1.0)"),
+			mcp.Min(0.25),
+			mcp.Max(4.0),
+		),
+		mcp.WithString("instructions",
+			mcp.Description("Custom voice instructions (e.g., 'Speak in a cheerful and positive tone'). Can be set via OPENAI_TTS_INSTRUCTIONS env var. Ignored by tts-1 and tts-1-hd"),
+		),
+		mcp.WithBoolean("align",
+			mcp.Description("Also return approximate word timestamps as JSON by transcribing the audio with whisper-1. Makes a second billed OpenAI request (default: false)"),
+		),
+		mcp.WithBoolean("stream",
+			mcp.Description("Request raw 24kHz PCM and play it as it arrives instead of decoding MP3, for the fastest start. Only affects playback, output_file and return_audio stay MP3 (default: false)"),
+		),
+		mcp.WithNumber("sentence_pause_ms",
+			mcp.Description("Silence to insert between sentences in milliseconds, up to 5000 (default: 0, off). Each sentence is synthesized separately when set"),
+			milliseconds(maxSentencePause),
+		),
+		mcp.WithNumber("crossfade_ms",
+			mcp.Description("Synthesize each sentence separately and overlap adjacent ones by this many milliseconds, fading one out as the next fades in, up to 1000. Ignored with sentence_pause_ms (default: 0, off)"),
+			milliseconds(maxCrossfade),
+		),
+		mcp.WithObject("extra",
+			mcp.Description("Raw OpenAI speech request fields for API parameters this tool doesn't expose, merged into the request body, e.g. {\"stream_format\": \"audio\"}. Fields the tool already sets take precedence. Not with audio chat models"),
+		),
+		mcp.WithString("output_file",
+			mcp.Description("Path to write the audio to instead of playing it (MP3 or WAV depending on the provider)"),
+		),
+		mcp.WithBoolean("return_audio",
+			mcp.Description("Return the audio inline in the result instead of playing it (default: false)"),
+		),
+		mcp.WithNumber("max_duration_ms",
+			mcp.Description("Stop playback after this many milliseconds, however long the audio is, e.g. 10000 for notifications (default: no limit)"),
+			mcp.Min(1),
+		),
+		mcp.WithBoolean("echo_text",
+			mcp.Description("Add the exact text sent to the provider, after text replacements, to the result as its own block, e.g. for a transcript display (default: false)"),
+		),
+		mcp.WithBoolean("interrupt",
+			mcp.Description("Stop whatever is currently playing before this speech starts (default: MCP_SAY_INTERRUPT or false)"),
+		),
+		mcp.WithBoolean("quiet",
+			mcp.Description("Return only 'ok' and the call duration (default: MCP_SAY_RESULT_VERBOSITY or normal)"),
+		),
+	)
+
+	s.AddTool(openaiTTSTool, WithCancellation(WithTextFallback(say.ProviderOpenAI, WithDedupe(say.ProviderOpenAI, WithCostReport(WithResultFormat(WithWebhook(say.ProviderOpenAI, WithCompletionTone(WithProgress(WithInterrupt(WithEchoText(WithMaxDuration(WithVoiceAlias(say.ProviderOpenAI, handleOpenAITTS)))))))))))))
+
+	if speakPolicy != nil {
+		// Add the provider-agnostic "speak" tool
+		speakTool := mcp.NewTool("speak",
+			mcp.WithDescription(fmt.Sprintf("Speaks the provided text out loud with a provider picked by its length: %s", speakPolicy.describe())),
 			mcp.WithString("text",
-				mcp.Required(),
 				mcp.Description("The text to be spoken"),
 			),
-			mcp.WithString("voice",
-				mcp.Description("Voice to use: coral, alloy, echo, fable, onyx, nova, shimmer (default: coral)"),
+			mcp.WithString("text_file",
+				mcp.Description("Path to a UTF-8 text file to speak instead of text"),
 			),
-			mcp.WithString("model",
-				mcp.Description("TTS model: gpt-4o-mini-tts, tts-1, tts-1-hd (default: gpt-4o-mini-tts)"),
+			mcp.WithString("output_file",
+				mcp.Description("Path to write the audio to instead of playing it (MP3 or WAV depending on the provider)"),
 			),
-			mcp.WithNumber("speed",
-				mcp.Description("Speed of speech from 0.25 to 4.0 (default: 1.0)"),
+			mcp.WithBoolean("return_audio",
+				mcp.Description("Return the audio inline in the result instead of playing it (default: false)"),
 			),
-			mcp.WithString("instructions",
-				mcp.Description("Custom voice instructions (e.g., 'Speak in a cheerful and positive tone'). Can be set via OPENAI_TTS_INSTRUCTIONS env var"),
+			mcp.WithNumber("max_duration_ms",
+				mcp.Description("Stop playback after this many milliseconds, however long the audio is, e.g. 10000 for notifications (default: no limit)"),
+				mcp.Min(1),
+			),
+			mcp.WithBoolean("echo_text",
+				mcp.Description("Add the exact text sent to the provider, after text replacements, to the result as its own block, e.g. for a transcript display (default: false)"),
+			),
+			mcp.WithBoolean("interrupt",
+				mcp.Description("Stop whatever is currently playing before this speech starts (default: MCP_SAY_INTERRUPT or false)"),
+			),
+			mcp.WithBoolean("quiet",
+				mcp.Description("Return only 'ok' and the call duration (default: MCP_SAY_RESULT_VERBOSITY or normal)"),
 			),
 		)
 
-		s.AddTool(openaiTTSTool, WithCancellation(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			log.Debug("OpenAI TTS tool called", "request", request)
-			arguments := request.GetArguments()
-			text, ok := arguments["text"].(string)
-			if !ok {
-				result := mcp.NewToolResultText("Error: text must be a string")
-				result.IsError = true
-				return result, nil
-			}
-
-			if text == "" {
-				result := mcp.NewToolResultText("Error: Empty text provided")
-				result.IsError = true
-				return result, nil
-			}
-
-			// Get configuration from arguments
-			voice := "coral"
-			if v, ok := arguments["voice"].(string); ok && v != "" {
-				voice = v
-			}
-
-			model := "gpt-4o-mini-tts"
-			if m, ok := arguments["model"].(string); ok && m != "" {
-				model = m
-			}
-
-			speed := 1.0
-			if s, ok := arguments["speed"].(float64); ok {
-				if s >= 0.25 && s <= 4.0 {
-					speed = s
-				} else {
-					log.Warn("Speed out of range, using default", "provided", s, "default", 1.0)
-				}
-			}
-
-			// Get voice instructions from arguments or environment variable
-			instructions := ""
-			if inst, ok := arguments["instructions"].(string); ok && inst != "" {
-				instructions = inst
-			} else {
-				// Fallback to environment variable
-				instructions = os.Getenv("OPENAI_TTS_INSTRUCTIONS")
-			}
-
-			// Basic validation for instructions length (OpenAI has reasonable limits)
-			if len(instructions) > 1000 {
-				log.Warn("Instructions are very long, may exceed API limits", "length", len(instructions))
-			}
-
-			// Get API key from environment
-			apiKey := os.Getenv("OPENAI_API_KEY")
-			if apiKey == "" {
-				log.Error("OPENAI_API_KEY not set")
-				result := mcp.NewToolResultText("Error: OPENAI_API_KEY is not set")
-				result.IsError = true
-				return result, nil
-			}
-
-			// Create OpenAI client
-			client := openai.NewClient(option.WithAPIKey(apiKey))
-
-			logFields := []any{
-				"model", model,
-				"voice", voice,
-				"speed", speed,
-				"text", text,
-			}
-			if instructions != "" {
-				logFields = append(logFields, "instructions", instructions)
-			}
-			log.Debug("Generating OpenAI TTS audio", logFields...)
-
-			// Generate TTS audio
-			params := openai.AudioSpeechNewParams{
-				Model: openai.SpeechModel(model),
-				Input: text,
-				Voice: openai.AudioSpeechNewParamsVoice(voice),
-			}
-			if speed != 1.0 {
-				params.Speed = openai.Float(speed)
-			}
-			if instructions != "" {
-				params.Instructions = openai.String(instructions)
-			}
-
-			response, err := client.Audio.Speech.New(ctx, params)
-			if err != nil {
-				log.Error("Failed to generate OpenAI TTS audio", "error", err)
-				result := mcp.NewToolResultText(fmt.Sprintf("Error: Failed to generate TTS audio: %v", err))
-				result.IsError = true
-				return result, nil
-			}
-			defer response.Body.Close()
-
-			log.Debug("Decoding MP3 stream from OpenAI")
-			// OpenAI returns MP3 format by default
-			streamer, format, err := mp3.Decode(response.Body)
-			if err != nil {
-				log.Error("Failed to decode OpenAI TTS response", "error", err)
-				result := mcp.NewToolResultText(fmt.Sprintf("Error: Failed to decode response: %v", err))
-				result.IsError = true
-				return result, nil
-			}
-			defer streamer.Close()
-
-			log.Debug("Initializing speaker for OpenAI TTS", "sampleRate", format.SampleRate)
-			speaker.Init(format.SampleRate, format.SampleRate.N(time.Second/10))
-			done := make(chan bool)
-			speaker.Play(beep.Seq(streamer, beep.Callback(func() {
-				done <- true
-			})))
-
-			logFields = []any{"text", text, "voice", voice, "model", model, "speed", speed}
-			if instructions != "" {
-				logFields = append(logFields, "instructions", instructions)
-			}
-			log.Info("Speaking text via OpenAI TTS", logFields...)
-
-			// Wait for either playback completion or cancellation
-			select {
-			case <-done:
-				log.Debug("OpenAI TTS audio playback completed normally")
-				if suppressSpeakingOutput {
-					return mcp.NewToolResultText("Speech completed"), nil
-				}
-				return mcp.NewToolResultText(fmt.Sprintf("Speaking: %s (via OpenAI TTS with voice %s)", text, voice)), nil
-			case <-ctx.Done():
-				log.Debug("Context cancelled, stopping OpenAI TTS audio playback")
-				speaker.Clear()
-				log.Info("OpenAI TTS audio playback cancelled by user")
-				return mcp.NewToolResultText("OpenAI TTS audio playback cancelled"), nil
-			}
-		}))
-
-		log.Info("Starting MCP server", "name", "Say TTS Service", "version", Version)
-		// Start the server using stdin/stdout
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
-
-		if err := ctrlc.Default.Run(ctx, func() error {
-			if err := server.ServeStdio(s); err != nil {
-				return fmt.Errorf("failed to serve MCP: %v", err)
-			}
-			return nil
-		}); err != nil {
-			if errors.As(err, &ctrlc.ErrorCtrlC{}) {
-				log.Warn("Exiting...")
-				os.Exit(0)
-			} else {
-				return fmt.Errorf("failed while serving MCP: %v", err)
-			}
-		}
-		return nil
-	},
-}
-
-func safeLog(message string, req *http.Request) {
-	reqCopy := req.Clone(context.Background())
-	if _, exists := reqCopy.Header["Xi-Api-Key"]; exists {
-		reqCopy.Header["Xi-Api-Key"] = []string{"******"} // Mask password
+		s.AddTool(speakTool, WithCancellation(WithTextFallback("", WithDedupe("", WithCostReport(WithResultFormat(WithWebhook("", WithCompletionTone(WithProgress(WithInterrupt(WithEchoText(WithMaxDuration(handleSpeak))))))))))))
 	}
-	log.With(reqCopy).Debug(message)
+
+	// Add SSML tool
+	speakSSMLTool := mcp.NewTool("speak_ssml",
+		mcp.WithDescription(speakSSMLDescription),
+		mcp.WithString("provider",
+			mcp.Required(),
+			mcp.Description("Provider to speak with: google, openai, elevenlabs, say"),
+			mcp.Enum("google", "openai", "elevenlabs", "say"),
+		),
+		mcp.WithString("ssml",
+			mcp.Required(),
+			mcp.Description("The SSML document to speak, e.g. <speak>Hello <break time=\"500ms\"/> world</speak>"),
+			mcp.MinLength(1),
+		),
+		mcp.WithString("voice",
+			mcp.Description("Provider-specific voice name or ID (default: the provider's default voice)"),
+		),
+		mcp.WithString("output_file",
+			mcp.Description("Path to write the audio to instead of playing it (MP3 or WAV depending on the provider)"),
+		),
+		mcp.WithBoolean("return_audio",
+			mcp.Description("Return the audio inline in the result instead of playing it (default: false)"),
+		),
+		mcp.WithNumber("max_duration_ms",
+			mcp.Description("Stop playback after this many milliseconds, however long the audio is, e.g. 10000 for notifications (default: no limit)"),
+			mcp.Min(1),
+		),
+		mcp.WithBoolean("echo_text",
+			mcp.Description("Add the exact text sent to the provider, after text replacements, to the result as its own block, e.g. for a transcript display (default: false)"),
+		),
+		mcp.WithBoolean("interrupt",
+			mcp.Description("Stop whatever is currently playing before this speech starts (default: MCP_SAY_INTERRUPT or false)"),
+		),
+		mcp.WithBoolean("quiet",
+			mcp.Description("Return only 'ok' and the call duration (default: MCP_SAY_RESULT_VERBOSITY or normal)"),
+		),
+	)
+
+	s.AddTool(speakSSMLTool, WithCancellation(WithTextFallback("", WithDedupe("", WithCostReport(WithResultFormat(WithWebhook("", WithCompletionTone(WithProgress(WithInterrupt(WithEchoText(WithMaxDuration(WithVoiceAlias("", handleSpeakSSML)))))))))))))
+
+	// Add sequence tool
+	speakSequenceTool := mcp.NewTool("speak_sequence",
+		mcp.WithDescription("Speaks a list of segments back-to-back as one continuous clip, each with its own provider and voice. Useful for skits and announcements"),
+		mcp.WithArray("segments",
+			mcp.Required(),
+			mcp.Description("Up to 20 segments to speak in order"),
+			mcp.MinItems(1),
+			mcp.MaxItems(maxSequenceSegments),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"text":     map[string]any{"type": "string", "description": "The text to be spoken"},
+					"provider": map[string]any{"type": "string", "enum": []string{"google", "openai", "elevenlabs", "say"}, "description": "Provider to speak this segment with"},
+					"voice":    map[string]any{"type": "string", "description": "Provider-specific voice name or ID (default: the provider's default voice)"},
+				},
+				"required": []string{"text", "provider"},
+			}),
+		),
+		mcp.WithNumber("gap_ms",
+			mcp.Description("Silence between segments in milliseconds, up to 5000 (default: 0, gapless)"),
+			milliseconds(maxSequenceGap),
+		),
+		mcp.WithNumber("crossfade_ms",
+			mcp.Description("Overlap adjacent segments by this many milliseconds, fading one out as the next fades in, up to 1000. Smooths level jumps between voices, ignored with gap_ms (default: 0, hard cut)"),
+			milliseconds(maxCrossfade),
+		),
+		mcp.WithString("output_file",
+			mcp.Description("Path to write the audio to instead of playing it (MP3 or WAV depending on the provider)"),
+		),
+		mcp.WithBoolean("return_audio",
+			mcp.Description("Return the audio inline in the result instead of playing it (default: false)"),
+		),
+		mcp.WithNumber("max_duration_ms",
+			mcp.Description("Stop playback after this many milliseconds, however long the audio is, e.g. 10000 for notifications (default: no limit)"),
+			mcp.Min(1),
+		),
+		mcp.WithBoolean("echo_text",
+			mcp.Description("Add the exact text sent to the provider, after text replacements, to the result as its own block, e.g. for a transcript display (default: false)"),
+		),
+		mcp.WithBoolean("interrupt",
+			mcp.Description("Stop whatever is currently playing before this speech starts (default: MCP_SAY_INTERRUPT or false)"),
+		),
+		mcp.WithBoolean("quiet",
+			mcp.Description("Return only 'ok' and the call duration (default: MCP_SAY_RESULT_VERBOSITY or normal)"),
+		),
+	)
+
+	s.AddTool(speakSequenceTool, WithCancellation(WithCostReport(WithResultFormat(WithWebhook("", WithCompletionTone(WithProgress(WithInterrupt(WithEchoText(WithMaxDuration(handleSpeakSequence))))))))))
+
+	sayStreamAppendTool := mcp.NewTool("say_stream_append",
+		mcp.WithDescription("Appends a text fragment, e.g. a few tokens of an LLM's output, to a stream and returns right away. Each sentence the fragments complete is synthesized and played in order in the background, the next one while the current one plays. Call say_stream_flush to speak the rest"),
+		mcp.WithString("stream_id",
+			mcp.Required(),
+			mcp.Description("ID of the stream, any string. The first fragment with a new ID opens the stream"),
+		),
+		mcp.WithString("text",
+			mcp.Required(),
+			mcp.Description("Fragment to append as is, including its leading and trailing whitespace"),
+		),
+		mcp.WithString("provider",
+			mcp.Description("Provider to speak the stream with: google, openai, elevenlabs, say. Required for the first fragment, later ones keep it"),
+			mcp.Enum("google", "openai", "elevenlabs", "say"),
+		),
+		mcp.WithString("voice",
+			mcp.Description("Provider-specific voice, read from the first fragment (default: the provider's default voice)"),
+		),
+		mcp.WithString("model",
+			mcp.Description("Provider-specific model, read from the first fragment (default: the provider's default model)"),
+		),
+	)
+
+	s.AddTool(sayStreamAppendTool, handleSayStreamAppend)
+
+	sayStreamFlushTool := mcp.NewTool("say_stream_flush",
+		mcp.WithDescription("Speaks the text left in a say_stream_append stream, waits until the whole stream has played and closes it"),
+		mcp.WithString("stream_id",
+			mcp.Required(),
+			mcp.Description("ID of the stream to flush"),
+		),
+		mcp.WithBoolean("discard",
+			mcp.Description("Drop the rest of the stream and stop its playback instead (default: false)"),
+		),
+	)
+
+	s.AddTool(sayStreamFlushTool, WithCancellation(handleSayStreamFlush))
+
+	// Add voice preview tool
+	previewVoiceTool := mcp.NewTool("preview_voice",
+		mcp.WithDescription("Plays the sentence \""+previewText+"\" with one or more voices to compare them. With several voices each preview is announced by name with the native say command (macOS) and separated by a short pause"),
+		mcp.WithString("provider",
+			mcp.Required(),
+			mcp.Description("Provider of the voices: google, openai, elevenlabs, say. A voice alias switches to its own provider"),
+			mcp.Enum("google", "openai", "elevenlabs", "say"),
+		),
+		mcp.WithString("voice",
+			mcp.Description("Provider-specific voice name or ID to preview (default: the provider's default voice)"),
+		),
+		mcp.WithArray("voices",
+			mcp.Description("Up to 10 voices to preview in order, after voice if both are given"),
+			mcp.MaxItems(maxPreviewVoices),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithBoolean("announce",
+			mcp.Description("Say each voice's name before its preview (default: true for several voices)"),
+		),
+		mcp.WithBoolean("interrupt",
+			mcp.Description("Stop whatever is currently playing before this speech starts (default: MCP_SAY_INTERRUPT or false)"),
+		),
+		mcp.WithBoolean("quiet",
+			mcp.Description("Return only 'ok' and the call duration (default: MCP_SAY_RESULT_VERBOSITY or normal)"),
+		),
+	)
+
+	s.AddTool(previewVoiceTool, WithCancellation(WithCostReport(WithResultFormat(WithProgress(WithInterrupt(handlePreviewVoice))))))
+
+	// Add batch tool
+	batchSynthesizeTool := mcp.NewTool("batch_synthesize",
+		mcp.WithDescription("Synthesizes many texts to audio files in output_dir without playing them, e.g. to pre-generate game dialogue. Each item is written to {id}.mp3 ({id}.wav for google and say)"),
+		mcp.WithString("provider",
+			mcp.Required(),
+			mcp.Description("Provider to synthesize with: google, openai, elevenlabs, say"),
+			mcp.Enum("google", "openai", "elevenlabs", "say"),
+		),
+		mcp.WithArray("items",
+			mcp.Required(),
+			mcp.Description("Up to 500 items to synthesize"),
+			mcp.MinItems(1),
+			mcp.MaxItems(maxBatchItems),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"id":    map[string]any{"type": "string", "description": "Unique id used as the file name: letters, digits, '.', '_' or '-'"},
+					"text":  map[string]any{"type": "string", "description": "The text to synthesize"},
+					"voice": map[string]any{"type": "string", "description": "Provider-specific voice name or ID (default: the provider's default voice)"},
+				},
+				"required": []string{"id", "text"},
+			}),
+		),
+		mcp.WithString("output_dir",
+			mcp.Required(),
+			mcp.Description("Existing directory to write the audio files to, existing files are overwritten"),
+		),
+		mcp.WithString("manifest_file",
+			mcp.Description("Path to write a JSON manifest to once the batch completes, listing each item's id, text, provider, voice, file, duration_ms and bytes, or its error"),
+		),
+		mcp.WithNumber("concurrency",
+			mcp.Description("Items synthesized at once, from 1 to 8 (default: 4). Provider rate limits still apply"),
+			mcp.Min(1),
+			mcp.Max(maxBatchConcurrency),
+			wholeNumber(),
+		),
+	)
+
+	s.AddTool(batchSynthesizeTool, WithCancellation(WithCostReport(handleBatchSynthesize)))
+
+	// Add play file tool
+	playFileTool := mcp.NewTool("play_file",
+		mcp.WithDescription("Plays a local audio file (MP3, WAV or FLAC, detected by extension) through the speaker, e.g. a chime or pre-recorded clip"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Path to the .mp3, .wav or .flac file to play"),
+			mcp.MinLength(1),
+		),
+		mcp.WithNumber("max_duration_ms",
+			mcp.Description("Stop playback after this many milliseconds, however long the audio is, e.g. 10000 for notifications (default: no limit)"),
+			mcp.Min(1),
+		),
+		mcp.WithBoolean("interrupt",
+			mcp.Description("Stop whatever is currently playing before this file starts (default: MCP_SAY_INTERRUPT or false)"),
+		),
+		mcp.WithBoolean("quiet",
+			mcp.Description("Return only 'ok' and the call duration (default: MCP_SAY_RESULT_VERBOSITY or normal)"),
+		),
+	)
+
+	s.AddTool(playFileTool, WithCancellation(WithResultFormat(WithProgress(WithInterrupt(WithMaxDuration(handlePlayFile))))))
+
+	// Add inline audio playback tool
+	playAudioTool := mcp.NewTool("play_audio",
+		mcp.WithDescription("Plays audio passed inline as base64 (MP3, WAV or FLAC) through the speaker, for audio another tool already produced"),
+		mcp.WithString("data",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("A base64 data URI like data:audio/mpeg;base64,... or raw base64, up to %d MB decoded", say.MaxAudioFileSize>>20)),
+			mcp.MinLength(1),
+		),
+		mcp.WithString("mime_type",
+			mcp.Description("Media type of raw base64 data: audio/mpeg, audio/wav or audio/flac (default: detected from the audio)"),
+		),
+		mcp.WithNumber("max_duration_ms",
+			mcp.Description("Stop playback after this many milliseconds, however long the audio is, e.g. 10000 for notifications (default: no limit)"),
+			mcp.Min(1),
+		),
+		mcp.WithBoolean("interrupt",
+			mcp.Description("Stop whatever is currently playing before this audio starts (default: MCP_SAY_INTERRUPT or false)"),
+		),
+		mcp.WithBoolean("quiet",
+			mcp.Description("Return only 'ok' and the call duration (default: MCP_SAY_RESULT_VERBOSITY or normal)"),
+		),
+	)
+
+	s.AddTool(playAudioTool, WithCancellation(WithResultFormat(WithProgress(WithInterrupt(WithMaxDuration(handlePlayAudio))))))
+
+	// Add tone tool
+	toneTool := mcp.NewTool("tone",
+		mcp.WithDescription("Plays a generated tone or beep through the speaker, for custom alerts without a TTS provider"),
+		mcp.WithNumber("frequency",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("Pitch in Hz, %g-%g (e.g. 440 for A4, 880 for a bright beep)", say.MinToneFrequency, say.MaxToneFrequency)),
+			mcp.Min(say.MinToneFrequency),
+			mcp.Max(say.MaxToneFrequency),
+		),
+		mcp.WithNumber("duration_ms",
+			mcp.Description(fmt.Sprintf("Length in milliseconds, %d-%d (default: %d)", say.MinToneDuration.Milliseconds(), say.MaxToneDuration.Milliseconds(), defaultToneDuration.Milliseconds())),
+			mcp.Min(float64(say.MinToneDuration.Milliseconds())),
+			mcp.Max(float64(say.MaxToneDuration.Milliseconds())),
+		),
+		mcp.WithNumber("volume",
+			mcp.Description(fmt.Sprintf("Volume from 0 to 1 (default: %g)", defaultToneVolume)),
+			mcp.Min(0),
+			mcp.Max(1),
+		),
+		mcp.WithString("waveform",
+			mcp.Description("Shape of the wave: sine is soft, square and saw are buzzier (default: sine)"),
+			mcp.Enum(string(say.WaveformSine), string(say.WaveformSquare), string(say.WaveformSaw)),
+		),
+		mcp.WithBoolean("interrupt",
+			mcp.Description("Stop whatever is currently playing before this tone starts (default: MCP_SAY_INTERRUPT or false)"),
+		),
+		mcp.WithBoolean("quiet",
+			mcp.Description("Return only 'ok' and the call duration (default: MCP_SAY_RESULT_VERBOSITY or normal)"),
+		),
+	)
+
+	s.AddTool(toneTool, WithCancellation(WithResultFormat(WithProgress(WithInterrupt(handleTone)))))
+
+	// Add history tools
+	historyTool := mcp.NewTool("history",
+		mcp.WithDescription("Lists this session's recent utterances, newest first: id, time, provider, voice, model, the text as sent to the provider and the audio duration"),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of utterances to list (default: %d)", defaultHistoryLimit)),
+			mcp.Min(1),
+			wholeNumber(),
+		),
+	)
+
+	s.AddTool(historyTool, handleHistory)
+
+	replayTool := mcp.NewTool("replay",
+		mcp.WithDescription("Plays a past utterance again by its history id, reusing the kept audio instead of synthesizing it again"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Utterance id from the history tool"),
+			mcp.MinLength(1),
+		),
+		mcp.WithString("output_file",
+			mcp.Description("Path to write the audio to instead of playing it"),
+		),
+		mcp.WithBoolean("return_audio",
+			mcp.Description("Return the audio inline in the result instead of playing it (default: false)"),
+		),
+		mcp.WithNumber("max_duration_ms",
+			mcp.Description("Stop playback after this many milliseconds, however long the audio is, e.g. 10000 for notifications (default: no limit)"),
+			mcp.Min(1),
+		),
+		mcp.WithBoolean("interrupt",
+			mcp.Description("Stop whatever is currently playing before the replay starts (default: MCP_SAY_INTERRUPT or false)"),
+		),
+		mcp.WithBoolean("quiet",
+			mcp.Description("Return only 'ok' and the call duration (default: MCP_SAY_RESULT_VERBOSITY or normal)"),
+		),
+	)
+
+	s.AddTool(replayTool, WithCancellation(WithResultFormat(WithProgress(WithInterrupt(WithMaxDuration(handleReplay))))))
+
+	// Add cost stats tool
+	costStatsTool := mcp.NewTool("cost_stats",
+		mcp.WithDescription("Reports the approximate cost of this session's TTS requests per provider, estimated from character counts"),
+		mcp.WithBoolean("reset",
+			mcp.Description("Reset the totals after reporting them (default: false)"),
+		),
+	)
+
+	s.AddTool(costStatsTool, handleCostStats)
+
+	// Add ElevenLabs voices tools
+	listVoicesTool := mcp.NewTool("list_voices",
+		mcp.WithDescription("Lists the ElevenLabs voices available to the account with their IDs. The list is cached for MCP_SAY_VOICES_TTL (default: 10m)"),
+	)
+
+	s.AddTool(listVoicesTool, handleListVoices)
+
+	refreshVoicesTool := mcp.NewTool("refresh_voices",
+		mcp.WithDescription("Fetches the ElevenLabs voices list again, e.g. after adding a voice. If the fetch fails the cached list is returned with a warning"),
+	)
+
+	s.AddTool(refreshVoicesTool, handleRefreshVoices)
+
+	describeVoiceTool := mcp.NewTool("describe_voice",
+		mcp.WithDescription("Returns a voice's metadata as JSON to help pick a voice that fits the context: gender, age, accent and use case from the account's ElevenLabs voices list (cached with list_voices), or gender, locale and style from built-in tables for OpenAI and Gemini voices"),
+		mcp.WithString("provider",
+			mcp.Required(),
+			mcp.Description("Provider of the voice: google, openai, elevenlabs. A voice alias switches to its own provider"),
+			mcp.Enum("google", "openai", "elevenlabs"),
+		),
+		mcp.WithString("voice",
+			mcp.Description("Voice to describe: an ElevenLabs voice ID or name, an OpenAI voice, or a Gemini voice optionally with a locale prefix like en-US-Kore (default: the provider's default voice)"),
+		),
+	)
+
+	s.AddTool(describeVoiceTool, server.ToolHandlerFunc(WithVoiceAlias("", handleDescribeVoice)))
+
+	// Add debug request tool
+	debugRequestTool := mcp.NewTool("debug_request",
+		mcp.WithDescription("Shows the exact provider request a TTS tool call would make (URL, headers with credentials redacted, and JSON body) without sending it. Use it to compare a rejected request with the provider's API docs"),
+		mcp.WithString("tool",
+			mcp.Required(),
+			mcp.Description("Tool to build the request for: openai_tts, google_tts, elevenlabs_tts or sound_effect"),
+			mcp.Enum(slices.Sorted(maps.Keys(debugTools))...),
+		),
+		mcp.WithObject("arguments",
+			mcp.Description("The arguments the tool would be called with, e.g. {\"text\": \"Hello\", \"voice\": \"nova\"}"),
+		),
+	)
+
+	s.AddTool(debugRequestTool, handleDebugRequest)
+
+	// Add status tool
+	statusTool := mcp.NewTool("status",
+		mcp.WithDescription("Reports which TTS providers are usable: whether API keys are set and native backends exist on this OS. Makes no network calls"),
+	)
+
+	s.AddTool(statusTool, handleStatus)
+
+	// Add playback status tool
+	playbackStatusTool := mcp.NewTool("playback_status",
+		mcp.WithDescription("Reports what is playing right now as JSON: for each playback the tool, provider and voice, position and total duration in milliseconds where known, plus how many calls are queued waiting to play. Use it to decide whether to interrupt or wait"),
+	)
+
+	s.AddTool(playbackStatusTool, handlePlaybackStatus)
+
+	// Add capabilities tool
+	capabilitiesTool := mcp.NewTool("capabilities",
+		mcp.WithDescription("Lists which features each TTS provider supports (speed, ssml, timestamps, streaming, instructions, multi_speaker) as JSON, with the tool argument that enables each. Use it to pick a provider for a task, e.g. timestamps need elevenlabs or openai"),
+	)
+
+	s.AddTool(capabilitiesTool, handleCapabilities)
+
+	// Add warmup tool
+	warmupTool := mcp.NewTool("warmup",
+		mcp.WithDescription("Opens the audio device and connects to every configured provider with a free request, also filling the ElevenLabs voices cache, so the first speech of a session starts sooner. Call it once at session start. Reports each provider's status, a failed provider does not fail the call"),
+	)
+
+	s.AddTool(warmupTool, WithCancellation(handleWarmup))
+
+	benchmarkProvidersTool := mcp.NewTool("benchmark_providers",
+		mcp.WithDescription("Synthesizes a short fixed phrase with every configured provider at once, without playing it, and reports each one's time to first byte and total synthesis time, fastest first. Use it to pick the fastest provider for this network. Each run is a small billed request, a failed provider does not fail the call"),
+	)
+
+	s.AddTool(benchmarkProvidersTool, WithCancellation(WithCostReport(handleBenchmarkProviders)))
+
+	// Add mute and unmute tools
+	muteTool := mcp.NewTool("mute",
+		mcp.WithDescription("Silences all playback until unmute, stopping whatever is playing. TTS tools keep synthesizing, saving output_file and returning audio, but skip the speaker"),
+	)
+
+	s.AddTool(muteTool, handleMute)
+
+	unmuteTool := mcp.NewTool("unmute",
+		mcp.WithDescription("Resumes playback after mute"),
+	)
+
+	s.AddTool(unmuteTool, handleUnmute)
+
+	// Add set_volume tool
+	setVolumeTool := mcp.NewTool("set_volume",
+		mcp.WithDescription("Sets the master volume for all playback for the rest of the session, including speech that is playing now. Returns the new level"),
+		mcp.WithNumber("level",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("Linear volume from 0 (silent) to %g (full, the default)", say.MaxMasterVolume)),
+			mcp.Min(0),
+			mcp.Max(say.MaxMasterVolume),
+		),
+	)
+
+	s.AddTool(setVolumeTool, handleSetVolume)
+
+	return s
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
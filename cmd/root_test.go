@@ -3,11 +3,12 @@ package cmd
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/gopxl/beep/v2"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -15,20 +16,50 @@ import (
 
 // MockAudioPlayer simulates audio playback for testing
 type MockAudioPlayer struct {
-	PlayedAudio []byte
-	Duration    time.Duration
-	Played      bool
+	PlayedAudio   []byte
+	PlayedSamples int
+	Duration      time.Duration
+	Played        bool
 }
 
-func (m *MockAudioPlayer) Play(audioData []byte) error {
-	m.PlayedAudio = audioData
+var _ say.AudioPlayer = (*MockAudioPlayer)(nil)
+
+func (m *MockAudioPlayer) Play(ctx context.Context, audio *say.Audio) error {
+	m.PlayedAudio = audio.Data
 	m.Played = true
 	// Simulate audio playback duration
-	time.Sleep(m.Duration)
-	return nil
+	select {
+	case <-time.After(m.Duration):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *MockAudioPlayer) PlayStream(ctx context.Context, streamer beep.Streamer, format beep.Format) error {
+	m.Played = true
+	// Drain the stream like a real sink would
+	samples := make([][2]float64, 512)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, ok := streamer.Stream(samples)
+		m.PlayedSamples += n
+		if !ok {
+			return nil
+		}
+	}
+}
+
+// pcmAudio wraps generated test audio as Google TTS output
+func pcmAudio(data []byte) *say.Audio {
+	return &say.Audio{Data: data, Encoding: say.EncodingPCM, SampleRate: say.GoogleSampleRate}
 }
 
 func TestGoogleTTSTool(t *testing.T) {
+	useMockPlayer(t)
+	useTestMode(t)
 	// Set up test environment variables
 	originalAPIKey := os.Getenv("GOOGLE_AI_API_KEY")
 	defer func() {
@@ -56,7 +87,7 @@ func TestGoogleTTSTool(t *testing.T) {
 				"text": "Hello, this is a test of Google TTS",
 			},
 			expectedError: false,
-			shouldContain: []string{"Google TTS", "gemini-2.5-flash-preview-tts", "voice Kore"},
+			shouldContain: []string{"Google TTS", "voice Kore"},
 		},
 		{
 			name: "successful TTS request with custom voice and model",
@@ -69,7 +100,7 @@ func TestGoogleTTSTool(t *testing.T) {
 				"model": "gemini-2.5-pro-preview-tts",
 			},
 			expectedError: false,
-			shouldContain: []string{"Google TTS", "voice Puck", "gemini-2.5-pro-preview-tts"},
+			shouldContain: []string{"Google TTS", "voice Puck"},
 		},
 		{
 			name: "missing API key",
@@ -114,7 +145,7 @@ func TestGoogleTTSTool(t *testing.T) {
 				"text": "Test with defaults",
 			},
 			expectedError: false,
-			shouldContain: []string{"Google TTS", "voice Kore", "gemini-2.5-flash-preview-tts"},
+			shouldContain: []string{"Google TTS", "voice Kore"},
 		},
 	}
 
@@ -138,58 +169,9 @@ func TestGoogleTTSTool(t *testing.T) {
 			err = json.Unmarshal(jsonData, &request)
 			require.NoError(t, err)
 
-			// For testing purposes, we'll directly invoke the tool handler
+			// Test mode stubs the provider request, everything else is the real handler
 			ctx := context.Background()
-
-			// Create a handler variable that we can test directly
-			var testHandler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)
-
-			// Re-create the tool handler logic for testing
-			testHandler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-				arguments := request.GetArguments()
-
-				// Validate text parameter
-				text, ok := arguments["text"].(string)
-				if !ok {
-					result := mcp.NewToolResultText("Error: text must be a string")
-					result.IsError = true
-					return result, nil
-				}
-
-				if text == "" {
-					result := mcp.NewToolResultText("Error: Empty text provided")
-					result.IsError = true
-					return result, nil
-				}
-
-				// Check API key
-				apiKey := os.Getenv("GOOGLE_AI_API_KEY")
-				if apiKey == "" {
-					apiKey = os.Getenv("GEMINI_API_KEY")
-				}
-				if apiKey == "" {
-					result := mcp.NewToolResultText("Error: GOOGLE_AI_API_KEY or GEMINI_API_KEY is not set")
-					result.IsError = true
-					return result, nil
-				}
-
-				// Get configuration from arguments
-				voice := "Kore"
-				if v, ok := arguments["voice"].(string); ok && v != "" {
-					voice = v
-				}
-
-				model := "gemini-2.5-flash-preview-tts"
-				if m, ok := arguments["model"].(string); ok && m != "" {
-					model = m
-				}
-
-				// For testing, we'll simulate a successful TTS generation without actually calling the API
-				return mcp.NewToolResultText(
-					"Speaking: " + text + " (via Google TTS with voice " + voice + " using model " + model + ")"), nil
-			}
-
-			result, err := testHandler(ctx, request)
+			result, err := handleGoogleTTS(ctx, request)
 
 			if tt.expectedError {
 				require.NotNil(t, result)
@@ -236,7 +218,7 @@ func TestGoogleTTSAudioPlayback(t *testing.T) {
 	audioData := generateTestAudio(sampleRate, duration, frequency)
 
 	// Test playing the audio
-	err := mockPlayer.Play(audioData)
+	err := mockPlayer.Play(context.Background(), pcmAudio(audioData))
 	assert.NoError(t, err)
 	assert.True(t, mockPlayer.Played)
 	assert.Equal(t, audioData, mockPlayer.PlayedAudio)
@@ -359,7 +341,7 @@ func TestGoogleTTSAudioIntegration(t *testing.T) {
 
 		// Simulate playback
 		start := time.Now()
-		err := mockPlayer.Play(audioData)
+		err := mockPlayer.Play(context.Background(), pcmAudio(audioData))
 		duration := time.Since(start)
 
 		assert.NoError(t, err)
@@ -384,7 +366,7 @@ func TestGoogleTTSAudioIntegration(t *testing.T) {
 				frequency := 300.0 + float64(i*40) // Start at 300Hz, increment by 40Hz
 				audioData := generateTestAudio(24000, 0.4, frequency)
 
-				err := mockPlayer.Play(audioData)
+				err := mockPlayer.Play(context.Background(), pcmAudio(audioData))
 				assert.NoError(t, err)
 				assert.True(t, mockPlayer.Played)
 				t.Logf("   ✅ Google TTS Voice %s tested successfully (%.0fHz)", voice, frequency)
@@ -415,7 +397,7 @@ func TestGoogleTTSAudioIntegration(t *testing.T) {
 				mockPlayer.Played = false
 				mockPlayer.PlayedAudio = nil
 
-				err := mockPlayer.Play(audioData)
+				err := mockPlayer.Play(context.Background(), pcmAudio(audioData))
 				assert.NoError(t, err)
 				assert.True(t, mockPlayer.Played)
 
@@ -433,11 +415,7 @@ func TestGoogleTTSAudioIntegration(t *testing.T) {
 		t.Log("🎼 Testing PCM Stream functionality...")
 
 		audioData := generateTestAudio(24000, 0.5, 440.0)
-		pcmStream := &PCMStream{
-			data:       audioData,
-			sampleRate: 24000,
-			position:   0,
-		}
+		pcmStream := say.NewPCMStream(audioData, 24000)
 
 		// Test stream properties
 		assert.Equal(t, len(audioData)/2, pcmStream.Len())
@@ -494,6 +472,8 @@ func BenchmarkGoogleTTSTool(t *testing.B) {
 }
 
 func TestOpenAITTSTool(t *testing.T) {
+	useMockPlayer(t)
+	useTestMode(t)
 	// Set up test environment variables
 	originalAPIKey := os.Getenv("OPENAI_API_KEY")
 	originalInstructions := os.Getenv("OPENAI_TTS_INSTRUCTIONS")
@@ -658,74 +638,9 @@ func TestOpenAITTSTool(t *testing.T) {
 			err = json.Unmarshal(jsonData, &request)
 			require.NoError(t, err)
 
-			// For testing purposes, we'll directly invoke the tool handler
+			// Test mode stubs the provider request, everything else is the real handler
 			ctx := context.Background()
-
-			// Create a handler variable that we can test directly
-			var testHandler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)
-
-			// Re-create the tool handler logic for testing
-			testHandler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-				arguments := request.GetArguments()
-
-				// Validate text parameter
-				text, ok := arguments["text"].(string)
-				if !ok {
-					result := mcp.NewToolResultText("Error: text must be a string")
-					result.IsError = true
-					return result, nil
-				}
-
-				if text == "" {
-					result := mcp.NewToolResultText("Error: Empty text provided")
-					result.IsError = true
-					return result, nil
-				}
-
-				// Check API key
-				apiKey := os.Getenv("OPENAI_API_KEY")
-				if apiKey == "" {
-					result := mcp.NewToolResultText("Error: OPENAI_API_KEY is not set")
-					result.IsError = true
-					return result, nil
-				}
-
-				// Get configuration from arguments
-				voice := "coral"
-				if v, ok := arguments["voice"].(string); ok && v != "" {
-					voice = v
-				}
-
-				model := "gpt-4o-mini-tts"
-				if m, ok := arguments["model"].(string); ok && m != "" {
-					model = m
-				}
-
-				speed := 1.0
-				if s, ok := arguments["speed"].(float64); ok {
-					if s >= 0.25 && s <= 4.0 {
-						speed = s
-					}
-				}
-
-				// Get voice instructions from arguments or environment variable
-				instructions := ""
-				if inst, ok := arguments["instructions"].(string); ok && inst != "" {
-					instructions = inst
-				} else {
-					// Fallback to environment variable
-					instructions = os.Getenv("OPENAI_TTS_INSTRUCTIONS")
-				}
-
-				// For testing, we'll simulate a successful TTS generation without actually calling the API
-				resultText := fmt.Sprintf("Speaking: %s (via OpenAI TTS with voice %s, model %s, speed %.1f)", text, voice, model, speed)
-				if instructions != "" {
-					resultText += fmt.Sprintf(" with instructions: %s", instructions)
-				}
-				return mcp.NewToolResultText(resultText), nil
-			}
-
-			result, err := testHandler(ctx, request)
+			result, err := handleOpenAITTS(ctx, request)
 
 			if tt.expectedError {
 				require.NotNil(t, result)
@@ -852,7 +767,7 @@ func TestOpenAITTSAudioIntegration(t *testing.T) {
 
 		// Simulate playback
 		start := time.Now()
-		err := mockPlayer.Play(audioData)
+		err := mockPlayer.Play(context.Background(), pcmAudio(audioData))
 		duration := time.Since(start)
 
 		assert.NoError(t, err)
@@ -877,7 +792,7 @@ func TestOpenAITTSAudioIntegration(t *testing.T) {
 				frequency := 350.0 + float64(i*50) // Start at 350Hz, increment by 50Hz
 				audioData := generateTestAudio(22050, 0.4, frequency)
 
-				err := mockPlayer.Play(audioData)
+				err := mockPlayer.Play(context.Background(), pcmAudio(audioData))
 				assert.NoError(t, err)
 				assert.True(t, mockPlayer.Played)
 				t.Logf("   ✅ OpenAI TTS Voice %s tested successfully (%.0fHz)", voice, frequency)
@@ -908,7 +823,7 @@ func TestOpenAITTSAudioIntegration(t *testing.T) {
 				mockPlayer.Played = false
 				mockPlayer.PlayedAudio = nil
 
-				err := mockPlayer.Play(audioData)
+				err := mockPlayer.Play(context.Background(), pcmAudio(audioData))
 				assert.NoError(t, err)
 				assert.True(t, mockPlayer.Played)
 
@@ -942,7 +857,7 @@ func TestOpenAITTSAudioIntegration(t *testing.T) {
 
 				audioData := generateTestAudio(22050, 0.5, 440.0)
 
-				err := mockPlayer.Play(audioData)
+				err := mockPlayer.Play(context.Background(), pcmAudio(audioData))
 				assert.NoError(t, err)
 				assert.True(t, mockPlayer.Played)
 
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+	"unicode/utf8"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// sayProgressiveLength is the text length, in characters, from which say_tts speaks sentence by sentence
+const sayProgressiveLength = 400
+
+// handleSayTTS speaks text with the macOS say command
+func handleSayTTS(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Say tool called", "request", request)
+	arguments := request.GetArguments()
+	text, err := textArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	out, err := outputArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	params := say.SaySpeechParams{Text: applyReplacements(text)}
+
+	// Add rate if provided
+	if raw, ok := arguments["rate"]; ok && raw != nil {
+		rate, ok := raw.(float64)
+		if !ok || rate != float64(int(rate)) {
+			result := mcp.NewToolResultText("Error: rate must be a whole number of words per minute")
+			result.IsError = true
+			return result, nil
+		}
+		if err := say.ValidateSayRate(int(rate)); err != nil {
+			result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+			result.IsError = true
+			return result, nil
+		}
+		params.Rate = int(rate)
+	}
+
+	// Add voice if provided and validate it
+	if voice := providerSetting(arguments, say.ProviderSay, "voice", ""); voice != "" {
+		if !say.ValidSayVoice(voice) {
+			result := mcp.NewToolResultText(fmt.Sprintf("Error: Voice contains invalid characters: %s", voice))
+			result.IsError = true
+			return result, nil
+		}
+		params.Voice = voice
+	}
+
+	// Text is passed as a separate argument, not through shell, which provides some safety
+	// but we'll still do basic validation
+	if text == "" {
+		result := mcp.NewToolResultText("Error: Empty text provided")
+		result.IsError = true
+		return result, nil
+	}
+
+	// Check for potentially dangerous shell metacharacters
+	// Note: exec.Command with separate arguments is already safe from command injection,
+	// but we're adding this check as an additional safeguard
+	dangerousChars := []rune{';', '&', '|', '<', '>', '`', '$', '(', ')', '{', '}', '[', ']', '\\', '\'', '"', '\n', '\r'}
+	for _, char := range dangerousChars {
+		if bytes.ContainsRune([]byte(text), char) {
+			log.Warn("Potentially dangerous character in text input",
+				"char", string(char),
+				"text", text)
+		}
+	}
+
+	recordSpokenText(ctx, params.Text)
+	if out.enabled() {
+		data, err := say.SynthesizeSay(ctx, params)
+		if err != nil {
+			log.Error("Say synthesis failed", "error", err)
+			result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+			result.IsError = true
+			return result, nil
+		}
+		return deliverAudio(ctx, out, &say.Audio{Data: data, Encoding: say.EncodingWAV}, synthesizedMessage(text)), nil
+	}
+
+	if mutedPlayback(ctx) {
+		return mcp.NewToolResultText(fmt.Sprintf("Speaking: %s", text)), nil
+	}
+
+	noteSpeaking(ctx, say.ProviderSay, params.Voice)
+	ctx, current, stop, err := beginPlayback(ctx)
+	if errors.Is(err, context.Canceled) {
+		log.Info("Say command cancelled by user")
+		return mcp.NewToolResultText("Say command cancelled"), nil
+	}
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	defer stop()
+	playbacks.playing(current)
+
+	if err := runSay(ctx, params, saySentences(params.Text)); err != nil {
+		if ctx.Err() != nil {
+			log.Info("Say command cancelled by user")
+			return mcp.NewToolResultText("Say command cancelled"), nil
+		}
+		log.Error("Say command failed", "error", err)
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	log.Info("Speaking text completed", "text", text)
+	if suppressSpeakingOutput {
+		return mcp.NewToolResultText("Speech completed"), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Speaking: %s", text)), nil
+}
+
+// saySentences returns the texts to run the say command with in turn. Long text is
+// spoken a sentence at a time, so a call can be interrupted between sentences and
+// reports progress per sentence. Shorter text stays one invocation, which keeps the
+// intonation across sentences.
+func saySentences(text string) []string {
+	if utf8.RuneCountInString(text) < sayProgressiveLength {
+		return []string{text}
+	}
+	sentences := splitSentences(text)
+	if len(sentences) < 2 {
+		return []string{text}
+	}
+	return sentences
+}
+
+// execSay runs the say command once, the process is killed when ctx is cancelled
+func execSay(ctx context.Context, args []string) error {
+	sayCmd := exec.CommandContext(ctx, sayBinary, args...)
+	if err := sayCmd.Start(); err != nil {
+		return fmt.Errorf("Failed to start say command: %v", err)
+	}
+	// say plays as it runs, so its run time is the length of the audio played
+	start := time.Now()
+	defer func() { notePlayedAudio(ctx, time.Since(start)) }()
+	if err := sayCmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("Say command failed: %v", err)
+	}
+	return nil
+}
+
+// runSay runs the say command for each of texts in turn, stopping when ctx is cancelled
+func runSay(ctx context.Context, params say.SaySpeechParams, texts []string) error {
+	reporter, _ := ctx.Value(progressKey{}).(*progressReporter)
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		params.Text = text
+		args := say.SayArgs(params)
+		log.Debug("Executing say command", "args", args, "sentence", i+1, "of", len(texts))
+		if say.TestMode() {
+			log.Debug("Test mode, not running the say command")
+		} else if err := execSay(ctx, args); err != nil {
+			return err
+		}
+		if reporter != nil && len(texts) > 1 {
+			reporter.sentence(i+1, len(texts))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useFakeSay replaces the say binary with a script logging the text of each invocation
+func useFakeSay(t *testing.T) func() []string {
+	t.Helper()
+	dir := t.TempDir()
+	log := filepath.Join(dir, "spoken")
+	script := filepath.Join(dir, "say")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\nfor last; do :; done\necho \"$last\" >> "+log+"\n"), 0o755))
+	prev := sayBinary
+	sayBinary = script
+	t.Cleanup(func() { sayBinary = prev })
+	return func() []string {
+		data, _ := os.ReadFile(log)
+		os.Remove(log)
+		if len(data) == 0 {
+			return nil
+		}
+		return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+}
+
+func TestSaySentences(t *testing.T) {
+	assert.Equal(t, []string{"One. Two."}, saySentences("One. Two."))
+	long := strings.Repeat("word ", 100)
+	assert.Equal(t, []string{long}, saySentences(long), "a single long sentence is not split")
+	first, second := strings.Repeat("a", 250)+".", strings.Repeat("b", 250)+"."
+	assert.Equal(t, []string{first, second}, saySentences(first+" "+second))
+}
+
+func TestHandleSayTTSSentences(t *testing.T) {
+	spoken := useFakeSay(t)
+	first, second := strings.Repeat("a", 250)+".", strings.Repeat("b", 250)+"."
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"text": first + " " + second}
+	var progress []map[string]any
+	ctx := context.WithValue(context.Background(), progressKey{}, &progressReporter{send: func(params map[string]any) error {
+		progress = append(progress, params)
+		return nil
+	}})
+	result, err := handleSayTTS(ctx, request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content)
+	assert.Equal(t, []string{first, second}, spoken())
+	require.Len(t, progress, 2)
+	assert.Equal(t, "Spoke sentence 2 of 2", progress[1]["message"])
+
+	request.Params.Arguments = map[string]any{"text": "Short. Text."}
+	_, err = handleSayTTS(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Short. Text."}, spoken())
+}
+
+func TestRunSayCancelled(t *testing.T) {
+	spoken := useFakeSay(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, runSay(ctx, say.SaySpeechParams{}, []string{"One.", "Two."}), context.Canceled)
+	assert.Empty(t, spoken())
+}
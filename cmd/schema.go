@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// wholeNumber restricts a number argument to integers, so clients can reject fractions before calling
+func wholeNumber() mcp.PropertyOption {
+	return mcp.MultipleOf(1)
+}
+
+// numberEnum restricts a number argument to a fixed set of values
+func numberEnum(values ...int) mcp.PropertyOption {
+	return func(schema map[string]any) {
+		schema["enum"] = values
+	}
+}
+
+// milliseconds bounds a millisecond argument from 0 to max
+func milliseconds(max time.Duration) mcp.PropertyOption {
+	return func(schema map[string]any) {
+		schema["minimum"] = 0
+		schema["maximum"] = max.Milliseconds()
+	}
+}
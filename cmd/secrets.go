@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// secretEnvs are the API key variables that can be read from a file instead, by setting the
+// variable with a _FILE suffix to its path, e.g. ELEVENLABS_API_KEY_FILE=/run/secrets/elevenlabs
+var secretEnvs = []string{"OPENAI_API_KEY", "GOOGLE_AI_API_KEY", "GEMINI_API_KEY", "ELEVENLABS_API_KEY", "ELEVENLABS_API_KEYS"}
+
+// secrets holds the API keys read from _FILE variables, getenv consults it before the environment
+var secrets = map[string]string{}
+
+// loadSecretFiles reads the API keys named by _FILE variables, trimming surrounding whitespace.
+// A key file wins over the plain variable and the config file. An unreadable or empty file
+// is an error, so a broken secret mount fails at startup instead of on the first call.
+func loadSecretFiles() error {
+	loaded := map[string]string{}
+	for _, name := range secretEnvs {
+		path := os.Getenv(name + "_FILE")
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s_FILE: %v", name, err)
+		}
+		key := strings.TrimSpace(string(data))
+		if key == "" {
+			return fmt.Errorf("%s_FILE: %s is empty", name, path)
+		}
+		if os.Getenv(name) != "" {
+			log.Warn("Both an API key and a key file are set, using the file", "env", name, "file", name+"_FILE")
+		}
+		loaded[name] = key
+		log.Info("Loaded API key from file", "env", name, "path", path)
+	}
+	secrets = loaded
+	return nil
+}
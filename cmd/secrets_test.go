@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSecretFiles(t *testing.T) {
+	t.Cleanup(func() { secrets = map[string]string{} })
+	useConfig(t, &Config{env: map[string]string{"OPENAI_API_KEY": "sk-config"}})
+	dir := t.TempDir()
+	path := filepath.Join(dir, "elevenlabs")
+	require.NoError(t, os.WriteFile(path, []byte("  el-file\n"), 0o600))
+	t.Setenv("ELEVENLABS_API_KEY", "el-env")
+	t.Setenv("ELEVENLABS_API_KEY_FILE", path)
+
+	require.NoError(t, loadSecretFiles())
+	assert.Equal(t, "el-file", providerAPIKey(say.ProviderElevenLabs))
+	assert.Equal(t, "sk-config", providerAPIKey(say.ProviderOpenAI))
+	_, detail := envStatus("ELEVENLABS_API_KEY")
+	assert.Equal(t, "ELEVENLABS_API_KEY is set from ELEVENLABS_API_KEY_FILE", detail)
+
+	empty := filepath.Join(dir, "empty")
+	require.NoError(t, os.WriteFile(empty, []byte("\n"), 0o600))
+	t.Setenv("OPENAI_API_KEY_FILE", empty)
+	assert.ErrorContains(t, loadSecretFiles(), "OPENAI_API_KEY_FILE")
+
+	t.Setenv("OPENAI_API_KEY_FILE", filepath.Join(dir, "missing"))
+	assert.Error(t, loadSecretFiles())
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/gopxl/beep/v2"
+)
+
+const (
+	selfTestSampleRate = beep.SampleRate(44100)
+	selfTestFrequency  = 440.0
+	selfTestDuration   = 500 * time.Millisecond
+	// selfTestTimeout is how much longer than the tone playback may take before the test gives up
+	selfTestTimeout = 5 * time.Second
+)
+
+// audioBackend names the system audio API the speaker plays through on this OS
+func audioBackend() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "Core Audio"
+	case "windows":
+		return "WASAPI"
+	case "linux":
+		return "ALSA (libasound, PulseAudio and PipeWire are reached through it)"
+	default:
+		return "oto default for " + runtime.GOOS
+	}
+}
+
+// runSelfTest plays a short tone through p and checks that the speaker took every
+// sample, writing diagnostics to w. It returns an error when the test fails.
+// buffer and prebuffer are only reported, p must not prebuffer for the count to be exact.
+func runSelfTest(ctx context.Context, p say.AudioPlayer, buffer, prebuffer time.Duration, w io.Writer) error {
+	fmt.Fprintln(w, "mcp-say audio self-test")
+	fmt.Fprintf(w, "  os:         %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(w, "  backend:    %s\n", audioBackend())
+	fmt.Fprintf(w, "  buffer:     %v\n", buffer)
+	if prebuffer < 0 {
+		fmt.Fprintln(w, "  prebuffer:  off")
+	} else {
+		fmt.Fprintf(w, "  prebuffer:  %v (not used by the test)\n", prebuffer)
+	}
+	fmt.Fprintf(w, "  tone:       %g Hz for %v at %d Hz\n", selfTestFrequency, selfTestDuration, selfTestSampleRate)
+
+	tone := say.Tone(selfTestSampleRate, selfTestFrequency, selfTestDuration, 0.3)
+	expected := tone.Len()
+	counter := &countingStreamer{Streamer: tone}
+	ctx, cancel := context.WithTimeout(ctx, selfTestDuration+selfTestTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := p.PlayStream(ctx, counter, beep.Format{SampleRate: selfTestSampleRate, NumChannels: 1, Precision: 2})
+	elapsed := time.Since(start)
+	played := int(counter.samples.Load())
+	if rate := say.SpeakerSampleRate(); rate != 0 {
+		fmt.Fprintf(w, "  speaker:    opened at %d Hz\n", rate)
+	}
+	fmt.Fprintf(w, "  played:     %d of %d samples in %v\n", played, expected, elapsed.Round(time.Millisecond))
+
+	switch {
+	case errors.Is(err, say.ErrNoAudioDevice):
+		fmt.Fprintf(w, "FAIL: could not open the audio device: %v\n", err)
+		fmt.Fprintln(w, "  Check that a sound server or device is available to this user. On Linux, libasound2 must be installed, and headless machines should run with --no-audio.")
+		return errors.New("audio self-test failed")
+	case errors.Is(err, context.DeadlineExceeded):
+		fmt.Fprintf(w, "FAIL: playback did not finish within %v, the device stopped taking samples\n", selfTestDuration+selfTestTimeout)
+		fmt.Fprintln(w, "  The device is open but stalled. Another program may hold it exclusively, or try a larger MCP_SAY_BUFFER_MS.")
+		return errors.New("audio self-test failed")
+	case err != nil:
+		fmt.Fprintf(w, "FAIL: %v\n", err)
+		return errors.New("audio self-test failed")
+	case played < expected:
+		fmt.Fprintf(w, "FAIL: the speaker took only %d of %d samples\n", played, expected)
+		return errors.New("audio self-test failed")
+	}
+	if elapsed < selfTestDuration/2 {
+		fmt.Fprintln(w, "WARN: samples were taken much faster than real time, the output may be a null device")
+	}
+	fmt.Fprintln(w, "PASS: the speaker accepted every sample. If you heard nothing, check the system output device and volume.")
+	return nil
+}
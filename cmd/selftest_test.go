@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunSelfTest(t *testing.T) {
+	var out bytes.Buffer
+	mock := &MockAudioPlayer{}
+	require.NoError(t, runSelfTest(context.Background(), mock, say.DefaultSpeakerBuffer, -1, &out))
+	assert.Equal(t, 22050, mock.PlayedSamples)
+	assert.Contains(t, out.String(), "prebuffer:  off")
+	assert.Contains(t, out.String(), "played:     22050 of 22050 samples")
+	// The mock drains the tone instantly, unlike a real device
+	assert.Contains(t, out.String(), "WARN: samples were taken much faster than real time")
+	assert.Contains(t, out.String(), "PASS")
+
+	out.Reset()
+	err := runSelfTest(context.Background(), say.NoAudioPlayer{}, say.DefaultSpeakerBuffer, say.DefaultPrebuffer, &out)
+	require.Error(t, err)
+	assert.Contains(t, out.String(), "prebuffer:  200ms")
+	assert.Contains(t, out.String(), "FAIL: could not open the audio device")
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// Most segments accepted by speak_sequence
+	maxSequenceSegments = 20
+	// Longest accepted gap_ms value
+	maxSequenceGap = 5 * time.Second
+)
+
+// newProvider looks up a rate limited provider by name, swapped out in tests
+var newProvider = func(name string) (say.Provider, error) {
+	provider, err := configuredProvider(name)
+	if err != nil {
+		return nil, err
+	}
+	return wrapProvider(name, provider), nil
+}
+
+// sequenceSegment is one entry of the speak_sequence `segments` argument
+type sequenceSegment struct {
+	Text  string
+	Voice string
+	// Model is set by a voice alias, empty uses the provider's default
+	Model    string
+	Provider string
+}
+
+// parseSequenceSegments validates the raw `segments` tool argument
+func parseSequenceSegments(raw any) ([]sequenceSegment, error) {
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, errors.New("segments must be an array of {text, provider, voice} objects")
+	}
+	if len(items) == 0 {
+		return nil, errors.New("segments must contain at least one segment")
+	}
+	if len(items) > maxSequenceSegments {
+		return nil, fmt.Errorf("too many segments (%d, max %d)", len(items), maxSequenceSegments)
+	}
+
+	segments := make([]sequenceSegment, 0, len(items))
+	for i, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("segments[%d] must be an object", i)
+		}
+		text, _ := obj["text"].(string)
+		provider, _ := obj["provider"].(string)
+		voice := providerSetting(obj, provider, "voice", "")
+		var model string
+		if _, alias, ok := lookupVoiceAlias(obj); ok {
+			provider, voice, model = alias.Provider, alias.Voice, alias.Model
+		}
+		if strings.TrimSpace(text) == "" {
+			return nil, fmt.Errorf("segments[%d] must have non-empty text", i)
+		}
+		if len([]rune(text)) > maxTextLength {
+			return nil, fmt.Errorf("segments[%d] text too long (max %d characters)", i, maxTextLength)
+		}
+		if provider == "" {
+			return nil, fmt.Errorf("segments[%d] must have a provider", i)
+		}
+		segments = append(segments, sequenceSegment{Text: text, Voice: voice, Model: model, Provider: provider})
+	}
+	return segments, nil
+}
+
+// synthesizeSequence synthesizes every segment concurrently. Failed segments get a nil clip
+// buffer and their error, so the rest of the sequence can still be played.
+// Segments are joined by gap, or overlapped by crossfade when there is no gap.
+func synthesizeSequence(ctx context.Context, segments []sequenceSegment, gap, crossfade time.Duration) ([]say.Clip, []error) {
+	clips := make([]say.Clip, len(segments))
+	errs := make([]error, len(segments))
+	// Segments finish in any order, their spoken text is collected apart and added in order
+	spoken := make([]*spokenText, len(segments))
+
+	var wg sync.WaitGroup
+	for i, seg := range segments {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var segCtx context.Context
+			segCtx, spoken[i] = withSpokenText(ctx)
+			provider, err := newProvider(seg.Provider)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			clips[i].Buffer, errs[i] = say.ProviderSegments(provider, say.Options{
+				Voice: seg.Voice,
+				Model: providerSetting(map[string]any{"model": seg.Model}, seg.Provider, "model", ""),
+			})(segCtx, say.Segment{Text: seg.Text})
+		}()
+	}
+	wg.Wait()
+	for i := range segments {
+		if errs[i] == nil {
+			recordSpokenText(ctx, spoken[i].String())
+		}
+	}
+
+	// Only put gaps between segments that will actually be heard
+	last := -1
+	for i := range clips {
+		if clips[i].Buffer != nil {
+			if last >= 0 {
+				clips[last].Pause = gap
+				clips[last].Crossfade = crossfade
+			}
+			last = i
+		}
+	}
+	return clips, errs
+}
+
+// handleSpeakSequence synthesizes a list of segments, each with its own provider and
+// voice, and plays them back-to-back as one clip
+func handleSpeakSequence(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Speak sequence tool called", "request", request)
+	arguments := request.GetArguments()
+
+	segments, err := parseSequenceSegments(arguments["segments"])
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	out, err := outputArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	var gap time.Duration
+	if ms, ok := arguments["gap_ms"].(float64); ok {
+		gap = time.Duration(ms * float64(time.Millisecond))
+		if gap < 0 || gap > maxSequenceGap {
+			log.Warn("Gap out of range, using default", "provided", ms, "max", maxSequenceGap.Milliseconds())
+			gap = 0
+		}
+	}
+
+	crossfade := crossfadeArgument(arguments)
+	if crossfade > 0 && gap > 0 {
+		log.Warn("Crossfade ignored, segments are separated by a gap", "gap", gap, "crossfade", crossfade)
+	}
+
+	clips, errs := synthesizeSequence(ctx, segments, gap, crossfade)
+
+	var (
+		lines  []string
+		spoken int
+	)
+	for i, seg := range segments {
+		label := seg.Provider
+		if seg.Voice != "" {
+			label += "/" + seg.Voice
+		}
+		if errs[i] != nil {
+			log.Error("Failed to synthesize sequence segment", "index", i, "provider", seg.Provider, "error", errs[i])
+			lines = append(lines, fmt.Sprintf("%d. [failed] %s: %v", i+1, label, errs[i]))
+			continue
+		}
+		spoken++
+		if suppressSpeakingOutput {
+			lines = append(lines, fmt.Sprintf("%d. [ok] %s", i+1, label))
+		} else {
+			lines = append(lines, fmt.Sprintf("%d. [ok] %s: %s", i+1, label, seg.Text))
+		}
+	}
+
+	if spoken == 0 {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			log.Info("Sequence playback cancelled by user")
+			return mcp.NewToolResultText("Sequence playback cancelled"), nil
+		}
+		result := mcp.NewToolResultText("Error: every segment failed\n" + strings.Join(lines, "\n"))
+		result.IsError = true
+		return result, nil
+	}
+
+	streamer, format := say.JoinClips(clips)
+	if out.enabled() {
+		summary := fmt.Sprintf("Synthesized %d of %d segments", spoken, len(segments))
+		return deliverAudio(ctx, out, captureStream(streamer, format), summary+"\n"+strings.Join(lines, "\n")), nil
+	}
+	log.Info("Speaking sequence", "segments", len(segments), "spoken", spoken, "gap", gap, "crossfade", crossfade)
+	if err := playStream(ctx, streamer, format); err != nil {
+		if errors.Is(err, context.Canceled) {
+			log.Info("Sequence playback cancelled by user")
+			return mcp.NewToolResultText("Sequence playback cancelled"), nil
+		}
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	summary := fmt.Sprintf("Spoke %d of %d segments", spoken, len(segments))
+	return mcp.NewToolResultText(summary + "\n" + strings.Join(lines, "\n")), nil
+}
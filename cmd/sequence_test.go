@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSequenceSegments(t *testing.T) {
+	segments, err := parseSequenceSegments([]any{
+		map[string]any{"text": "Welcome aboard.", "provider": "openai", "voice": "nova"},
+		map[string]any{"text": "Thanks!", "provider": "google"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []sequenceSegment{
+		{Text: "Welcome aboard.", Provider: "openai", Voice: "nova"},
+		{Text: "Thanks!", Provider: "google"},
+	}, segments)
+
+	tests := []struct {
+		name string
+		raw  any
+	}{
+		{"not an array", "Hello"},
+		{"empty", []any{}},
+		{"missing text", []any{map[string]any{"provider": "openai"}}},
+		{"missing provider", []any{map[string]any{"text": "Hello"}}},
+		{"too many", make([]any, maxSequenceSegments+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSequenceSegments(tt.raw)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestHandleSpeakSequence(t *testing.T) {
+	mock := useMockPlayer(t)
+	prev := newProvider
+	newProvider = func(name string) (say.Provider, error) {
+		if name == "elevenlabs" {
+			return nil, fmt.Errorf("ELEVENLABS_API_KEY is not set")
+		}
+		return &fakeProvider{}, nil
+	}
+	t.Cleanup(func() { newProvider = prev })
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{
+		"segments": []any{
+			map[string]any{"text": "One.", "provider": "openai"},
+			map[string]any{"text": "Two.", "provider": "elevenlabs"},
+			map[string]any{"text": "Three.", "provider": "google", "voice": "Puck"},
+		},
+		"gap_ms": 100.0,
+	}
+
+	result, err := handleSpeakSequence(context.Background(), request)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Spoke 2 of 3 segments")
+	assert.Contains(t, text, "2. [failed] elevenlabs: ELEVENLABS_API_KEY is not set")
+	assert.Contains(t, text, "3. [ok] google/Puck: Three.")
+	// Two 100ms clips joined by a single 100ms gap, the failed segment leaves no hole
+	assert.Equal(t, 2400*3, mock.PlayedSamples)
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// handleSoundEffect generates a sound effect with ElevenLabs and plays it
+func handleSoundEffect(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Sound effect tool called", "request", request)
+	arguments := request.GetArguments()
+	prompt, _ := arguments["prompt"].(string)
+	duration := 0.0
+	if raw, ok := arguments["duration"]; ok && raw != nil {
+		d, ok := raw.(float64)
+		if !ok {
+			result := mcp.NewToolResultText("Error: duration must be a number of seconds")
+			result.IsError = true
+			return result, nil
+		}
+		duration = d
+	}
+	if err := say.ValidateSoundEffect(prompt, duration); err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	out, err := outputArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	log.Info("Generating sound effect via ElevenLabs", "prompt", prompt, "duration", duration)
+	provider := wrapProvider(say.ProviderElevenLabs, &say.ElevenLabsSoundEffect{
+		APIKey:   providerAPIKey(say.ProviderElevenLabs),
+		Duration: duration,
+	})
+	opts := say.Options{Text: prompt}
+	if out.enabled() {
+		audio, err := renderText(ctx, provider, opts, chunking{})
+		if err != nil {
+			log.Error("Sound effect generation failed", "error", err)
+			result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+			result.IsError = true
+			return result, nil
+		}
+		return deliverAudio(ctx, out, audio, fmt.Sprintf("Generated sound effect: %s", prompt)), nil
+	}
+	err = speakText(ctx, provider, opts, chunking{})
+
+	if errors.Is(err, context.Canceled) {
+		log.Info("Sound effect playback cancelled by user")
+		return mcp.NewToolResultText("Sound effect playback cancelled"), nil
+	}
+	if err != nil {
+		log.Error("Sound effect generation failed", "error", err)
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Played sound effect: %s", prompt)), nil
+}
@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// Maximum accepted SSML document size
+	maxSSMLLength = 10000
+	// ElevenLabs ignores <break> tags longer than 3 seconds
+	maxElevenLabsBreak = 3 * time.Second
+)
+
+// speakSSMLDescription documents which SSML tags each provider honors
+const speakSSMLDescription = `Speaks an SSML document using the chosen provider.
+
+The document must have a <speak> root. Supported tags per provider:
+• google - <break>, <sub>, <p>, <s>. Gemini TTS has no SSML input, so <break> is rendered as inserted silence and all other tags are stripped
+• openai - <break>, <sub>, <p>, <s>. No SSML input, so <break> is rendered as inserted silence and all other tags are stripped
+• elevenlabs - <break> is passed through natively (max 3s), <sub> is replaced by its alias and all other tags are stripped
+• say - <break> becomes an embedded [[slnc]] silence command (macOS only), <sub> is replaced by its alias, and all other tags and square brackets are stripped
+
+<break> accepts time="500ms"/"1.5s" (max 10s) or strength="none|x-weak|weak|medium|strong|x-strong".`
+
+// parseSSML validates an SSML tool argument and downconverts it into plain text segments
+func parseSSML(ssml string) ([]say.Segment, error) {
+	if strings.TrimSpace(ssml) == "" {
+		return nil, errors.New("empty SSML provided")
+	}
+	if len(ssml) > maxSSMLLength {
+		return nil, fmt.Errorf("SSML too long (%d characters, max %d)", len(ssml), maxSSMLLength)
+	}
+	return say.SSMLToSegments(ssml)
+}
+
+// ssmlToElevenLabsText renders segments as text with ElevenLabs' native <break> tags
+func ssmlToElevenLabsText(segments []say.Segment) string {
+	var sb strings.Builder
+	for _, seg := range segments {
+		sb.WriteString(seg.Text)
+		if seg.Pause > 0 {
+			pause := min(seg.Pause, maxElevenLabsBreak)
+			fmt.Fprintf(&sb, ` <break time="%.1fs" /> `, pause.Seconds())
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// sayCommandBrackets removes the square brackets say reads embedded commands from
+var sayCommandBrackets = strings.NewReplacer("[", "", "]", "")
+
+// ssmlToSayText renders segments as text with macOS `say` silence embedded commands.
+// Brackets are stripped from the text so it can't smuggle in commands of its own, e.g.
+// "[[rate 500]]", even split across adjacent segments.
+func ssmlToSayText(segments []say.Segment) string {
+	var sb strings.Builder
+	for _, seg := range segments {
+		sb.WriteString(sayCommandBrackets.Replace(seg.Text))
+		if seg.Pause > 0 {
+			fmt.Fprintf(&sb, " [[slnc %d]] ", seg.Pause.Milliseconds())
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// handleSpeakSSML validates SSML and speaks it with the requested provider
+func handleSpeakSSML(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Speak SSML tool called", "request", request)
+	arguments := request.GetArguments()
+	provider, ok := arguments["provider"].(string)
+	if !ok {
+		result := mcp.NewToolResultText("Error: provider must be a string")
+		result.IsError = true
+		return result, nil
+	}
+	ssml, ok := arguments["ssml"].(string)
+	if !ok {
+		result := mcp.NewToolResultText("Error: ssml must be a string")
+		result.IsError = true
+		return result, nil
+	}
+	voice := providerSetting(arguments, provider, "voice", "")
+
+	segments, err := parseSSML(ssml)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	out, err := outputArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	var plain []string
+	for _, seg := range segments {
+		if seg.Text != "" {
+			plain = append(plain, seg.Text)
+		}
+	}
+	text := strings.Join(plain, " ")
+
+	if out.enabled() {
+		audio, err := renderSSML(ctx, provider, segments, voice)
+		if err != nil {
+			log.Error("Failed to synthesize SSML", "provider", provider, "error", err)
+			result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+			result.IsError = true
+			return result, nil
+		}
+		return deliverAudio(ctx, out, audio, synthesizedMessage(text)), nil
+	}
+
+	switch provider {
+	case "say":
+		err = speakSSMLWithSay(ctx, segments, voice)
+	case "elevenlabs":
+		err = speakSSMLWithElevenLabs(ctx, segments, voice)
+	case "openai", "google":
+		err = speakSSMLSegments(ctx, provider, segments, voice)
+	default:
+		err = fmt.Errorf("unsupported provider %q (use google, openai, elevenlabs or say)", provider)
+	}
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			log.Info("SSML playback cancelled by user")
+			return mcp.NewToolResultText("SSML playback cancelled"), nil
+		}
+		log.Error("Failed to speak SSML", "provider", provider, "error", err)
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	log.Info("Speaking SSML", "provider", provider, "voice", voice, "text", text)
+	if suppressSpeakingOutput {
+		return mcp.NewToolResultText("Speech completed"), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Speaking: %s (via %s SSML)", text, provider)), nil
+}
+
+// renderSSML synthesizes segments with the requested provider without playing them
+func renderSSML(ctx context.Context, provider string, segments []say.Segment, voice string) (*say.Audio, error) {
+	switch provider {
+	case "say":
+		if voice != "" && !say.ValidSayVoice(voice) {
+			return nil, fmt.Errorf("voice contains invalid characters: %s", voice)
+		}
+		text := ssmlToSayText(segments)
+		recordSpokenText(ctx, text)
+		data, err := say.SynthesizeSay(ctx, say.SaySpeechParams{Text: text, Voice: voice})
+		if err != nil {
+			return nil, err
+		}
+		return &say.Audio{Data: data, Encoding: say.EncodingWAV}, nil
+	case "elevenlabs":
+		return wrapProvider(say.ProviderElevenLabs, say.NewElevenLabs(providerAPIKey(say.ProviderElevenLabs))).Synthesize(ctx, say.Options{
+			Text:  ssmlToElevenLabsText(segments),
+			Voice: voice,
+			Model: providerSetting(nil, say.ProviderElevenLabs, "model", ""),
+		})
+	case "openai", "google":
+		p, err := newProvider(provider)
+		if err != nil {
+			return nil, err
+		}
+		streamer, format, err := say.RenderSegments(ctx, segments, say.ProviderSegments(p, say.Options{
+			Voice: voice,
+			Model: providerSetting(nil, provider, "model", ""),
+		}))
+		if err != nil {
+			return nil, err
+		}
+		return captureStream(streamer, format), nil
+	default:
+		return nil, fmt.Errorf("unsupported provider %q (use google, openai, elevenlabs or say)", provider)
+	}
+}
+
+// speakSSMLWithSay speaks segments with the macOS say command using embedded silence commands
+func speakSSMLWithSay(ctx context.Context, segments []say.Segment, voice string) error {
+	if runtime.GOOS != "darwin" {
+		return errors.New("the say provider is only available on macOS")
+	}
+	args := []string{"--rate", "200"}
+	if voice != "" {
+		if !say.ValidSayVoice(voice) {
+			return fmt.Errorf("voice contains invalid characters: %s", voice)
+		}
+		args = append(args, "--voice", voice)
+	}
+	text := ssmlToSayText(segments)
+	recordSpokenText(ctx, text)
+	args = append(args, "--", text)
+	if mutedPlayback(ctx) {
+		return nil
+	}
+
+	noteSpeaking(ctx, say.ProviderSay, voice)
+	ctx, current, done, err := beginPlayback(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	playbacks.playing(current)
+
+	log.Debug("Executing say command", "args", args)
+	return playbackErr(ctx, execSay(ctx, args))
+}
+
+// speakSSMLWithElevenLabs speaks segments in a single request using ElevenLabs' native <break> support
+func speakSSMLWithElevenLabs(ctx context.Context, segments []say.Segment, voice string) error {
+	return withAudioHint(say.SpeakWith(ctx, wrapProvider(say.ProviderElevenLabs, say.NewElevenLabs(providerAPIKey(say.ProviderElevenLabs))), say.Options{
+		Text:   ssmlToElevenLabsText(segments),
+		Voice:  voice,
+		Model:  providerSetting(nil, say.ProviderElevenLabs, "model", ""),
+		Player: player(),
+	}))
+}
+
+// speakSSMLSegments synthesizes each segment separately and plays them with silence inserted for breaks
+func speakSSMLSegments(ctx context.Context, provider string, segments []say.Segment, voice string) error {
+	p, err := newProvider(provider)
+	if err != nil {
+		return err
+	}
+	streamer, format, err := say.RenderSegments(ctx, segments, say.ProviderSegments(p, say.Options{
+		Voice: voice,
+		Model: providerSetting(nil, provider, "model", ""),
+	}))
+	if err != nil {
+		return err
+	}
+	return playStream(ctx, streamer, format)
+}
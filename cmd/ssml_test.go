@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSSMLLimits(t *testing.T) {
+	_, err := parseSSML("   ")
+	assert.ErrorContains(t, err, "empty SSML")
+
+	_, err = parseSSML("<speak>" + strings.Repeat("a", maxSSMLLength) + "</speak>")
+	assert.ErrorContains(t, err, "SSML too long")
+
+	segments, err := parseSSML(`<speak>Hello <break time="500ms"/> world</speak>`)
+	require.NoError(t, err)
+	assert.Equal(t, []say.Segment{{Text: "Hello", Pause: 500 * time.Millisecond}, {Text: "world"}}, segments)
+}
+
+func TestSSMLProviderRendering(t *testing.T) {
+	segments := []say.Segment{
+		{Text: "Hello", Pause: 500 * time.Millisecond},
+		{Text: "world", Pause: 5 * time.Second},
+		{Text: "again"},
+	}
+
+	assert.Equal(t, `Hello <break time="0.5s" /> world <break time="3.0s" /> again`, ssmlToElevenLabsText(segments))
+	assert.Equal(t, "Hello [[slnc 500]] world [[slnc 5000]] again", ssmlToSayText(segments))
+}
+
+func TestSSMLToSayTextStripsCommands(t *testing.T) {
+	segments := []say.Segment{
+		{Text: "Hi [[rate 500]] there [[volm 0]]", Pause: 200 * time.Millisecond},
+		{Text: "split ["},
+		{Text: "[inpt PHON]]"},
+	}
+	assert.Equal(t, "Hi rate 500 there volm 0 [[slnc 200]] split inpt PHON", ssmlToSayText(segments))
+
+	parsed, err := parseSSML(`<speak>Say [[slnc 9999]] this</speak>`)
+	require.NoError(t, err)
+	assert.NotContains(t, ssmlToSayText(parsed), "[[slnc 9999]]")
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Path to the macOS say binary, swapped out in tests
+var sayBinary = "/usr/bin/say"
+
+// providerStatus describes whether a provider can be used, without making network calls
+type providerStatus struct {
+	Name   string
+	Tool   string
+	Ready  bool
+	Detail string
+}
+
+// envStatus reports the first of the given environment variables that is set
+func envStatus(names ...string) (bool, string) {
+	for _, name := range names {
+		if secrets[name] != "" {
+			return true, name + " is set from " + name + "_FILE"
+		}
+	}
+	for _, name := range names {
+		if os.Getenv(name) != "" {
+			return true, name + " is set"
+		}
+	}
+	for _, name := range names {
+		if config.env[name] != "" {
+			return true, name + " is set in the config file"
+		}
+	}
+	return false, strings.Join(names, " or ") + " is not set"
+}
+
+// googleADCFileExists reports whether `gcloud auth application-default login` has stored credentials
+func googleADCFileExists() bool {
+	dir := os.Getenv("CLOUDSDK_CONFIG")
+	if dir == "" {
+		if runtime.GOOS == "windows" {
+			dir = filepath.Join(os.Getenv("APPDATA"), "gcloud")
+		} else {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return false
+			}
+			dir = filepath.Join(home, ".config", "gcloud")
+		}
+	}
+	_, err := os.Stat(filepath.Join(dir, "application_default_credentials.json"))
+	return err == nil
+}
+
+// providerStatuses inspects the environment and local binaries for every provider
+func providerStatuses() []providerStatus {
+	var statuses []providerStatus
+
+	sayStatus := providerStatus{Name: say.ProviderSay, Tool: "say_tts"}
+	if runtime.GOOS != "darwin" {
+		sayStatus.Detail = fmt.Sprintf("only available on macOS (running on %s)", runtime.GOOS)
+	} else if _, err := os.Stat(sayBinary); err != nil {
+		sayStatus.Detail = sayBinary + " not found"
+	} else {
+		sayStatus.Ready = true
+		sayStatus.Detail = sayBinary + " found"
+	}
+	statuses = append(statuses, sayStatus)
+
+	ready, detail := envStatus("ELEVENLABS_API_KEY", "ELEVENLABS_API_KEYS")
+	statuses = append(statuses, providerStatus{Name: say.ProviderElevenLabs, Tool: "elevenlabs_tts", Ready: ready, Detail: detail})
+
+	ready, detail = envStatus("GOOGLE_AI_API_KEY", "GEMINI_API_KEY")
+	if googleGRPC {
+		ready, detail = envStatus("GOOGLE_APPLICATION_CREDENTIALS")
+		if !ready && googleADCFileExists() {
+			ready, detail = true, "gcloud application default credentials found"
+		}
+		detail += " (gRPC)"
+	}
+	statuses = append(statuses, providerStatus{Name: say.ProviderGoogle, Tool: "google_tts", Ready: ready, Detail: detail})
+
+	ready, detail = envStatus("OPENAI_API_KEY")
+	statuses = append(statuses, providerStatus{Name: say.ProviderOpenAI, Tool: "openai_tts", Ready: ready, Detail: detail})
+
+	return statuses
+}
+
+// logProviderStatus logs which providers are usable at startup
+func logProviderStatus() {
+	var ready, unavailable []string
+	for _, status := range providerStatuses() {
+		if status.Ready {
+			ready = append(ready, status.Name)
+		} else {
+			unavailable = append(unavailable, status.Name)
+			log.Debug("Provider unavailable", "provider", status.Name, "reason", status.Detail)
+		}
+	}
+	log.Info("Provider status", "ready", strings.Join(ready, ","), "unavailable", strings.Join(unavailable, ","))
+}
+
+// handleStatus reports which providers are configured and available on this machine
+func handleStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Status tool called", "request", request)
+
+	var sb strings.Builder
+	sb.WriteString("Provider status:\n")
+	for _, status := range providerStatuses() {
+		mark := "✗"
+		if status.Ready {
+			mark = "✓"
+		}
+		fmt.Fprintf(&sb, "%s %s (%s): %s\n", mark, status.Name, status.Tool, status.Detail)
+	}
+	if noAudio {
+		sb.WriteString("\nAudio playback: disabled (--no-audio), use output_file or return_audio")
+	} else if muted.Load() {
+		sb.WriteString("\nAudio playback: muted, call unmute to resume")
+	} else if volume := say.MasterVolume(); volume < say.MaxMasterVolume {
+		fmt.Fprintf(&sb, "\nAudio playback: enabled, master volume %g", volume)
+	} else {
+		sb.WriteString("\nAudio playback: enabled")
+	}
+	if say.TestMode() {
+		fmt.Fprintf(&sb, "\nTest mode: provider requests are answered with synthetic audio and never sent (%s)", testModeEnv)
+	}
+	return mcp.NewToolResultText(strings.TrimSpace(sb.String())), nil
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderStatuses(t *testing.T) {
+	t.Setenv("ELEVENLABS_API_KEY", "")
+	t.Setenv("GOOGLE_AI_API_KEY", "")
+	t.Setenv("GEMINI_API_KEY", "test-key")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	statuses := map[string]providerStatus{}
+	for _, status := range providerStatuses() {
+		statuses[status.Name] = status
+	}
+
+	assert.False(t, statuses["elevenlabs"].Ready)
+	assert.Equal(t, "ELEVENLABS_API_KEY or ELEVENLABS_API_KEYS is not set", statuses["elevenlabs"].Detail)
+	assert.True(t, statuses["google"].Ready)
+	assert.Equal(t, "GEMINI_API_KEY is set", statuses["google"].Detail)
+	assert.False(t, statuses["openai"].Ready)
+	if runtime.GOOS != "darwin" {
+		assert.False(t, statuses["say"].Ready)
+		assert.Contains(t, statuses["say"].Detail, "only available on macOS")
+	}
+}
+
+func TestHandleStatus(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	result, err := handleStatus(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "✓ openai (openai_tts): OPENAI_API_KEY is set")
+}
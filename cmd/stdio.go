@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	stdlog "log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// errClientGone stops playback when the MCP client disconnects.
+// It wraps context.Canceled so the stopped call reports a cancellation.
+var errClientGone = fmt.Errorf("%w: MCP client disconnected", context.Canceled)
+
+// ignoreBrokenPipe makes writes to a closed stdout fail with EPIPE instead of the
+// process being killed by SIGPIPE, so serveStdio can notice and exit cleanly
+func ignoreBrokenPipe() {
+	signal.Ignore(syscall.SIGPIPE)
+}
+
+// brokenPipe reports whether a write failed because the reader went away
+func brokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrClosedPipe) || errors.Is(err, os.ErrClosed)
+}
+
+// clientConn tracks whether the MCP client on the other end of stdio is still there
+type clientConn struct {
+	gone   atomic.Bool
+	once   sync.Once
+	cancel context.CancelFunc
+}
+
+// disconnect stops all playback and the server, once, when the client goes away
+func (c *clientConn) disconnect(reason string, err error) {
+	c.once.Do(func() {
+		c.gone.Store(true)
+		fields := []any{"reason", reason}
+		if err != nil && err != io.EOF {
+			fields = append(fields, "error", err)
+		}
+		if n := playbacks.stop(errClientGone); n > 0 {
+			fields = append(fields, "stoppedPlayback", n)
+		}
+		log.Info("MCP client disconnected, shutting down", fields...)
+		c.cancel()
+	})
+}
+
+// clientWriter writes to stdout, a write failing because the client closed it disconnects
+type clientWriter struct {
+	w    io.Writer
+	conn *clientConn
+}
+
+func (c *clientWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	if err != nil && brokenPipe(err) {
+		c.conn.disconnect("stdout closed", err)
+	}
+	return n, err
+}
+
+// serverErrorLog logs the stdio server's errors, the failed writes after the client
+// disconnected are expected and only logged at debug level
+type serverErrorLog struct {
+	conn *clientConn
+}
+
+func (l serverErrorLog) Write(p []byte) (int, error) {
+	message := strings.TrimSpace(string(p))
+	if l.conn.gone.Load() {
+		log.Debug("MCP server error after the client disconnected", "error", message)
+	} else {
+		log.Error("MCP server error", "error", message)
+	}
+	return len(p), nil
+}
+
+// serveStdio serves MCP over stdin and stdout until ctx is cancelled or the client
+// disconnects. Stdin EOF only means no more requests: the ones read before it are still
+// answered, e.g. when requests are piped in, and playback stops once they have been.
+// A closed stdout stops all playback right away. Either way it returns nil so the
+// server exits cleanly.
+func serveStdio(ctx context.Context, s *server.MCPServer, stdin io.Reader, stdout io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	conn := &clientConn{cancel: cancel}
+
+	stdio := server.NewStdioServer(s)
+	stdio.SetErrorLogger(stdlog.New(serverErrorLog{conn: conn}, "", 0))
+	err := stdio.Listen(ctx, stdin, &clientWriter{w: stdout, conn: conn})
+	if conn.gone.Load() || errors.Is(err, context.Canceled) {
+		return nil
+	}
+	if err == nil {
+		// Listen returns at stdin EOF once every request before it has been answered
+		conn.disconnect("stdin closed", io.EOF)
+	}
+	return err
+}
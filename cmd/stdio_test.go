@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// brokenStdout fails every write like a pipe whose reader exited
+type brokenStdout struct{}
+
+func (brokenStdout) Write(p []byte) (int, error) {
+	return 0, syscall.EPIPE
+}
+
+func TestServeStdio(t *testing.T) {
+	t.Run("requests piped before EOF are answered", func(t *testing.T) {
+		var stdout bytes.Buffer
+		stdin := strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","clientInfo":{"name":"test","version":"1.0.0"}}}` + "\n" +
+			`{"jsonrpc":"2.0","id":2,"method":"tools/list"}` + "\n")
+		require.NoError(t, serveStdio(context.Background(), server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(false)), stdin, &stdout))
+
+		lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[0], `"id":1`)
+		assert.Contains(t, lines[1], `"id":2`)
+		assert.Contains(t, lines[1], `"tools"`)
+	})
+
+	t.Run("stdin closed during a call still answers it", func(t *testing.T) {
+		playing := make(chan struct{})
+		release := make(chan struct{})
+		s := server.NewMCPServer("test", "1.0.0")
+		s.AddTool(mcp.NewTool("play"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx, _, done := playbacks.start(ctx)
+			defer done()
+			close(playing)
+			select {
+			case <-release:
+				return mcp.NewToolResultText("played"), nil
+			case <-ctx.Done():
+				return mcp.NewToolResultText("stopped"), nil
+			}
+		})
+
+		stdin, client := io.Pipe()
+		var stdout bytes.Buffer
+		served := make(chan error, 1)
+		go func() { served <- serveStdio(context.Background(), s, stdin, &stdout) }()
+		_, err := io.WriteString(client, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"play"}}`+"\n")
+		require.NoError(t, err)
+		<-playing
+		client.Close()
+
+		select {
+		case <-served:
+			t.Fatal("server exited before answering the call")
+		case <-time.After(50 * time.Millisecond):
+		}
+		close(release)
+		select {
+		case err := <-served:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("server did not exit after stdin closed")
+		}
+		assert.Contains(t, stdout.String(), "played")
+	})
+
+	t.Run("broken stdout stops background playback", func(t *testing.T) {
+		cause := make(chan error, 1)
+		s := server.NewMCPServer("test", "1.0.0")
+		s.AddTool(mcp.NewTool("play"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			// Like a stream, playback outlives the call
+			ctx, _, done := playbacks.start(context.Background())
+			go func() {
+				defer done()
+				<-ctx.Done()
+				cause <- context.Cause(ctx)
+			}()
+			return mcp.NewToolResultText("queued"), nil
+		})
+
+		stdin, client := io.Pipe()
+		defer client.Close()
+		go io.WriteString(client, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"play"}}`+"\n")
+		assert.NoError(t, serveStdio(context.Background(), s, stdin, brokenStdout{}))
+		select {
+		case err := <-cause:
+			assert.ErrorIs(t, err, errClientGone)
+		case <-time.After(5 * time.Second):
+			t.Fatal("playback was not stopped")
+		}
+	})
+
+	t.Run("stdin EOF while idle", func(t *testing.T) {
+		stdin, client := io.Pipe()
+		client.Close()
+		assert.NoError(t, serveStdio(context.Background(), server.NewMCPServer("test", "1.0.0"), stdin, io.Discard))
+	})
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+)
+
+// testModeEnv enables test mode. It is only read from the process environment, never
+// from the config file or secret files, so a shared config can't turn it on.
+const testModeEnv = "MCP_SAY_TEST_MODE"
+
+// loadTestMode enables test mode when MCP_SAY_TEST_MODE is exactly 1: the real handlers
+// run, but provider requests are answered with synthetic audio and never sent, see
+// say.SetTestMode. Any other non-empty value is an error rather than silently ignored.
+func loadTestMode() error {
+	value := os.Getenv(testModeEnv)
+	switch value {
+	case "":
+		return nil
+	case "1":
+		say.SetTestMode(true)
+		log.Warn("TEST MODE: provider requests are answered with synthetic audio and never sent, do not use in production", "env", testModeEnv)
+		return nil
+	}
+	return fmt.Errorf("%s must be 1 to enable test mode or unset, got %q", testModeEnv, value)
+}
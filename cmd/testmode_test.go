@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useTestMode stubs provider requests with synthetic audio for the rest of the test
+func useTestMode(t *testing.T) {
+	t.Helper()
+	say.SetTestMode(true)
+	t.Cleanup(func() { say.SetTestMode(false) })
+}
+
+func TestLoadTestMode(t *testing.T) {
+	t.Cleanup(func() { say.SetTestMode(false) })
+
+	t.Setenv(testModeEnv, "")
+	require.NoError(t, loadTestMode())
+	assert.False(t, say.TestMode())
+
+	for _, value := range []string{"true", "yes", "0", " 1"} {
+		t.Setenv(testModeEnv, value)
+		assert.Error(t, loadTestMode(), value)
+		assert.False(t, say.TestMode(), value)
+	}
+
+	t.Setenv(testModeEnv, "1")
+	require.NoError(t, loadTestMode())
+	assert.True(t, say.TestMode())
+}
+
+func TestTestModeHandlers(t *testing.T) {
+	mock := useMockPlayer(t)
+	useTestMode(t)
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	t.Setenv("ELEVENLABS_API_KEY", "xi-test")
+	t.Setenv("GOOGLE_AI_API_KEY", "ai-test")
+
+	tests := []struct {
+		name    string
+		handler ToolHandlerFunc
+		args    map[string]any
+	}{
+		{"openai", handleOpenAITTS, map[string]any{"text": "Hello"}},
+		{"openai stream", handleOpenAITTS, map[string]any{"text": "Hello", "stream": true}},
+		{"google", handleGoogleTTS, map[string]any{"text": "Hello"}},
+		{"elevenlabs", handleElevenLabsTTS, map[string]any{"text": "Hello"}},
+		{"elevenlabs captions", handleElevenLabsTTS, map[string]any{"text": "Hello there", "vtt_output": t.TempDir() + "/out.vtt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.Played = false
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tt.args
+			result, err := tt.handler(context.Background(), request)
+			require.NoError(t, err)
+			require.False(t, result.IsError, result.Content)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Speaking: ")
+			assert.True(t, mock.Played)
+		})
+	}
+
+	status, err := handleStatus(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.Contains(t, status.Content[0].(mcp.TextContent).Text, "Test mode:")
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+)
+
+const (
+	// Longest text accepted by the TTS tools, in characters
+	maxTextLength = 50000
+)
+
+// errNothingToSpeak is returned for text that is only markdown, emoji, punctuation or
+// whitespace once text replacements are applied. Providers would otherwise reply with
+// silence or a vague error.
+var errNothingToSpeak = errors.New("text was empty after removing formatting/emoji, nothing to speak")
+
+// speakable reports whether text has at least one letter or digit to say
+func speakable(text string) bool {
+	return strings.IndexFunc(text, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsNumber(r)
+	}) >= 0
+}
+
+// textArgument returns the text to speak from either the `text` argument or the
+// UTF-8 file named by the `text_file` argument
+func textArgument(arguments map[string]any) (string, error) {
+	rawText, hasText := arguments["text"]
+	rawFile, hasFile := arguments["text_file"]
+	hasText = hasText && rawText != nil
+	hasFile = hasFile && rawFile != nil
+
+	var text string
+	switch {
+	case hasText && hasFile:
+		return "", errors.New("text and text_file are mutually exclusive, provide only one")
+	case hasFile:
+		path, ok := rawFile.(string)
+		if !ok || path == "" {
+			return "", errors.New("text_file must be a non-empty string")
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read text_file: %v", err)
+		}
+		// A UTF-8 character is at most 4 bytes, skip reading files that can't fit
+		if info.Size() > maxTextLength*utf8.UTFMax {
+			return "", fmt.Errorf("text_file too large (%d bytes)", info.Size())
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read text_file: %v", err)
+		}
+		if !utf8.Valid(data) {
+			return "", fmt.Errorf("text_file %s is not valid UTF-8", path)
+		}
+		log.Debug("Read text from file", "path", path, "bytes", len(data))
+		text = string(data)
+	case hasText:
+		var ok bool
+		if text, ok = rawText.(string); !ok {
+			return "", errors.New("text must be a string")
+		}
+	default:
+		return "", errors.New("either text or text_file must be provided")
+	}
+
+	if n := utf8.RuneCountInString(text); n > maxTextLength {
+		return "", fmt.Errorf("text too long (%d characters, max %d)", n, maxTextLength)
+	}
+	// Empty text is left to the handlers, which report it as such
+	if text != "" && !speakable(applyReplacements(text)) {
+		return "", errNothingToSpeak
+	}
+	return text, nil
+}
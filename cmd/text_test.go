@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextArgument(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "speech.txt")
+	require.NoError(t, os.WriteFile(file, []byte("Hello from a file ✨"), 0o644))
+	invalid := filepath.Join(dir, "invalid.txt")
+	require.NoError(t, os.WriteFile(invalid, []byte{0xff, 0xfe, 0xfd}, 0o644))
+	long := filepath.Join(dir, "long.txt")
+	require.NoError(t, os.WriteFile(long, []byte(strings.Repeat("a", maxTextLength+1)), 0o644))
+
+	t.Run("text", func(t *testing.T) {
+		text, err := textArgument(map[string]any{"text": "Hello"})
+		require.NoError(t, err)
+		assert.Equal(t, "Hello", text)
+	})
+
+	t.Run("text_file", func(t *testing.T) {
+		text, err := textArgument(map[string]any{"text_file": file})
+		require.NoError(t, err)
+		assert.Equal(t, "Hello from a file ✨", text)
+	})
+
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		errMsg    string
+	}{
+		{"both", map[string]any{"text": "Hello", "text_file": file}, "mutually exclusive"},
+		{"neither", map[string]any{}, "either text or text_file"},
+		{"text not a string", map[string]any{"text": 42.0}, "text must be a string"},
+		{"missing file", map[string]any{"text_file": filepath.Join(dir, "missing.txt")}, "failed to read text_file"},
+		{"invalid UTF-8", map[string]any{"text_file": invalid}, "not valid UTF-8"},
+		{"too long", map[string]any{"text_file": long}, "text too long"},
+		{"emoji only", map[string]any{"text": "🎉🚀 ✅"}, "empty after removing formatting/emoji"},
+		{"markdown only", map[string]any{"text": "**  ---\n> `` **"}, "empty after removing formatting/emoji"},
+		{"whitespace only", map[string]any{"text": " \n\t "}, "empty after removing formatting/emoji"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := textArgument(tt.arguments)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestTextArgumentEmptyAfterReplacements(t *testing.T) {
+	r, err := loadReplacements(writeReplacements(t, "rules.json", `[{"find": "TODO", "replace": ""}]`))
+	require.NoError(t, err)
+	textReplacements = r
+	t.Cleanup(func() { textReplacements = nil })
+
+	_, err = textArgument(map[string]any{"text": "TODO!"})
+	assert.ErrorIs(t, err, errNothingToSpeak)
+
+	text, err := textArgument(map[string]any{"text": "TODO: ship it 🚀"})
+	require.NoError(t, err)
+	assert.Equal(t, "TODO: ship it 🚀", text)
+}
+
+func TestHandlersRejectEmojiOnlyText(t *testing.T) {
+	for name, handler := range map[string]ToolHandlerFunc{
+		"openai_tts":     handleOpenAITTS,
+		"google_tts":     handleGoogleTTS,
+		"elevenlabs_tts": handleElevenLabsTTS,
+	} {
+		t.Run(name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = map[string]any{"text": "👍🎉"}
+			result, err := handler(context.Background(), request)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Equal(t, "Error: text was empty after removing formatting/emoji, nothing to speak", result.Content[0].(mcp.TextContent).Text)
+		})
+	}
+}
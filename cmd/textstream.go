@@ -0,0 +1,393 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// Most text streams open at once
+	maxTextStreams = 8
+	// textStreamIdle is how long a stream may go without appends before it is discarded
+	textStreamIdle = 10 * time.Minute
+	// textStreamQueue is how many sentences may wait for synthesis, an append beyond it blocks
+	textStreamQueue = 32
+)
+
+// textStream speaks fragments appended by say_stream_append. Complete sentences are
+// synthesized while earlier ones play, so speech keeps up with a token stream.
+type textStream struct {
+	id       string
+	provider say.Provider
+	name     string
+	voice    string
+	model    string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	// played collects what the stream played for the webhook, nil when it is off
+	played *playedUtterances
+	// sentences feeds the synthesis goroutine, audio the playback goroutine
+	sentences chan string
+	done      chan struct{}
+
+	// appendMu keeps fragments in order and the sentences channel open while they are sent
+	appendMu sync.Mutex
+	mu       sync.Mutex
+	pending  string // text after the last sentence boundary, not queued yet
+	queued   int
+	spoken   int
+	err      error // first failure since it was last reported
+	failure  error // first failure of the stream, for the webhook
+	appended time.Time
+	closed   bool
+}
+
+// textStreams are the open say_stream_append streams by ID
+type textStreams struct {
+	mu      sync.Mutex
+	streams map[string]*textStream
+}
+
+var openStreams = &textStreams{streams: make(map[string]*textStream)}
+
+// completeSentences splits text at its last sentence boundary. sentences are ready to
+// speak, rest may still be continued by the next fragment. A terminator only ends a
+// sentence once what follows it has arrived, "3." may be the start of "3.14".
+func completeSentences(text string) (sentences []string, rest string) {
+	runes := []rune(text)
+	cut := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '\n' {
+			cut = i + 1
+			continue
+		}
+		if !isSentenceTerminator(r) {
+			continue
+		}
+		for i+1 < len(runes) && (isSentenceTerminator(runes[i+1]) || isClosingPunct(runes[i+1])) {
+			i++
+		}
+		if i+1 < len(runes) && (unicode.IsSpace(runes[i+1]) || isFullWidthTerminator(runes[i])) {
+			cut = i + 1
+		}
+	}
+	sentences = splitSentences(string(runes[:cut]))
+	rest = string(runes[cut:])
+	// A run on sentence is spoken in chunks rather than held back indefinitely
+	if chunks := splitLongSentence(rest, maxChunkLength); len(chunks) > 1 {
+		sentences = append(sentences, chunks[:len(chunks)-1]...)
+		rest = chunks[len(chunks)-1]
+	}
+	return sentences, rest
+}
+
+// open returns the stream with id, starting it with the provider, voice and model
+// arguments if it is new. Streams idle for longer than textStreamIdle are discarded first.
+func (t *textStreams) open(id string, arguments map[string]any) (*textStream, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, s := range t.streams {
+		if s.idle() > textStreamIdle {
+			log.Info("Discarding idle text stream", "stream", key, "idle", s.idle().Round(time.Second))
+			go s.stop()
+			delete(t.streams, key)
+		}
+	}
+	name, _ := arguments["provider"].(string)
+	if s, ok := t.streams[id]; ok {
+		if name != "" && name != s.name {
+			return nil, fmt.Errorf("stream %q speaks with %s, flush it before switching to %s", id, s.name, name)
+		}
+		return s, nil
+	}
+	if name == "" {
+		return nil, fmt.Errorf("provider is required for the first fragment of stream %q", id)
+	}
+	if len(t.streams) >= maxTextStreams {
+		return nil, fmt.Errorf("too many open streams (max %d), flush one first", maxTextStreams)
+	}
+	provider, err := newProvider(name)
+	if err != nil {
+		return nil, err
+	}
+	s := &textStream{
+		id:        id,
+		provider:  provider,
+		name:      name,
+		voice:     providerSetting(arguments, name, "voice", activeDefaultVoice(name)),
+		model:     providerSetting(arguments, name, "model", ""),
+		sentences: make(chan string, textStreamQueue),
+		done:      make(chan struct{}),
+		appended:  time.Now(),
+	}
+	// The stream outlives the tool call that opened it
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	if webhookURL != "" && !noAudio {
+		s.ctx, s.played = trackPlayed(s.ctx)
+	}
+	go s.run()
+	t.streams[id] = s
+	log.Info("Opened text stream", "stream", id, "provider", name, "voice", s.voice)
+	return s, nil
+}
+
+// remove forgets the stream with id, returning it
+func (t *textStreams) remove(id string) (*textStream, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.streams[id]
+	delete(t.streams, id)
+	return s, ok
+}
+
+func (s *textStream) idle() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Since(s.appended)
+}
+
+// run synthesizes queued sentences one ahead of playback, so the next sentence is
+// ready when the current one ends
+func (s *textStream) run() {
+	defer close(s.done)
+	audio := make(chan *say.Audio, 1)
+	played := make(chan struct{})
+	go func() {
+		defer close(played)
+		for clip := range audio {
+			err := playbackErr(s.ctx, withAudioHint(player().Play(s.ctx, clip)))
+			s.finish(err)
+		}
+	}()
+	for sentence := range s.sentences {
+		if s.ctx.Err() != nil {
+			continue
+		}
+		clip, err := s.provider.Synthesize(s.ctx, say.Options{Text: sentence, Voice: s.voice, Model: s.model})
+		if err != nil {
+			s.finish(err)
+			continue
+		}
+		select {
+		case audio <- clip:
+		case <-s.ctx.Done():
+		}
+	}
+	close(audio)
+	<-played
+	s.notifyWebhook()
+}
+
+// notifyWebhook reports the end of the stream's background playback, which no tool
+// call waits for unless it is flushed
+func (s *textStream) notifyWebhook() {
+	if s.played == nil {
+		return
+	}
+	event := s.played.event("say_stream_append", s.name)
+	event.StreamID = s.id
+	s.mu.Lock()
+	failure := s.failure
+	s.mu.Unlock()
+	switch {
+	case s.ctx.Err() != nil:
+		event.Status = webhookCancelled
+	case failure != nil:
+		event.Status = webhookFailed
+		event.Error = failure.Error()
+	}
+	go sendWebhook(event)
+}
+
+// finish records a sentence as spoken or failed. An interrupted sentence is neither.
+func (s *textStream) finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case err == nil:
+		s.spoken++
+	case errors.Is(err, context.Canceled):
+		log.Debug("Text stream sentence interrupted", "stream", s.id)
+	default:
+		log.Error("Text stream sentence failed", "stream", s.id, "provider", s.name, "error", err)
+		if s.err == nil {
+			s.err = err
+		}
+		if s.failure == nil {
+			s.failure = err
+		}
+	}
+}
+
+// append adds a fragment and queues the sentences it completes, returning how many.
+// flush also queues the remainder and closes the stream to further fragments.
+func (s *textStream) append(ctx context.Context, fragment string, flush bool) (int, error) {
+	s.appendMu.Lock()
+	defer s.appendMu.Unlock()
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return 0, fmt.Errorf("stream %q was flushed", s.id)
+	}
+	sentences, rest := completeSentences(s.pending + fragment)
+	if flush {
+		sentences = append(sentences, splitSentences(rest)...)
+		rest = ""
+		s.closed = true
+		defer close(s.sentences)
+	}
+	s.pending = rest
+	s.appended = time.Now()
+	s.mu.Unlock()
+
+	queued := 0
+	for _, sentence := range sentences {
+		if !speakable(applyReplacements(sentence)) {
+			continue
+		}
+		select {
+		case s.sentences <- sentence:
+			queued++
+			s.mu.Lock()
+			s.queued++
+			s.mu.Unlock()
+		case <-ctx.Done():
+			return queued, ctx.Err()
+		case <-s.ctx.Done():
+			return queued, s.ctx.Err()
+		}
+	}
+	return queued, nil
+}
+
+// stop cancels the stream, dropping queued sentences and what is playing
+func (s *textStream) stop() {
+	// Cancel first so an append blocked on a full queue lets go of appendMu
+	s.cancel()
+	s.appendMu.Lock()
+	defer s.appendMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.sentences)
+	}
+}
+
+// takeErr returns the failure recorded since the last call, if any
+func (s *textStream) takeErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.err
+	s.err = nil
+	return err
+}
+
+// streamIDArgument reads the required stream_id argument
+func streamIDArgument(arguments map[string]any) (string, error) {
+	id, _ := arguments["stream_id"].(string)
+	if id = strings.TrimSpace(id); id == "" {
+		return "", errors.New("stream_id must be a non-empty string")
+	}
+	return id, nil
+}
+
+// handleSayStreamAppend adds a text fragment to a stream and returns right away. Each
+// sentence the fragment completes is synthesized and played in order in the background.
+func handleSayStreamAppend(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Say stream append tool called", "request", request)
+	arguments := request.GetArguments()
+	id, err := streamIDArgument(arguments)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	// Fragments are kept as is, the whitespace between them separates words and sentences
+	fragment, ok := arguments["text"].(string)
+	if !ok {
+		result := mcp.NewToolResultText("Error: text must be a string")
+		result.IsError = true
+		return result, nil
+	}
+
+	s, err := openStreams.open(id, arguments)
+	if err == nil {
+		err = s.takeErr()
+	}
+	if err != nil {
+		log.Error("Say stream append failed", "stream", id, "error", err)
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	queued, err := s.append(ctx, fragment, false)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	log.Debug("Appended to text stream", "stream", id, "queued", queued)
+	return mcp.NewToolResultText(fmt.Sprintf("Appended to stream %s, %d sentences queued", id, queued)), nil
+}
+
+// handleSayStreamFlush speaks what is left of a stream and waits for it to finish, or
+// with discard stops it right away. Either way the stream is closed.
+func handleSayStreamFlush(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Say stream flush tool called", "request", request)
+	id, err := streamIDArgument(request.GetArguments())
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	s, ok := openStreams.remove(id)
+	if !ok {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: no open stream %q", id))
+		result.IsError = true
+		return result, nil
+	}
+	if request.GetBool("discard", false) {
+		s.stop()
+		log.Info("Discarded text stream", "stream", id)
+		return mcp.NewToolResultText(fmt.Sprintf("Discarded stream %s", id)), nil
+	}
+
+	if _, err = s.append(ctx, "", true); err == nil {
+		select {
+		case <-s.done:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	if errors.Is(err, context.Canceled) {
+		s.stop()
+		log.Info("Text stream flush cancelled by user", "stream", id)
+		return mcp.NewToolResultText("Stream playback cancelled"), nil
+	}
+	s.cancel()
+	if err == nil {
+		err = s.takeErr()
+	}
+	s.mu.Lock()
+	spoken, queued := s.spoken, s.queued
+	s.mu.Unlock()
+	log.Info("Flushed text stream", "stream", id, "spoken", spoken, "queued", queued)
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v (spoke %d of %d sentences)", err, spoken, queued))
+		result.IsError = true
+		return result, nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Flushed stream %s, spoke %d of %d sentences", id, spoken, queued)), nil
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompleteSentences(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		sentences []string
+		rest      string
+	}{
+		{"no boundary", "Hello wor", nil, "Hello wor"},
+		{"terminator awaits what follows", "Pi is 3.", nil, "Pi is 3."},
+		{"decimal", "Pi is 3.14 or so", nil, "Pi is 3.14 or so"},
+		{"boundary", "Hello world. How", []string{"Hello world."}, " How"},
+		{"several", "One. Two! Three? Fo", []string{"One.", "Two!", "Three?"}, " Fo"},
+		{"closing quote", "He said \"hi.\" Then", []string{"He said \"hi.\""}, " Then"},
+		{"line break", "A heading\nBody", []string{"A heading"}, "Body"},
+		{"full width", "你好。今天", []string{"你好。"}, "今天"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sentences, rest := completeSentences(tt.text)
+			assert.Equal(t, tt.sentences, sentences)
+			assert.Equal(t, tt.rest, rest)
+		})
+	}
+}
+
+// useStreamProvider speaks streams with a fakeProvider
+func useStreamProvider(t *testing.T) *fakeProvider {
+	provider := &fakeProvider{}
+	prev := newProvider
+	newProvider = func(name string) (say.Provider, error) { return provider, nil }
+	t.Cleanup(func() { newProvider = prev })
+	return provider
+}
+
+func callStreamTool(t *testing.T, handler ToolHandlerFunc, arguments map[string]any) (string, bool) {
+	t.Helper()
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = arguments
+	result, err := handler(context.Background(), request)
+	require.NoError(t, err)
+	return result.Content[0].(mcp.TextContent).Text, result.IsError
+}
+
+func TestSayStream(t *testing.T) {
+	mock := useMockPlayer(t)
+	provider := useStreamProvider(t)
+
+	text, isErr := callStreamTool(t, handleSayStreamAppend, map[string]any{"stream_id": "s1", "text": "Hello"})
+	assert.True(t, isErr)
+	assert.Contains(t, text, "provider is required")
+
+	for _, fragment := range []string{"Hello", " wor", "ld. How are", " you? I", "'m fine"} {
+		arguments := map[string]any{"stream_id": "s1", "text": fragment, "provider": "openai"}
+		_, isErr := callStreamTool(t, handleSayStreamAppend, arguments)
+		require.False(t, isErr, fragment)
+	}
+	text, isErr = callStreamTool(t, handleSayStreamAppend, map[string]any{"stream_id": "s1", "text": "!", "provider": "google"})
+	assert.True(t, isErr, "provider can't change")
+	assert.Contains(t, text, "flush it before switching")
+
+	text, isErr = callStreamTool(t, handleSayStreamFlush, map[string]any{"stream_id": "s1"})
+	require.False(t, isErr, text)
+	assert.Equal(t, "Flushed stream s1, spoke 3 of 3 sentences", text)
+	assert.Equal(t, []string{"Hello world.", "How are you?", "I'm fine"}, provider.texts)
+	assert.True(t, mock.Played)
+
+	_, isErr = callStreamTool(t, handleSayStreamFlush, map[string]any{"stream_id": "s1"})
+	assert.True(t, isErr, "flushed streams are closed")
+}
+
+func TestSayStreamDiscard(t *testing.T) {
+	useMockPlayer(t)
+	provider := useStreamProvider(t)
+
+	_, isErr := callStreamTool(t, handleSayStreamAppend, map[string]any{"stream_id": "s2", "text": "Never finished", "provider": "openai"})
+	require.False(t, isErr)
+	text, isErr := callStreamTool(t, handleSayStreamFlush, map[string]any{"stream_id": "s2", "discard": true})
+	require.False(t, isErr)
+	assert.Equal(t, "Discarded stream s2", text)
+	assert.Empty(t, provider.texts)
+}
+
+// failingProvider fails every request
+type failingProvider struct{}
+
+func (failingProvider) Synthesize(ctx context.Context, opts say.Options) (*say.Audio, error) {
+	return nil, errors.New("OPENAI_API_KEY is not set")
+}
+
+func TestSayStreamError(t *testing.T) {
+	useMockPlayer(t)
+	prev := newProvider
+	newProvider = func(name string) (say.Provider, error) { return failingProvider{}, nil }
+	t.Cleanup(func() { newProvider = prev })
+
+	_, isErr := callStreamTool(t, handleSayStreamAppend, map[string]any{"stream_id": "s3", "text": "One. Two", "provider": "openai"})
+	require.False(t, isErr)
+	text, isErr := callStreamTool(t, handleSayStreamFlush, map[string]any{"stream_id": "s3"})
+	assert.True(t, isErr)
+	assert.Equal(t, "Error: OPENAI_API_KEY is not set (spoke 0 of 2 sentences)", text)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	toneSampleRate      = beep.SampleRate(44100)
+	defaultToneDuration = 300 * time.Millisecond
+	defaultToneVolume   = 0.3
+)
+
+// toneArguments reads the tone tool's arguments, falling back to a 300ms sine at 30% volume
+func toneArguments(arguments map[string]any) (say.ToneParams, error) {
+	params := say.ToneParams{
+		SampleRate: toneSampleRate,
+		Waveform:   say.WaveformSine,
+		Duration:   defaultToneDuration,
+		Volume:     defaultToneVolume,
+	}
+	frequency, ok := arguments["frequency"].(float64)
+	if !ok {
+		return params, errors.New("frequency must be a number of Hz")
+	}
+	params.Frequency = frequency
+	if raw, ok := arguments["duration_ms"]; ok && raw != nil {
+		ms, ok := raw.(float64)
+		if !ok {
+			return params, errors.New("duration_ms must be a number")
+		}
+		params.Duration = time.Duration(ms * float64(time.Millisecond))
+	}
+	if raw, ok := arguments["volume"]; ok && raw != nil {
+		volume, ok := raw.(float64)
+		if !ok {
+			return params, errors.New("volume must be a number from 0 to 1")
+		}
+		params.Volume = volume
+	}
+	if raw, ok := arguments["waveform"]; ok && raw != nil {
+		waveform, ok := raw.(string)
+		if !ok {
+			return params, errors.New("waveform must be a string")
+		}
+		params.Waveform = say.Waveform(waveform)
+	}
+	return params, params.Validate()
+}
+
+// handleTone plays a generated tone, for alerts that need no TTS provider
+func handleTone(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Tone tool called", "request", request)
+	params, err := toneArguments(request.GetArguments())
+	if err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+
+	log.Info("Playing tone", "waveform", params.Waveform, "frequency", params.Frequency, "duration", params.Duration, "volume", params.Volume)
+	if err := withAudioHint(player().Play(ctx, say.ToneAudio(params))); err != nil {
+		if errors.Is(err, context.Canceled) {
+			log.Info("Tone playback cancelled by user")
+			return mcp.NewToolResultText("Tone playback cancelled"), nil
+		}
+		log.Error("Failed to play tone", "error", err)
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Played %s tone: %g Hz for %v", params.Waveform, params.Frequency, params.Duration)), nil
+}
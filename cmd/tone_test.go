@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleTone(t *testing.T) {
+	mock := useMockPlayer(t)
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"frequency": 440.0, "duration_ms": 100.0, "waveform": "square"}
+
+	result, err := handleTone(context.Background(), request)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, "Played square tone: 440 Hz for 100ms", result.Content[0].(mcp.TextContent).Text)
+	// 100ms of 16-bit mono at 44.1kHz
+	assert.Len(t, mock.PlayedAudio, 2*4410)
+}
+
+func TestToneArgumentErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		errMsg    string
+	}{
+		{"missing frequency", map[string]any{}, "frequency must be a number"},
+		{"inaudible", map[string]any{"frequency": 5.0}, "frequency must be between 20 and 20000 Hz"},
+		{"too long", map[string]any{"frequency": 440.0, "duration_ms": 60000.0}, "duration must be between"},
+		{"too loud", map[string]any{"frequency": 440.0, "volume": 1.5}, "volume must be between 0 and 1"},
+		{"unknown waveform", map[string]any{"frequency": 440.0, "waveform": "triangle"}, "waveform must be sine, square or saw"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handleTone(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: tt.arguments}})
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errMsg)
+		})
+	}
+}
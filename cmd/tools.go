@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/spf13/cobra"
+)
+
+// Flag to print the tools command's output as JSON
+var toolsJSON bool
+
+func init() {
+	toolsCmd.Flags().BoolVar(&toolsJSON, "json", false, "Print the tools as the JSON array a tools/list request returns")
+	rootCmd.AddCommand(toolsCmd)
+}
+
+// toolsCmd lists the registered tools without starting the MCP server
+var toolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "List the MCP tools with their descriptions and argument schemas",
+	Long: `List the MCP tools with their descriptions and argument schemas, then exit.
+
+The tools are registered as for serving, with the same flags, environment and
+config file, so the list shows exactly what an MCP client would see, e.g. say_tts
+only on macOS and speak only with MCP_SAY_PROVIDER_POLICY set.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if verbose {
+			log.SetLevel(log.DebugLevel)
+		}
+		if err := loadSettings(); err != nil {
+			return err
+		}
+		tools, err := listTools(cmd.Context(), newServer())
+		if err != nil {
+			return err
+		}
+		return printTools(cmd.OutOrStdout(), tools, toolsJSON)
+	},
+}
+
+// listTools asks s for its tools through an in-process client, as an MCP client would
+func listTools(ctx context.Context, s *server.MCPServer) ([]mcp.Tool, error) {
+	c, err := client.NewInProcessClient(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create in-process client: %v", err)
+	}
+	defer c.Close()
+	if err := c.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start in-process client: %v", err)
+	}
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "mcp-say tools", Version: Version}
+	if _, err := c.Initialize(ctx, initRequest); err != nil {
+		return nil, fmt.Errorf("failed to initialize: %v", err)
+	}
+	result, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools: %v", err)
+	}
+	return result.Tools, nil
+}
+
+// printTools writes each tool's name, description and input schema to w, or all of them as JSON
+func printTools(w io.Writer, tools []mcp.Tool, asJSON bool) error {
+	if asJSON {
+		data, err := json.MarshalIndent(tools, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode tools: %v", err)
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	}
+	for i, tool := range tools {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		schema, err := json.MarshalIndent(tool.InputSchema, "  ", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s schema: %v", tool.Name, err)
+		}
+		fmt.Fprintln(w, tool.Name)
+		fmt.Fprintf(w, "  %s\n", strings.ReplaceAll(tool.Description, "\n", "\n  "))
+		fmt.Fprintf(w, "  %s\n", schema)
+	}
+	return nil
+}
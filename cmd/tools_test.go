@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListTools(t *testing.T) {
+	tools, err := listTools(context.Background(), newServer())
+	require.NoError(t, err)
+
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	assert.Contains(t, names, "elevenlabs_tts")
+	assert.Contains(t, names, "capabilities")
+	assert.NotContains(t, names, "speak", "speak needs a provider policy")
+
+	var out bytes.Buffer
+	require.NoError(t, printTools(&out, tools, false))
+	assert.Contains(t, out.String(), "openai_tts\n  Uses OpenAI's Text-to-Speech API")
+	assert.Contains(t, out.String(), `"instructions": {`)
+
+	out.Reset()
+	require.NoError(t, printTools(&out, tools, true))
+	var decoded []mcp.Tool
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Len(t, decoded, len(tools))
+}
+
+func TestToolSchemaConstraints(t *testing.T) {
+	tools, err := listTools(context.Background(), newServer())
+	require.NoError(t, err)
+	// Properties as a client decodes them from tools/list
+	schemas := map[string]map[string]any{}
+	for _, tool := range tools {
+		schemas[tool.Name] = tool.InputSchema.Properties
+	}
+	property := func(tool, name string) map[string]any {
+		t.Helper()
+		p, ok := schemas[tool][name].(map[string]any)
+		require.True(t, ok, "%s.%s", tool, name)
+		return p
+	}
+
+	speed := property("openai_tts", "speed")
+	assert.Equal(t, 0.25, speed["minimum"])
+	assert.Equal(t, 4.0, speed["maximum"])
+	assert.Equal(t, []any{8000.0, 16000.0, 22050.0, 24000.0, 44100.0, 48000.0}, property("google_tts", "sample_rate")["enum"])
+	assert.Equal(t, 5000.0, property("elevenlabs_tts", "sentence_pause_ms")["maximum"])
+	assert.Equal(t, 1.0, property("elevenlabs_tts", "optimize_streaming_latency")["multipleOf"])
+	assert.Equal(t, float64(maxSequenceSegments), property("speak_sequence", "segments")["maxItems"])
+	assert.Equal(t, []any{"elevenlabs_tts", "google_tts", "openai_tts", "sound_effect"}, property("debug_request", "tool")["enum"])
+	assert.Equal(t, 1.0, property("play_file", "max_duration_ms")["minimum"])
+}
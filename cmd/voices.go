@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultVoicesTTL is how long the ElevenLabs voices list is reused before it is fetched again
+const defaultVoicesTTL = 10 * time.Minute
+
+// voiceCache keeps the ElevenLabs voices list in memory. A failed refresh keeps
+// serving the previous list, however old, rather than failing the call.
+type voiceCache struct {
+	fetch func(ctx context.Context) ([]say.ElevenLabsVoice, error)
+
+	// fetching lets one call fetch at a time, mu guards the list so lookups never wait on a fetch
+	fetching sync.Mutex
+	mu       sync.Mutex
+	voices   []say.ElevenLabsVoice
+	fetched  time.Time // zero until the first successful fetch
+}
+
+// ElevenLabs voices shared by list_voices, refresh_voices and voice checks
+var elevenLabsVoices = &voiceCache{
+	fetch: func(ctx context.Context) ([]say.ElevenLabsVoice, error) {
+		return say.ListElevenLabsVoices(ctx, providerAPIKey(say.ProviderElevenLabs))
+	},
+}
+
+// voicesTTL returns the cache lifetime from MCP_SAY_VOICES_TTL, defaulting to 10 minutes
+func voicesTTL() time.Duration {
+	value := getenv("MCP_SAY_VOICES_TTL")
+	if value == "" {
+		return defaultVoicesTTL
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		log.Warn("Invalid voices TTL, using the default", "env", "MCP_SAY_VOICES_TTL", "value", value, "default", defaultVoicesTTL)
+		return defaultVoicesTTL
+	}
+	return ttl
+}
+
+// get returns the voices, fetching them when the cache is empty, expired or refresh is
+// set. warning is non-empty when a failed fetch was answered from the stale cache.
+func (c *voiceCache) get(ctx context.Context, refresh bool) (voices []say.ElevenLabsVoice, warning string, err error) {
+	c.fetching.Lock()
+	defer c.fetching.Unlock()
+	c.mu.Lock()
+	cached, fetched := c.voices, c.fetched
+	c.mu.Unlock()
+	if !refresh && !fetched.IsZero() && time.Since(fetched) < voicesTTL() {
+		return cached, "", nil
+	}
+
+	voices, err = c.fetch(ctx)
+	if err != nil {
+		if fetched.IsZero() || ctx.Err() != nil {
+			return nil, "", err
+		}
+		age := time.Since(fetched).Round(time.Second)
+		log.Warn("Failed to refresh ElevenLabs voices, serving the cached list", "age", age, "error", err)
+		return cached, fmt.Sprintf("Warning: refreshing the voices failed, showing the list from %s ago: %v", age, err), nil
+	}
+	log.Debug("Fetched ElevenLabs voices", "count", len(voices))
+	c.mu.Lock()
+	c.voices, c.fetched = voices, time.Now()
+	c.mu.Unlock()
+	return voices, "", nil
+}
+
+// known reports whether id is in the cached list, without fetching. ok is false
+// while nothing has been cached, so callers can't tell either way.
+func (c *voiceCache) known(id string) (found, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fetched.IsZero() {
+		return false, false
+	}
+	return slices.ContainsFunc(c.voices, func(v say.ElevenLabsVoice) bool { return v.ID == id }), true
+}
+
+// formatVoices lists voices one per line, sorted by name
+func formatVoices(voices []say.ElevenLabsVoice) string {
+	voices = slices.Clone(voices)
+	slices.SortFunc(voices, func(a, b say.ElevenLabsVoice) int { return strings.Compare(a.Name, b.Name) })
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d ElevenLabs voices:\n", len(voices))
+	for _, v := range voices {
+		fmt.Fprintf(&sb, "- %s (%s)", v.Name, v.ID)
+		if v.Category != "" {
+			fmt.Fprintf(&sb, ", %s", v.Category)
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// voicesResult answers list_voices and refresh_voices
+func voicesResult(ctx context.Context, refresh bool) *mcp.CallToolResult {
+	voices, warning, err := elevenLabsVoices.get(ctx, refresh)
+	if err != nil {
+		log.Error("Failed to fetch ElevenLabs voices", "error", err)
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", withProviderHint(err)))
+		result.IsError = true
+		return result
+	}
+	text := formatVoices(voices)
+	if warning != "" {
+		text = warning + "\n" + text
+	}
+	return mcp.NewToolResultText(text)
+}
+
+// handleListVoices lists the ElevenLabs voices, from the cache while it is fresh
+func handleListVoices(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("List voices tool called", "request", request)
+	return voicesResult(ctx, false), nil
+}
+
+// handleRefreshVoices fetches the ElevenLabs voices again, ignoring the cache
+func handleRefreshVoices(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Refresh voices tool called", "request", request)
+	return voicesResult(ctx, true), nil
+}
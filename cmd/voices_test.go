@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useVoiceCache replaces the ElevenLabs voices cache with one fetching from fetch
+func useVoiceCache(t *testing.T, fetch func(ctx context.Context) ([]say.ElevenLabsVoice, error)) {
+	prev := elevenLabsVoices
+	elevenLabsVoices = &voiceCache{fetch: fetch}
+	t.Cleanup(func() { elevenLabsVoices = prev })
+}
+
+func TestVoiceCache(t *testing.T) {
+	var fetches int
+	var fetchErr error
+	useVoiceCache(t, func(ctx context.Context) ([]say.ElevenLabsVoice, error) {
+		fetches++
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+		return []say.ElevenLabsVoice{{ID: "v2", Name: "Zed"}, {ID: "v1", Name: "Adam", Category: "premade"}}, nil
+	})
+
+	_, ok := elevenLabsVoices.known("v1")
+	assert.False(t, ok, "nothing cached yet")
+
+	result, err := handleListVoices(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "2 ElevenLabs voices:\n- Adam (v1), premade\n- Zed (v2)", result.Content[0].(mcp.TextContent).Text)
+	handleListVoices(context.Background(), mcp.CallToolRequest{})
+	assert.Equal(t, 1, fetches, "served from the cache within the TTL")
+	found, ok := elevenLabsVoices.known("v1")
+	assert.True(t, found && ok)
+
+	// A failed refresh keeps serving the stale list
+	fetchErr = errors.New("ElevenLabs API error (status 429): too_many_requests")
+	result, err = handleRefreshVoices(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, 2, fetches)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Warning: refreshing the voices failed")
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "- Adam (v1)")
+
+	// An expired entry is fetched again
+	t.Setenv("MCP_SAY_VOICES_TTL", "1ns")
+	fetchErr = nil
+	time.Sleep(time.Millisecond)
+	handleListVoices(context.Background(), mcp.CallToolRequest{})
+	assert.Equal(t, 3, fetches)
+}
+
+func TestVoiceCacheEmptyError(t *testing.T) {
+	useVoiceCache(t, func(ctx context.Context) ([]say.ElevenLabsVoice, error) {
+		return nil, errors.New("ELEVENLABS_API_KEY is not set")
+	})
+	result, err := handleListVoices(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "Error: ELEVENLABS_API_KEY is not set", result.Content[0].(mcp.TextContent).Text)
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// handleSetVolume sets the master volume applied to all playback, including what is playing now
+func handleSetVolume(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Set volume tool called", "request", request)
+	level, ok := request.GetArguments()["level"].(float64)
+	if !ok {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: level must be a number from 0 to %g", say.MaxMasterVolume))
+		result.IsError = true
+		return result, nil
+	}
+	previous := say.MasterVolume()
+	if err := say.SetMasterVolume(level); err != nil {
+		result := mcp.NewToolResultText(fmt.Sprintf("Error: %v", err))
+		result.IsError = true
+		return result, nil
+	}
+	log.Info("Master volume set", "level", level, "previous", previous)
+	return mcp.NewToolResultText(fmt.Sprintf("Master volume set to %g (was %g)", level, previous)), nil
+}
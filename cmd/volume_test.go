@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleSetVolume(t *testing.T) {
+	t.Cleanup(func() { say.SetMasterVolume(say.MaxMasterVolume) })
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"level": 0.25}
+	result, err := handleSetVolume(context.Background(), request)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, "Master volume set to 0.25 (was 1)", result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, 0.25, say.MasterVolume())
+
+	status, err := handleStatus(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.Contains(t, status.Content[0].(mcp.TextContent).Text, "Audio playback: enabled, master volume 0.25")
+
+	for _, level := range []any{1.5, -0.1, "loud", nil} {
+		request.Params.Arguments = map[string]any{"level": level}
+		result, err := handleSetVolume(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "level %v", level)
+	}
+	assert.Equal(t, 0.25, say.MasterVolume())
+}
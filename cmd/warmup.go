@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// warmupTimeout bounds each provider's warmup, a slow provider must not hold up the others' report
+const warmupTimeout = 10 * time.Second
+
+// warmupProvider opens a connection to a configured provider with a free, authenticated
+// call and returns what it did. Swapped out in tests.
+var warmupProvider = func(ctx context.Context, name string) (string, error) {
+	if name == say.ProviderElevenLabs {
+		// Listing the voices connects and fills the cache list_voices and voice checks use
+		voices, _, err := elevenLabsVoices.get(ctx, false)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("connected, %d voices cached", len(voices)), nil
+	}
+	provider, err := configuredProvider(name)
+	if err != nil {
+		return "", err
+	}
+	warmer, ok := provider.(say.Warmer)
+	if !ok {
+		return "nothing to warm up", nil
+	}
+	if err := warmer.Warmup(ctx); err != nil {
+		return "", err
+	}
+	return "connected", nil
+}
+
+// warmupSpeaker opens the output device so the first clip doesn't wait for it
+func warmupSpeaker() (bool, string) {
+	if noAudio {
+		return true, "disabled (--no-audio), nothing to open"
+	}
+	opener, ok := audioPlayer.(interface {
+		Open() (beep.SampleRate, error)
+	})
+	if !ok {
+		return true, "nothing to open"
+	}
+	rate, err := opener.Open()
+	if err != nil {
+		return false, withAudioHint(err).Error()
+	}
+	return true, fmt.Sprintf("open at %d Hz", rate)
+}
+
+// handleWarmup opens the speaker and connects to every configured provider in parallel.
+// Failures are reported per provider and never fail the call.
+func handleWarmup(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("Warmup tool called", "request", request)
+	start := time.Now()
+
+	statuses := providerStatuses()
+	lines := make([]string, len(statuses))
+	var wg sync.WaitGroup
+	for i, status := range statuses {
+		switch {
+		case !status.Ready:
+			lines[i] = fmt.Sprintf("- %s: skipped, %s", status.Name, status.Detail)
+			continue
+		case status.Name == say.ProviderSay:
+			lines[i] = fmt.Sprintf("✓ %s: local, nothing to warm up", status.Name)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, warmupTimeout)
+			defer cancel()
+			began := time.Now()
+			detail, err := warmupProvider(ctx, status.Name)
+			took := time.Since(began).Round(time.Millisecond)
+			if err != nil {
+				if errors.Is(err, context.DeadlineExceeded) {
+					err = fmt.Errorf("no response within %v", warmupTimeout)
+				}
+				log.Warn("Provider warmup failed", "provider", status.Name, "error", err)
+				lines[i] = fmt.Sprintf("✗ %s: %v", status.Name, err)
+				return
+			}
+			log.Info("Warmed up provider", "provider", status.Name, "took", took)
+			lines[i] = fmt.Sprintf("✓ %s: %s in %v", status.Name, detail, took)
+		}()
+	}
+
+	// The speaker opens while the providers connect
+	mark := "✓"
+	ok, detail := warmupSpeaker()
+	if !ok {
+		mark = "✗"
+		log.Warn("Speaker warmup failed", "error", detail)
+	}
+	wg.Wait()
+	if errors.Is(ctx.Err(), context.Canceled) {
+		log.Info("Warmup cancelled by user")
+		return mcp.NewToolResultText("Warmup cancelled"), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Warmup finished in %v:\n", time.Since(start).Round(time.Millisecond))
+	fmt.Fprintf(&sb, "%s speaker: %s\n", mark, detail)
+	for _, line := range lines {
+		sb.WriteString(line + "\n")
+	}
+	return mcp.NewToolResultText(strings.TrimSpace(sb.String())), nil
+}
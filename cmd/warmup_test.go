@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleWarmup(t *testing.T) {
+	useMockPlayer(t)
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	t.Setenv("ELEVENLABS_API_KEY", "xi-test")
+	t.Setenv("GOOGLE_AI_API_KEY", "")
+	t.Setenv("GEMINI_API_KEY", "")
+	useConfig(t, &Config{})
+
+	var warmed []string
+	prev := warmupProvider
+	warmupProvider = func(ctx context.Context, name string) (string, error) {
+		if name == say.ProviderElevenLabs {
+			return "", errors.New("ElevenLabs API error (status 401)")
+		}
+		warmed = append(warmed, name)
+		return "connected", nil
+	}
+	t.Cleanup(func() { warmupProvider = prev })
+
+	result, err := handleWarmup(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.False(t, result.IsError, "a failed provider does not fail the call")
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "✓ speaker: nothing to open")
+	assert.Contains(t, text, "✓ openai: connected in ")
+	assert.Contains(t, text, "✗ elevenlabs: ElevenLabs API error (status 401)")
+	assert.Contains(t, text, "- google: skipped, GOOGLE_AI_API_KEY or GEMINI_API_KEY is not set")
+	assert.Equal(t, []string{say.ProviderOpenAI}, warmed)
+}
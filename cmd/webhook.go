@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// webhookTimeout bounds a webhook delivery, a slow receiver never holds anything up
+const webhookTimeout = 5 * time.Second
+
+// Set from MCP_SAY_WEBHOOK_URL, empty disables the webhook
+var webhookURL string
+
+// parseWebhookURL validates an MCP_SAY_WEBHOOK_URL value
+func parseWebhookURL(value string) (string, error) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return "", err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%q must be an http or https URL", value)
+	}
+	return value, nil
+}
+
+// Webhook statuses
+const (
+	webhookCompleted = "completed"
+	webhookFailed    = "failed"
+	webhookCancelled = "cancelled"
+)
+
+// webhookEvent is the JSON body POSTed to MCP_SAY_WEBHOOK_URL when a call's playback ends
+type webhookEvent struct {
+	Tool string `json:"tool"`
+	// StreamID is the say_stream_append stream whose background playback ended
+	StreamID string `json:"stream_id,omitempty"`
+	// Provider is the provider that spoke, comma separated and sorted when a sequence used several
+	Provider string `json:"provider,omitempty"`
+	// UtteranceIDs are the history ids of the audio the call synthesized, see the replay tool
+	UtteranceIDs []string `json:"utterance_ids"`
+	// DurationMS is the length of the audio played, up to where it stopped when cut short
+	DurationMS int64  `json:"duration_ms"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+type playedUtterancesKey struct{}
+
+// playedUtterances collects the utterances a tool call synthesized and how much audio it played
+type playedUtterances struct {
+	mu        sync.Mutex
+	ids       []string
+	providers []string
+	audio     time.Duration
+}
+
+// trackPlayed returns ctx collecting what is synthesized and played in it for a webhook event
+func trackPlayed(ctx context.Context) (context.Context, *playedUtterances) {
+	played := &playedUtterances{}
+	return context.WithValue(ctx, playedUtterancesKey{}, played), played
+}
+
+// event returns the webhook event for the utterances and audio played so far
+func (p *playedUtterances) event(tool, provider string) webhookEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return webhookEvent{
+		Tool:         tool,
+		Provider:     cmp.Or(provider, strings.Join(p.providers, ",")),
+		UtteranceIDs: append([]string{}, p.ids...),
+		DurationMS:   p.audio.Milliseconds(),
+		Status:       webhookCompleted,
+	}
+}
+
+// notePlayedAudio adds played audio to the call's webhook event, if it has one
+func notePlayedAudio(ctx context.Context, d time.Duration) {
+	played, ok := ctx.Value(playedUtterancesKey{}).(*playedUtterances)
+	if !ok {
+		return
+	}
+	played.mu.Lock()
+	defer played.mu.Unlock()
+	played.audio += d
+}
+
+// countPlayedSamples counts the samples read from streamer for the call's webhook event.
+// The returned func adds their duration and must be called once playback has ended.
+func countPlayedSamples(ctx context.Context, streamer beep.Streamer, format beep.Format) (beep.Streamer, func()) {
+	if _, ok := ctx.Value(playedUtterancesKey{}).(*playedUtterances); !ok {
+		return streamer, func() {}
+	}
+	counted := &countingStreamer{Streamer: streamer}
+	return counted, func() { notePlayedAudio(ctx, format.SampleRate.D(int(counted.samples.Load()))) }
+}
+
+// notePlayedUtterance adds an utterance to the call's webhook event, if it has one
+func notePlayedUtterance(ctx context.Context, u *utterance) {
+	played, ok := ctx.Value(playedUtterancesKey{}).(*playedUtterances)
+	if !ok {
+		return
+	}
+	played.mu.Lock()
+	defer played.mu.Unlock()
+	played.ids = append(played.ids, u.ID)
+	if !slices.Contains(played.providers, u.Provider) {
+		played.providers = append(played.providers, u.Provider)
+		// Sequence segments are synthesized in parallel, keep the list stable
+		slices.Sort(played.providers)
+	}
+}
+
+// WithWebhook POSTs a webhookEvent to MCP_SAY_WEBHOOK_URL once a call that plays speech
+// ends, whether it completed, failed or was cancelled. provider is the tool's provider,
+// empty for tools that pick one per call. Delivery runs in the background and is never retried.
+func WithWebhook(provider string, handler ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if webhookURL == "" || noAudio {
+			return handler(ctx, request)
+		}
+		if out, _ := outputArgument(request.GetArguments()); out.enabled() {
+			// Nothing is played, there is no playback to report
+			return handler(ctx, request)
+		}
+		ctx, played := trackPlayed(ctx)
+		result, err := handler(ctx, request)
+
+		event := played.event(request.Params.Name, provider)
+		switch {
+		case ctx.Err() != nil:
+			event.Status = webhookCancelled
+		case err != nil:
+			event.Status = webhookFailed
+			event.Error = err.Error()
+		case result != nil && result.IsError:
+			event.Status = webhookFailed
+			event.Error = resultError(result).Error()
+		}
+		go sendWebhook(event)
+		return result, err
+	}
+}
+
+// sendWebhook delivers event, logging a failure instead of returning it
+func sendWebhook(event webhookEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Warn("Failed to encode webhook event", "error", err)
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		log.Warn("Failed to create webhook request", "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", say.UserAgent())
+	// The shared client applies the proxy and TLS settings provider requests get
+	resp, err := say.HTTPClient().Do(req)
+	if err != nil {
+		log.Warn("Webhook delivery failed", "url", webhookURL, "error", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Warn("Webhook rejected the event", "url", webhookURL, "status", resp.StatusCode)
+		return
+	}
+	log.Debug("Webhook delivered", "tool", event.Tool, "status", event.Status)
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/blacktop/mcp-tts/say"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWebhookURL(t *testing.T) {
+	_, err := parseWebhookURL("https://hooks.example.com/tts")
+	assert.NoError(t, err)
+	for _, value := range []string{"hooks.example.com", "ftp://example.com", "http://"} {
+		_, err := parseWebhookURL(value)
+		assert.Error(t, err, value)
+	}
+}
+
+// useWebhook points MCP_SAY_WEBHOOK_URL at a server, returning the events it receives
+// and a func waiting for the next one
+func useWebhook(t *testing.T) (chan webhookEvent, func() webhookEvent) {
+	events := make(chan webhookEvent, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event webhookEvent
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		events <- event
+	}))
+	t.Cleanup(server.Close)
+	webhookURL = server.URL
+	t.Cleanup(func() { webhookURL = "" })
+	prev := newProvider
+	newProvider = func(name string) (say.Provider, error) {
+		return wrapProvider(name, &fakeProvider{}), nil
+	}
+	t.Cleanup(func() { newProvider = prev })
+
+	return events, func() webhookEvent {
+		t.Helper()
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(time.Second):
+			t.Fatal("no webhook event")
+			return webhookEvent{}
+		}
+	}
+}
+
+func TestWithWebhook(t *testing.T) {
+	useMockPlayer(t)
+	events, receive := useWebhook(t)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "speak_sequence"
+	request.Params.Arguments = map[string]any{"segments": []any{
+		map[string]any{"text": "One.", "provider": "openai"},
+		map[string]any{"text": "Two.", "provider": "google"},
+	}}
+	result, err := WithWebhook("", handleSpeakSequence)(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content)
+	event := receive()
+	assert.Equal(t, "speak_sequence", event.Tool)
+	assert.Equal(t, webhookCompleted, event.Status)
+	assert.Equal(t, "google,openai", event.Provider)
+	// Two 100ms clips, however long synthesis took
+	assert.InDelta(t, 200, event.DurationMS, 5)
+	require.Len(t, event.UtteranceIDs, 2)
+	_, ok := history.get(event.UtteranceIDs[0])
+	assert.True(t, ok)
+
+	failing := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result := mcp.NewToolResultText("Error: Empty text provided")
+		result.IsError = true
+		return result, nil
+	}
+	request.Params.Name = "openai_tts"
+	_, err = WithWebhook(say.ProviderOpenAI, failing)(context.Background(), request)
+	require.NoError(t, err)
+	event = receive()
+	assert.Equal(t, webhookFailed, event.Status)
+	assert.Equal(t, "Empty text provided", event.Error)
+	assert.Equal(t, say.ProviderOpenAI, event.Provider)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = WithWebhook(say.ProviderOpenAI, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("cancelled")
+	})(ctx, request)
+	assert.Error(t, err)
+	assert.Equal(t, webhookCancelled, receive().Status)
+
+	t.Run("no playback", func(t *testing.T) {
+		request.Params.Arguments = map[string]any{"text": "Hi", "return_audio": true}
+		_, err := WithWebhook(say.ProviderOpenAI, failing)(context.Background(), request)
+		require.NoError(t, err)
+		select {
+		case event := <-events:
+			t.Fatalf("unexpected webhook event %+v", event)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+}
+
+func TestStreamWebhook(t *testing.T) {
+	useMockPlayer(t)
+	_, receive := useWebhook(t)
+
+	text, isErr := callStreamTool(t, handleSayStreamAppend, map[string]any{"stream_id": "hook", "provider": "openai", "text": "One. Two"})
+	require.False(t, isErr, text)
+	text, isErr = callStreamTool(t, handleSayStreamFlush, map[string]any{"stream_id": "hook"})
+	require.False(t, isErr, text)
+
+	event := receive()
+	assert.Equal(t, "say_stream_append", event.Tool)
+	assert.Equal(t, "hook", event.StreamID)
+	assert.Equal(t, say.ProviderOpenAI, event.Provider)
+	assert.Equal(t, webhookCompleted, event.Status)
+	assert.Len(t, event.UtteranceIDs, 2)
+	assert.InDelta(t, 200, event.DurationMS, 5)
+
+	_, isErr = callStreamTool(t, handleSayStreamAppend, map[string]any{"stream_id": "dropped", "provider": "openai", "text": "One"})
+	require.False(t, isErr)
+	_, isErr = callStreamTool(t, handleSayStreamFlush, map[string]any{"stream_id": "dropped", "discard": true})
+	require.False(t, isErr)
+	assert.Equal(t, webhookCancelled, receive().Status)
+}
@@ -0,0 +1,50 @@
+package say
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/openai/openai-go"
+)
+
+// WordTiming is a spoken word with its approximate position in the audio, in seconds
+type WordTiming struct {
+	Word  string  `json:"word"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// AlignOpenAI transcribes synthesized audio with OpenAI's whisper-1 to get approximate
+// word timestamps, since OpenAI TTS doesn't return any. It is a second billed API call.
+// Empty account fields fall back to the OPENAI_* environment variables.
+func AlignOpenAI(ctx context.Context, account OpenAIAccount, audio *Audio) ([]WordTiming, error) {
+	client, err := account.client()
+	if err != nil {
+		return nil, err
+	}
+
+	filename := "speech.wav"
+	if audio.Encoding == EncodingMP3 {
+		filename = "speech.mp3"
+	}
+	transcription, err := client.Audio.Transcriptions.New(ctx, openai.AudioTranscriptionNewParams{
+		File:                   openai.File(bytes.NewReader(audio.Encoded()), filename, audio.MIMEType()),
+		Model:                  openai.AudioModelWhisper1,
+		ResponseFormat:         openai.AudioResponseFormatVerboseJSON,
+		TimestampGranularities: []string{"word"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to transcribe audio: %v", err)
+	}
+
+	// The SDK only models the plain transcription, word timings are in the verbose JSON
+	var verbose struct {
+		Words []WordTiming `json:"words"`
+	}
+	if err := json.Unmarshal([]byte(transcription.RawJSON()), &verbose); err != nil {
+		return nil, fmt.Errorf("failed to parse transcription: %v", err)
+	}
+	return verbose.Words, nil
+}
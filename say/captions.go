@@ -0,0 +1,109 @@
+package say
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Cue limits, following common subtitle guidelines
+const (
+	// maxCueLineChars is the longest caption line, cues wrap onto a second line
+	maxCueLineChars = 42
+	// maxCueDuration is the longest a cue stays on screen, in seconds
+	maxCueDuration = 5.0
+	// maxCuePause is the longest silence kept inside a cue, in seconds
+	maxCuePause = 1.0
+)
+
+// captionCue is one caption shown from Start to End, in seconds
+type captionCue struct {
+	Start float64
+	End   float64
+	Lines []string
+}
+
+// captionCues groups word timings into cues of at most two lines, starting a new one
+// after a sentence ends, at a long pause or when the cue would stay up too long.
+// SRT and WebVTT share it so both break captions in the same places.
+func captionCues(words []WordTiming) []captionCue {
+	var cues []captionCue
+	var current []WordTiming
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		text := make([]string, len(current))
+		for i, w := range current {
+			text[i] = w.Word
+		}
+		cues = append(cues, captionCue{Start: current[0].Start, End: current[len(current)-1].End, Lines: wrapCaption(text)})
+		current = nil
+	}
+	length := 0
+	for _, w := range words {
+		w.Word = strings.TrimSpace(w.Word)
+		if w.Word == "" {
+			continue
+		}
+		if len(current) > 0 {
+			last := current[len(current)-1]
+			if length+1+len(w.Word) > 2*maxCueLineChars || w.End-current[0].Start > maxCueDuration ||
+				w.Start-last.End > maxCuePause || strings.ContainsAny(last.Word[len(last.Word)-1:], ".!?") {
+				flush()
+				length = 0
+			}
+		}
+		if len(current) > 0 {
+			length++
+		}
+		length += len(w.Word)
+		current = append(current, w)
+	}
+	flush()
+	return cues
+}
+
+// wrapCaption joins words into one line, or two split near the middle when too long
+func wrapCaption(words []string) []string {
+	line := strings.Join(words, " ")
+	if len(line) <= maxCueLineChars || len(words) < 2 {
+		return []string{line}
+	}
+	best, bestDiff := 1, math.MaxInt
+	for i := 1; i < len(words); i++ {
+		first := len(strings.Join(words[:i], " "))
+		if diff := max(first, len(line)-first-1) - min(first, len(line)-first-1); diff < bestDiff {
+			best, bestDiff = i, diff
+		}
+	}
+	return []string{strings.Join(words[:best], " "), strings.Join(words[best:], " ")}
+}
+
+// captionTimestamp formats seconds as hh:mm:ss followed by sep and milliseconds
+func captionTimestamp(seconds float64, sep string) string {
+	ms := int64(math.Round(max(seconds, 0) * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+// FormatSRT formats word timings as SubRip subtitles
+func FormatSRT(words []WordTiming) string {
+	var sb strings.Builder
+	for i, cue := range captionCues(words) {
+		fmt.Fprintf(&sb, "%d\n%s --> %s\n%s\n\n", i+1, captionTimestamp(cue.Start, ","), captionTimestamp(cue.End, ","), strings.Join(cue.Lines, "\n"))
+	}
+	return sb.String()
+}
+
+// vttEscaper escapes the characters WebVTT cue text reserves for markup
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// FormatWebVTT formats word timings as WebVTT captions for HTML5 <track> elements
+func FormatWebVTT(words []WordTiming) string {
+	var sb strings.Builder
+	sb.WriteString("WEBVTT\n\n")
+	for _, cue := range captionCues(words) {
+		fmt.Fprintf(&sb, "%s --> %s\n%s\n\n", captionTimestamp(cue.Start, "."), captionTimestamp(cue.End, "."), vttEscaper.Replace(strings.Join(cue.Lines, "\n")))
+	}
+	return sb.String()
+}
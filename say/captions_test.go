@@ -0,0 +1,44 @@
+package say
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaptionCues(t *testing.T) {
+	words := []WordTiming{
+		{Word: "Hello", Start: 0, End: 0.4},
+		{Word: "there.", Start: 0.45, End: 0.9},
+		{Word: "This", Start: 1.0, End: 1.2},
+		{Word: "continues", Start: 1.25, End: 1.7},
+		{Word: "later", Start: 3.5, End: 3.9},
+	}
+	cues := captionCues(words)
+	assert.Equal(t, []captionCue{
+		{Start: 0, End: 0.9, Lines: []string{"Hello there."}},
+		{Start: 1.0, End: 1.7, Lines: []string{"This continues"}},
+		{Start: 3.5, End: 3.9, Lines: []string{"later"}},
+	}, cues)
+}
+
+func TestWrapCaption(t *testing.T) {
+	assert.Equal(t, []string{"short line"}, wrapCaption([]string{"short", "line"}))
+	lines := wrapCaption([]string{"this", "caption", "is", "long", "enough", "that", "it", "needs", "two", "lines"})
+	assert.Equal(t, []string{"this caption is long enough", "that it needs two lines"}, lines)
+}
+
+func TestFormatCaptions(t *testing.T) {
+	words := []WordTiming{
+		{Word: "Fish", Start: 0.5, End: 0.8},
+		{Word: "&", Start: 0.85, End: 0.9},
+		{Word: "<chips>.", Start: 0.95, End: 1.5},
+		{Word: "Done", Start: 3661.25, End: 3661.75},
+	}
+	assert.Equal(t, "WEBVTT\n\n"+
+		"00:00:00.500 --> 00:00:01.500\nFish &amp; &lt;chips&gt;.\n\n"+
+		"01:01:01.250 --> 01:01:01.750\nDone\n\n", FormatWebVTT(words))
+	assert.Equal(t, "1\n00:00:00,500 --> 00:00:01,500\nFish & <chips>.\n\n"+
+		"2\n01:01:01,250 --> 01:01:01,750\nDone\n\n", FormatSRT(words))
+	assert.Equal(t, "WEBVTT\n\n", FormatWebVTT(nil))
+}
@@ -0,0 +1,82 @@
+package say
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// audioMIMETypes maps the accepted audio media types to their encoding
+var audioMIMETypes = map[string]Encoding{
+	"audio/mpeg":     EncodingMP3,
+	"audio/mp3":      EncodingMP3,
+	"audio/wav":      EncodingWAV,
+	"audio/wave":     EncodingWAV,
+	"audio/x-wav":    EncodingWAV,
+	"audio/vnd.wave": EncodingWAV,
+	"audio/flac":     EncodingFLAC,
+	"audio/x-flac":   EncodingFLAC,
+}
+
+// DecodeAudioData decodes base64 audio passed inline, either as a data URI like
+// "data:audio/mpeg;base64,..." or as raw base64 with its mimeType. Without a mimeType
+// the format is detected from the audio itself. The decoded audio is at most MaxAudioFileSize.
+func DecodeAudioData(data, mimeType string) (*Audio, error) {
+	payload := data
+	if rest, ok := strings.CutPrefix(data, "data:"); ok {
+		header, body, ok := strings.Cut(rest, ",")
+		if !ok || !strings.HasSuffix(header, ";base64") {
+			return nil, fmt.Errorf("data URI must be base64 encoded, e.g. data:audio/mpeg;base64,...")
+		}
+		uriType := strings.TrimSuffix(header, ";base64")
+		if mimeType != "" && uriType != "" && !sameMediaType(mimeType, uriType) {
+			return nil, fmt.Errorf("mime type %q does not match the data URI's %q", mimeType, uriType)
+		}
+		if uriType != "" {
+			mimeType = uriType
+		}
+		payload = body
+	}
+
+	var encoding Encoding
+	if mimeType != "" {
+		mediaType, _, err := mime.ParseMediaType(mimeType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mime type %q: %v", mimeType, err)
+		}
+		var ok bool
+		if encoding, ok = audioMIMETypes[mediaType]; !ok {
+			return nil, fmt.Errorf("unsupported mime type %q (use audio/mpeg, audio/wav or audio/flac)", mediaType)
+		}
+	}
+
+	payload = strings.Join(strings.Fields(payload), "")
+	if n := base64.StdEncoding.DecodedLen(len(payload)); n > MaxAudioFileSize {
+		return nil, fmt.Errorf("audio data too large (about %d bytes, max %d)", n, MaxAudioFileSize)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		if decoded, err = base64.RawStdEncoding.DecodeString(payload); err != nil {
+			return nil, fmt.Errorf("audio data is not valid base64: %v", err)
+		}
+	}
+	if len(decoded) == 0 {
+		return nil, fmt.Errorf("audio data is empty")
+	}
+
+	if encoding == "" {
+		var ok bool
+		if encoding, ok = SniffEncoding(decoded); !ok {
+			return nil, fmt.Errorf("could not detect the audio format, pass the mime type (audio/mpeg, audio/wav or audio/flac)")
+		}
+	}
+	return &Audio{Data: decoded, Encoding: encoding}, nil
+}
+
+// sameMediaType compares the media types of two mime types, ignoring parameters and case
+func sameMediaType(a, b string) bool {
+	a, _, _ = strings.Cut(a, ";")
+	b, _, _ = strings.Cut(b, ";")
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
@@ -0,0 +1,32 @@
+package say
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeAudioData(t *testing.T) {
+	mp3 := []byte("ID3\x04\x00\x00\x00\x00\x00\x00")
+	encoded := base64.StdEncoding.EncodeToString(mp3)
+
+	audio, err := DecodeAudioData("data:audio/mpeg;base64,"+encoded, "")
+	require.NoError(t, err)
+	assert.Equal(t, EncodingMP3, audio.Encoding)
+	assert.Equal(t, mp3, audio.Data)
+
+	// Parameters, unpadded base64 and line breaks are accepted
+	audio, err = DecodeAudioData(strings.TrimRight(encoded[:8]+"\n"+encoded[8:], "="), "audio/mpeg; charset=binary")
+	require.NoError(t, err)
+	assert.Equal(t, mp3, audio.Data)
+
+	_, err = DecodeAudioData("data:audio/mpeg,ID3", "")
+	assert.ErrorContains(t, err, "must be base64 encoded")
+	_, err = DecodeAudioData(base64.StdEncoding.EncodeToString([]byte("hello world")), "")
+	assert.ErrorContains(t, err, "could not detect the audio format")
+	_, err = DecodeAudioData(strings.Repeat("A", MaxAudioFileSize/3*4+8), "audio/mpeg")
+	assert.ErrorContains(t, err, "audio data too large")
+}
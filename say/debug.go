@@ -0,0 +1,93 @@
+package say
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ErrDryRun is the cause a dry run's context is cancelled with once a request is captured
+var ErrDryRun = errors.New("request captured by dry run, not sent")
+
+// redactedHeaders carry credentials and are never shown by a dry run
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Xi-Api-Key", "X-Goog-Api-Key", "Api-Key"}
+
+// CapturedRequest is a provider request recorded by a dry run instead of being sent
+type CapturedRequest struct {
+	Method string
+	URL    string
+	// Header has credentials replaced with "REDACTED"
+	Header http.Header
+	Body   []byte
+}
+
+type dryRunKey struct{}
+
+type dryRun struct {
+	cancel context.CancelCauseFunc
+
+	mu       sync.Mutex
+	requests []CapturedRequest
+}
+
+// WithDryRun returns a context in which provider requests are captured instead of sent.
+// The first captured request cancels the context with ErrDryRun so the call stops there,
+// captured returns what was recorded.
+func WithDryRun(ctx context.Context) (_ context.Context, captured func() []CapturedRequest) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	d := &dryRun{cancel: cancel}
+	return context.WithValue(ctx, dryRunKey{}, d), func() []CapturedRequest {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return d.requests
+	}
+}
+
+// captureDryRun records req if ctx is a dry run, reporting whether it was
+func captureDryRun(ctx context.Context, req CapturedRequest) bool {
+	d, ok := ctx.Value(dryRunKey{}).(*dryRun)
+	if !ok {
+		return false
+	}
+	for _, name := range redactedHeaders {
+		if req.Header.Get(name) != "" {
+			req.Header.Set(name, "REDACTED")
+		}
+	}
+	d.mu.Lock()
+	d.requests = append(d.requests, req)
+	d.mu.Unlock()
+	d.cancel(ErrDryRun)
+	return true
+}
+
+// dryRunTransport captures the requests of a dry run and sends all others
+type dryRunTransport struct {
+	base http.RoundTripper
+}
+
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if _, ok := req.Context().Value(dryRunKey{}).(*dryRun); !ok {
+		return t.base.RoundTrip(req)
+	}
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+	}
+	u := *req.URL
+	if query := u.Query(); query.Has("key") {
+		query.Set("key", "REDACTED")
+		u.RawQuery = query.Encode()
+	}
+	captureDryRun(req.Context(), CapturedRequest{
+		Method: req.Method,
+		URL:    u.String(),
+		Header: req.Header.Clone(),
+		Body:   bytes.TrimSpace(body),
+	})
+	return nil, ErrDryRun
+}
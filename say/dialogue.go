@@ -0,0 +1,33 @@
+package say
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DialogueLine is one line of a dialogue transcript
+type DialogueLine struct {
+	Speaker string
+	Text    string
+}
+
+// ParseDialogue splits a transcript formatted as one "Name: line" per line, blank lines are skipped
+func ParseDialogue(text string) ([]DialogueLine, error) {
+	var lines []DialogueLine
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, spoken, found := strings.Cut(line, ":")
+		name, spoken = strings.TrimSpace(name), strings.TrimSpace(spoken)
+		if !found || name == "" || spoken == "" {
+			return nil, fmt.Errorf("line %d is not in 'Name: line' format: %q", i+1, line)
+		}
+		lines = append(lines, DialogueLine{Speaker: name, Text: spoken})
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("transcript contains no 'Name: line' entries")
+	}
+	return lines, nil
+}
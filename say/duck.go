@@ -0,0 +1,99 @@
+package say
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// duckApps are the macOS media players DuckAudio turns down. Both script "sound volume"
+// from 0 to 100.
+var duckApps = []string{"Music", "Spotify"}
+
+// ErrDuckingUnsupported is returned by DuckAudio where lowering other audio isn't implemented
+var ErrDuckingUnsupported = errors.New("audio ducking is only implemented on macOS")
+
+// DuckingSupported reports whether DuckAudio can lower other audio on this platform
+func DuckingSupported() bool {
+	return runtime.GOOS == "darwin"
+}
+
+// runAppleScript runs an AppleScript with osascript, returning its trimmed output. Swapped out in tests.
+var runAppleScript = func(ctx context.Context, script string) (string, error) {
+	out, err := exec.CommandContext(ctx, "/usr/bin/osascript", "-e", script).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("osascript failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("osascript failed: %v", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// duckScript lowers app to percent of its volume if it is running and returns the volume it had
+func duckScript(app string, percent int) string {
+	return fmt.Sprintf(`if application %[1]q is running then
+	tell application %[1]q
+		set previous to sound volume
+		set sound volume to (previous * %[2]d div 100)
+	end tell
+	return previous
+end if
+return ""`, app, percent)
+}
+
+// restoreScript sets app's volume back if it is still running
+func restoreScript(app string, volume int) string {
+	return fmt.Sprintf(`if application %[1]q is running then tell application %[1]q to set sound volume to %[2]d`, app, volume)
+}
+
+// DuckAudio lowers the volume of the media players that are running (Music and Spotify)
+// to percent of their current volume, e.g. so music doesn't drown out speech. restore
+// sets each one back to the volume it had. Players that fail to duck are skipped.
+func DuckAudio(ctx context.Context, percent int) (restore func(ctx context.Context) error, err error) {
+	if !DuckingSupported() {
+		return nil, ErrDuckingUnsupported
+	}
+	if TestMode() {
+		log.Debug("Test mode, not ducking other audio")
+		return func(ctx context.Context) error { return nil }, nil
+	}
+	previous := make(map[string]int)
+	var errs []error
+	for _, app := range duckApps {
+		out, err := runAppleScript(ctx, duckScript(app, percent))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", app, err))
+			continue
+		}
+		if out == "" {
+			continue
+		}
+		volume, err := strconv.Atoi(out)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: unexpected volume %q", app, out))
+			continue
+		}
+		previous[app] = volume
+		log.Debug("Ducked app audio", "app", app, "volume", volume, "percent", percent)
+	}
+	restore = func(ctx context.Context) error {
+		var errs []error
+		for app, volume := range previous {
+			if _, err := runAppleScript(ctx, restoreScript(app, volume)); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", app, err))
+				continue
+			}
+			log.Debug("Restored app audio", "app", app, "volume", volume)
+		}
+		return errors.Join(errs...)
+	}
+	return restore, errors.Join(errs...)
+}
@@ -0,0 +1,28 @@
+package say
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuckScripts(t *testing.T) {
+	script := duckScript("Spotify", 25)
+	assert.Contains(t, script, `if application "Spotify" is running then`)
+	assert.Contains(t, script, "set sound volume to (previous * 25 div 100)")
+	assert.Contains(t, script, "return previous")
+
+	assert.Equal(t, `if application "Music" is running then tell application "Music" to set sound volume to 80`, restoreScript("Music", 80))
+}
+
+func TestDuckAudioUnsupported(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("ducking is implemented on macOS")
+	}
+	assert.False(t, DuckingSupported())
+	restore, err := DuckAudio(context.Background(), 25)
+	assert.ErrorIs(t, err, ErrDuckingUnsupported)
+	assert.Nil(t, restore)
+}
@@ -0,0 +1,573 @@
+package say
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+)
+
+// elevenLabsAPIBase is the ElevenLabs API root, a variable so tests can point it at a fake server
+var elevenLabsAPIBase = "https://api.elevenlabs.io"
+
+const (
+	DefaultElevenLabsVoiceID = "1SM7GgM6IMuvQlz2BwM3"
+	DefaultElevenLabsModelID = "eleven_multilingual_v2"
+	// DefaultElevenLabsDialogueModelID is the model used for dialogue, the only one the endpoint supports
+	DefaultElevenLabsDialogueModelID = "eleven_v3"
+	// MaxPronunciationDictionaries is the most dictionaries ElevenLabs applies to one request
+	MaxPronunciationDictionaries = 3
+	// MaxStreamingLatencyOptimization is the highest optimize_streaming_latency level
+	MaxStreamingLatencyOptimization = 4
+	// DefaultElevenLabsBitrate is the MP3 bitrate in kbps ElevenLabs returns unless asked otherwise
+	DefaultElevenLabsBitrate = 128
+	// MaxElevenLabsContextLength is the longest previous_text or next_text accepted, in characters
+	MaxElevenLabsContextLength = 5000
+)
+
+// ElevenLabsBitrates are the MP3 bitrates in kbps ElevenLabs can return at 44.1kHz.
+// 192 needs a Creator plan or higher.
+var ElevenLabsBitrates = []int{32, 64, 96, 128, 192}
+
+// elevenLabsOutputFormat returns the output_format for an MP3 bitrate, "" for the API default
+func elevenLabsOutputFormat(bitrate int) (string, error) {
+	if bitrate == 0 {
+		return "", nil
+	}
+	if !slices.Contains(ElevenLabsBitrates, bitrate) {
+		return "", fmt.Errorf("unsupported ElevenLabs bitrate %d kbps, use one of %v", bitrate, ElevenLabsBitrates)
+	}
+	return fmt.Sprintf("mp3_44100_%d", bitrate), nil
+}
+
+// PronunciationDictionary locates a version of an ElevenLabs pronunciation dictionary.
+// An empty VersionID uses the dictionary's latest version.
+type PronunciationDictionary struct {
+	ID        string `json:"pronunciation_dictionary_id"`
+	VersionID string `json:"version_id,omitempty"`
+}
+
+type SynthesisOptions struct {
+	Stability       float64 `json:"stability,omitempty"`
+	SimilarityBoost float64 `json:"similarity_boost,omitempty"`
+	Style           float64 `json:"style,omitempty"`
+	UseSpeakerBoost bool    `json:"use_speaker_boost,omitempty"`
+	// Speed           float64 `json:"speed,omitempty"`
+}
+
+type ElevenLabsParams struct {
+	Text          string           `json:"text"`
+	ModelID       string           `json:"model_id,omitempty"`
+	LanguageCode  string           `json:"language_code,omitempty"`
+	PreviousText  string           `json:"previous_text,omitempty"`
+	NextText      string           `json:"next_text,omitempty"`
+	VoiceSettings SynthesisOptions `json:"voice_settings,omitempty"`
+	Seed          *uint32          `json:"seed,omitempty"`
+
+	PronunciationDictionaryLocators []PronunciationDictionary `json:"pronunciation_dictionary_locators,omitempty"`
+}
+
+// ElevenLabsSpeechParams configures an ElevenLabs speech synthesis request.
+// Empty fields fall back to ELEVENLABS_API_KEY/ELEVENLABS_VOICE_ID/ELEVENLABS_MODEL_ID and then the provider defaults.
+type ElevenLabsSpeechParams struct {
+	APIKey  string
+	Text    string
+	VoiceID string
+	ModelID string
+	// PronunciationDictionaries apply custom lexicons, up to MaxPronunciationDictionaries
+	PronunciationDictionaries []PronunciationDictionary
+	// OptimizeStreamingLatency trades quality for a faster first byte, from 0 (off) to
+	// MaxStreamingLatencyOptimization. Levels 3 and up also disable the text normalizer.
+	OptimizeStreamingLatency int
+	// Seed makes repeated requests sample the same way, nil picks one at random
+	Seed *uint32
+	// Bitrate is the MP3 bitrate in kbps, one of ElevenLabsBitrates. 0 uses DefaultElevenLabsBitrate.
+	Bitrate int
+	// Extra holds raw request body fields for API parameters without a dedicated field,
+	// merged at the top level. Fields the request already sets take precedence.
+	Extra map[string]any
+	// PreviousText and NextText are the text around Text, up to MaxElevenLabsContextLength
+	// characters each, which the model conditions on without speaking it
+	PreviousText string
+	NextText     string
+}
+
+// elevenLabsQuery returns the query string of a speech request, "" when it has no parameters
+func elevenLabsQuery(optimizeLatency int, outputFormat string) string {
+	query := url.Values{}
+	if optimizeLatency > 0 {
+		query.Set("optimize_streaming_latency", strconv.Itoa(optimizeLatency))
+	}
+	if outputFormat != "" {
+		query.Set("output_format", outputFormat)
+	}
+	if len(query) == 0 {
+		return ""
+	}
+	return "?" + query.Encode()
+}
+
+// elevenLabsStreamURL returns the stream endpoint for a voice
+func elevenLabsStreamURL(voiceID string, optimizeLatency int, outputFormat string) string {
+	return fmt.Sprintf("%s/v1/text-to-speech/%s/stream", elevenLabsAPIBase, voiceID) + elevenLabsQuery(optimizeLatency, outputFormat)
+}
+
+// elevenLabsSpeechRequest applies the defaults to params, validates them and builds the
+// request body shared by the stream and timestamps endpoints
+func elevenLabsSpeechRequest(params *ElevenLabsSpeechParams) (string, ElevenLabsParams, error) {
+	apiKey, err := elevenLabsAPIKey(params.APIKey)
+	if err != nil {
+		return "", ElevenLabsParams{}, err
+	}
+	if params.VoiceID == "" {
+		params.VoiceID = os.Getenv("ELEVENLABS_VOICE_ID")
+	}
+	if params.VoiceID == "" {
+		params.VoiceID = DefaultElevenLabsVoiceID
+		log.Debug("Voice not specified, using default", "voiceID", params.VoiceID)
+	}
+	if params.ModelID == "" {
+		params.ModelID = os.Getenv("ELEVENLABS_MODEL_ID")
+	}
+	if params.ModelID == "" {
+		params.ModelID = DefaultElevenLabsModelID // eleven_turbo_v2_5 is also available
+		log.Debug("Model not specified, using default", "modelID", params.ModelID)
+	}
+
+	if len(params.PronunciationDictionaries) > MaxPronunciationDictionaries {
+		return "", ElevenLabsParams{}, fmt.Errorf("too many pronunciation dictionaries (%d, max %d)", len(params.PronunciationDictionaries), MaxPronunciationDictionaries)
+	}
+	if params.OptimizeStreamingLatency < 0 || params.OptimizeStreamingLatency > MaxStreamingLatencyOptimization {
+		return "", ElevenLabsParams{}, fmt.Errorf("optimize_streaming_latency must be between 0 and %d, got %d", MaxStreamingLatencyOptimization, params.OptimizeStreamingLatency)
+	}
+	if _, err := elevenLabsOutputFormat(params.Bitrate); err != nil {
+		return "", ElevenLabsParams{}, err
+	}
+	if err := ValidateElevenLabsContext(params.PreviousText, params.NextText); err != nil {
+		return "", ElevenLabsParams{}, err
+	}
+
+	body := ElevenLabsParams{
+		Text:    params.Text,
+		ModelID: params.ModelID,
+		VoiceSettings: SynthesisOptions{
+			Stability:       0.60,
+			SimilarityBoost: 0.75,
+			Style:           0.50,
+			UseSpeakerBoost: false,
+		},
+		PronunciationDictionaryLocators: params.PronunciationDictionaries,
+		Seed:                            params.Seed,
+		PreviousText:                    params.PreviousText,
+		NextText:                        params.NextText,
+	}
+	return apiKey, body, nil
+}
+
+// ValidateElevenLabsContext checks previous_text and next_text are within MaxElevenLabsContextLength
+func ValidateElevenLabsContext(previous, next string) error {
+	if n := utf8.RuneCountInString(previous); n > MaxElevenLabsContextLength {
+		return fmt.Errorf("previous_text is too long (%d characters, max %d)", n, MaxElevenLabsContextLength)
+	}
+	if n := utf8.RuneCountInString(next); n > MaxElevenLabsContextLength {
+		return fmt.Errorf("next_text is too long (%d characters, max %d)", n, MaxElevenLabsContextLength)
+	}
+	return nil
+}
+
+// elevenLabsDictionaryError points at the pronunciation dictionary when the API rejected it
+func elevenLabsDictionaryError(err error, params ElevenLabsSpeechParams) error {
+	var apiErr *ElevenLabsError
+	if errors.As(err, &apiErr) && len(params.PronunciationDictionaries) > 0 && strings.Contains(strings.ToLower(apiErr.Body), "pronunciation") {
+		return fmt.Errorf("ElevenLabs API error (status %d), check the pronunciation dictionary id and version: %s", apiErr.StatusCode, apiErr.Body)
+	}
+	return err
+}
+
+// StreamElevenLabs requests speech from ElevenLabs and returns the MP3 response body as it streams in.
+// The HTTP status is validated before returning so errors surface with the provider's message.
+func StreamElevenLabs(ctx context.Context, params ElevenLabsSpeechParams) (io.ReadCloser, error) {
+	apiKey, body, err := elevenLabsSpeechRequest(&params)
+	if err != nil {
+		return nil, err
+	}
+	outputFormat, _ := elevenLabsOutputFormat(params.Bitrate)
+	url := elevenLabsStreamURL(params.VoiceID, params.OptimizeStreamingLatency, outputFormat)
+
+	log.Debug("Making ElevenLabs API request",
+		"url", url,
+		"voice", params.VoiceID,
+		"model", params.ModelID,
+		"text", params.Text,
+		"params", body,
+	)
+	request, err := withExtra(ProviderElevenLabs, body, params.Extra)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := postElevenLabs(ctx, apiKey, url, request)
+	if err != nil {
+		return nil, elevenLabsDictionaryError(err, params)
+	}
+	return stream, nil
+}
+
+// elevenLabsAlignment is the character timing ElevenLabs returns alongside the audio
+type elevenLabsAlignment struct {
+	Characters []string  `json:"characters"`
+	Starts     []float64 `json:"character_start_times_seconds"`
+	Ends       []float64 `json:"character_end_times_seconds"`
+}
+
+// words joins the character timings into whitespace separated words
+func (a elevenLabsAlignment) words() []WordTiming {
+	var words []WordTiming
+	var current strings.Builder
+	var start, end float64
+	flush := func() {
+		if current.Len() > 0 {
+			words = append(words, WordTiming{Word: current.String(), Start: start, End: end})
+			current.Reset()
+		}
+	}
+	for i, char := range a.Characters {
+		if i >= len(a.Starts) || i >= len(a.Ends) {
+			break
+		}
+		if strings.TrimSpace(char) == "" {
+			flush()
+			continue
+		}
+		if current.Len() == 0 {
+			start = a.Starts[i]
+		}
+		current.WriteString(char)
+		end = a.Ends[i]
+	}
+	flush()
+	return words
+}
+
+// SynthesizeElevenLabsWithTimestamps requests speech from ElevenLabs' with-timestamps endpoint
+// and returns the MP3 audio with the time each word is spoken, from the provider's own
+// character alignment rather than a transcription
+func SynthesizeElevenLabsWithTimestamps(ctx context.Context, params ElevenLabsSpeechParams) ([]byte, []WordTiming, error) {
+	apiKey, body, err := elevenLabsSpeechRequest(&params)
+	if err != nil {
+		return nil, nil, err
+	}
+	outputFormat, _ := elevenLabsOutputFormat(params.Bitrate)
+	url := fmt.Sprintf("%s/v1/text-to-speech/%s/with-timestamps", elevenLabsAPIBase, params.VoiceID) +
+		elevenLabsQuery(params.OptimizeStreamingLatency, outputFormat)
+
+	request, err := withExtra(ProviderElevenLabs, body, params.Extra)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Debug("Making ElevenLabs timestamps request", "url", url, "voice", params.VoiceID, "model", params.ModelID)
+	res, err := postElevenLabsAccepting(ctx, apiKey, url, "application/json", request)
+	if err != nil {
+		return nil, nil, elevenLabsDictionaryError(err, params)
+	}
+	defer res.Close()
+
+	var response struct {
+		AudioBase64 string              `json:"audio_base64"`
+		Alignment   elevenLabsAlignment `json:"alignment"`
+	}
+	if err := json.NewDecoder(res).Decode(&response); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse ElevenLabs timestamps response: %v", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(response.AudioBase64)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode ElevenLabs audio: %v", err)
+	}
+	return data, response.Alignment.words(), nil
+}
+
+// ElevenLabsError is a non-200 response from the ElevenLabs API
+type ElevenLabsError struct {
+	StatusCode int
+	Status     string
+	// Body is the response body, usually JSON with a detail.status code like voice_not_found
+	Body string
+}
+
+func (e *ElevenLabsError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("ElevenLabs API error: status %d %s", e.StatusCode, e.Status)
+	}
+	return fmt.Sprintf("ElevenLabs API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// elevenLabsAPIKey returns apiKey, falling back to ELEVENLABS_API_KEY
+func elevenLabsAPIKey(apiKey string) (string, error) {
+	if apiKey == "" {
+		apiKey = os.Getenv("ELEVENLABS_API_KEY")
+	}
+	if apiKey == "" {
+		return "", fmt.Errorf("ELEVENLABS_API_KEY is not set")
+	}
+	return apiKey, nil
+}
+
+// postElevenLabs sends a JSON request to an ElevenLabs audio endpoint and returns the
+// MP3 response body as it streams in. The HTTP status is validated before returning,
+// errors are an *ElevenLabsError carrying the provider's message.
+func postElevenLabs(ctx context.Context, apiKey, url string, body any) (io.ReadCloser, error) {
+	return postElevenLabsAccepting(ctx, apiKey, url, "audio/mpeg", body)
+}
+
+// postElevenLabsAccepting is postElevenLabs for endpoints answering with another content type
+func postElevenLabsAccepting(ctx context.Context, apiKey, url, accept string, body any) (io.ReadCloser, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %v", err)
+	}
+
+	attempts := elevenLabsKeyAttempts(apiKey)
+	for attempt := 1; ; attempt++ {
+		key := nextElevenLabsKey(apiKey)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %v", err)
+		}
+
+		addExtraHeaders(req.Header, ProviderElevenLabs)
+		req.Header.Set("xi-api-key", key)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("accept", accept)
+
+		safeLog("Sending HTTP request", req)
+		res, err := HTTPClient().Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %v", err)
+		}
+		if res.StatusCode == http.StatusOK {
+			return res.Body, nil
+		}
+
+		// Read the error response body for more details
+		apiErr := &ElevenLabsError{StatusCode: res.StatusCode, Status: res.Status}
+		if errBody, readErr := io.ReadAll(res.Body); readErr == nil && len(errBody) > 0 {
+			apiErr.Body = string(errBody)
+		}
+		res.Body.Close()
+		if elevenLabsKeyRejected(key, res) && attempt < attempts {
+			continue
+		}
+		log.Error("Request failed", "status", res.Status, "statusCode", res.StatusCode)
+		if apiErr.Body != "" {
+			log.Error("Error response body", "body", apiErr.Body)
+		}
+		return nil, apiErr
+	}
+}
+
+// ElevenLabsDialogueParams configures an ElevenLabs text-to-dialogue request
+type ElevenLabsDialogueParams struct {
+	APIKey string
+	// Text is a transcript formatted as one "Name: line" per line
+	Text string
+	// Voices maps every speaker name in Text to an ElevenLabs voice ID
+	Voices  map[string]string
+	ModelID string
+	// PronunciationDictionaries apply custom lexicons, up to MaxPronunciationDictionaries
+	PronunciationDictionaries []PronunciationDictionary
+	// Seed makes repeated requests sample the same way, nil picks one at random
+	Seed *uint32
+	// Bitrate is the MP3 bitrate in kbps, see ElevenLabsSpeechParams
+	Bitrate int
+	// Extra holds raw request body fields, see ElevenLabsSpeechParams
+	Extra map[string]any
+}
+
+type elevenLabsDialogueInput struct {
+	Text    string `json:"text"`
+	VoiceID string `json:"voice_id"`
+}
+
+type elevenLabsDialogueRequest struct {
+	Inputs  []elevenLabsDialogueInput `json:"inputs"`
+	ModelID string                    `json:"model_id,omitempty"`
+	Seed    *uint32                   `json:"seed,omitempty"`
+
+	PronunciationDictionaryLocators []PronunciationDictionary `json:"pronunciation_dictionary_locators,omitempty"`
+}
+
+// elevenLabsDialogueInputs turns a transcript into dialogue inputs, every speaker needs a voice
+func elevenLabsDialogueInputs(text string, voices map[string]string) ([]elevenLabsDialogueInput, error) {
+	lines, err := ParseDialogue(text)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	inputs := make([]elevenLabsDialogueInput, 0, len(lines))
+	for _, line := range lines {
+		voice, ok := voices[line.Speaker]
+		if !ok {
+			if !slices.Contains(missing, line.Speaker) {
+				missing = append(missing, line.Speaker)
+			}
+			continue
+		}
+		inputs = append(inputs, elevenLabsDialogueInput{Text: line.Text, VoiceID: voice})
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no voice mapping for speaker(s): %s", strings.Join(missing, ", "))
+	}
+	return inputs, nil
+}
+
+// ValidateElevenLabsDialogue checks that text is a "Name: line" transcript whose speakers all have a voice
+func ValidateElevenLabsDialogue(text string, voices map[string]string) error {
+	_, err := elevenLabsDialogueInputs(text, voices)
+	return err
+}
+
+// StreamElevenLabsDialogue requests a multi-voice dialogue from ElevenLabs and returns the
+// MP3 response body as it streams in
+func StreamElevenLabsDialogue(ctx context.Context, params ElevenLabsDialogueParams) (io.ReadCloser, error) {
+	apiKey, err := elevenLabsAPIKey(params.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	inputs, err := elevenLabsDialogueInputs(params.Text, params.Voices)
+	if err != nil {
+		return nil, err
+	}
+	if len(params.PronunciationDictionaries) > MaxPronunciationDictionaries {
+		return nil, fmt.Errorf("too many pronunciation dictionaries (%d, max %d)", len(params.PronunciationDictionaries), MaxPronunciationDictionaries)
+	}
+	outputFormat, err := elevenLabsOutputFormat(params.Bitrate)
+	if err != nil {
+		return nil, err
+	}
+	body := elevenLabsDialogueRequest{
+		Inputs:                          inputs,
+		ModelID:                         cmp.Or(params.ModelID, DefaultElevenLabsDialogueModelID),
+		Seed:                            params.Seed,
+		PronunciationDictionaryLocators: params.PronunciationDictionaries,
+	}
+	request, err := withExtra(ProviderElevenLabs, body, params.Extra)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug("Making ElevenLabs dialogue request", "model", body.ModelID, "lines", len(inputs))
+	return postElevenLabs(ctx, apiKey, elevenLabsAPIBase+"/v1/text-to-dialogue/stream"+elevenLabsQuery(0, outputFormat), request)
+}
+
+// SynthesizeElevenLabs requests speech from ElevenLabs and returns the MP3 audio bytes without playing them
+func SynthesizeElevenLabs(ctx context.Context, params ElevenLabsSpeechParams) ([]byte, error) {
+	body, err := StreamElevenLabs(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// safeLog logs a request with the API key masked
+func safeLog(message string, req *http.Request) {
+	reqCopy := req.Clone(context.Background())
+	if _, exists := reqCopy.Header["Xi-Api-Key"]; exists {
+		reqCopy.Header["Xi-Api-Key"] = []string{"******"} // Mask password
+	}
+	log.With(reqCopy).Debug(message)
+}
+
+// ElevenLabs is the ElevenLabs provider
+type ElevenLabs struct {
+	APIKey string
+	// PronunciationDictionaries apply custom lexicons to every request
+	PronunciationDictionaries []PronunciationDictionary
+	// OptimizeStreamingLatency is passed to every request, see ElevenLabsSpeechParams
+	OptimizeStreamingLatency int
+	// Seed is passed to every request, see ElevenLabsSpeechParams
+	Seed *uint32
+	// Bitrate is passed to every request, see ElevenLabsSpeechParams
+	Bitrate int
+	// Extra is merged into every request body, see ElevenLabsSpeechParams
+	Extra map[string]any
+	// Voices switches to the text-to-dialogue endpoint, mapping the speaker names of a
+	// "Name: line" transcript to voice IDs
+	Voices map[string]string
+	// WordTimings switches Synthesize to the with-timestamps endpoint and receives the
+	// time each word is spoken. It is not used for dialogue or streaming.
+	WordTimings func([]WordTiming)
+}
+
+// NewElevenLabs returns an ElevenLabs provider, an empty key falls back to ELEVENLABS_API_KEY
+func NewElevenLabs(apiKey string) *ElevenLabs {
+	return &ElevenLabs{APIKey: apiKey}
+}
+
+func (p *ElevenLabs) params(opts Options) ElevenLabsSpeechParams {
+	return ElevenLabsSpeechParams{
+		APIKey:  p.APIKey,
+		Text:    opts.Text,
+		VoiceID: opts.Voice,
+		ModelID: opts.Model,
+
+		PronunciationDictionaries: p.PronunciationDictionaries,
+		OptimizeStreamingLatency:  p.OptimizeStreamingLatency,
+		Seed:                      p.Seed,
+		Bitrate:                   p.Bitrate,
+		Extra:                     p.Extra,
+		PreviousText:              opts.PreviousText,
+		NextText:                  opts.NextText,
+	}
+}
+
+// Stream implements StreamingProvider
+func (p *ElevenLabs) Stream(ctx context.Context, opts Options) (io.ReadCloser, error) {
+	if len(p.Voices) > 0 {
+		return StreamElevenLabsDialogue(ctx, ElevenLabsDialogueParams{
+			APIKey:  p.APIKey,
+			Text:    opts.Text,
+			Voices:  p.Voices,
+			ModelID: opts.Model,
+			Seed:    p.Seed,
+			Bitrate: p.Bitrate,
+			Extra:   p.Extra,
+
+			PronunciationDictionaries: p.PronunciationDictionaries,
+		})
+	}
+	return StreamElevenLabs(ctx, p.params(opts))
+}
+
+// Synthesize implements Provider
+func (p *ElevenLabs) Synthesize(ctx context.Context, opts Options) (*Audio, error) {
+	if p.WordTimings != nil && len(p.Voices) == 0 {
+		data, words, err := SynthesizeElevenLabsWithTimestamps(ctx, p.params(opts))
+		if err != nil {
+			return nil, err
+		}
+		p.WordTimings(words)
+		return &Audio{Data: data, Encoding: sniffEncoding(data, EncodingMP3)}, nil
+	}
+	body, err := p.Stream(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return &Audio{Data: data, Encoding: sniffEncoding(data, EncodingMP3)}, nil
+}
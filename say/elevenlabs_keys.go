@@ -0,0 +1,108 @@
+package say
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+const (
+	// ElevenLabsRateLimitCooldown is how long a key that got a 429 is skipped, unless the response says otherwise
+	ElevenLabsRateLimitCooldown = time.Minute
+	// ElevenLabsAuthCooldown is how long a key that got a 401 is skipped
+	ElevenLabsAuthCooldown = 10 * time.Minute
+)
+
+// elevenLabsKey is one key of the rotation set with SetElevenLabsKeys
+type elevenLabsKey struct {
+	key       string
+	coolUntil time.Time
+}
+
+var (
+	elevenLabsKeysMu sync.Mutex
+	elevenLabsKeys   []*elevenLabsKey // nil unless several keys are set
+	elevenLabsNext   int
+)
+
+// SetElevenLabsKeys spreads ElevenLabs requests over several API keys, round-robin. A request
+// made with any of the keys is sent with the next one that isn't cooling down, and a key
+// answered with 401 or 429 is skipped for a while, the request moving on to the next key.
+// With fewer than two keys requests use the key they are given.
+func SetElevenLabsKeys(keys []string) {
+	elevenLabsKeysMu.Lock()
+	defer elevenLabsKeysMu.Unlock()
+	elevenLabsKeys, elevenLabsNext = nil, 0
+	if len(keys) < 2 {
+		return
+	}
+	for _, key := range keys {
+		elevenLabsKeys = append(elevenLabsKeys, &elevenLabsKey{key: key})
+	}
+}
+
+// elevenLabsKeyAttempts returns how many keys a request made with apiKey may try
+func elevenLabsKeyAttempts(apiKey string) int {
+	elevenLabsKeysMu.Lock()
+	defer elevenLabsKeysMu.Unlock()
+	if !slices.ContainsFunc(elevenLabsKeys, func(k *elevenLabsKey) bool { return k.key == apiKey }) {
+		return 1
+	}
+	return len(elevenLabsKeys)
+}
+
+// nextElevenLabsKey returns the key a request made with apiKey is sent with. When every
+// key is cooling down, the one that recovers first is used anyway.
+func nextElevenLabsKey(apiKey string) string {
+	elevenLabsKeysMu.Lock()
+	defer elevenLabsKeysMu.Unlock()
+	if !slices.ContainsFunc(elevenLabsKeys, func(k *elevenLabsKey) bool { return k.key == apiKey }) {
+		return apiKey
+	}
+	now := time.Now()
+	pick := -1
+	for i := range elevenLabsKeys {
+		n := (elevenLabsNext + i) % len(elevenLabsKeys)
+		if !now.Before(elevenLabsKeys[n].coolUntil) {
+			pick = n
+			break
+		}
+		if pick < 0 || elevenLabsKeys[n].coolUntil.Before(elevenLabsKeys[pick].coolUntil) {
+			pick = n
+		}
+	}
+	elevenLabsNext = (pick + 1) % len(elevenLabsKeys)
+	log.Debug("Using ElevenLabs API key", "key", pick+1, "of", len(elevenLabsKeys))
+	return elevenLabsKeys[pick].key
+}
+
+// elevenLabsKeyRejected puts key on cooldown when res is a 401 or 429 and key is part of
+// the rotation, and reports whether it did so the request can move on to the next key
+func elevenLabsKeyRejected(key string, res *http.Response) bool {
+	var cooldown time.Duration
+	switch res.StatusCode {
+	case http.StatusUnauthorized:
+		cooldown = ElevenLabsAuthCooldown
+	case http.StatusTooManyRequests:
+		cooldown = ElevenLabsRateLimitCooldown
+		if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			cooldown = time.Duration(seconds) * time.Second
+		}
+	default:
+		return false
+	}
+	elevenLabsKeysMu.Lock()
+	defer elevenLabsKeysMu.Unlock()
+	for i, k := range elevenLabsKeys {
+		if k.key == key {
+			k.coolUntil = time.Now().Add(cooldown)
+			log.Warn("ElevenLabs rejected an API key, skipping it", "key", i+1, "of", len(elevenLabsKeys), "status", res.StatusCode, "cooldown", cooldown)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package say
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+)
+
+const (
+	// MinSoundEffectDuration and MaxSoundEffectDuration bound a sound effect's length in seconds
+	MinSoundEffectDuration = 0.5
+	MaxSoundEffectDuration = 30.0
+	// MaxSoundEffectPromptLength is the longest sound effect description accepted, in characters
+	MaxSoundEffectPromptLength = 1000
+)
+
+// ElevenLabsSoundParams configures an ElevenLabs sound generation request
+type ElevenLabsSoundParams struct {
+	APIKey string
+	// Prompt describes the sound, e.g. "glass shattering on a tile floor"
+	Prompt string
+	// Duration in seconds, 0 lets ElevenLabs pick a length that fits the prompt
+	Duration float64
+}
+
+type elevenLabsSoundRequest struct {
+	Text     string  `json:"text"`
+	Duration float64 `json:"duration_seconds,omitempty"`
+}
+
+// ValidateSoundEffect checks a sound effect prompt and duration against the API limits
+func ValidateSoundEffect(prompt string, duration float64) error {
+	if prompt == "" {
+		return fmt.Errorf("prompt must not be empty")
+	}
+	if n := utf8.RuneCountInString(prompt); n > MaxSoundEffectPromptLength {
+		return fmt.Errorf("prompt is too long (%d characters, max %d)", n, MaxSoundEffectPromptLength)
+	}
+	if duration != 0 && (duration < MinSoundEffectDuration || duration > MaxSoundEffectDuration) {
+		return fmt.Errorf("duration must be between %g and %g seconds, got %g", MinSoundEffectDuration, MaxSoundEffectDuration, duration)
+	}
+	return nil
+}
+
+// StreamElevenLabsSound generates a sound effect with ElevenLabs and returns the MP3 response body
+func StreamElevenLabsSound(ctx context.Context, params ElevenLabsSoundParams) (io.ReadCloser, error) {
+	apiKey, err := elevenLabsAPIKey(params.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateSoundEffect(params.Prompt, params.Duration); err != nil {
+		return nil, err
+	}
+	log.Debug("Making ElevenLabs sound generation request", "prompt", params.Prompt, "duration", params.Duration)
+	return postElevenLabs(ctx, apiKey, elevenLabsAPIBase+"/v1/sound-generation", elevenLabsSoundRequest{
+		Text:     params.Prompt,
+		Duration: params.Duration,
+	})
+}
+
+// ElevenLabsSoundEffect generates sound effects instead of speech, opts.Text is the prompt
+type ElevenLabsSoundEffect struct {
+	APIKey string
+	// Duration in seconds, 0 lets ElevenLabs pick
+	Duration float64
+}
+
+// Stream implements StreamingProvider
+func (p *ElevenLabsSoundEffect) Stream(ctx context.Context, opts Options) (io.ReadCloser, error) {
+	return StreamElevenLabsSound(ctx, ElevenLabsSoundParams{APIKey: p.APIKey, Prompt: opts.Text, Duration: p.Duration})
+}
+
+// Synthesize implements Provider
+func (p *ElevenLabsSoundEffect) Synthesize(ctx context.Context, opts Options) (*Audio, error) {
+	body, err := p.Stream(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return &Audio{Data: data, Encoding: sniffEncoding(data, EncodingMP3)}, nil
+}
@@ -0,0 +1,235 @@
+package say
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeElevenLabs points the ElevenLabs client at a server recording each request
+func fakeElevenLabs(t *testing.T) (paths *[]string, bodies *[]map[string]any) {
+	paths, bodies = &[]string{}, &[]map[string]any{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		*paths = append(*paths, r.URL.Path)
+		*bodies = append(*bodies, body)
+		w.Write([]byte("ID3"))
+	}))
+	t.Cleanup(server.Close)
+	prev := elevenLabsAPIBase
+	elevenLabsAPIBase = server.URL
+	t.Cleanup(func() { elevenLabsAPIBase = prev })
+	return paths, bodies
+}
+
+func TestElevenLabsDialogue(t *testing.T) {
+	paths, bodies := fakeElevenLabs(t)
+	seed := uint32(4294967295)
+	provider := &ElevenLabs{APIKey: "test-key", Voices: map[string]string{"Joe": "voice-joe", "Jane": "voice-jane"}, Seed: &seed}
+
+	audio, err := provider.Synthesize(context.Background(), Options{Text: "Joe: Hi Jane.\n\nJane: Hi Joe!\nJoe: Bye."})
+	require.NoError(t, err)
+	assert.Equal(t, "ID3", string(audio.Data))
+	assert.Equal(t, []string{"/v1/text-to-dialogue/stream"}, *paths)
+	assert.Equal(t, DefaultElevenLabsDialogueModelID, (*bodies)[0]["model_id"])
+	assert.Equal(t, float64(4294967295), (*bodies)[0]["seed"])
+	assert.Equal(t, []any{
+		map[string]any{"text": "Hi Jane.", "voice_id": "voice-joe"},
+		map[string]any{"text": "Hi Joe!", "voice_id": "voice-jane"},
+		map[string]any{"text": "Bye.", "voice_id": "voice-joe"},
+	}, (*bodies)[0]["inputs"])
+
+	err = ValidateElevenLabsDialogue("Joe: Hi\nBob: Hey\nAl: Yo\nBob: Hm", map[string]string{"Joe": "voice-joe"})
+	assert.EqualError(t, err, "no voice mapping for speaker(s): Bob, Al")
+	assert.Error(t, ValidateElevenLabsDialogue("Hi there", map[string]string{"Joe": "voice-joe"}))
+}
+
+func TestElevenLabsSeed(t *testing.T) {
+	_, bodies := fakeElevenLabs(t)
+	provider := &ElevenLabs{APIKey: "test-key"}
+
+	_, err := provider.Synthesize(context.Background(), Options{Text: "Hello"})
+	require.NoError(t, err)
+	assert.NotContains(t, (*bodies)[0], "seed", "omitted unless set")
+
+	seed := uint32(0)
+	provider.Seed = &seed
+	_, err = provider.Synthesize(context.Background(), Options{Text: "Hello"})
+	require.NoError(t, err)
+	assert.Equal(t, float64(0), (*bodies)[1]["seed"])
+}
+
+func TestElevenLabsContext(t *testing.T) {
+	_, bodies := fakeElevenLabs(t)
+	provider := &ElevenLabs{APIKey: "test-key"}
+
+	_, err := provider.Synthesize(context.Background(), Options{Text: "Two."})
+	require.NoError(t, err)
+	assert.NotContains(t, (*bodies)[0], "previous_text", "omitted unless set")
+	assert.NotContains(t, (*bodies)[0], "next_text", "omitted unless set")
+
+	_, err = provider.Synthesize(context.Background(), Options{Text: "Two.", PreviousText: "One.", NextText: "Three."})
+	require.NoError(t, err)
+	assert.Equal(t, "One.", (*bodies)[1]["previous_text"])
+	assert.Equal(t, "Three.", (*bodies)[1]["next_text"])
+
+	_, err = provider.Synthesize(context.Background(), Options{Text: "Two.", PreviousText: strings.Repeat("a", MaxElevenLabsContextLength+1)})
+	assert.ErrorContains(t, err, "previous_text is too long")
+	assert.Len(t, *bodies, 2, "not sent")
+}
+
+func TestElevenLabsSoundEffect(t *testing.T) {
+	paths, bodies := fakeElevenLabs(t)
+	provider := &ElevenLabsSoundEffect{APIKey: "test-key", Duration: 2.5}
+
+	body, err := provider.Stream(context.Background(), Options{Text: "thunder rolling"})
+	require.NoError(t, err)
+	data, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "ID3", string(data))
+	assert.Equal(t, []string{"/v1/sound-generation"}, *paths)
+	assert.Equal(t, map[string]any{"text": "thunder rolling", "duration_seconds": 2.5}, (*bodies)[0])
+
+	// Without a duration ElevenLabs picks one
+	_, err = (&ElevenLabsSoundEffect{APIKey: "test-key"}).Synthesize(context.Background(), Options{Text: "a bell"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"text": "a bell"}, (*bodies)[1])
+}
+
+func TestValidateSoundEffect(t *testing.T) {
+	assert.NoError(t, ValidateSoundEffect("rain", 0))
+	assert.NoError(t, ValidateSoundEffect("rain", MaxSoundEffectDuration))
+	assert.ErrorContains(t, ValidateSoundEffect("", 0), "prompt must not be empty")
+	assert.ErrorContains(t, ValidateSoundEffect(strings.Repeat("a", MaxSoundEffectPromptLength+1), 0), "prompt is too long")
+	assert.ErrorContains(t, ValidateSoundEffect("rain", 0.1), "duration must be between")
+	assert.ErrorContains(t, ValidateSoundEffect("rain", MaxSoundEffectDuration+1), "duration must be between")
+}
+
+func TestElevenLabsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"detail":"quota exceeded"}`, http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	prev := elevenLabsAPIBase
+	elevenLabsAPIBase = server.URL
+	t.Cleanup(func() { elevenLabsAPIBase = prev })
+
+	_, err := SynthesizeElevenLabs(context.Background(), ElevenLabsSpeechParams{APIKey: "test-key", Text: "Hello"})
+	assert.ErrorContains(t, err, `ElevenLabs API error (status 429): {"detail":"quota exceeded"}`)
+}
+
+func TestListElevenLabsVoices(t *testing.T) {
+	prevDelay := elevenLabsRetryDelay
+	elevenLabsRetryDelay = time.Millisecond
+	t.Cleanup(func() { elevenLabsRetryDelay = prevDelay })
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/v1/voices", r.URL.Path)
+		assert.Equal(t, "test-key", r.Header.Get("xi-api-key"))
+		if requests == 1 {
+			http.Error(w, `{"detail":"too_many_requests"}`, http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"voices":[{"voice_id":"abc","name":"Rachel","category":"premade","labels":{"accent":"american"}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	prev := elevenLabsAPIBase
+	elevenLabsAPIBase = server.URL
+	t.Cleanup(func() { elevenLabsAPIBase = prev })
+
+	voices, err := ListElevenLabsVoices(context.Background(), "test-key")
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, []ElevenLabsVoice{{ID: "abc", Name: "Rachel", Category: "premade", Labels: map[string]string{"accent": "american"}}}, voices)
+
+	// Client errors are not retried
+	requests = 0
+	_, err = ListElevenLabsVoices(context.Background(), "")
+	assert.EqualError(t, err, "ELEVENLABS_API_KEY is not set")
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, `{"detail":"invalid_api_key"}`, http.StatusUnauthorized)
+	})
+	_, err = ListElevenLabsVoices(context.Background(), "test-key")
+	assert.ErrorContains(t, err, "status 401")
+	assert.Equal(t, 1, requests)
+}
+
+func TestElevenLabsWithTimestamps(t *testing.T) {
+	var path, accept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, accept = r.URL.Path, r.Header.Get("Accept")
+		w.Write([]byte(`{"audio_base64":"SUQz","alignment":{"characters":["H","i"," ","y","o","u"],` +
+			`"character_start_times_seconds":[0,0.1,0.2,0.3,0.4,0.5],"character_end_times_seconds":[0.1,0.2,0.3,0.4,0.5,0.6]}}`))
+	}))
+	t.Cleanup(server.Close)
+	prev := elevenLabsAPIBase
+	elevenLabsAPIBase = server.URL
+	t.Cleanup(func() { elevenLabsAPIBase = prev })
+
+	var words []WordTiming
+	provider := &ElevenLabs{APIKey: "test-key", WordTimings: func(w []WordTiming) { words = w }}
+	audio, err := provider.Synthesize(context.Background(), Options{Text: "Hi you", Voice: "voice-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "ID3", string(audio.Data))
+	assert.Equal(t, "/v1/text-to-speech/voice-1/with-timestamps", path)
+	assert.Equal(t, "application/json", accept)
+	assert.Equal(t, []WordTiming{{Word: "Hi", Start: 0, End: 0.2}, {Word: "you", Start: 0.3, End: 0.6}}, words)
+}
+
+func TestElevenLabsKeyRotation(t *testing.T) {
+	var mu sync.Mutex
+	var used []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("xi-api-key")
+		mu.Lock()
+		used = append(used, key)
+		mu.Unlock()
+		switch key {
+		case "key-a":
+			http.Error(w, `{"detail":"quota exceeded"}`, http.StatusTooManyRequests)
+		case "key-c":
+			http.Error(w, `{"detail":"invalid key"}`, http.StatusUnauthorized)
+		default:
+			w.Write([]byte("ID3 mp3"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	prev := elevenLabsAPIBase
+	elevenLabsAPIBase = server.URL
+	t.Cleanup(func() { elevenLabsAPIBase = prev })
+	SetElevenLabsKeys([]string{"key-a", "key-b", "key-c"})
+	t.Cleanup(func() { SetElevenLabsKeys(nil) })
+
+	speak := func(apiKey string) error {
+		_, err := SynthesizeElevenLabs(context.Background(), ElevenLabsSpeechParams{APIKey: apiKey, Text: "Hello"})
+		return err
+	}
+	// key-a is rate limited, the request moves on to key-b
+	require.NoError(t, speak("key-a"))
+	// Round-robin continues with key-c, which is rejected, then skips key-a while it cools down
+	require.NoError(t, speak("key-a"))
+	require.NoError(t, speak("key-a"))
+	assert.Equal(t, []string{"key-a", "key-b", "key-c", "key-b", "key-b"}, used)
+
+	// A key outside the rotation is used as is
+	used = nil
+	require.NoError(t, speak("other-key"))
+	assert.Equal(t, []string{"other-key"}, used)
+
+	// When every key is cooling down the request still fails with the provider's error
+	SetElevenLabsKeys([]string{"key-a", "key-c"})
+	assert.ErrorContains(t, speak("key-a"), "status 401")
+}
@@ -0,0 +1,98 @@
+package say
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// Attempts made to fetch the voices list when ElevenLabs rate limits or fails
+const elevenLabsVoicesAttempts = 3
+
+// elevenLabsRetryDelay is the wait before the first retry, doubled for each one after
+var elevenLabsRetryDelay = 500 * time.Millisecond
+
+// ElevenLabsVoice is a voice available to the ElevenLabs account
+type ElevenLabsVoice struct {
+	ID          string            `json:"voice_id"`
+	Name        string            `json:"name"`
+	Category    string            `json:"category"`
+	Description string            `json:"description"`
+	Labels      map[string]string `json:"labels"`
+	PreviewURL  string            `json:"preview_url"`
+}
+
+// ListElevenLabsVoices fetches the voices available to the account, retrying rate
+// limited and failed requests with backoff
+func ListElevenLabsVoices(ctx context.Context, apiKey string) ([]ElevenLabsVoice, error) {
+	apiKey, err := elevenLabsAPIKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	delay := elevenLabsRetryDelay
+	for attempt := 1; ; attempt++ {
+		voices, retryAfter, err := getElevenLabsVoices(ctx, apiKey)
+		if err == nil || retryAfter < 0 || attempt == elevenLabsVoicesAttempts {
+			return voices, err
+		}
+		wait := max(delay, retryAfter)
+		log.Warn("Fetching ElevenLabs voices failed, retrying", "attempt", attempt, "wait", wait, "error", err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		delay *= 2
+	}
+}
+
+// getElevenLabsVoices makes a single voices request. retryAfter is negative when the
+// error is not worth retrying, otherwise the wait the server asked for, if any.
+func getElevenLabsVoices(ctx context.Context, apiKey string) (voices []ElevenLabsVoice, retryAfter time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, elevenLabsAPIBase+"/v1/voices", nil)
+	if err != nil {
+		return nil, -1, fmt.Errorf("failed to create request: %v", err)
+	}
+	addExtraHeaders(req.Header, ProviderElevenLabs)
+	key := nextElevenLabsKey(apiKey)
+	req.Header.Set("xi-api-key", key)
+	safeLog("Sending HTTP request", req)
+	res, err := HTTPClient().Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, -1, ctx.Err()
+		}
+		return nil, 0, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		apiErr := &ElevenLabsError{StatusCode: res.StatusCode, Status: res.Status}
+		if body, readErr := io.ReadAll(res.Body); readErr == nil {
+			apiErr.Body = string(body)
+		}
+		if elevenLabsKeyRejected(key, res) {
+			// Retry right away, the next attempt uses another key
+			return nil, 0, apiErr
+		}
+		if res.StatusCode != http.StatusTooManyRequests && res.StatusCode < 500 {
+			return nil, -1, apiErr
+		}
+		seconds, _ := strconv.Atoi(res.Header.Get("Retry-After"))
+		return nil, time.Duration(min(seconds, 30)) * time.Second, apiErr
+	}
+
+	var body struct {
+		Voices []ElevenLabsVoice `json:"voices"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, -1, fmt.Errorf("failed to decode voices: %v", err)
+	}
+	return body.Voices, 0, nil
+}
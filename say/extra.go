@@ -0,0 +1,92 @@
+package say
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+
+	"github.com/charmbracelet/log"
+)
+
+// extraKey matches the top-level request fields Extra may set. Paths like "a.b" are left
+// out, the OpenAI client would read them as nested fields.
+var extraKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateExtra checks that extra only sets top-level fields and encodes as a JSON object
+func ValidateExtra(extra map[string]any) error {
+	for key := range extra {
+		if !extraKey.MatchString(key) {
+			return fmt.Errorf("extra field %q must be a top-level request field name", key)
+		}
+	}
+	data, err := json.Marshal(extra)
+	if err != nil {
+		return fmt.Errorf("extra request fields are not valid JSON: %v", err)
+	}
+	if !json.Valid(data) {
+		return fmt.Errorf("extra request fields are not valid JSON")
+	}
+	return nil
+}
+
+// mergeExtra adds the top-level fields of extra that body doesn't set, so overrides reach
+// provider parameters this package doesn't wrap without replacing the ones it does.
+// It returns the keys applied and those ignored because body already sets them.
+func mergeExtra(provider string, body, extra map[string]any) []string {
+	var applied, ignored []string
+	for _, key := range slices.Sorted(maps.Keys(extra)) {
+		if _, ok := body[key]; ok {
+			ignored = append(ignored, key)
+			continue
+		}
+		body[key] = extra[key]
+		applied = append(applied, key)
+	}
+	if len(ignored) > 0 {
+		log.Warn("Ignored extra request fields the request already sets", "provider", provider, "fields", ignored)
+	}
+	if len(applied) > 0 {
+		log.Info("Applied extra request fields", "provider", provider, "fields", applied)
+	}
+	return applied
+}
+
+// jsonFields returns the fields of a request body as it is sent
+func jsonFields(body any) (map[string]any, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// Keep integers like seeds exact instead of turning them into float64
+	decoder.UseNumber()
+	var fields map[string]any
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// withExtra returns the JSON request body with extra merged in, see mergeExtra.
+// body is returned unchanged when there is nothing to merge.
+func withExtra(provider string, body any, extra map[string]any) (any, error) {
+	if len(extra) == 0 {
+		return body, nil
+	}
+	if err := ValidateExtra(extra); err != nil {
+		return nil, err
+	}
+	fields, err := jsonFields(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %v", err)
+	}
+	mergeExtra(provider, fields, extra)
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("extra request fields are not valid JSON: %v", err)
+	}
+	return json.RawMessage(merged), nil
+}
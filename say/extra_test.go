@@ -0,0 +1,40 @@
+package say
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithExtra(t *testing.T) {
+	seed := uint32(4294967295)
+	body := ElevenLabsParams{Text: "Hello", ModelID: "eleven_multilingual_v2", Seed: &seed}
+
+	unchanged, err := withExtra(ProviderElevenLabs, body, nil)
+	require.NoError(t, err)
+	assert.Equal(t, body, unchanged)
+
+	merged, err := withExtra(ProviderElevenLabs, body, map[string]any{
+		"apply_text_normalization": "off",
+		"text":                     "Goodbye",
+	})
+	require.NoError(t, err)
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(merged.(json.RawMessage), &fields))
+	assert.Equal(t, "off", fields["apply_text_normalization"])
+	assert.Equal(t, "Hello", fields["text"], "the request's own fields take precedence")
+	assert.Contains(t, string(merged.(json.RawMessage)), `"seed":4294967295`)
+
+	_, err = withExtra(ProviderElevenLabs, body, map[string]any{"voice_settings.style": 1.0})
+	assert.ErrorContains(t, err, "top-level")
+}
+
+func TestValidateExtra(t *testing.T) {
+	assert.NoError(t, ValidateExtra(nil))
+	assert.NoError(t, ValidateExtra(map[string]any{"stream_format": "audio", "_x1": []any{1.0, "a"}}))
+	assert.Error(t, ValidateExtra(map[string]any{"1st": true}))
+	assert.Error(t, ValidateExtra(map[string]any{"a*": true}))
+	assert.ErrorContains(t, ValidateExtra(map[string]any{"fn": func() {}}), "not valid JSON")
+}
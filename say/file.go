@@ -0,0 +1,44 @@
+package say
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxAudioFileSize is the largest file ReadAudioFile accepts
+const MaxAudioFileSize = 50 << 20
+
+// audioFileEncodings maps supported file extensions to their encoding
+var audioFileEncodings = map[string]Encoding{
+	".mp3":  EncodingMP3,
+	".wav":  EncodingWAV,
+	".flac": EncodingFLAC,
+}
+
+// ReadAudioFile reads an MP3, WAV or FLAC file, detecting the format from its extension
+func ReadAudioFile(path string) (*Audio, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	encoding, ok := audioFileEncodings[ext]
+	if !ok {
+		return nil, fmt.Errorf("unsupported audio format %q (use .mp3, .wav or .flac)", ext)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio file: %v", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("failed to read audio file: %s is a directory", path)
+	}
+	if info.Size() > MaxAudioFileSize {
+		return nil, fmt.Errorf("audio file too large (%d bytes, max %d)", info.Size(), MaxAudioFileSize)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio file: %v", err)
+	}
+	return &Audio{Data: data, Encoding: encoding}, nil
+}
@@ -0,0 +1,339 @@
+package say
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gopxl/beep/v2"
+	"google.golang.org/genai"
+)
+
+const (
+	DefaultGoogleVoice = "Kore"
+	DefaultGoogleModel = "gemini-2.5-flash-preview-tts"
+	// Google TTS returns 24kHz 16-bit mono PCM
+	GoogleSampleRate = beep.SampleRate(24000)
+
+	GoogleDefaultSpeakingRate = 1.0
+	GoogleMinSpeakingRate     = 0.25
+	GoogleMaxSpeakingRate     = 4.0
+	GoogleDefaultPitch        = 0.0
+	GoogleMinPitch            = -20.0
+	GoogleMaxPitch            = 20.0
+	GoogleMinTemperature      = 0.0
+	GoogleMaxTemperature      = 2.0
+)
+
+// googleDeliveryPrompt wraps text with a delivery directive for speaking rate and pitch.
+//
+// Gemini TTS models have no audioConfig like the Cloud TTS synthesize API, instead
+// delivery is steered with a natural-language prefix (e.g. "Say slowly: ...").
+// A dialogue gets the directive on its own line, so the first "Name: line" turn stays
+// intact. Default values leave the text untouched.
+func googleDeliveryPrompt(text string, speakingRate, pitch float64, dialogue bool) string {
+	var directives []string
+	if speakingRate != GoogleDefaultSpeakingRate {
+		directives = append(directives, fmt.Sprintf("at %.2fx your normal speaking rate", speakingRate))
+	}
+	if pitch != GoogleDefaultPitch {
+		direction := "higher"
+		if pitch < 0 {
+			direction = "lower"
+		}
+		directives = append(directives, fmt.Sprintf("with your pitch %.1f semitones %s than normal", math.Abs(pitch), direction))
+	}
+	if len(directives) == 0 {
+		return text
+	}
+	separator := " "
+	if dialogue {
+		separator = "\n"
+	}
+	return fmt.Sprintf("Say the following %s:%s%s", strings.Join(directives, " and "), separator, text)
+}
+
+var (
+	// googleVoiceName matches Gemini prebuilt voice names like Kore
+	googleVoiceName = regexp.MustCompile(`^[A-Za-z]+$`)
+	// googleLocaleVoice matches a Gemini voice with a locale prefix like en-US-Kore,
+	// or the equivalent Cloud TTS Chirp 3 HD name like en-US-Chirp3-HD-Kore
+	googleLocaleVoice = regexp.MustCompile(`^([a-z]{2,3}-(?:[A-Z]{2}|[0-9]{3}))-(?:Chirp3-HD-)?([A-Za-z]+)$`)
+	// googleCloudVoice matches other Cloud TTS voice names like en-US-Wavenet-D
+	googleCloudVoice = regexp.MustCompile(`^[a-z]{2,3}-(?:[A-Z]{2}|[0-9]{3})-[A-Za-z0-9]+-[A-Za-z0-9-]+$`)
+)
+
+// GoogleVoice is a Gemini voice name with the language its locale prefix selected
+type GoogleVoice struct {
+	Name string
+	// LanguageCode is the BCP-47 locale, e.g. en-US, empty without a prefix
+	LanguageCode string
+}
+
+// ParseGoogleVoice parses a Gemini voice name with an optional locale prefix
+// ("Kore", "en-US-Kore" or "en-US-Chirp3-HD-Kore"). Malformed names and Cloud TTS
+// voices Gemini has no equivalent for are rejected before they cause a 400 from Google.
+func ParseGoogleVoice(voice string) (GoogleVoice, error) {
+	if googleVoiceName.MatchString(voice) {
+		return GoogleVoice{Name: voice}, nil
+	}
+	if m := googleLocaleVoice.FindStringSubmatch(voice); m != nil {
+		return GoogleVoice{Name: m[2], LanguageCode: m[1]}, nil
+	}
+	if googleCloudVoice.MatchString(voice) {
+		return GoogleVoice{}, fmt.Errorf("voice %q is a Cloud Text-to-Speech voice, Gemini TTS needs a Gemini voice like Kore, optionally with a locale prefix like en-US-Kore", voice)
+	}
+	return GoogleVoice{}, fmt.Errorf("malformed Google voice %q, use a Gemini voice like Kore, optionally with a locale prefix like en-US-Kore", voice)
+}
+
+// GoogleDialogueSpeakers is the number of speakers Gemini multi-speaker TTS takes
+const GoogleDialogueSpeakers = 2
+
+// GoogleSpeaker maps a speaker name used in a dialogue transcript to a Gemini voice
+type GoogleSpeaker struct {
+	Name  string `json:"name"`
+	Voice string `json:"voice"`
+}
+
+// parseDialogueSpeakers returns the speaker names referenced in a transcript
+// formatted as one "Name: line" per line, in order of first appearance
+func parseDialogueSpeakers(text string) ([]string, error) {
+	lines, err := ParseDialogue(text)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		if !seen[line.Speaker] {
+			seen[line.Speaker] = true
+			names = append(names, line.Speaker)
+		}
+	}
+	return names, nil
+}
+
+// googleMultiSpeakerConfig validates that the transcript's speakers and the voice mapping
+// match, with exactly GoogleDialogueSpeakers of them, and builds the Gemini multi-speaker
+// voice configuration
+func googleMultiSpeakerConfig(text string, speakers []GoogleSpeaker) (*genai.MultiSpeakerVoiceConfig, error) {
+	if len(speakers) != GoogleDialogueSpeakers {
+		return nil, fmt.Errorf("multi-speaker dialogue takes exactly %d speakers with Gemini, got %d", GoogleDialogueSpeakers, len(speakers))
+	}
+	referenced, err := parseDialogueSpeakers(text)
+	if err != nil {
+		return nil, err
+	}
+
+	voices := make(map[string]string, len(speakers))
+	for _, sp := range speakers {
+		voices[sp.Name] = sp.Voice
+	}
+	var missing []string
+	for _, name := range referenced {
+		if _, ok := voices[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no voice mapping for speaker(s): %s", strings.Join(missing, ", "))
+	}
+	var unused []string
+	for _, sp := range speakers {
+		if !slices.Contains(referenced, sp.Name) {
+			unused = append(unused, sp.Name)
+		}
+	}
+	if len(unused) > 0 {
+		return nil, fmt.Errorf("speaker(s) never speak in the transcript: %s", strings.Join(unused, ", "))
+	}
+
+	config := &genai.MultiSpeakerVoiceConfig{}
+	for _, sp := range speakers {
+		voice, err := ParseGoogleVoice(sp.Voice)
+		if err != nil {
+			return nil, fmt.Errorf("speaker %q: %v", sp.Name, err)
+		}
+		config.SpeakerVoiceConfigs = append(config.SpeakerVoiceConfigs, &genai.SpeakerVoiceConfig{
+			Speaker: sp.Name,
+			VoiceConfig: &genai.VoiceConfig{
+				PrebuiltVoiceConfig: &genai.PrebuiltVoiceConfig{
+					VoiceName: voice.Name,
+				},
+			},
+		})
+	}
+	return config, nil
+}
+
+// GoogleSpeechParams configures a Gemini TTS speech synthesis request.
+// Empty fields fall back to GOOGLE_AI_API_KEY/GEMINI_API_KEY and the provider defaults.
+type GoogleSpeechParams struct {
+	APIKey       string
+	Text         string
+	Voice        string
+	Model        string
+	SpeakingRate float64
+	Pitch        float64
+	// Speakers enables multi-speaker dialogue, Text must then be "Name: line" formatted
+	Speakers []GoogleSpeaker
+	// Temperature and Seed go into the generation config, nil leaves the model's default
+	Temperature *float32
+	Seed        *int32
+	// Extra holds raw request body fields for API parameters without a dedicated field,
+	// merged at the top level. Fields the request already sets take precedence.
+	Extra map[string]any
+}
+
+// googleAPIKey reads the Gemini API key from the environment
+func googleAPIKey() string {
+	if apiKey := os.Getenv("GOOGLE_AI_API_KEY"); apiKey != "" {
+		return apiKey
+	}
+	return os.Getenv("GEMINI_API_KEY")
+}
+
+// ValidateGoogleDialogue checks that text is a "Name: line" transcript of exactly two
+// speakers, each with a voice and each speaking at least once
+func ValidateGoogleDialogue(text string, speakers []GoogleSpeaker) error {
+	_, err := googleMultiSpeakerConfig(text, speakers)
+	return err
+}
+
+// googleClient returns a Gemini API client using the shared HTTP client and extra headers
+func googleClient(ctx context.Context, apiKey string) (*genai.Client, error) {
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     apiKey,
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: HTTPClient(),
+		HTTPOptions: genai.HTTPOptions{
+			Headers: providerHeaders(ProviderGoogle),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %v", err)
+	}
+	return client, nil
+}
+
+// SynthesizeGoogle requests speech from Gemini TTS and returns 24kHz 16-bit mono PCM without playing it
+func SynthesizeGoogle(ctx context.Context, params GoogleSpeechParams) ([]byte, error) {
+	apiKey := params.APIKey
+	if apiKey == "" {
+		apiKey = googleAPIKey()
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("GOOGLE_AI_API_KEY or GEMINI_API_KEY is not set")
+	}
+	if params.Voice == "" {
+		params.Voice = DefaultGoogleVoice
+	}
+	if params.Model == "" {
+		params.Model = DefaultGoogleModel
+	}
+	if params.SpeakingRate == 0 {
+		params.SpeakingRate = GoogleDefaultSpeakingRate
+	}
+
+	voice, err := ParseGoogleVoice(params.Voice)
+	if err != nil {
+		return nil, err
+	}
+	speechConfig := &genai.SpeechConfig{
+		VoiceConfig: &genai.VoiceConfig{
+			PrebuiltVoiceConfig: &genai.PrebuiltVoiceConfig{
+				VoiceName: voice.Name,
+			},
+		},
+		LanguageCode: voice.LanguageCode,
+	}
+	if err := ValidateExtra(params.Extra); err != nil {
+		return nil, err
+	}
+	if len(params.Speakers) > 0 {
+		multiSpeaker, err := googleMultiSpeakerConfig(params.Text, params.Speakers)
+		if err != nil {
+			return nil, err
+		}
+		// VoiceConfig and MultiSpeakerVoiceConfig are mutually exclusive
+		speechConfig.VoiceConfig = nil
+		speechConfig.MultiSpeakerVoiceConfig = multiSpeaker
+	}
+
+	client, err := googleClient(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	content := []*genai.Content{
+		genai.NewContentFromText(googleDeliveryPrompt(params.Text, params.SpeakingRate, params.Pitch, len(params.Speakers) > 0), genai.RoleUser),
+	}
+	config := &genai.GenerateContentConfig{
+		ResponseModalities: []string{"AUDIO"},
+		SpeechConfig:       speechConfig,
+		Temperature:        params.Temperature,
+		Seed:               params.Seed,
+	}
+	if len(params.Extra) > 0 {
+		config.HTTPOptions = &genai.HTTPOptions{
+			ExtrasRequestProvider: func(body map[string]any) map[string]any {
+				mergeExtra(ProviderGoogle, body, params.Extra)
+				return body
+			},
+		}
+	}
+	response, err := client.Models.GenerateContent(ctx, params.Model, content, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TTS audio: %w", err)
+	}
+
+	if len(response.Candidates) == 0 || response.Candidates[0].Content == nil ||
+		len(response.Candidates[0].Content.Parts) == 0 || response.Candidates[0].Content.Parts[0].InlineData == nil {
+		return nil, fmt.Errorf("no audio data received from Google TTS")
+	}
+	return response.Candidates[0].Content.Parts[0].InlineData.Data, nil
+}
+
+// Google is the Gemini TTS provider
+type Google struct {
+	APIKey string
+	// Pitch in semitones, from -20 to 20
+	Pitch float64
+	// Speakers enables multi-speaker dialogue
+	Speakers []GoogleSpeaker
+	// Temperature varies the delivery, from 0 to 2, nil leaves the model's default
+	Temperature *float32
+	// Seed makes the output reproducible for the same request, nil picks one at random
+	Seed *int32
+	// Extra is merged into every request body, see GoogleSpeechParams
+	Extra map[string]any
+}
+
+// NewGoogle returns a Google provider, an empty key falls back to GOOGLE_AI_API_KEY/GEMINI_API_KEY
+func NewGoogle(apiKey string) *Google {
+	return &Google{APIKey: apiKey}
+}
+
+// Synthesize implements Provider, opts.Speed maps to the speaking rate
+func (p *Google) Synthesize(ctx context.Context, opts Options) (*Audio, error) {
+	data, err := SynthesizeGoogle(ctx, GoogleSpeechParams{
+		APIKey:       p.APIKey,
+		Text:         opts.Text,
+		Voice:        opts.Voice,
+		Model:        opts.Model,
+		SpeakingRate: opts.Speed,
+		Pitch:        p.Pitch,
+		Speakers:     p.Speakers,
+		Temperature:  p.Temperature,
+		Seed:         p.Seed,
+		Extra:        p.Extra,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Audio{Data: data, Encoding: EncodingPCM, SampleRate: GoogleSampleRate}, nil
+}
@@ -0,0 +1,154 @@
+package say
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	texttospeech "cloud.google.com/go/texttospeech/apiv1"
+	"cloud.google.com/go/texttospeech/apiv1/texttospeechpb"
+	"github.com/charmbracelet/log"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// googleCloudSynthesizeMethod is the gRPC method Gemini TTS requests go to
+const googleCloudSynthesizeMethod = "texttospeech.googleapis.com/google.cloud.texttospeech.v1.TextToSpeech/SynthesizeSpeech"
+
+// googleCloudDefaultLanguage is used when the voice has no locale prefix, the Cloud API requires one
+const googleCloudDefaultLanguage = "en-US"
+
+var (
+	googleCloudMu     sync.Mutex
+	googleCloudClient *texttospeech.Client
+)
+
+// sharedGoogleCloudClient returns the process-wide Cloud Text-to-Speech gRPC client,
+// dialing it on first use so later calls reuse the connection
+func sharedGoogleCloudClient() (*texttospeech.Client, error) {
+	googleCloudMu.Lock()
+	defer googleCloudMu.Unlock()
+	if googleCloudClient != nil {
+		return googleCloudClient, nil
+	}
+	opts := []option.ClientOption{option.WithUserAgent(UserAgent())}
+	if proxy := explicitProxy(); proxy != nil {
+		// gRPC follows HTTPS_PROXY on its own, but not a proxy set with SetProxy
+		opts = append(opts, option.WithGRPCDialOption(grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialProxy(ctx, proxy, addr)
+		})))
+	}
+	// The client outlives any single call, so it must not be bound to a call's context
+	client, err := texttospeech.NewClient(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cloud Text-to-Speech client, set GOOGLE_APPLICATION_CREDENTIALS or run `gcloud auth application-default login`: %v", err)
+	}
+	googleCloudClient = client
+	return client, nil
+}
+
+// googleCloudRequest builds the Cloud Text-to-Speech request for a Gemini TTS model.
+// Unlike the Gemini API, the Cloud API takes speaking rate and pitch as numbers.
+func googleCloudRequest(params GoogleSpeechParams) (*texttospeechpb.SynthesizeSpeechRequest, error) {
+	if len(params.Speakers) > 0 {
+		return nil, fmt.Errorf("multi-speaker dialogue is not supported over gRPC, use the default REST client")
+	}
+	if params.Voice == "" {
+		params.Voice = DefaultGoogleVoice
+	}
+	if params.Model == "" {
+		params.Model = DefaultGoogleModel
+	}
+	if params.SpeakingRate == 0 {
+		params.SpeakingRate = GoogleDefaultSpeakingRate
+	}
+	voice, err := ParseGoogleVoice(params.Voice)
+	if err != nil {
+		return nil, err
+	}
+	if voice.LanguageCode == "" {
+		voice.LanguageCode = googleCloudDefaultLanguage
+	}
+	return &texttospeechpb.SynthesizeSpeechRequest{
+		Input: &texttospeechpb.SynthesisInput{
+			InputSource: &texttospeechpb.SynthesisInput_Text{Text: params.Text},
+		},
+		Voice: &texttospeechpb.VoiceSelectionParams{
+			LanguageCode: voice.LanguageCode,
+			Name:         voice.Name,
+			ModelName:    params.Model,
+		},
+		AudioConfig: &texttospeechpb.AudioConfig{
+			AudioEncoding:   texttospeechpb.AudioEncoding_PCM,
+			SampleRateHertz: int32(GoogleSampleRate),
+			SpeakingRate:    params.SpeakingRate,
+			Pitch:           params.Pitch,
+		},
+	}, nil
+}
+
+// SynthesizeGoogleCloud requests speech from Gemini TTS over the Cloud Text-to-Speech
+// gRPC API with Application Default Credentials and returns 24kHz 16-bit mono PCM.
+// params.APIKey is ignored.
+func SynthesizeGoogleCloud(ctx context.Context, params GoogleSpeechParams) ([]byte, error) {
+	request, err := googleCloudRequest(params)
+	if err != nil {
+		return nil, err
+	}
+	header := providerHeaders(ProviderGoogle)
+	for name, values := range header {
+		// Extra headers travel as gRPC metadata
+		ctx = metadata.AppendToOutgoingContext(ctx, name, values[0])
+	}
+	header.Set("Authorization", "Bearer (Application Default Credentials)")
+	header.Set("User-Agent", UserAgent())
+	if body, err := protojson.Marshal(request); err == nil && captureDryRun(ctx, CapturedRequest{
+		Method: "gRPC",
+		URL:    googleCloudSynthesizeMethod,
+		Header: header,
+		Body:   body,
+	}) {
+		return nil, ErrDryRun
+	}
+	if TestMode() {
+		log.Debug("Test mode, answering Cloud Text-to-Speech request with synthetic audio")
+		return testModeTone(GoogleSampleRate).Data, nil
+	}
+	client, err := sharedGoogleCloudClient()
+	if err != nil {
+		return nil, err
+	}
+	response, err := client.SynthesizeSpeech(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TTS audio: %w", err)
+	}
+	if len(response.AudioContent) == 0 {
+		return nil, fmt.Errorf("no audio data received from Google Cloud TTS")
+	}
+	return response.AudioContent, nil
+}
+
+// GoogleCloud is the Gemini TTS provider over the Cloud Text-to-Speech gRPC API.
+// It authenticates with Application Default Credentials instead of an API key.
+type GoogleCloud struct {
+	// Pitch in semitones, from -20 to 20
+	Pitch float64
+}
+
+// Synthesize implements Provider, opts.Speed maps to the speaking rate
+func (p *GoogleCloud) Synthesize(ctx context.Context, opts Options) (*Audio, error) {
+	data, err := SynthesizeGoogleCloud(ctx, GoogleSpeechParams{
+		Text:         opts.Text,
+		Voice:        opts.Voice,
+		Model:        opts.Model,
+		SpeakingRate: opts.Speed,
+		Pitch:        p.Pitch,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Audio{Data: data, Encoding: EncodingPCM, SampleRate: GoogleSampleRate}, nil
+}
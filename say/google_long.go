@@ -0,0 +1,239 @@
+package say
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	texttospeech "cloud.google.com/go/texttospeech/apiv1"
+	"cloud.google.com/go/texttospeech/apiv1/texttospeechpb"
+	"github.com/charmbracelet/log"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	// GoogleSyncTextLimit is the most text, in bytes, a synchronous Cloud Text-to-Speech
+	// request takes. Longer text needs SynthesizeGoogleLongAudio.
+	GoogleSyncTextLimit = 5000
+	// googleLongAudioMethod is the gRPC method long audio requests go to
+	googleLongAudioMethod = "texttospeech.googleapis.com/google.cloud.texttospeech.v1.TextToSpeechLongAudioSynthesize/SynthesizeLongAudio"
+	// googleLongAudioLocation is the API location long audio requests go to
+	googleLongAudioLocation = "global"
+	// googleLongAudioPollAttempts is how many polls in a row may fail before giving up
+	googleLongAudioPollAttempts = 5
+	// googleStorageReadScope lets the download read the synthesized object
+	googleStorageReadScope = "https://www.googleapis.com/auth/devstorage.read_only"
+)
+
+// googleLongAudioPollInterval is the wait between polls of a running operation
+var googleLongAudioPollInterval = 5 * time.Second
+
+var (
+	googleLongAudioMu     sync.Mutex
+	googleLongAudioClient *texttospeech.TextToSpeechLongAudioSynthesizeClient
+)
+
+// GoogleLongAudioParams configures a long audio synthesis
+type GoogleLongAudioParams struct {
+	GoogleSpeechParams
+	// Output is the gs:// URI of the WAV file Google writes, it must not exist yet
+	Output string
+	// Project is the Google Cloud project billed, the credentials' project when empty
+	Project string
+	// Progress, when set, is called with the percentage done after each poll
+	Progress func(percent float64)
+}
+
+// sharedGoogleLongAudioClient returns the process-wide long audio gRPC client, dialing
+// it on first use like sharedGoogleCloudClient
+func sharedGoogleLongAudioClient() (*texttospeech.TextToSpeechLongAudioSynthesizeClient, error) {
+	googleLongAudioMu.Lock()
+	defer googleLongAudioMu.Unlock()
+	if googleLongAudioClient != nil {
+		return googleLongAudioClient, nil
+	}
+	client, err := texttospeech.NewTextToSpeechLongAudioSynthesizeClient(context.Background(), option.WithUserAgent(UserAgent()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cloud Text-to-Speech long audio client, set GOOGLE_APPLICATION_CREDENTIALS or run `gcloud auth application-default login`: %v", err)
+	}
+	googleLongAudioClient = client
+	return client, nil
+}
+
+// ParseGCSURI splits a gs://bucket/object URI. object is empty for a bare bucket.
+func ParseGCSURI(uri string) (bucket, object string, err error) {
+	rest, ok := strings.CutPrefix(uri, "gs://")
+	if !ok {
+		return "", "", fmt.Errorf("%q is not a gs://bucket/object URI", uri)
+	}
+	bucket, object, _ = strings.Cut(rest, "/")
+	if bucket == "" {
+		return "", "", fmt.Errorf("%q has no bucket name", uri)
+	}
+	return bucket, object, nil
+}
+
+// googleLongAudioRequest builds the long audio request. Long audio is always written
+// as LINEAR16, a WAV file.
+func googleLongAudioRequest(params GoogleLongAudioParams) (*texttospeechpb.SynthesizeLongAudioRequest, error) {
+	_, object, err := ParseGCSURI(params.Output)
+	if err != nil {
+		return nil, err
+	}
+	if object == "" || strings.HasSuffix(object, "/") {
+		return nil, fmt.Errorf("output %q must name an object, not a bucket or folder", params.Output)
+	}
+	if params.Project == "" {
+		return nil, fmt.Errorf("long audio needs a Google Cloud project, set GOOGLE_CLOUD_PROJECT")
+	}
+	request, err := googleCloudRequest(params.GoogleSpeechParams)
+	if err != nil {
+		return nil, err
+	}
+	request.AudioConfig.AudioEncoding = texttospeechpb.AudioEncoding_LINEAR16
+	return &texttospeechpb.SynthesizeLongAudioRequest{
+		Parent:       fmt.Sprintf("projects/%s/locations/%s", params.Project, googleLongAudioLocation),
+		Input:        request.Input,
+		AudioConfig:  request.AudioConfig,
+		OutputGcsUri: params.Output,
+		Voice:        request.Voice,
+	}, nil
+}
+
+// SynthesizeGoogleLongAudio synthesizes text too long for a synchronous request with the
+// Cloud Text-to-Speech long audio API and Application Default Credentials. Google writes
+// the audio to params.Output as the operation runs; it is polled until done, failed polls
+// are retried against the same operation rather than starting over, and the WAV file is
+// then downloaded. The file is left in the bucket.
+func SynthesizeGoogleLongAudio(ctx context.Context, params GoogleLongAudioParams) (*Audio, error) {
+	if params.Project == "" && !TestMode() && ctx.Value(dryRunKey{}) == nil {
+		// Looking the credentials up can probe the GCE metadata server, not worth it for a stub
+		params.Project = googleCredentialsProject(ctx)
+	}
+	request, err := googleLongAudioRequest(params)
+	if err != nil {
+		return nil, err
+	}
+	header := providerHeaders(ProviderGoogle)
+	header.Set("Authorization", "Bearer (Application Default Credentials)")
+	header.Set("User-Agent", UserAgent())
+	if body, err := protojson.Marshal(request); err == nil && captureDryRun(ctx, CapturedRequest{
+		Method: "gRPC",
+		URL:    googleLongAudioMethod,
+		Header: header,
+		Body:   body,
+	}) {
+		return nil, ErrDryRun
+	}
+	if TestMode() {
+		log.Debug("Test mode, answering long audio request with synthetic audio")
+		if params.Progress != nil {
+			params.Progress(100)
+		}
+		return testModeTone(GoogleSampleRate), nil
+	}
+
+	client, err := sharedGoogleLongAudioClient()
+	if err != nil {
+		return nil, err
+	}
+	op, err := client.SynthesizeLongAudio(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start long audio synthesis: %w", err)
+	}
+	log.Info("Started Google long audio synthesis", "operation", op.Name(), "output", params.Output)
+
+	failures := 0
+	for !op.Done() {
+		select {
+		case <-time.After(googleLongAudioPollInterval):
+		case <-ctx.Done():
+			log.Warn("Stopped waiting for Google long audio synthesis, it keeps running", "operation", op.Name(), "output", params.Output)
+			return nil, ctx.Err()
+		}
+		if _, err := op.Poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if op.Done() {
+				return nil, fmt.Errorf("long audio synthesis failed: %w", err)
+			}
+			failures++
+			if failures == googleLongAudioPollAttempts {
+				return nil, fmt.Errorf("failed to poll long audio operation %s, the audio may still be written to %s: %w", op.Name(), params.Output, err)
+			}
+			log.Warn("Polling Google long audio synthesis failed, retrying", "operation", op.Name(), "attempt", failures, "error", err)
+			continue
+		}
+		failures = 0
+		if metadata, err := op.Metadata(); err == nil && metadata != nil {
+			log.Debug("Google long audio synthesis progress", "operation", op.Name(), "percent", metadata.ProgressPercentage)
+			if params.Progress != nil {
+				params.Progress(metadata.ProgressPercentage)
+			}
+		}
+	}
+	if params.Progress != nil {
+		params.Progress(100)
+	}
+
+	data, err := downloadGCSObject(ctx, params.Output)
+	if err != nil {
+		return nil, fmt.Errorf("long audio was written to %s but downloading it failed: %w", params.Output, err)
+	}
+	return &Audio{Data: data, Encoding: EncodingWAV}, nil
+}
+
+// googleCredentialsProject returns the project of the Application Default Credentials,
+// or "" when they name none
+func googleCredentialsProject(ctx context.Context) string {
+	creds, err := google.FindDefaultCredentials(ctx, googleStorageReadScope)
+	if err != nil {
+		return ""
+	}
+	return creds.ProjectID
+}
+
+// downloadGCSObject reads a gs:// object with Application Default Credentials through
+// the Cloud Storage JSON API, over the shared HTTP client so proxies apply
+func downloadGCSObject(ctx context.Context, uri string) ([]byte, error) {
+	bucket, object, err := ParseGCSURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := google.FindDefaultCredentials(ctx, googleStorageReadScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find Application Default Credentials: %v", err)
+	}
+	client := oauth2.NewClient(context.WithValue(ctx, oauth2.HTTPClient, HTTPClient()), creds.TokenSource)
+
+	endpoint := fmt.Sprintf("https://storage.googleapis.com/storage/v1/b/%s/o/%s?alt=media", url.PathEscape(bucket), url.PathEscape(object))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	addExtraHeaders(req.Header, ProviderGoogle)
+	safeLog("Sending HTTP request", req)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return nil, fmt.Errorf("Cloud Storage API error (status %d): %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio: %v", err)
+	}
+	return data, nil
+}
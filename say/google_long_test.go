@@ -0,0 +1,55 @@
+package say
+
+import (
+	"testing"
+
+	"cloud.google.com/go/texttospeech/apiv1/texttospeechpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGCSURI(t *testing.T) {
+	bucket, object, err := ParseGCSURI("gs://tts-out/books/one.wav")
+	require.NoError(t, err)
+	assert.Equal(t, "tts-out", bucket)
+	assert.Equal(t, "books/one.wav", object)
+
+	bucket, object, err = ParseGCSURI("gs://tts-out")
+	require.NoError(t, err)
+	assert.Equal(t, "tts-out", bucket)
+	assert.Empty(t, object)
+
+	for _, uri := range []string{"tts-out/one.wav", "gs://", "gs:///one.wav", "https://storage.googleapis.com/tts-out"} {
+		_, _, err := ParseGCSURI(uri)
+		assert.Error(t, err, uri)
+	}
+}
+
+func TestGoogleLongAudioRequest(t *testing.T) {
+	params := GoogleLongAudioParams{
+		GoogleSpeechParams: GoogleSpeechParams{Text: "Chapter one", Voice: "en-GB-Puck", SpeakingRate: 1.5},
+		Output:             "gs://tts-out/one.wav",
+		Project:            "my-project",
+	}
+	request, err := googleLongAudioRequest(params)
+	require.NoError(t, err)
+	assert.Equal(t, "projects/my-project/locations/global", request.Parent)
+	assert.Equal(t, "gs://tts-out/one.wav", request.OutputGcsUri)
+	assert.Equal(t, texttospeechpb.AudioEncoding_LINEAR16, request.AudioConfig.AudioEncoding)
+	assert.Equal(t, 1.5, request.AudioConfig.SpeakingRate)
+	assert.Equal(t, "Puck", request.Voice.Name)
+	assert.Equal(t, "en-GB", request.Voice.LanguageCode)
+	assert.Equal(t, DefaultGoogleModel, request.Voice.ModelName)
+
+	for name, change := range map[string]func(p *GoogleLongAudioParams){
+		"bucket only":  func(p *GoogleLongAudioParams) { p.Output = "gs://tts-out" },
+		"folder":       func(p *GoogleLongAudioParams) { p.Output = "gs://tts-out/books/" },
+		"no project":   func(p *GoogleLongAudioParams) { p.Project = "" },
+		"multispeaker": func(p *GoogleLongAudioParams) { p.Speakers = []GoogleSpeaker{{Name: "Joe", Voice: "Kore"}} },
+	} {
+		p := params
+		change(&p)
+		_, err := googleLongAudioRequest(p)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,136 @@
+package say
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoogleDeliveryPrompt(t *testing.T) {
+	tests := []struct {
+		name         string
+		speakingRate float64
+		pitch        float64
+		expected     string
+	}{
+		{"defaults leave text untouched", 1.0, 0.0, "Hello"},
+		{"faster rate", 1.5, 0.0, "Say the following at 1.50x your normal speaking rate: Hello"},
+		{"higher pitch", 1.0, 4.0, "Say the following with your pitch 4.0 semitones higher than normal: Hello"},
+		{"lower pitch", 1.0, -2.5, "Say the following with your pitch 2.5 semitones lower than normal: Hello"},
+		{"rate and pitch", 0.5, 3.0, "Say the following at 0.50x your normal speaking rate and with your pitch 3.0 semitones higher than normal: Hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, googleDeliveryPrompt("Hello", tt.speakingRate, tt.pitch, false))
+		})
+	}
+
+	t.Run("dialogue keeps the first turn on its own line", func(t *testing.T) {
+		assert.Equal(t, "Say the following at 1.50x your normal speaking rate:\nJoe: Hi\nJane: Hello",
+			googleDeliveryPrompt("Joe: Hi\nJane: Hello", 1.5, 0, true))
+		assert.Equal(t, "Joe: Hi", googleDeliveryPrompt("Joe: Hi", 1.0, 0, true))
+	})
+}
+
+func TestGoogleMultiSpeakerConfig(t *testing.T) {
+	speakers := []GoogleSpeaker{
+		{Name: "Joe", Voice: "Kore"},
+		{Name: "Jane", Voice: "Puck"},
+	}
+
+	t.Run("valid transcript", func(t *testing.T) {
+		config, err := googleMultiSpeakerConfig("Joe: How's it going today Jane?\nJane: Not too bad, how about you?", speakers)
+		require.NoError(t, err)
+		require.Len(t, config.SpeakerVoiceConfigs, 2)
+		assert.Equal(t, "Joe", config.SpeakerVoiceConfigs[0].Speaker)
+		assert.Equal(t, "Kore", config.SpeakerVoiceConfigs[0].VoiceConfig.PrebuiltVoiceConfig.VoiceName)
+		assert.Equal(t, "Jane", config.SpeakerVoiceConfigs[1].Speaker)
+		assert.Equal(t, "Puck", config.SpeakerVoiceConfigs[1].VoiceConfig.PrebuiltVoiceConfig.VoiceName)
+	})
+
+	t.Run("unmapped speaker", func(t *testing.T) {
+		_, err := googleMultiSpeakerConfig("Joe: Hi\nBob: Hello", speakers)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Bob")
+	})
+
+	t.Run("one speaker", func(t *testing.T) {
+		_, err := googleMultiSpeakerConfig("Joe: Hi", speakers[:1])
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exactly 2 speakers with Gemini, got 1")
+	})
+
+	t.Run("three speakers", func(t *testing.T) {
+		_, err := googleMultiSpeakerConfig("Joe: Hi\nJane: Hello\nBob: Hey", append(speakers, GoogleSpeaker{Name: "Bob", Voice: "Charon"}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exactly 2 speakers with Gemini, got 3")
+	})
+
+	t.Run("speaker not in the transcript", func(t *testing.T) {
+		err := ValidateGoogleDialogue("Joe: Hi\nJoe: Anyone there?", speakers)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "never speak in the transcript: Jane")
+	})
+
+	t.Run("malformed line", func(t *testing.T) {
+		_, err := googleMultiSpeakerConfig("Joe: Hi\njust some narration", speakers)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "line 2")
+	})
+}
+
+func TestParseGoogleVoice(t *testing.T) {
+	tests := []struct {
+		voice    string
+		expected GoogleVoice
+		errMsg   string
+	}{
+		{voice: "Kore", expected: GoogleVoice{Name: "Kore"}},
+		{voice: "en-US-Kore", expected: GoogleVoice{Name: "Kore", LanguageCode: "en-US"}},
+		{voice: "es-419-Puck", expected: GoogleVoice{Name: "Puck", LanguageCode: "es-419"}},
+		{voice: "de-DE-Chirp3-HD-Charon", expected: GoogleVoice{Name: "Charon", LanguageCode: "de-DE"}},
+		{voice: "en-US-Wavenet-D", errMsg: "Cloud Text-to-Speech voice"},
+		{voice: "en-US-Neural2-F", errMsg: "Cloud Text-to-Speech voice"},
+		{voice: "en_US-Kore", errMsg: "malformed"},
+		{voice: "en-us-Kore", errMsg: "malformed"},
+		{voice: "Kore!", errMsg: "malformed"},
+		{voice: "", errMsg: "malformed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.voice, func(t *testing.T) {
+			voice, err := ParseGoogleVoice(tt.voice)
+			if tt.errMsg != "" {
+				assert.ErrorContains(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, voice)
+		})
+	}
+}
+
+func TestGoogleCloudRequest(t *testing.T) {
+	request, err := googleCloudRequest(GoogleSpeechParams{Text: "Hallo", Voice: "de-DE-Puck", Pitch: -2})
+	require.NoError(t, err)
+	assert.Equal(t, "Hallo", request.GetInput().GetText())
+	assert.Equal(t, "de-DE", request.GetVoice().GetLanguageCode())
+	assert.Equal(t, "Puck", request.GetVoice().GetName())
+	assert.Equal(t, DefaultGoogleModel, request.GetVoice().GetModelName())
+	assert.Equal(t, int32(GoogleSampleRate), request.GetAudioConfig().GetSampleRateHertz())
+	assert.Equal(t, GoogleDefaultSpeakingRate, request.GetAudioConfig().GetSpeakingRate())
+	assert.Equal(t, -2.0, request.GetAudioConfig().GetPitch())
+
+	// The Cloud API requires a language, voices without a locale prefix get the default
+	request, err = googleCloudRequest(GoogleSpeechParams{Text: "Hello"})
+	require.NoError(t, err)
+	assert.Equal(t, googleCloudDefaultLanguage, request.GetVoice().GetLanguageCode())
+	assert.Equal(t, DefaultGoogleVoice, request.GetVoice().GetName())
+
+	_, err = googleCloudRequest(GoogleSpeechParams{Text: "Joe: Hi", Speakers: []GoogleSpeaker{{Name: "Joe", Voice: "Kore"}}})
+	assert.ErrorContains(t, err, "multi-speaker")
+	_, err = googleCloudRequest(GoogleSpeechParams{Text: "Hello", Voice: "en-US-Wavenet-D"})
+	assert.Error(t, err)
+}
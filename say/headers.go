@@ -0,0 +1,92 @@
+package say
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// protectedHeaders are set by the provider clients themselves and can't be overridden
+var protectedHeaders = []string{
+	"Accept",
+	"Api-Key",
+	"Authorization",
+	"Content-Length",
+	"Content-Type",
+	"Host",
+	"Openai-Organization",
+	"Openai-Project",
+	"Proxy-Authorization",
+	"X-Goog-Api-Key",
+	"Xi-Api-Key",
+}
+
+// validHeaderName matches an HTTP header field name token
+var validHeaderName = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
+var (
+	headersMu    sync.RWMutex
+	extraHeaders = map[string]http.Header{}
+)
+
+// SetExtraHeaders adds headers to every request sent to provider, e.g. an X-Team-Id an API
+// gateway requires. An empty provider applies them to all providers, a provider's own
+// headers win over those. Headers the clients set themselves, like Authorization and
+// Content-Type, are rejected. Call it before the first request.
+func SetExtraHeaders(provider string, headers map[string]string) error {
+	if err := ValidateExtraHeaders(headers); err != nil {
+		return err
+	}
+	header := make(http.Header, len(headers))
+	for name, value := range headers {
+		header.Set(name, value)
+	}
+	headersMu.Lock()
+	defer headersMu.Unlock()
+	extraHeaders[provider] = header
+	return nil
+}
+
+// ValidateExtraHeaders checks that headers are valid and none is one the clients set themselves
+func ValidateExtraHeaders(headers map[string]string) error {
+	for name, value := range headers {
+		if !validHeaderName.MatchString(name) {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("header %s: value must not contain line breaks", name)
+		}
+		name = http.CanonicalHeaderKey(name)
+		if name == "User-Agent" {
+			return fmt.Errorf("header User-Agent is set with MCP_SAY_USER_AGENT, not as an extra header")
+		}
+		if slices.Contains(protectedHeaders, name) {
+			return fmt.Errorf("header %s is set by the provider client and can't be overridden", name)
+		}
+	}
+	return nil
+}
+
+// providerHeaders returns the extra headers for provider, merged with those for all providers
+func providerHeaders(provider string) http.Header {
+	headersMu.RLock()
+	defer headersMu.RUnlock()
+	header := extraHeaders[""].Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	maps.Copy(header, extraHeaders[provider])
+	return header
+}
+
+// addExtraHeaders sets provider's extra headers on h. Call it before setting the
+// required headers, the validation in SetExtraHeaders keeps them from colliding.
+func addExtraHeaders(h http.Header, provider string) {
+	for name, values := range providerHeaders(provider) {
+		h[name] = slices.Clone(values)
+	}
+}
@@ -0,0 +1,55 @@
+package say
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useExtraHeaders sets extra headers for the test and clears them afterwards
+func useExtraHeaders(t *testing.T, provider string, headers map[string]string) {
+	t.Helper()
+	require.NoError(t, SetExtraHeaders(provider, headers))
+	t.Cleanup(func() { SetExtraHeaders(provider, nil) })
+}
+
+func TestValidateExtraHeaders(t *testing.T) {
+	assert.NoError(t, ValidateExtraHeaders(map[string]string{"X-Team-Id": "team-1"}))
+	assert.ErrorContains(t, ValidateExtraHeaders(map[string]string{"content-type": "text/plain"}), "Content-Type is set by the provider client")
+	assert.ErrorContains(t, ValidateExtraHeaders(map[string]string{"xi-api-key": "k"}), "Xi-Api-Key is set by the provider client")
+	assert.ErrorContains(t, ValidateExtraHeaders(map[string]string{"user-agent": "curl/8"}), "MCP_SAY_USER_AGENT")
+	assert.ErrorContains(t, ValidateExtraHeaders(map[string]string{"X Team": "t"}), "invalid header name")
+	assert.ErrorContains(t, ValidateExtraHeaders(map[string]string{"X-Team": "t\r\nX-Evil: 1"}), "line breaks")
+}
+
+func TestExtraHeaders(t *testing.T) {
+	useExtraHeaders(t, "", map[string]string{"X-Team-Id": "team-all", "X-Env": "prod"})
+	useExtraHeaders(t, ProviderElevenLabs, map[string]string{"x-team-id": "team-voice"})
+
+	assert.Equal(t, http.Header{"X-Team-Id": {"team-all"}, "X-Env": {"prod"}}, providerHeaders(ProviderOpenAI))
+	assert.Equal(t, http.Header{"X-Team-Id": {"team-voice"}, "X-Env": {"prod"}}, providerHeaders(ProviderElevenLabs))
+
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Write([]byte("ID3"))
+	}))
+	t.Cleanup(server.Close)
+	prev := elevenLabsAPIBase
+	elevenLabsAPIBase = server.URL
+	t.Cleanup(func() { elevenLabsAPIBase = prev })
+
+	body, err := StreamElevenLabs(context.Background(), ElevenLabsSpeechParams{APIKey: "test-key", Text: "hi"})
+	require.NoError(t, err)
+	io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "team-voice", got.Get("X-Team-Id"))
+	assert.Equal(t, "prod", got.Get("X-Env"))
+	assert.Equal(t, "test-key", got.Get("Xi-Api-Key"))
+	assert.Equal(t, "application/json", got.Get("Content-Type"))
+}
@@ -0,0 +1,162 @@
+package say
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// DefaultUserAgent identifies requests until SetUserAgent is called
+const DefaultUserAgent = "mcp-say"
+
+var (
+	httpMu     sync.Mutex
+	httpProxy  *url.URL // nil to use HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	httpClient *http.Client
+	userAgent  = DefaultUserAgent
+)
+
+// SetUserAgent sets the User-Agent of every provider request, replacing the one each
+// SDK would send. Call it before the first request, gRPC clients are dialed once.
+func SetUserAgent(ua string) error {
+	ua = strings.TrimSpace(ua)
+	if ua == "" || strings.IndexFunc(ua, func(r rune) bool { return r > unicode.MaxASCII || !unicode.IsPrint(r) }) >= 0 {
+		return fmt.Errorf("invalid user agent %q, expected printable ASCII like mcp-say/1.0", ua)
+	}
+	httpMu.Lock()
+	defer httpMu.Unlock()
+	userAgent = ua
+	return nil
+}
+
+// UserAgent returns the User-Agent sent with provider requests
+func UserAgent() string {
+	httpMu.Lock()
+	defer httpMu.Unlock()
+	return userAgent
+}
+
+// userAgentTransport sets the User-Agent on every request it sends
+type userAgentTransport struct {
+	base http.RoundTripper
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", UserAgent())
+	return t.base.RoundTrip(req)
+}
+
+// SetProxy routes every provider request through proxyURL, e.g. http://proxy.example.com:3128,
+// instead of the proxy named by HTTP_PROXY, HTTPS_PROXY and NO_PROXY. An empty URL goes
+// back to the environment. Call it before the first request, gRPC clients are dialed once.
+func SetProxy(proxyURL string) error {
+	var proxy *url.URL
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("invalid proxy URL %q, expected http://host:port or https://host:port", proxyURL)
+		}
+		proxy = u
+	}
+	httpMu.Lock()
+	defer httpMu.Unlock()
+	httpProxy = proxy
+	httpClient = nil
+	return nil
+}
+
+// HTTPClient returns the client every provider sends its HTTP requests with
+func HTTPClient() *http.Client {
+	httpMu.Lock()
+	defer httpMu.Unlock()
+	if httpClient == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyFromEnvironment
+		if httpProxy != nil {
+			transport.Proxy = http.ProxyURL(httpProxy)
+		}
+		httpClient = &http.Client{Transport: &userAgentTransport{base: &dryRunTransport{base: &testModeTransport{base: transport}}}}
+	}
+	return httpClient
+}
+
+// explicitProxy returns the proxy set with SetProxy, nil when the environment decides
+func explicitProxy() *url.URL {
+	httpMu.Lock()
+	defer httpMu.Unlock()
+	return httpProxy
+}
+
+// dialProxy opens a tunnel to addr through an HTTP CONNECT proxy, for gRPC clients
+// which only follow the environment's proxy on their own
+func dialProxy(ctx context.Context, proxy *url.URL, addr string) (net.Conn, error) {
+	host := proxy.Host
+	if proxy.Port() == "" {
+		host = net.JoinHostPort(proxy.Hostname(), map[string]string{"http": "80", "https": "443"}[proxy.Scheme])
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy %s: %v", proxy.Redacted(), err)
+	}
+	if proxy.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: proxy.Hostname()})
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if proxy.User != nil {
+		password, _ := proxy.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxy.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send CONNECT to proxy %s: %v", proxy.Redacted(), err)
+	}
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read CONNECT response from proxy %s: %v", proxy.Redacted(), err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s refused CONNECT to %s: %s", proxy.Redacted(), addr, res.Status)
+	}
+	if br.Buffered() > 0 {
+		// The server spoke first, keep what was read past the response
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn reads through a reader that may hold bytes already read from Conn
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
@@ -0,0 +1,112 @@
+package say
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/charmbracelet/log"
+)
+
+const (
+	// Default words per minute for the say command
+	defaultSayRate = 200
+	// MinSayRate and MaxSayRate bound the words per minute accepted for the say command.
+	// say itself takes more, but outside this range speech is hard to follow.
+	MinSayRate = 90
+	MaxSayRate = 300
+)
+
+// SaySpeechParams configures a macOS say synthesis request.
+// Zero fields fall back to the system voice and the default rate.
+type SaySpeechParams struct {
+	Text  string
+	Voice string
+	Rate  int
+}
+
+// ValidateSayRate checks a words per minute rate, 0 means the default
+func ValidateSayRate(rate int) error {
+	if rate != 0 && (rate < MinSayRate || rate > MaxSayRate) {
+		return fmt.Errorf("rate must be between %d and %d words per minute, got %d", MinSayRate, MaxSayRate, rate)
+	}
+	return nil
+}
+
+// SayArgs builds the say command line arguments, text last after "--" so text
+// starting with a dash, e.g. a "-o file" bullet line, isn't read as an option
+func SayArgs(params SaySpeechParams) []string {
+	rate := params.Rate
+	if rate == 0 {
+		rate = defaultSayRate
+	}
+	args := []string{"--rate", fmt.Sprintf("%d", rate)}
+	if params.Voice != "" {
+		args = append(args, "--voice", params.Voice)
+	}
+	return append(args, "--", params.Text)
+}
+
+// SynthesizeSay renders speech with the macOS say command and returns the WAV audio bytes without playing them
+func SynthesizeSay(ctx context.Context, params SaySpeechParams) ([]byte, error) {
+	if runtime.GOOS != "darwin" {
+		return nil, errors.New("the say command is only available on macOS")
+	}
+	if params.Voice != "" && !ValidSayVoice(params.Voice) {
+		return nil, fmt.Errorf("voice contains invalid characters: %s", params.Voice)
+	}
+	if TestMode() {
+		log.Debug("Test mode, returning a tone instead of running the say command")
+		return testModeTone(22050).Encoded(), nil
+	}
+
+	dir, err := os.MkdirTemp("", "mcp-say-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "speech.wav")
+
+	args := append([]string{"-o", out, "--file-format=WAVE", "--data-format=LEI16@22050"}, SayArgs(params)...)
+	log.Debug("Executing say command", "args", args)
+	if err := exec.CommandContext(ctx, "/usr/bin/say", args...).Run(); err != nil {
+		return nil, fmt.Errorf("say command failed: %v", err)
+	}
+	return os.ReadFile(out)
+}
+
+// ValidSayVoice is a simple validation to prevent command injection.
+// Only allow alphabetic characters, spaces, and parentheses.
+func ValidSayVoice(voice string) bool {
+	for _, r := range voice {
+		if !((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == ' ' || r == '(' || r == ')') {
+			return false
+		}
+	}
+	return true
+}
+
+// MacOS is the macOS say command provider
+type MacOS struct{}
+
+// NewMacOS returns a macOS say provider
+func NewMacOS() *MacOS {
+	return &MacOS{}
+}
+
+// Synthesize implements Provider, opts.Speed scales the default words per minute
+func (p *MacOS) Synthesize(ctx context.Context, opts Options) (*Audio, error) {
+	params := SaySpeechParams{Text: opts.Text, Voice: opts.Voice}
+	if opts.Speed != 0 {
+		params.Rate = int(defaultSayRate * opts.Speed)
+	}
+	data, err := SynthesizeSay(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &Audio{Data: data, Encoding: EncodingWAV}, nil
+}
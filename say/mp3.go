@@ -0,0 +1,95 @@
+package say
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+	"github.com/gopxl/beep/v2/mp3"
+)
+
+// Bytes of an undecodable response included in the error
+const maxBodySnippet = 256
+
+// errStreamTruncated wraps the error that cut an MP3 stream short after some frames.
+// The audio decoded before it is still valid, so buffering keeps it.
+var errStreamTruncated = errors.New("audio stream ended early")
+
+// decodeMP3 decodes MP3 audio. A response without a single MP3 frame is an error that
+// includes the start of the body, since it's usually an error message from the provider.
+// A stream that breaks off after some frames plays what was decoded and logs a warning.
+func decodeMP3(r io.Reader) (beep.StreamSeekCloser, beep.Format, error) {
+	head := &headRecorder{r: r}
+	streamer, format, err := mp3.Decode(io.NopCloser(head))
+	if err != nil {
+		if body := head.snippet(); body != "" {
+			return nil, beep.Format{}, fmt.Errorf("failed to decode response: no MP3 audio found (%v), response body: %s", err, body)
+		}
+		return nil, beep.Format{}, fmt.Errorf("failed to decode response: empty audio (%v)", err)
+	}
+	return &partialMP3{StreamSeekCloser: streamer, source: head}, format, nil
+}
+
+// headRecorder keeps the first bytes read through it and the error that ended reading
+type headRecorder struct {
+	r    io.Reader
+	head []byte
+	// err is the first read error other than io.EOF. The decoder ends quietly on
+	// io.ErrUnexpectedEOF, which is also how a dropped connection reads.
+	err error
+}
+
+func (h *headRecorder) Read(p []byte) (int, error) {
+	n, err := h.r.Read(p)
+	if err != nil && err != io.EOF && h.err == nil {
+		h.err = err
+	}
+	if room := maxBodySnippet - len(h.head); room > 0 {
+		h.head = append(h.head, p[:min(n, room)]...)
+	}
+	return n, err
+}
+
+// snippet returns the recorded bytes as text, or a description when they are binary
+func (h *headRecorder) snippet() string {
+	if len(h.head) == 0 {
+		return ""
+	}
+	if !utf8.Valid(h.head) {
+		return fmt.Sprintf("%d bytes of binary data", len(h.head))
+	}
+	return strings.TrimSpace(string(h.head))
+}
+
+// partialMP3 ends the stream cleanly when decoding fails part way through, so the
+// audio decoded so far still plays
+type partialMP3 struct {
+	beep.StreamSeekCloser
+	source  *headRecorder
+	samples int
+	err     error
+}
+
+func (p *partialMP3) Stream(samples [][2]float64) (int, bool) {
+	n, ok := p.StreamSeekCloser.Stream(samples)
+	p.samples += n
+	err := p.StreamSeekCloser.Err()
+	if err == nil && !ok {
+		err = p.source.err
+	}
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("%w: %w", errStreamTruncated, err)
+		log.Warn("MP3 stream ended early, playing the audio decoded so far", "samples", p.samples, "error", err)
+	}
+	return n, ok
+}
+
+// Err implements beep.Streamer. Errors after the first frame only truncate the audio,
+// they are reported wrapping errStreamTruncated so a dropped stream can be resumed.
+func (p *partialMP3) Err() error {
+	return p.err
+}
@@ -0,0 +1,49 @@
+package say
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeMP3(t *testing.T) {
+	t.Run("complete", func(t *testing.T) {
+		buffer, err := (&Audio{Data: silentMP3Frames(10), Encoding: EncodingMP3}).Buffer()
+		require.NoError(t, err)
+		assert.Equal(t, 10*1152, buffer.Len())
+	})
+
+	t.Run("truncated stream plays partial audio", func(t *testing.T) {
+		data := silentMP3Frames(10)
+		r := io.MultiReader(bytes.NewReader(data[:417*4+100]), iotest.ErrReader(io.ErrUnexpectedEOF))
+		streamer, format, err := decodeMP3(r)
+		require.NoError(t, err)
+
+		buffer := beep.NewBuffer(format)
+		buffer.Append(streamer)
+		assert.ErrorIs(t, streamer.Err(), errStreamTruncated)
+		assert.ErrorIs(t, streamer.Err(), io.ErrUnexpectedEOF)
+		assert.Equal(t, 4*1152, buffer.Len())
+
+		buffer, err = (&Audio{Data: data[:417*4+100], Encoding: EncodingMP3}).Buffer()
+		require.NoError(t, err, "a truncated download still buffers")
+		assert.Equal(t, 4*1152, buffer.Len())
+	})
+
+	t.Run("no frames surfaces the body", func(t *testing.T) {
+		_, err := (&Audio{Data: []byte(`{"error": "quota exceeded"}`), Encoding: EncodingMP3}).Buffer()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no MP3 audio found")
+		assert.Contains(t, err.Error(), "quota exceeded")
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, _, err := decodeMP3(bytes.NewReader(nil))
+		assert.ErrorContains(t, err, "empty audio")
+	})
+}
@@ -0,0 +1,214 @@
+package say
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+const (
+	DefaultOpenAIVoice = "coral"
+	DefaultOpenAIModel = "gpt-4o-mini-tts"
+)
+
+// OpenAIAccount identifies who OpenAI requests are made as. Empty fields fall back
+// to OPENAI_API_KEY, OPENAI_ORG_ID and OPENAI_PROJECT_ID.
+type OpenAIAccount struct {
+	APIKey string
+	// Organization and Project are sent as the OpenAI-Organization and
+	// OpenAI-Project headers, which org-scoped keys require
+	Organization string
+	Project      string
+}
+
+// client returns an OpenAI client for the account
+func (a OpenAIAccount) client() (openai.Client, error) {
+	apiKey := cmp.Or(a.APIKey, os.Getenv("OPENAI_API_KEY"))
+	if apiKey == "" {
+		return openai.Client{}, fmt.Errorf("OPENAI_API_KEY is not set")
+	}
+	opts := []option.RequestOption{option.WithAPIKey(apiKey), option.WithHTTPClient(HTTPClient())}
+	for name, values := range providerHeaders(ProviderOpenAI) {
+		opts = append(opts, option.WithHeader(name, values[0]))
+	}
+	if organization := cmp.Or(a.Organization, os.Getenv("OPENAI_ORG_ID")); organization != "" {
+		opts = append(opts, option.WithOrganization(organization))
+	}
+	if project := cmp.Or(a.Project, os.Getenv("OPENAI_PROJECT_ID")); project != "" {
+		opts = append(opts, option.WithProject(project))
+	}
+	return openai.NewClient(opts...), nil
+}
+
+// OpenAISupportsInstructions reports whether model follows voice instructions. The
+// older tts-1 and tts-1-hd models accept the parameter but ignore it.
+func OpenAISupportsInstructions(model string) bool {
+	return !strings.HasPrefix(model, "tts-1")
+}
+
+// OpenAISpeechParams configures an OpenAI speech synthesis request.
+// Empty fields fall back to OPENAI_API_KEY and the provider defaults.
+type OpenAISpeechParams struct {
+	APIKey       string
+	Organization string
+	Project      string
+	Text         string
+	Voice        string
+	Model        string
+	Speed        float64
+	Instructions string
+	// PCM requests raw 24kHz 16-bit little-endian mono samples instead of MP3
+	PCM bool
+	// Extra holds raw request body fields for API parameters without a dedicated field,
+	// merged at the top level. Fields the request already sets take precedence.
+	Extra map[string]any
+}
+
+// openAIExtraOptions sets the fields of extra the request doesn't already set
+func openAIExtraOptions(request openai.AudioSpeechNewParams, extra map[string]any) ([]option.RequestOption, error) {
+	if len(extra) == 0 {
+		return nil, nil
+	}
+	if err := ValidateExtra(extra); err != nil {
+		return nil, err
+	}
+	fields, err := jsonFields(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %v", err)
+	}
+	var opts []option.RequestOption
+	for _, key := range mergeExtra(ProviderOpenAI, fields, extra) {
+		opts = append(opts, option.WithJSONSet(key, extra[key]))
+	}
+	return opts, nil
+}
+
+// StreamOpenAI requests speech from OpenAI and returns the MP3 response body as it streams in,
+// or the raw samples when params.PCM is set
+func StreamOpenAI(ctx context.Context, params OpenAISpeechParams) (io.ReadCloser, error) {
+	client, err := OpenAIAccount{APIKey: params.APIKey, Organization: params.Organization, Project: params.Project}.client()
+	if err != nil {
+		return nil, err
+	}
+	if params.Voice == "" {
+		params.Voice = DefaultOpenAIVoice
+	}
+	if params.Model == "" {
+		params.Model = DefaultOpenAIModel
+	}
+
+	request := openai.AudioSpeechNewParams{
+		Model: openai.SpeechModel(params.Model),
+		Input: params.Text,
+		Voice: openai.AudioSpeechNewParamsVoice(params.Voice),
+	}
+	if params.Speed != 0 && params.Speed != 1.0 {
+		request.Speed = openai.Float(params.Speed)
+	}
+	if params.Instructions != "" && OpenAISupportsInstructions(params.Model) {
+		request.Instructions = openai.String(params.Instructions)
+	}
+	if params.PCM {
+		request.ResponseFormat = openai.AudioSpeechNewParamsResponseFormatPCM
+	}
+
+	opts, err := openAIExtraOptions(request, params.Extra)
+	if err != nil {
+		return nil, err
+	}
+	response, err := client.Audio.Speech.New(ctx, request, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TTS audio: %w", err)
+	}
+	return response.Body, nil
+}
+
+// SynthesizeOpenAI requests speech from OpenAI and returns the MP3 audio bytes without playing them
+func SynthesizeOpenAI(ctx context.Context, params OpenAISpeechParams) ([]byte, error) {
+	body, err := StreamOpenAI(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// OpenAI is the OpenAI TTS provider
+type OpenAI struct {
+	APIKey       string
+	Organization string
+	Project      string
+	// Instructions steer the voice's tone and delivery (gpt-4o-mini-tts only)
+	Instructions string
+	// Extra is merged into every request body, see OpenAISpeechParams
+	Extra map[string]any
+}
+
+// NewOpenAI returns an OpenAI provider, an empty key falls back to OPENAI_API_KEY
+func NewOpenAI(apiKey string) *OpenAI {
+	return &OpenAI{APIKey: apiKey}
+}
+
+func (p *OpenAI) params(opts Options) OpenAISpeechParams {
+	return OpenAISpeechParams{
+		APIKey:       p.APIKey,
+		Organization: p.Organization,
+		Project:      p.Project,
+		Text:         opts.Text,
+		Voice:        opts.Voice,
+		Model:        opts.Model,
+		Speed:        opts.Speed,
+		Instructions: p.Instructions,
+		Extra:        p.Extra,
+	}
+}
+
+// Stream implements StreamingProvider
+func (p *OpenAI) Stream(ctx context.Context, opts Options) (io.ReadCloser, error) {
+	return StreamOpenAI(ctx, p.params(opts))
+}
+
+// Synthesize implements Provider
+func (p *OpenAI) Synthesize(ctx context.Context, opts Options) (*Audio, error) {
+	data, err := SynthesizeOpenAI(ctx, p.params(opts))
+	if err != nil {
+		return nil, err
+	}
+	return &Audio{Data: data, Encoding: sniffEncoding(data, EncodingMP3)}, nil
+}
+
+// OpenAIPCM is the OpenAI TTS provider requesting raw PCM instead of MP3. Samples play
+// as they arrive without an MP3 decoder waiting for whole frames, so speech starts sooner.
+type OpenAIPCM OpenAI
+
+// StreamPCM implements PCMStreamingProvider
+func (p *OpenAIPCM) StreamPCM(ctx context.Context, opts Options) (io.ReadCloser, beep.SampleRate, error) {
+	params := (*OpenAI)(p).params(opts)
+	params.PCM = true
+	body, err := StreamOpenAI(ctx, params)
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, openAIPCMSampleRate, nil
+}
+
+// Synthesize implements Provider
+func (p *OpenAIPCM) Synthesize(ctx context.Context, opts Options) (*Audio, error) {
+	body, _, err := p.StreamPCM(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return &Audio{Data: data, Encoding: EncodingPCM, SampleRate: openAIPCMSampleRate}, nil
+}
@@ -0,0 +1,148 @@
+package say
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+	"github.com/openai/openai-go"
+)
+
+// Sample rate of the pcm16 audio streamed by OpenAI's audio chat models and TTS pcm format
+const openAIPCMSampleRate = beep.SampleRate(24000)
+
+// openAIAudioPrompt keeps the chat model reading the text instead of answering it
+const openAIAudioPrompt = "Read the user's message aloud exactly as written. Do not answer it, comment on it or add anything."
+
+// IsOpenAIAudioModel reports whether model is an audio-capable chat model such as
+// gpt-4o-audio-preview, rather than a dedicated TTS model
+func IsOpenAIAudioModel(model string) bool {
+	return strings.HasPrefix(model, "gpt-4o") && strings.Contains(model, "-audio")
+}
+
+// PCMStreamingProvider is a Provider that can return 16-bit little-endian mono
+// PCM while it is still being generated
+type PCMStreamingProvider interface {
+	Provider
+	// StreamPCM returns the raw samples as they stream in, with their sample rate
+	StreamPCM(ctx context.Context, opts Options) (io.ReadCloser, beep.SampleRate, error)
+}
+
+// OpenAIAudio speaks text with an OpenAI audio chat model. It is slower than the
+// TTS models but follows Instructions more expressively.
+type OpenAIAudio struct {
+	APIKey       string
+	Organization string
+	Project      string
+	// Instructions steer the voice's tone and delivery
+	Instructions string
+}
+
+// StreamPCM implements PCMStreamingProvider. The base64 audio deltas of the chat
+// completion stream are decoded into a continuous PCM byte stream.
+func (p *OpenAIAudio) StreamPCM(ctx context.Context, opts Options) (io.ReadCloser, beep.SampleRate, error) {
+	client, err := OpenAIAccount{APIKey: p.APIKey, Organization: p.Organization, Project: p.Project}.client()
+	if err != nil {
+		return nil, 0, err
+	}
+	if !IsOpenAIAudioModel(opts.Model) {
+		return nil, 0, fmt.Errorf("%q is not an OpenAI audio chat model", opts.Model)
+	}
+	if opts.Voice == "" {
+		opts.Voice = DefaultOpenAIVoice
+	}
+	if opts.Speed != 0 && opts.Speed != 1.0 {
+		log.Warn("Speed is not supported by OpenAI audio chat models, ignoring", "model", opts.Model, "speed", opts.Speed)
+	}
+
+	prompt := openAIAudioPrompt
+	if p.Instructions != "" {
+		prompt += "\n\nVoice instructions: " + p.Instructions
+	}
+
+	stream := client.Chat.Completions.NewStreaming(ctx, openai.ChatCompletionNewParams{
+		Model:      opts.Model,
+		Modalities: []string{"text", "audio"},
+		Audio: openai.ChatCompletionAudioParam{
+			Format: openai.ChatCompletionAudioParamFormatPcm16,
+			Voice:  openai.ChatCompletionAudioParamVoice(opts.Voice),
+		},
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(prompt),
+			openai.UserMessage(opts.Text),
+		},
+	})
+	if err := stream.Err(); err != nil {
+		stream.Close()
+		return nil, 0, fmt.Errorf("failed to generate audio: %v", err)
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		defer stream.Close()
+		for stream.Next() {
+			for _, choice := range stream.Current().Choices {
+				pcm, err := openAIAudioDelta(choice.Delta.RawJSON())
+				if err != nil {
+					w.CloseWithError(err)
+					return
+				}
+				if len(pcm) == 0 {
+					continue
+				}
+				if _, err := w.Write(pcm); err != nil {
+					return // reader closed
+				}
+			}
+		}
+		if err := stream.Err(); err != nil {
+			w.CloseWithError(fmt.Errorf("audio stream failed: %v", err))
+			return
+		}
+		w.Close()
+	}()
+	return r, openAIPCMSampleRate, nil
+}
+
+// openAIAudioDelta decodes the audio bytes of a streamed chat completion delta.
+// The SDK doesn't model delta.audio, so it is read from the raw JSON.
+func openAIAudioDelta(raw string) ([]byte, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var delta struct {
+		Audio struct {
+			Data string `json:"data"`
+		} `json:"audio"`
+	}
+	if err := json.Unmarshal([]byte(raw), &delta); err != nil {
+		return nil, fmt.Errorf("failed to parse audio delta: %v", err)
+	}
+	if delta.Audio.Data == "" {
+		return nil, nil
+	}
+	pcm, err := base64.StdEncoding.DecodeString(delta.Audio.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audio delta: %v", err)
+	}
+	return pcm, nil
+}
+
+// Synthesize implements Provider
+func (p *OpenAIAudio) Synthesize(ctx context.Context, opts Options) (*Audio, error) {
+	body, rate, err := p.StreamPCM(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return &Audio{Data: data, Encoding: EncodingPCM, SampleRate: rate}, nil
+}
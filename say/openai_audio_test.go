@@ -0,0 +1,52 @@
+package say
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsOpenAIAudioModel(t *testing.T) {
+	assert.True(t, IsOpenAIAudioModel("gpt-4o-audio-preview"))
+	assert.True(t, IsOpenAIAudioModel("gpt-4o-mini-audio-preview-2024-12-17"))
+	assert.False(t, IsOpenAIAudioModel("gpt-4o-mini-tts"))
+	assert.False(t, IsOpenAIAudioModel("tts-1"))
+}
+
+func TestOpenAIAudioDelta(t *testing.T) {
+	pcm := []byte{1, 2, 3}
+	raw := `{"audio":{"id":"audio_1","data":"` + base64.StdEncoding.EncodeToString(pcm) + `"}}`
+	data, err := openAIAudioDelta(raw)
+	require.NoError(t, err)
+	assert.Equal(t, pcm, data)
+
+	data, err = openAIAudioDelta(`{"audio":{"transcript":"Hello"}}`)
+	require.NoError(t, err)
+	assert.Empty(t, data)
+
+	data, err = openAIAudioDelta("")
+	require.NoError(t, err)
+	assert.Empty(t, data)
+
+	_, err = openAIAudioDelta(`{"audio":{"data":"not base64!"}}`)
+	assert.Error(t, err)
+}
+
+func TestPCMReaderStream(t *testing.T) {
+	// Two samples followed by a stray byte
+	stream := NewPCMReaderStream(bytes.NewReader([]byte{0x00, 0x40, 0x00, 0xc0, 0xff}))
+	samples := make([][2]float64, 4)
+	n, ok := stream.Stream(samples)
+	assert.True(t, ok)
+	assert.Equal(t, 2, n)
+	assert.InDelta(t, 0.5, samples[0][0], 1e-9)
+	assert.InDelta(t, -0.5, samples[1][1], 1e-9)
+
+	n, ok = stream.Stream(samples)
+	assert.False(t, ok)
+	assert.Zero(t, n)
+	assert.NoError(t, stream.Err())
+}
@@ -0,0 +1,98 @@
+package say
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIAccountHeaders(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Write([]byte("ID3"))
+	}))
+	defer server.Close()
+	t.Setenv("OPENAI_BASE_URL", server.URL)
+	t.Setenv("OPENAI_ORG_ID", "")
+	t.Setenv("OPENAI_PROJECT_ID", "proj_env")
+
+	_, err := SynthesizeOpenAI(context.Background(), OpenAISpeechParams{APIKey: "sk-test", Organization: "org-test", Text: "Hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer sk-test", headers.Get("Authorization"))
+	assert.Equal(t, "org-test", headers.Get("OpenAI-Organization"))
+	assert.Equal(t, "proj_env", headers.Get("OpenAI-Project"))
+
+	// Unset fields send no organization or project
+	t.Setenv("OPENAI_PROJECT_ID", "")
+	_, err = SynthesizeOpenAI(context.Background(), OpenAISpeechParams{APIKey: "sk-test", Text: "Hello"})
+	require.NoError(t, err)
+	assert.Empty(t, headers.Get("OpenAI-Organization"))
+	assert.Empty(t, headers.Get("OpenAI-Project"))
+}
+
+func TestOpenAIInstructions(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Write([]byte("ID3"))
+	}))
+	defer server.Close()
+	t.Setenv("OPENAI_BASE_URL", server.URL)
+
+	for model, supported := range map[string]bool{"gpt-4o-mini-tts": true, "tts-1": false, "tts-1-hd": false} {
+		assert.Equal(t, supported, OpenAISupportsInstructions(model), model)
+		_, err := SynthesizeOpenAI(context.Background(), OpenAISpeechParams{APIKey: "sk-test", Text: "Hello", Model: model, Instructions: "Whisper"})
+		require.NoError(t, err)
+		if supported {
+			assert.Equal(t, "Whisper", body["instructions"], model)
+		} else {
+			assert.NotContains(t, body, "instructions", model)
+		}
+	}
+}
+
+func TestOpenAIPCMStream(t *testing.T) {
+	// 5 samples written in chunks that split samples between flushes
+	samples := []byte{0x00, 0x40, 0x00, 0xc0, 0xff, 0x7f, 0x01, 0x80, 0x00, 0x00}
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "audio/pcm")
+		for _, chunk := range [][]byte{samples[:3], samples[3:4], samples[4:9], samples[9:]} {
+			w.Write(chunk)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+	t.Setenv("OPENAI_BASE_URL", server.URL)
+
+	provider := &OpenAIPCM{APIKey: "sk-test"}
+	r, rate, err := provider.StreamPCM(context.Background(), Options{Text: "Hello"})
+	require.NoError(t, err)
+	defer r.Close()
+	assert.Equal(t, "pcm", body["response_format"])
+	assert.Equal(t, openAIPCMSampleRate, rate)
+
+	stream := NewPCMReaderStream(r)
+	got := make([][2]float64, 8)
+	n := 0
+	for {
+		read, ok := stream.Stream(got[n:])
+		n += read
+		if !ok {
+			break
+		}
+	}
+	require.NoError(t, stream.Err())
+	require.Equal(t, 5, n)
+	assert.Equal(t, []float64{0.5, -0.5, 32767.0 / 32768, -32767.0 / 32768, 0}, []float64{got[0][0], got[1][0], got[2][0], got[3][0], got[4][0]})
+}
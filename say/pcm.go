@@ -0,0 +1,250 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package say
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+)
+
+// pcmDiagnostics is set by SetPCMDiagnostics
+var pcmDiagnostics atomic.Bool
+
+// SetPCMDiagnostics logs PCMStats at debug level for each raw PCM clip decoded for
+// playback, to diagnose audio that plays too fast, too slow or too quietly
+func SetPCMDiagnostics(enabled bool) {
+	pcmDiagnostics.Store(enabled)
+}
+
+// PCMStats describes raw PCM audio
+type PCMStats struct {
+	SampleRate beep.SampleRate
+	Channels   int
+	Bytes      int
+	// Duration is computed from the byte length, sample rate and channel count
+	Duration time.Duration
+	// Peak is the largest absolute sample, from 0 to 1
+	Peak float64
+}
+
+// PeakDBFS returns Peak in decibels relative to full scale, -Inf for silence
+func (s PCMStats) PeakDBFS() float64 {
+	return 20 * math.Log10(s.Peak)
+}
+
+// PCMStream implements beep.StreamSeeker for playing raw PCM audio data
+type PCMStream struct {
+	data       []byte
+	sampleRate beep.SampleRate
+	// channels is 1 for mono or 2 for interleaved stereo
+	channels int
+	// position is the byte offset of the next frame
+	position int
+}
+
+// NewPCMStream returns a stream over 16-bit little-endian mono PCM
+func NewPCMStream(data []byte, sampleRate beep.SampleRate) *PCMStream {
+	return NewPCMStreamChannels(data, sampleRate, 1)
+}
+
+// NewPCMStreamChannels returns a stream over 16-bit little-endian PCM with 1 (mono)
+// or 2 (interleaved left/right stereo) channels. Other channel counts are read as mono.
+func NewPCMStreamChannels(data []byte, sampleRate beep.SampleRate, channels int) *PCMStream {
+	if channels != 2 {
+		channels = 1
+	}
+	return &PCMStream{
+		data:       data,
+		sampleRate: sampleRate,
+		channels:   channels,
+		position:   0,
+	}
+}
+
+// frameSize is the bytes per sample frame, one 16-bit sample per channel
+func (s *PCMStream) frameSize() int {
+	return 2 * s.channels
+}
+
+// sample converts the 16-bit little-endian sample at offset to float64
+func (s *PCMStream) sample(offset int) float64 {
+	return float64(int16(s.data[offset])|int16(s.data[offset+1])<<8) / 32768.0
+}
+
+// Stats describes the stream's audio, scanning every sample for the peak
+func (s *PCMStream) Stats() PCMStats {
+	stats := PCMStats{SampleRate: s.sampleRate, Channels: s.channels, Bytes: len(s.data)}
+	if s.sampleRate > 0 {
+		stats.Duration = s.sampleRate.D(s.Len())
+	}
+	for offset := 0; offset+1 < len(s.data); offset += 2 {
+		stats.Peak = max(stats.Peak, math.Abs(s.sample(offset)))
+	}
+	return stats
+}
+
+// logPCMStats logs the stream's stats when SetPCMDiagnostics is on and debug logging is enabled
+func logPCMStats(s *PCMStream) {
+	if !pcmDiagnostics.Load() || log.GetLevel() > log.DebugLevel {
+		return
+	}
+	stats := s.Stats()
+	log.Debug("PCM diagnostics",
+		"sample_rate", int(stats.SampleRate),
+		"channels", stats.Channels,
+		"bytes", stats.Bytes,
+		"duration", stats.Duration.Round(time.Millisecond),
+		"peak", math.Round(stats.Peak*1000)/1000,
+		"peak_dbfs", math.Round(stats.PeakDBFS()*10)/10,
+	)
+	if len(s.data)%s.frameSize() != 0 {
+		log.Debug("PCM length is not a whole number of frames, the channel count or sample width may be wrong", "bytes", stats.Bytes, "frame_bytes", s.frameSize())
+	}
+}
+
+// logStreamedPCMFormat logs the format of PCM played as it streams in, when PCM
+// diagnostics are on. Length, duration and peak aren't known until it ends.
+func logStreamedPCMFormat(format beep.Format) {
+	if !pcmDiagnostics.Load() || log.GetLevel() > log.DebugLevel {
+		return
+	}
+	log.Debug("PCM diagnostics", "sample_rate", int(format.SampleRate), "channels", format.NumChannels, "streamed", true)
+}
+
+func (s *PCMStream) Stream(samples [][2]float64) (n int, ok bool) {
+	if s.position >= len(s.data) {
+		return 0, false
+	}
+
+	frame := s.frameSize()
+	for i := range samples {
+		// A trailing partial frame is dropped
+		if s.position+frame > len(s.data) {
+			s.position = len(s.data)
+			return i, i > 0
+		}
+
+		left := s.sample(s.position)
+		right := left // Mono to stereo
+		if s.channels == 2 {
+			right = s.sample(s.position + 2)
+		}
+		samples[i][0] = left
+		samples[i][1] = right
+
+		s.position += frame
+	}
+
+	return len(samples), true
+}
+
+func (s *PCMStream) Err() error {
+	return nil
+}
+
+func (s *PCMStream) Len() int {
+	return len(s.data) / s.frameSize()
+}
+
+func (s *PCMStream) Position() int {
+	return s.position / s.frameSize()
+}
+
+func (s *PCMStream) Seek(p int) error {
+	s.position = p * s.frameSize()
+	if s.position < 0 {
+		s.position = 0
+	}
+	if s.position >= len(s.data) {
+		s.position = len(s.data)
+	}
+	return nil
+}
+
+// PCMReaderStream implements beep.Streamer for 16-bit little-endian mono PCM
+// read from r as it arrives, e.g. from a network stream
+type PCMReaderStream struct {
+	r   io.Reader
+	buf []byte
+	eof bool
+	err error
+}
+
+// NewPCMReaderStream returns a stream reading samples from r
+func NewPCMReaderStream(r io.Reader) *PCMReaderStream {
+	return &PCMReaderStream{r: r}
+}
+
+func (s *PCMReaderStream) Stream(samples [][2]float64) (n int, ok bool) {
+	if s.eof || s.err != nil {
+		return 0, false
+	}
+	if cap(s.buf) < len(samples)*2 {
+		s.buf = make([]byte, len(samples)*2)
+	}
+	read, err := io.ReadFull(s.r, s.buf[:len(samples)*2])
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		s.eof = true
+	} else if err != nil {
+		s.err = err
+	}
+
+	n = read / 2
+	for i := 0; i < n; i++ {
+		sampleFloat := float64(int16(s.buf[2*i])|int16(s.buf[2*i+1])<<8) / 32768.0
+		samples[i][0] = sampleFloat
+		samples[i][1] = sampleFloat
+	}
+	return n, n > 0
+}
+
+func (s *PCMReaderStream) Err() error {
+	return s.err
+}
+
+// pcmToWAV wraps little-endian integer PCM samples in a canonical 44-byte RIFF/WAVE header
+func pcmToWAV(data []byte, sampleRate, channels, bitsPerSample int) []byte {
+	blockAlign := channels * bitsPerSample / 8
+	var buf bytes.Buffer
+	buf.Grow(44 + len(data))
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+len(data)))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))
+	binary.Write(&buf, binary.LittleEndian, uint16(1)) // PCM
+	binary.Write(&buf, binary.LittleEndian, uint16(channels))
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate*blockAlign))
+	binary.Write(&buf, binary.LittleEndian, uint16(blockAlign))
+	binary.Write(&buf, binary.LittleEndian, uint16(bitsPerSample))
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
+	return buf.Bytes()
+}
@@ -0,0 +1,100 @@
+package say
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPCMToWAV(t *testing.T) {
+	// Canonical 44-byte header followed by two 16-bit samples at 8kHz mono
+	want := []byte{
+		'R', 'I', 'F', 'F', 0x28, 0, 0, 0, 'W', 'A', 'V', 'E',
+		'f', 'm', 't', ' ', 0x10, 0, 0, 0, 0x01, 0, 0x01, 0,
+		0x40, 0x1f, 0, 0, 0x80, 0x3e, 0, 0, 0x02, 0, 0x10, 0,
+		'd', 'a', 't', 'a', 0x04, 0, 0, 0, 0x01, 0x00, 0xff, 0x7f,
+	}
+	assert.Equal(t, want, pcmToWAV([]byte{0x01, 0x00, 0xff, 0x7f}, 8000, 1, 16))
+
+	wav := pcmToWAV(make([]byte, 100), 44100, 2, 8)
+	require.Len(t, wav, 144)
+	le := binary.LittleEndian
+	assert.Equal(t, uint32(136), le.Uint32(wav[4:]), "RIFF chunk size")
+	assert.Equal(t, uint16(2), le.Uint16(wav[22:]), "channels")
+	assert.Equal(t, uint32(44100), le.Uint32(wav[24:]), "sample rate")
+	assert.Equal(t, uint32(88200), le.Uint32(wav[28:]), "byte rate")
+	assert.Equal(t, uint16(2), le.Uint16(wav[32:]), "block align")
+	assert.Equal(t, uint16(8), le.Uint16(wav[34:]), "bits per sample")
+	assert.Equal(t, uint32(100), le.Uint32(wav[40:]), "data size")
+
+	encoding, ok := SniffEncoding(wav)
+	assert.True(t, ok)
+	assert.Equal(t, EncodingWAV, encoding)
+}
+
+func TestPCMStreamChannels(t *testing.T) {
+	// Frames of (left, right) 16-bit samples: (0.5, -0.5), (0.25, 0)
+	stereo := []byte{0x00, 0x40, 0x00, 0xc0, 0x00, 0x20, 0x00, 0x00}
+
+	s := NewPCMStreamChannels(stereo, 8000, 2)
+	assert.Equal(t, 2, s.Len())
+	samples := make([][2]float64, 4)
+	n, ok := s.Stream(samples)
+	assert.True(t, ok)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, [2]float64{0.5, -0.5}, samples[0])
+	assert.Equal(t, [2]float64{0.25, 0}, samples[1])
+	assert.Equal(t, 2, s.Position())
+	_, ok = s.Stream(samples)
+	assert.False(t, ok)
+
+	require.NoError(t, s.Seek(1))
+	n, _ = s.Stream(samples)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, [2]float64{0.25, 0}, samples[0])
+
+	mono := NewPCMStream(stereo, 8000)
+	assert.Equal(t, 4, mono.Len())
+	n, _ = mono.Stream(samples)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, [2]float64{0.5, 0.5}, samples[0])
+	assert.Equal(t, [2]float64{-0.5, -0.5}, samples[1])
+
+	// A trailing partial frame ends the stream
+	partial := NewPCMStreamChannels(stereo[:6], 8000, 2)
+	assert.Equal(t, 1, partial.Len())
+	n, ok = partial.Stream(samples)
+	assert.Equal(t, 1, n)
+	assert.True(t, ok)
+	n, ok = partial.Stream(samples)
+	assert.Equal(t, 0, n)
+	assert.False(t, ok)
+}
+
+func TestPCMStreamStats(t *testing.T) {
+	// 8000 mono frames at 8kHz peaking at -0.5
+	data := make([]byte, 16000)
+	binary.LittleEndian.PutUint16(data[100:], 0x2000)
+	binary.LittleEndian.PutUint16(data[200:], 0xc000)
+	stats := NewPCMStream(data, 8000).Stats()
+	assert.Equal(t, beep.SampleRate(8000), stats.SampleRate)
+	assert.Equal(t, 1, stats.Channels)
+	assert.Equal(t, 16000, stats.Bytes)
+	assert.Equal(t, time.Second, stats.Duration)
+	assert.InDelta(t, 0.5, stats.Peak, 1e-9)
+	assert.InDelta(t, -6.02, stats.PeakDBFS(), 0.01)
+
+	// The same bytes read as stereo last half as long
+	stats = NewPCMStreamChannels(data, 8000, 2).Stats()
+	assert.Equal(t, 2, stats.Channels)
+	assert.Equal(t, 500*time.Millisecond, stats.Duration)
+
+	silence := NewPCMStream(make([]byte, 4), 24000).Stats()
+	assert.Zero(t, silence.Peak)
+	assert.True(t, math.IsInf(silence.PeakDBFS(), -1))
+}
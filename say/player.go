@@ -0,0 +1,312 @@
+package say
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+	"github.com/gopxl/beep/v2/speaker"
+)
+
+// AudioPlayer plays synthesized speech. Implementations can send audio anywhere,
+// e.g. stream it over the network instead of the local speaker.
+type AudioPlayer interface {
+	// Play plays encoded audio and blocks until it finishes or ctx is cancelled
+	Play(ctx context.Context, audio *Audio) error
+	// PlayStream plays decoded samples and blocks until they finish or ctx is cancelled
+	PlayStream(ctx context.Context, streamer beep.Streamer, format beep.Format) error
+}
+
+// DefaultPlayer is used when Options.Player is nil
+var DefaultPlayer AudioPlayer = NewSpeakerPlayer()
+
+// ErrNoAudioDevice is returned when the speaker cannot be initialized, e.g. on headless machines
+var ErrNoAudioDevice = errors.New("no audio output device available")
+
+// Attempts made to initialize the speaker before giving up on a call
+const speakerInitAttempts = 2
+
+const (
+	// DefaultSpeakerBuffer is the speaker buffer used when SpeakerPlayer.Buffer is 0
+	DefaultSpeakerBuffer = 100 * time.Millisecond
+	// DefaultPrebuffer is the audio decoded before playback starts when SpeakerPlayer.Prebuffer is 0
+	DefaultPrebuffer = 200 * time.Millisecond
+	// DefaultSpeakerSampleRate is the speaker rate used when SpeakerPlayer.SampleRate is 0
+	DefaultSpeakerSampleRate = beep.SampleRate(44100)
+	// MinSpeakerSampleRate and MaxSpeakerSampleRate bound SpeakerPlayer.SampleRate
+	MinSpeakerSampleRate = beep.SampleRate(8000)
+	MaxSpeakerSampleRate = beep.SampleRate(192000)
+)
+
+// resampleQuality is beep's interpolation quality, 4 is transparent for speech at little CPU cost
+const resampleQuality = 4
+
+var (
+	speakerMu        sync.Mutex
+	speakerRate      beep.SampleRate // 0 until the speaker is initialized
+	speakerSuspended bool            // set by SuspendSpeaker until the next playback
+)
+
+// fallbackSampleRates are tried when the device rejects the requested rate, some ALSA
+// devices only open at 48kHz while others only take 44.1kHz
+var fallbackSampleRates = []beep.SampleRate{48000, 44100, 32000, 22050, 16000}
+
+// initSpeaker lazily initializes the beep speaker and returns its sample rate.
+// The speaker can only be initialized once per process, so later calls reuse the
+// first sample rate and buffer, and streams are resampled to it. A failed init is not remembered, the next call tries again.
+// When the device rejects sampleRate, the fallback rates are tried in order.
+func initSpeaker(sampleRate beep.SampleRate, buffer time.Duration) (beep.SampleRate, error) {
+	speakerMu.Lock()
+	defer speakerMu.Unlock()
+	if speakerRate != 0 {
+		if speakerSuspended {
+			if err := resumeSpeaker(); err != nil {
+				return 0, fmt.Errorf("%w: %v", ErrNoAudioDevice, err)
+			}
+			speakerSuspended = false
+			log.Info("Speaker resumed")
+		}
+		return speakerRate, nil
+	}
+
+	rates := []beep.SampleRate{sampleRate}
+	for _, rate := range fallbackSampleRates {
+		if !slices.Contains(rates, rate) {
+			rates = append(rates, rate)
+		}
+	}
+	var err error
+	for attempt := 1; attempt <= speakerInitAttempts; attempt++ {
+		for _, rate := range rates {
+			log.Debug("Initializing speaker", "sampleRate", rate, "buffer", buffer, "attempt", attempt)
+			if err = tryInitSpeaker(rate, buffer); err == nil {
+				speakerRate = rate
+				startMaster()
+				if rate != sampleRate {
+					log.Warn("Speaker rejected the requested sample rate, using a fallback", "requested", sampleRate, "selected", rate)
+				} else {
+					log.Info("Speaker initialized", "sampleRate", rate)
+				}
+				return speakerRate, nil
+			}
+			log.Debug("Speaker rejected sample rate", "sampleRate", rate, "error", err)
+		}
+		log.Warn("Failed to initialize speaker at any sample rate", "attempt", attempt, "rates", rates, "error", err)
+		if attempt < speakerInitAttempts {
+			time.Sleep(250 * time.Millisecond)
+		}
+	}
+	return 0, fmt.Errorf("%w: %v", ErrNoAudioDevice, err)
+}
+
+// SpeakerSampleRate returns the sample rate the speaker was opened at, 0 until the first playback
+func SpeakerSampleRate() beep.SampleRate {
+	speakerMu.Lock()
+	defer speakerMu.Unlock()
+	return speakerRate
+}
+
+// SpeakerPlaying reports whether the speaker is playing anything, including audio
+// playing in the background after the call that started it returned
+func SpeakerPlaying() bool {
+	speaker.Lock()
+	defer speaker.Unlock()
+	return masterMixer.Len() > 0
+}
+
+// SuspendSpeaker releases the audio device while nothing is playing, e.g. after a long idle
+// period, so other apps can use it. The next playback resumes it transparently. It reports
+// whether the device was suspended, false when it isn't open, already suspended or busy.
+func SuspendSpeaker() (bool, error) {
+	speakerMu.Lock()
+	defer speakerMu.Unlock()
+	if speakerRate == 0 || speakerSuspended {
+		return false, nil
+	}
+	if SpeakerPlaying() {
+		return false, nil
+	}
+	if err := suspendSpeaker(); err != nil {
+		return false, err
+	}
+	speakerSuspended = true
+	log.Info("Speaker suspended")
+	return true, nil
+}
+
+// suspendSpeaker and resumeSpeaker pause and restart the audio device. Swapped out in tests.
+var (
+	suspendSpeaker = speaker.Suspend
+	resumeSpeaker  = speaker.Resume
+)
+
+// tryInitSpeaker calls speaker.Init, turning a driver panic into an error. Swapped out in tests.
+var tryInitSpeaker = func(sampleRate beep.SampleRate, buffer time.Duration) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("speaker init panicked: %v", r)
+		}
+	}()
+	return speaker.Init(sampleRate, sampleRate.N(buffer))
+}
+
+// SpeakerPlayer plays audio through the default output device. The device is
+// opened on first use, so creating one never touches the audio hardware.
+type SpeakerPlayer struct {
+	// Buffer is the speaker buffer, applied when the device is opened. A larger
+	// buffer stops stutter on slow machines but adds latency to every clip and
+	// makes cancellation take longer to be heard. 0 uses DefaultSpeakerBuffer.
+	Buffer time.Duration
+	// Prebuffer is how much audio is decoded before playback starts, so a stream
+	// that arrives slower than real time at first doesn't underrun. It delays the
+	// first sound by up to that long. 0 uses DefaultPrebuffer, negative disables it.
+	Prebuffer time.Duration
+	// SampleRate is the rate the device is opened at. Every stream is resampled to
+	// it, whatever rate the provider returns. 0 uses DefaultSpeakerSampleRate.
+	SampleRate beep.SampleRate
+}
+
+// NewSpeakerPlayer returns an AudioPlayer backed by the beep speaker
+func NewSpeakerPlayer() *SpeakerPlayer {
+	return &SpeakerPlayer{}
+}
+
+// Open opens the output device without playing anything, so the first clip doesn't
+// wait for it. It returns the rate the device is open at, see SpeakerSampleRate.
+func (p *SpeakerPlayer) Open() (beep.SampleRate, error) {
+	return initSpeaker(cmp.Or(p.SampleRate, DefaultSpeakerSampleRate), cmp.Or(p.Buffer, DefaultSpeakerBuffer))
+}
+
+// Play implements AudioPlayer
+func (p *SpeakerPlayer) Play(ctx context.Context, audio *Audio) error {
+	streamer, format, err := audio.Decode()
+	if err != nil {
+		return err
+	}
+	return p.PlayStream(ctx, streamer, format)
+}
+
+// PlayStream implements AudioPlayer. The speaker is cleared immediately when ctx is cancelled.
+func (p *SpeakerPlayer) PlayStream(ctx context.Context, streamer beep.Streamer, format beep.Format) error {
+	rate, err := p.Open()
+	if err != nil {
+		return err
+	}
+	if prebuffer := cmp.Or(p.Prebuffer, DefaultPrebuffer); prebuffer > 0 {
+		streamer = prebufferStreamer(streamer, format.SampleRate.N(prebuffer))
+	}
+	streamer = resampleTo(streamer, format.SampleRate, rate)
+
+	done := make(chan bool, 1)
+	playing := &stoppableStreamer{Streamer: beep.Seq(streamer, beep.Callback(func() {
+		done <- true
+	}))}
+	playMaster(playing)
+
+	select {
+	case <-done:
+		log.Debug("Audio playback completed normally")
+		return nil
+	case <-ctx.Done():
+		log.Debug("Context cancelled, stopping audio playback")
+		playing.stop()
+		return ctx.Err()
+	}
+}
+
+// resampleTo converts streamer from one sample rate to another, so audio of any rate
+// plays at its own pitch and speed on a speaker opened at another
+func resampleTo(streamer beep.Streamer, from, to beep.SampleRate) beep.Streamer {
+	if from == to {
+		return streamer
+	}
+	log.Debug("Resampling audio to the speaker rate", "from", from, "to", to)
+	return beep.Resample(resampleQuality, from, to, streamer)
+}
+
+// prebufferStreamer reads up to n samples from s right away and returns a streamer
+// playing them before the rest of s. Reads block the caller, not the speaker.
+func prebufferStreamer(s beep.Streamer, n int) beep.Streamer {
+	samples := make([][2]float64, n)
+	filled := 0
+	for filled < n {
+		k, ok := s.Stream(samples[filled:])
+		filled += k
+		if !ok {
+			break
+		}
+	}
+	log.Debug("Prebuffered audio", "samples", filled)
+	return beep.Seq(&prebuffered{samples: samples[:filled]}, s)
+}
+
+// prebuffered plays samples read ahead by prebufferStreamer
+type prebuffered struct {
+	samples [][2]float64
+}
+
+func (p *prebuffered) Stream(samples [][2]float64) (int, bool) {
+	if len(p.samples) == 0 {
+		return 0, false
+	}
+	n := copy(samples, p.samples)
+	p.samples = p.samples[n:]
+	return n, true
+}
+
+func (p *prebuffered) Err() error {
+	return nil
+}
+
+// stoppableStreamer can be ended early. The speaker drops it on its next buffer,
+// leaving other streams that are playing at the same time untouched.
+type stoppableStreamer struct {
+	beep.Streamer
+	stopped bool // guarded by the speaker lock
+}
+
+func (s *stoppableStreamer) Stream(samples [][2]float64) (int, bool) {
+	if s.stopped {
+		return 0, false
+	}
+	return s.Streamer.Stream(samples)
+}
+
+// stop ends the stream, the source is not read again
+func (s *stoppableStreamer) stop() {
+	speaker.Lock()
+	s.stopped = true
+	speaker.Unlock()
+}
+
+// NoAudioPlayer refuses to play, for machines without an audio device.
+// It never initializes the speaker.
+type NoAudioPlayer struct{}
+
+// Play implements AudioPlayer
+func (NoAudioPlayer) Play(ctx context.Context, audio *Audio) error {
+	return fmt.Errorf("%w: audio playback is disabled", ErrNoAudioDevice)
+}
+
+// PlayStream implements AudioPlayer
+func (NoAudioPlayer) PlayStream(ctx context.Context, streamer beep.Streamer, format beep.Format) error {
+	return fmt.Errorf("%w: audio playback is disabled", ErrNoAudioDevice)
+}
+
+// playAudio plays audio with player, decoding it first when the volume needs adjusting
+func playAudio(ctx context.Context, player AudioPlayer, audio *Audio, volume float64) error {
+	if volume == 0 || volume == 1 {
+		return player.Play(ctx, audio)
+	}
+	streamer, format, err := audio.Decode()
+	if err != nil {
+		return err
+	}
+	return player.PlayStream(ctx, WithVolume(streamer, volume), format)
+}
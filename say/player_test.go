@@ -0,0 +1,197 @@
+package say
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/gopxl/beep/v2/speaker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// risingZeroCrossings counts the upward zero crossings of the left channel
+func risingZeroCrossings(samples [][2]float64) int {
+	n := 0
+	for i := 1; i < len(samples); i++ {
+		if samples[i-1][0] < 0 && samples[i][0] >= 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func TestResampleToSpeakerRate(t *testing.T) {
+	const source, speaker = beep.SampleRate(24000), DefaultSpeakerSampleRate
+	const frequency = 440.0
+	sine := make([][2]float64, source.N(1e9)) // one second
+	for i := range sine {
+		v := 0.5 * math.Sin(2*math.Pi*frequency*float64(i)/float64(source))
+		sine[i] = [2]float64{v, v}
+	}
+	buffer := beep.NewBuffer(beep.Format{SampleRate: source, NumChannels: 1, Precision: 2})
+	buffer.Append(&prebuffered{samples: sine})
+
+	var out [][2]float64
+	streamer := resampleTo(buffer.Streamer(0, buffer.Len()), source, speaker)
+	chunk := make([][2]float64, 512)
+	for {
+		n, ok := streamer.Stream(chunk)
+		out = append(out, chunk[:n]...)
+		if !ok {
+			break
+		}
+	}
+
+	// One second of audio stays one second long and keeps its pitch at the speaker rate
+	assert.InDelta(t, int(speaker), len(out), float64(speaker.N(5e6)))
+	seconds := float64(len(out)) / float64(speaker)
+	assert.InDelta(t, frequency, float64(risingZeroCrossings(out))/seconds, 2)
+
+	// Without resampling the same samples would play 1.8 times too fast and high
+	assert.InDelta(t, frequency*float64(speaker)/float64(source), float64(risingZeroCrossings(sine))*float64(speaker)/float64(len(sine)), 2)
+
+	assert.Same(t, streamer, resampleTo(streamer, speaker, speaker))
+}
+
+func TestSpeakerPlayerResamples(t *testing.T) {
+	prev := tryInitSpeaker
+	t.Cleanup(func() {
+		tryInitSpeaker = prev
+		speakerRate = 0
+	})
+	tryInitSpeaker = func(sampleRate beep.SampleRate, buffer time.Duration) error { return nil }
+
+	const source, speaker = beep.SampleRate(24000), DefaultSpeakerSampleRate
+	const frequency = 440.0
+	sine := make([][2]float64, source.N(time.Second))
+	for i := range sine {
+		v := 0.5 * math.Sin(2*math.Pi*frequency*float64(i)/float64(source))
+		sine[i] = [2]float64{v, v}
+	}
+	played := make(chan error, 1)
+	go func() {
+		player := &SpeakerPlayer{SampleRate: speaker}
+		played <- player.PlayStream(t.Context(), &prebuffered{samples: sine}, beep.Format{SampleRate: source, NumChannels: 1, Precision: 2})
+	}()
+
+	// Pull the master mix the way the device would, a little longer than the stream lasts
+	require.Eventually(t, SpeakerPlaying, time.Second, time.Millisecond)
+	var out [][2]float64
+	chunk := make([][2]float64, 512)
+	for range speaker.N(1200*time.Millisecond) / len(chunk) {
+		playMasterChunk(chunk)
+		out = append(out, chunk...)
+	}
+	select {
+	case err := <-played:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("playback did not end")
+	}
+	// Trim the silence the mix plays after the stream ends
+	for len(out) > 0 && out[len(out)-1] == [2]float64{} {
+		out = out[:len(out)-1]
+	}
+
+	// Audio of any rate keeps its length and pitch on the speaker
+	assert.InDelta(t, int(speaker), len(out), float64(speaker.N(20*time.Millisecond)))
+	seconds := float64(len(out)) / float64(speaker)
+	assert.InDelta(t, frequency, float64(risingZeroCrossings(out))/seconds, 5)
+}
+
+// playMasterChunk streams the next samples of the master mix, as the speaker does
+func playMasterChunk(chunk [][2]float64) {
+	speaker.Lock()
+	defer speaker.Unlock()
+	masterVolume.Stream(chunk)
+}
+
+func TestInitSpeakerFallbackRates(t *testing.T) {
+	prev := tryInitSpeaker
+	t.Cleanup(func() {
+		tryInitSpeaker = prev
+		speakerRate = 0
+	})
+
+	var tried []beep.SampleRate
+	tryInitSpeaker = func(sampleRate beep.SampleRate, buffer time.Duration) error {
+		tried = append(tried, sampleRate)
+		if sampleRate != 48000 {
+			return errors.New("Invalid argument")
+		}
+		return nil
+	}
+	rate, err := initSpeaker(44100, DefaultSpeakerBuffer)
+	require.NoError(t, err)
+	assert.Equal(t, beep.SampleRate(48000), rate)
+	assert.Equal(t, []beep.SampleRate{44100, 48000}, tried)
+	assert.Equal(t, beep.SampleRate(48000), SpeakerSampleRate())
+
+	// Once open, the device keeps its rate
+	rate, err = initSpeaker(22050, DefaultSpeakerBuffer)
+	require.NoError(t, err)
+	assert.Equal(t, beep.SampleRate(48000), rate)
+
+	speakerRate = 0
+	tried = nil
+	tryInitSpeaker = func(sampleRate beep.SampleRate, buffer time.Duration) error {
+		tried = append(tried, sampleRate)
+		return errors.New("no such device")
+	}
+	_, err = initSpeaker(16000, DefaultSpeakerBuffer)
+	assert.ErrorIs(t, err, ErrNoAudioDevice)
+	assert.Equal(t, []beep.SampleRate{16000, 48000, 44100, 32000, 22050, 16000, 48000, 44100, 32000, 22050}, tried, "every rate once per attempt")
+}
+
+func TestSetMasterVolume(t *testing.T) {
+	t.Cleanup(func() { SetMasterVolume(MaxMasterVolume) })
+
+	require.NoError(t, SetMasterVolume(0.5))
+	assert.Equal(t, 0.5, MasterVolume())
+	assert.False(t, masterVolume.Silent)
+	assert.InDelta(t, -1, masterVolume.Volume, 1e-9)
+
+	require.NoError(t, SetMasterVolume(0))
+	assert.True(t, masterVolume.Silent)
+
+	assert.Error(t, SetMasterVolume(1.01))
+	assert.Error(t, SetMasterVolume(math.NaN()))
+	assert.Equal(t, 0.0, MasterVolume())
+}
+
+func TestSuspendSpeaker(t *testing.T) {
+	prevInit, prevSuspend, prevResume := tryInitSpeaker, suspendSpeaker, resumeSpeaker
+	t.Cleanup(func() {
+		tryInitSpeaker, suspendSpeaker, resumeSpeaker = prevInit, prevSuspend, prevResume
+		speakerRate = 0
+		speakerSuspended = false
+	})
+	var suspends, resumes int
+	tryInitSpeaker = func(sampleRate beep.SampleRate, buffer time.Duration) error { return nil }
+	suspendSpeaker = func() error { suspends++; return nil }
+	resumeSpeaker = func() error { resumes++; return nil }
+
+	suspended, err := SuspendSpeaker()
+	require.NoError(t, err)
+	assert.False(t, suspended, "a speaker that was never opened has nothing to release")
+
+	_, err = initSpeaker(44100, DefaultSpeakerBuffer)
+	require.NoError(t, err)
+	suspended, err = SuspendSpeaker()
+	require.NoError(t, err)
+	assert.True(t, suspended)
+	suspended, _ = SuspendSpeaker()
+	assert.False(t, suspended)
+	assert.Equal(t, 1, suspends)
+
+	rate, err := initSpeaker(22050, DefaultSpeakerBuffer)
+	require.NoError(t, err)
+	assert.Equal(t, beep.SampleRate(44100), rate, "resuming keeps the rate the device was opened at")
+	assert.Equal(t, 1, resumes)
+	_, err = initSpeaker(44100, DefaultSpeakerBuffer)
+	require.NoError(t, err)
+	assert.Equal(t, 1, resumes)
+}
@@ -0,0 +1,84 @@
+package say
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+)
+
+const (
+	// maxStreamResumes is how many times one utterance is resumed after its stream failed
+	maxStreamResumes = 2
+	// speechCharsPerSecond is a typical speaking rate at speed 1, used to estimate how
+	// far into the text a failed stream got
+	speechCharsPerSecond = 15.0
+)
+
+// openStream starts a provider stream for opts, returning its decoded samples and a
+// func releasing the response
+type openStream func(ctx context.Context, opts Options) (beep.Streamer, beep.Format, func(), error)
+
+// remainingText estimates the text still to be spoken after played audio. It backs up
+// to the start of the word, repeating a little is better than skipping some.
+func remainingText(text string, played time.Duration, speed float64) string {
+	runes := []rune(text)
+	offset := int(played.Seconds() * speechCharsPerSecond * cmp.Or(speed, 1))
+	if offset >= len(runes) {
+		return ""
+	}
+	for offset > 0 && !unicode.IsSpace(runes[offset]) && !unicode.IsSpace(runes[offset-1]) {
+		offset--
+	}
+	return strings.TrimSpace(string(runes[offset:]))
+}
+
+// countedStreamer counts the samples read through it
+type countedStreamer struct {
+	beep.Streamer
+	samples int
+}
+
+func (s *countedStreamer) Stream(samples [][2]float64) (int, bool) {
+	n, ok := s.Streamer.Stream(samples)
+	s.samples += n
+	return n, ok
+}
+
+// playResumable plays a provider stream. When the stream fails partway, e.g. the
+// connection drops, the rest of the text is requested again from where playback is
+// estimated to have got to, up to maxStreamResumes times. This is best effort: the
+// estimate comes from the speaking rate, so a few words may repeat or be skipped.
+func playResumable(ctx context.Context, opts Options, open openStream) error {
+	for resumes := 0; ; resumes++ {
+		source, format, release, err := open(ctx, opts)
+		if err != nil {
+			return err
+		}
+		counted := &countedStreamer{Streamer: source}
+		err = opts.player().PlayStream(ctx, WithVolume(counted, opts.Volume), format)
+		streamErr := source.Err()
+		release()
+		if err != nil || streamErr == nil || ctx.Err() != nil {
+			return err
+		}
+
+		played := format.SampleRate.D(counted.samples)
+		rest := remainingText(opts.Text, played, opts.Speed)
+		if rest == "" {
+			log.Warn("Speech stream failed near its end, not resuming", "played", played, "error", streamErr)
+			return nil
+		}
+		if resumes == maxStreamResumes {
+			return fmt.Errorf("speech stream failed after %v of audio, gave up after %d resumes: %v", played.Round(time.Millisecond), resumes, streamErr)
+		}
+		log.Warn("Speech stream failed, resuming the rest of the text with a new request",
+			"played", played.Round(time.Millisecond), "remaining_chars", len([]rune(rest)), "error", streamErr)
+		opts.Text = rest
+	}
+}
@@ -0,0 +1,111 @@
+package say
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drainPlayer plays streams instantly, counting their samples
+type drainPlayer struct{ samples int }
+
+func (p *drainPlayer) Play(ctx context.Context, audio *Audio) error { return nil }
+
+func (p *drainPlayer) PlayStream(ctx context.Context, streamer beep.Streamer, format beep.Format) error {
+	buf := make([][2]float64, 512)
+	for {
+		n, ok := streamer.Stream(buf)
+		p.samples += n
+		if !ok {
+			return nil
+		}
+	}
+}
+
+// droppingPCMProvider streams one second of PCM for its first drops calls, then fails
+// as if the connection dropped. Later calls stream a second and end normally.
+type droppingPCMProvider struct {
+	drops int
+	texts []string
+}
+
+func (p *droppingPCMProvider) Synthesize(ctx context.Context, opts Options) (*Audio, error) {
+	return nil, errors.New("not used")
+}
+
+func (p *droppingPCMProvider) StreamPCM(ctx context.Context, opts Options) (io.ReadCloser, beep.SampleRate, error) {
+	p.texts = append(p.texts, opts.Text)
+	second := bytes.NewReader(make([]byte, 2*24000))
+	if len(p.texts) <= p.drops {
+		return io.NopCloser(io.MultiReader(second, iotestErrReader{})), 24000, nil
+	}
+	return io.NopCloser(second), 24000, nil
+}
+
+// droppingMP3Provider is droppingPCMProvider for MP3 streams, the default for ElevenLabs
+// and OpenAI. Dropped streams break off after 39 frames, just over a second.
+type droppingMP3Provider struct {
+	drops int
+	texts []string
+}
+
+func (p *droppingMP3Provider) Synthesize(ctx context.Context, opts Options) (*Audio, error) {
+	return nil, errors.New("not used")
+}
+
+func (p *droppingMP3Provider) Stream(ctx context.Context, opts Options) (io.ReadCloser, error) {
+	p.texts = append(p.texts, opts.Text)
+	if len(p.texts) <= p.drops {
+		return io.NopCloser(io.MultiReader(bytes.NewReader(silentMP3Frames(40)[:417*39+100]), iotestErrReader{})), nil
+	}
+	return io.NopCloser(bytes.NewReader(silentMP3Frames(39))), nil
+}
+
+type iotestErrReader struct{}
+
+func (iotestErrReader) Read([]byte) (int, error) { return 0, errors.New("connection reset by peer") }
+
+func TestRemainingText(t *testing.T) {
+	text := "The quick brown fox jumps over the lazy dog"
+	// 15 characters a second lands inside "fox", so it restarts there
+	assert.Equal(t, "fox jumps over the lazy dog", remainingText(text, time.Second, 1))
+	assert.Equal(t, "jumps over the lazy dog", remainingText(text, time.Second, 4.0/3))
+	assert.Equal(t, text, remainingText(text, 0, 0))
+	assert.Empty(t, remainingText(text, 10*time.Second, 1))
+}
+
+func TestPlayResumable(t *testing.T) {
+	text := "The quick brown fox jumps over the lazy dog and then runs far away"
+
+	t.Run("resumes the rest", func(t *testing.T) {
+		player := &drainPlayer{}
+		provider := &droppingPCMProvider{drops: 1}
+		err := SpeakWith(context.Background(), provider, Options{Text: text, Player: player})
+		require.NoError(t, err)
+		assert.Equal(t, []string{text, "fox jumps over the lazy dog and then runs far away"}, provider.texts)
+		assert.Equal(t, 48000, player.samples)
+	})
+
+	t.Run("gives up", func(t *testing.T) {
+		provider := &droppingPCMProvider{drops: 10}
+		err := SpeakWith(context.Background(), provider, Options{Text: text, Player: &drainPlayer{}})
+		assert.ErrorContains(t, err, "gave up after 2 resumes: connection reset by peer")
+		assert.Len(t, provider.texts, maxStreamResumes+1)
+	})
+
+	t.Run("resumes a dropped MP3 stream", func(t *testing.T) {
+		player := &drainPlayer{}
+		provider := &droppingMP3Provider{drops: 1}
+		err := SpeakWith(context.Background(), provider, Options{Text: text, Player: player})
+		require.NoError(t, err)
+		assert.Equal(t, []string{text, "fox jumps over the lazy dog and then runs far away"}, provider.texts)
+		assert.Equal(t, 2*39*1152, player.samples)
+	})
+}
@@ -0,0 +1,230 @@
+// Package say synthesizes speech with OpenAI, Google Gemini, ElevenLabs or the
+// macOS say command and plays it through the default audio device.
+//
+// It is the engine behind the mcp-say MCP server and can be embedded in other
+// Go programs without the MCP layer:
+//
+//	err := say.Speak(ctx, say.Options{
+//		Provider: say.ProviderOpenAI,
+//		Text:     "Hello, world!",
+//		Voice:    "coral",
+//	})
+package say
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+	"github.com/gopxl/beep/v2/effects"
+)
+
+// Supported provider names
+const (
+	ProviderOpenAI     = "openai"
+	ProviderGoogle     = "google"
+	ProviderElevenLabs = "elevenlabs"
+	ProviderSay        = "say"
+)
+
+// Options configures a Speak call. Zero values fall back to the provider defaults.
+type Options struct {
+	// Provider is one of ProviderOpenAI, ProviderGoogle, ProviderElevenLabs or ProviderSay
+	Provider string
+	// Text to speak
+	Text string
+	// Voice name (or voice ID for ElevenLabs)
+	Voice string
+	// Model name, ignored by the say provider
+	Model string
+	// Speed multiplier where 1.0 is normal, ignored by ElevenLabs
+	Speed float64
+	// Volume multiplier where 1.0 is unchanged, only applies to playback
+	Volume float64
+	// PreviousText and NextText are the text spoken before and after Text, which ElevenLabs
+	// uses to keep prosody continuous across separately synthesized chunks. Not spoken
+	// themselves, and ignored by other providers.
+	PreviousText string
+	NextText     string
+	// Output receives the encoded audio (MP3 or WAV) instead of playing it when set
+	Output io.Writer
+	// Player plays the audio, DefaultPlayer is used when nil
+	Player AudioPlayer
+}
+
+func (o Options) player() AudioPlayer {
+	if o.Player != nil {
+		return o.Player
+	}
+	return DefaultPlayer
+}
+
+// Provider synthesizes speech without playing it
+type Provider interface {
+	Synthesize(ctx context.Context, opts Options) (*Audio, error)
+}
+
+// StreamingProvider is a Provider that can return audio while it is still being generated
+type StreamingProvider interface {
+	Provider
+	// Stream returns the encoded response body as it streams in, usually MP3.
+	// WAV and FLAC bodies are recognized by their signature.
+	Stream(ctx context.Context, opts Options) (io.ReadCloser, error)
+}
+
+// NewProvider returns the named provider configured from the environment
+func NewProvider(name string) (Provider, error) {
+	switch name {
+	case ProviderOpenAI:
+		return NewOpenAI(os.Getenv("OPENAI_API_KEY")), nil
+	case ProviderGoogle:
+		return NewGoogle(googleAPIKey()), nil
+	case ProviderElevenLabs:
+		return NewElevenLabs(os.Getenv("ELEVENLABS_API_KEY")), nil
+	case ProviderSay:
+		return NewMacOS(), nil
+	default:
+		return nil, fmt.Errorf("unsupported provider: %s", name)
+	}
+}
+
+// Speak synthesizes opts.Text with opts.Provider and plays it, or writes it to opts.Output
+func Speak(ctx context.Context, opts Options) error {
+	provider, err := NewProvider(opts.Provider)
+	if err != nil {
+		return err
+	}
+	return SpeakWith(ctx, provider, opts)
+}
+
+// SpeakWith is like Speak but uses an already configured provider, opts.Provider is ignored
+func SpeakWith(ctx context.Context, provider Provider, opts Options) error {
+	if opts.Text == "" {
+		return fmt.Errorf("empty text provided")
+	}
+
+	// Start playing streaming providers before the whole response has arrived
+	if sp, ok := provider.(PCMStreamingProvider); ok && opts.Output == nil {
+		return playResumable(ctx, opts, func(ctx context.Context, opts Options) (beep.Streamer, beep.Format, func(), error) {
+			body, rate, err := sp.StreamPCM(ctx, opts)
+			if err != nil {
+				return nil, beep.Format{}, nil, err
+			}
+			format := beep.Format{SampleRate: rate, NumChannels: 1, Precision: 2}
+			logStreamedPCMFormat(format)
+			return NewPCMReaderStream(body), format, func() { body.Close() }, nil
+		})
+	}
+	if sp, ok := provider.(StreamingProvider); ok && opts.Output == nil {
+		return playResumable(ctx, opts, func(ctx context.Context, opts Options) (beep.Streamer, beep.Format, func(), error) {
+			body, err := sp.Stream(ctx, opts)
+			if err != nil {
+				return nil, beep.Format{}, nil, err
+			}
+			log.Debug("Decoding audio stream")
+			streamer, format, err := decodeStream(body)
+			if err != nil {
+				body.Close()
+				return nil, beep.Format{}, nil, err
+			}
+			return streamer, format, func() {
+				streamer.Close()
+				body.Close()
+			}, nil
+		})
+	}
+
+	audio, err := provider.Synthesize(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if opts.Output != nil {
+		_, err := opts.Output.Write(audio.Encoded())
+		return err
+	}
+	return playAudio(ctx, opts.player(), audio, opts.Volume)
+}
+
+// Encoding identifies the format of Audio.Data
+type Encoding string
+
+const (
+	// EncodingMP3 is MPEG audio, returned by OpenAI and ElevenLabs
+	EncodingMP3 Encoding = "mp3"
+	// EncodingPCM is 16-bit little-endian mono PCM, returned by Google
+	EncodingPCM Encoding = "pcm"
+	// EncodingWAV is a WAVE file, returned by the say provider
+	EncodingWAV Encoding = "wav"
+	// EncodingFLAC is a FLAC file, only read from disk
+	EncodingFLAC Encoding = "flac"
+)
+
+// Audio is synthesized speech
+type Audio struct {
+	Data     []byte
+	Encoding Encoding
+	// SampleRate is only set for EncodingPCM
+	SampleRate beep.SampleRate
+}
+
+// Decode returns a streamer over the audio samples. Encoded audio is decoded by
+// its actual format when it doesn't match Encoding, e.g. WAV labelled as MP3.
+func (a *Audio) Decode() (beep.StreamSeeker, beep.Format, error) {
+	if a.Encoding == EncodingPCM {
+		// Raw samples can look like an MP3 frame header, only trust container signatures
+		if encoding, ok := SniffEncoding(a.Data); !ok || encoding == EncodingMP3 {
+			stream := NewPCMStream(a.Data, a.SampleRate)
+			logPCMStats(stream)
+			return stream, beep.Format{SampleRate: a.SampleRate, NumChannels: 1, Precision: 2}, nil
+		}
+	}
+	return decodeEncoded(bytes.NewReader(a.Data), sniffEncoding(a.Data, a.Encoding))
+}
+
+// Buffer fully decodes the audio into memory
+func (a *Audio) Buffer() (*beep.Buffer, error) {
+	streamer, format, err := a.Decode()
+	if err != nil {
+		return nil, err
+	}
+	buffer := beep.NewBuffer(format)
+	buffer.Append(streamer)
+	if err := streamer.Err(); err != nil && !errors.Is(err, errStreamTruncated) {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+	return buffer, nil
+}
+
+// Encoded returns the audio as a playable file, raw PCM is wrapped in a WAV header
+func (a *Audio) Encoded() []byte {
+	if a.Encoding != EncodingPCM {
+		return a.Data
+	}
+	return pcmToWAV(a.Data, int(a.SampleRate), 1, 16)
+}
+
+// MIMEType returns the media type of Encoded
+func (a *Audio) MIMEType() string {
+	switch a.Encoding {
+	case EncodingMP3:
+		return "audio/mpeg"
+	case EncodingFLAC:
+		return "audio/flac"
+	default:
+		return "audio/wav"
+	}
+}
+
+// WithVolume scales a streamer by a linear volume multiplier, 0 and 1 leave it unchanged
+func WithVolume(streamer beep.Streamer, volume float64) beep.Streamer {
+	if volume == 0 || volume == 1 {
+		return streamer
+	}
+	log.Debug("Applying volume", "volume", volume)
+	return &effects.Gain{Streamer: streamer, Gain: volume - 1}
+}
@@ -0,0 +1,201 @@
+package say
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSynthesizeMissingAPIKey(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("GOOGLE_AI_API_KEY", "")
+	t.Setenv("GEMINI_API_KEY", "")
+	t.Setenv("ELEVENLABS_API_KEY", "")
+	ctx := context.Background()
+
+	_, err := SynthesizeOpenAI(ctx, OpenAISpeechParams{Text: "Hello"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "OPENAI_API_KEY is not set")
+
+	_, err = SynthesizeGoogle(ctx, GoogleSpeechParams{Text: "Hello"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "GOOGLE_AI_API_KEY or GEMINI_API_KEY is not set")
+
+	_, err = SynthesizeElevenLabs(ctx, ElevenLabsSpeechParams{Text: "Hello"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ELEVENLABS_API_KEY is not set")
+}
+
+func TestSayArgs(t *testing.T) {
+	assert.Equal(t, []string{"--rate", "200", "--", "Hello"}, SayArgs(SaySpeechParams{Text: "Hello"}))
+	assert.Equal(t, []string{"--rate", "150", "--voice", "Alex", "--", "Hello"}, SayArgs(SaySpeechParams{Text: "Hello", Voice: "Alex", Rate: 150}))
+	assert.Equal(t, []string{"--rate", "200", "--", "-o /tmp/x"}, SayArgs(SaySpeechParams{Text: "-o /tmp/x"}), "text is never an option")
+}
+
+func TestValidateSayRate(t *testing.T) {
+	for _, rate := range []int{0, MinSayRate, 175, MaxSayRate} {
+		assert.NoError(t, ValidateSayRate(rate), rate)
+	}
+	for _, rate := range []int{-1, 50, MinSayRate - 1, MaxSayRate + 1} {
+		assert.Error(t, ValidateSayRate(rate), rate)
+	}
+}
+
+// Real synthesis benchmarks, skipped unless the provider API key is set
+
+func BenchmarkSynthesizeOpenAI(b *testing.B) {
+	if os.Getenv("OPENAI_API_KEY") == "" {
+		b.Skip("OPENAI_API_KEY not set")
+	}
+	for b.Loop() {
+		if _, err := SynthesizeOpenAI(context.Background(), OpenAISpeechParams{Text: "Benchmark test."}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSynthesizeGoogle(b *testing.B) {
+	if os.Getenv("GOOGLE_AI_API_KEY") == "" && os.Getenv("GEMINI_API_KEY") == "" {
+		b.Skip("GOOGLE_AI_API_KEY or GEMINI_API_KEY not set")
+	}
+	for b.Loop() {
+		if _, err := SynthesizeGoogle(context.Background(), GoogleSpeechParams{Text: "Benchmark test."}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSynthesizeElevenLabs(b *testing.B) {
+	if os.Getenv("ELEVENLABS_API_KEY") == "" {
+		b.Skip("ELEVENLABS_API_KEY not set")
+	}
+	for b.Loop() {
+		if _, err := SynthesizeElevenLabs(context.Background(), ElevenLabsSpeechParams{Text: "Benchmark test."}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestAudioEncodedWAV(t *testing.T) {
+	pcm := make([]byte, 2400) // 50ms of 24kHz 16-bit mono silence
+	audio := &Audio{Data: pcm, Encoding: EncodingPCM, SampleRate: GoogleSampleRate}
+
+	wavData := audio.Encoded()
+	require.Len(t, wavData, 44+len(pcm))
+	assert.Equal(t, "RIFF", string(wavData[:4]))
+
+	decoded := &Audio{Data: wavData, Encoding: EncodingWAV}
+	buffer, err := decoded.Buffer()
+	require.NoError(t, err)
+	assert.Equal(t, GoogleSampleRate, buffer.Format().SampleRate)
+	assert.Equal(t, len(pcm)/2, buffer.Len())
+}
+
+func TestSpeakValidation(t *testing.T) {
+	err := Speak(context.Background(), Options{Provider: "azure", Text: "Hello"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported provider")
+
+	err = Speak(context.Background(), Options{Provider: ProviderOpenAI})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "empty text")
+}
+
+func TestSpeakOutput(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+
+	// Writing to Output never touches the speaker, so errors come straight from synthesis
+	var out bytes.Buffer
+	err := Speak(context.Background(), Options{Provider: ProviderOpenAI, Text: "Hello", Output: &out})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "OPENAI_API_KEY is not set")
+	assert.Zero(t, out.Len())
+}
+
+func TestElevenLabsPronunciationDictionaries(t *testing.T) {
+	body, err := json.Marshal(ElevenLabsParams{
+		Text:                            "Hello",
+		PronunciationDictionaryLocators: []PronunciationDictionary{{ID: "dict", VersionID: "v1"}, {ID: "latest"}},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"pronunciation_dictionary_locators":[{"pronunciation_dictionary_id":"dict","version_id":"v1"},{"pronunciation_dictionary_id":"latest"}]`)
+
+	_, err = SynthesizeElevenLabs(context.Background(), ElevenLabsSpeechParams{
+		APIKey:                    "test-key",
+		Text:                      "Hello",
+		PronunciationDictionaries: make([]PronunciationDictionary, MaxPronunciationDictionaries+1),
+	})
+	assert.ErrorContains(t, err, "too many pronunciation dictionaries")
+}
+
+func TestElevenLabsStreamingLatency(t *testing.T) {
+	assert.Equal(t, "https://api.elevenlabs.io/v1/text-to-speech/voice/stream", elevenLabsStreamURL("voice", 0, ""))
+	assert.Equal(t, "https://api.elevenlabs.io/v1/text-to-speech/voice/stream?optimize_streaming_latency=3", elevenLabsStreamURL("voice", 3, ""))
+
+	_, err := SynthesizeElevenLabs(context.Background(), ElevenLabsSpeechParams{
+		APIKey:                   "test-key",
+		Text:                     "Hello",
+		OptimizeStreamingLatency: MaxStreamingLatencyOptimization + 1,
+	})
+	assert.ErrorContains(t, err, "optimize_streaming_latency must be between 0 and 4")
+}
+
+func TestElevenLabsBitrate(t *testing.T) {
+	format, err := elevenLabsOutputFormat(64)
+	require.NoError(t, err)
+	assert.Equal(t, "mp3_44100_64", format)
+	format, err = elevenLabsOutputFormat(0)
+	require.NoError(t, err)
+	assert.Empty(t, format, "the API default needs no output_format")
+	assert.Equal(t, "https://api.elevenlabs.io/v1/text-to-speech/voice/stream?optimize_streaming_latency=3&output_format=mp3_44100_64", elevenLabsStreamURL("voice", 3, "mp3_44100_64"))
+
+	_, err = SynthesizeElevenLabs(context.Background(), ElevenLabsSpeechParams{APIKey: "test-key", Text: "Hello", Bitrate: 100})
+	assert.ErrorContains(t, err, "unsupported ElevenLabs bitrate 100 kbps")
+}
+
+func TestStoppableStreamer(t *testing.T) {
+	tone := Tone(24000, 440, time.Second, 0.5)
+	playing := &stoppableStreamer{Streamer: tone}
+	samples := make([][2]float64, 512)
+
+	n, ok := playing.Stream(samples)
+	assert.True(t, ok)
+	assert.Equal(t, 512, n)
+
+	playing.stop()
+	n, ok = playing.Stream(samples)
+	assert.False(t, ok)
+	assert.Zero(t, n)
+	assert.Equal(t, 512, tone.Position(), "the source is not read after stopping")
+}
+
+func TestPrebufferStreamer(t *testing.T) {
+	tone := Tone(24000, 440, 100*time.Millisecond, 0.5)
+	want := make([][2]float64, tone.Len())
+	tone.Stream(want)
+	require.NoError(t, tone.Seek(0))
+
+	streamer := prebufferStreamer(tone, 1000)
+	assert.Equal(t, 1000, tone.Position(), "prebuffering reads ahead before playback")
+	got := make([][2]float64, 0, len(want))
+	samples := make([][2]float64, 333)
+	for {
+		n, ok := streamer.Stream(samples)
+		got = append(got, samples[:n]...)
+		if !ok {
+			break
+		}
+	}
+	assert.Equal(t, want, got)
+
+	// A stream shorter than the prebuffer is played in full
+	short := Tone(24000, 440, 10*time.Millisecond, 0.5)
+	n, _ := prebufferStreamer(short, 24000).Stream(make([][2]float64, 24000))
+	assert.Equal(t, 240, n)
+}
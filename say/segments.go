@@ -0,0 +1,145 @@
+package say
+
+import (
+	"context"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+)
+
+// Segment is a run of plain text followed by a pause
+type Segment struct {
+	Text  string
+	Pause time.Duration
+	// Crossfade overlaps the end of this segment with the start of the next when there is no pause
+	Crossfade time.Duration
+	// PreviousText and NextText are the neighbouring segments' text, see Options.PreviousText.
+	// Empty at the ends, where the text passed in Options applies.
+	PreviousText string
+	NextText     string
+}
+
+// SegmentSynthesizer synthesizes a single segment's text into a decoded buffer
+type SegmentSynthesizer func(ctx context.Context, seg Segment) (*beep.Buffer, error)
+
+// ProviderSegments returns a SegmentSynthesizer that synthesizes each segment with
+// provider using opts, replacing opts.Text and, where the segment has neighbours,
+// opts.PreviousText and opts.NextText
+func ProviderSegments(provider Provider, opts Options) SegmentSynthesizer {
+	return func(ctx context.Context, seg Segment) (*beep.Buffer, error) {
+		segmentOpts := opts
+		segmentOpts.Text = seg.Text
+		if seg.PreviousText != "" {
+			segmentOpts.PreviousText = seg.PreviousText
+		}
+		if seg.NextText != "" {
+			segmentOpts.NextText = seg.NextText
+		}
+		audio, err := provider.Synthesize(ctx, segmentOpts)
+		if err != nil {
+			return nil, err
+		}
+		return audio.Buffer()
+	}
+}
+
+// RenderSegments synthesizes each segment separately and joins them into a single
+// stream with silence inserted for each segment's pause
+func RenderSegments(ctx context.Context, segments []Segment, synth SegmentSynthesizer) (beep.Streamer, beep.Format, error) {
+	clips := make([]Clip, 0, len(segments))
+	for _, seg := range segments {
+		clip := Clip{Pause: seg.Pause, Crossfade: seg.Crossfade}
+		if seg.Text != "" {
+			buffer, err := synth(ctx, seg)
+			if err != nil {
+				return nil, beep.Format{}, err
+			}
+			clip.Buffer = buffer
+		}
+		clips = append(clips, clip)
+	}
+	streamer, format := JoinClips(clips)
+	return streamer, format, nil
+}
+
+// Clip is decoded audio followed by a pause, Buffer may be nil for a pause on its own
+type Clip struct {
+	Buffer *beep.Buffer
+	Pause  time.Duration
+	// Crossfade linearly fades this clip out while the next one fades in, overlapping them.
+	// It only applies when there is no pause and is capped at half of either clip.
+	Crossfade time.Duration
+}
+
+// JoinClips joins clips into a single gapless stream with silence inserted for each
+// clip's pause. Clips are resampled to the sample rate of the first one.
+func JoinClips(clips []Clip) (beep.Streamer, beep.Format) {
+	var format beep.Format
+	for _, clip := range clips {
+		if clip.Buffer != nil {
+			format = clip.Buffer.Format()
+			break
+		}
+	}
+
+	// Resample up front so crossfades can be cut at sample positions of the joined stream
+	buffers := make([]*beep.Buffer, len(clips))
+	for i, clip := range clips {
+		buffers[i] = clip.Buffer
+		if clip.Buffer != nil && clip.Buffer.Format().SampleRate != format.SampleRate {
+			resampled := beep.NewBuffer(format)
+			resampled.Append(beep.Resample(resampleQuality, clip.Buffer.Format().SampleRate, format.SampleRate, clip.Buffer.Streamer(0, clip.Buffer.Len())))
+			buffers[i] = resampled
+		}
+	}
+
+	var streamers []beep.Streamer
+	// start skips the samples of a clip already played in the crossfade from the previous one
+	start := 0
+	for i, clip := range clips {
+		buffer := buffers[i]
+		if buffer == nil {
+			start = 0
+		} else {
+			end := buffer.Len()
+			overlap := 0
+			if clip.Pause <= 0 && clip.Crossfade > 0 && i+1 < len(clips) && buffers[i+1] != nil {
+				overlap = min(format.SampleRate.N(clip.Crossfade), end/2, buffers[i+1].Len()/2)
+			}
+			streamers = append(streamers, buffer.Streamer(start, end-overlap))
+			if overlap > 0 {
+				next := buffers[i+1]
+				streamers = append(streamers, beep.Mix(
+					&linearFade{Streamer: buffer.Streamer(end-overlap, end), from: 1, to: 0, n: overlap},
+					&linearFade{Streamer: next.Streamer(0, overlap), from: 0, to: 1, n: overlap},
+				))
+			}
+			start = overlap
+		}
+		if clip.Pause > 0 {
+			streamers = append(streamers, beep.Silence(format.SampleRate.N(clip.Pause)))
+		}
+	}
+	return beep.Seq(streamers...), format
+}
+
+// linearFade ramps the gain of the first n samples of a streamer from one level to another
+type linearFade struct {
+	beep.Streamer
+	from, to float64
+	pos, n   int
+}
+
+func (f *linearFade) Stream(samples [][2]float64) (int, bool) {
+	n, ok := f.Streamer.Stream(samples)
+	for i := range samples[:n] {
+		gain := f.to
+		if f.pos < f.n {
+			gain = f.from + (f.to-f.from)*float64(f.pos)/float64(f.n)
+		}
+		samples[i][0] *= gain
+		samples[i][1] *= gain
+		f.pos++
+	}
+	return n, ok
+}
@@ -0,0 +1,146 @@
+package say
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// constantBuffer returns n samples at a constant level, like a clip recorded at a fixed volume
+func constantBuffer(rate beep.SampleRate, n int, level float64) *beep.Buffer {
+	buffer := beep.NewBuffer(beep.Format{SampleRate: rate, NumChannels: 1, Precision: 2})
+	samples := make([][2]float64, n)
+	for i := range samples {
+		samples[i] = [2]float64{level, level}
+	}
+	buffer.Append(&sliceStreamer{samples: samples})
+	return buffer
+}
+
+type sliceStreamer struct {
+	samples [][2]float64
+}
+
+func (s *sliceStreamer) Stream(samples [][2]float64) (int, bool) {
+	if len(s.samples) == 0 {
+		return 0, false
+	}
+	n := copy(samples, s.samples)
+	s.samples = s.samples[n:]
+	return n, true
+}
+
+func (s *sliceStreamer) Err() error { return nil }
+
+// drain reads a streamer to the end, keeping the left channel
+func drain(s beep.Streamer) []float64 {
+	var out []float64
+	samples := make([][2]float64, 512)
+	for {
+		n, ok := s.Stream(samples)
+		for _, sample := range samples[:n] {
+			out = append(out, sample[0])
+		}
+		if !ok {
+			return out
+		}
+	}
+}
+
+// maxStep is the largest jump between consecutive samples
+func maxStep(samples []float64) float64 {
+	step := 0.0
+	for i := 1; i < len(samples); i++ {
+		step = max(step, math.Abs(samples[i]-samples[i-1]))
+	}
+	return step
+}
+
+func TestJoinClipsCrossfade(t *testing.T) {
+	const rate = beep.SampleRate(1000)
+	clips := func(crossfade time.Duration) []Clip {
+		return []Clip{
+			{Buffer: constantBuffer(rate, 500, 0.8), Crossfade: crossfade},
+			{Buffer: constantBuffer(rate, 300, 0.2), Crossfade: crossfade},
+			{Buffer: constantBuffer(rate, 400, 0.6)},
+		}
+	}
+
+	// A hard cut jumps straight from one level to the next
+	hard := drain(first(JoinClips(clips(0))))
+	assert.Len(t, hard, 1200)
+	assert.InDelta(t, 0.6, maxStep(hard), 1e-3)
+
+	// 50ms is 50 samples of overlap at each of the two seams
+	streamer, format := JoinClips(clips(50 * time.Millisecond))
+	assert.Equal(t, rate, format.SampleRate)
+	faded := drain(streamer)
+	assert.Len(t, faded, 500+300+400-2*50)
+	// The levels ramp across the seam instead of jumping
+	assert.Less(t, maxStep(faded), 0.6/50+1e-3)
+	assert.InDelta(t, 0.8, faded[0], 1e-3)
+	assert.InDelta(t, 0.2, faded[500], 1e-3)
+	assert.InDelta(t, 0.6, faded[len(faded)-1], 1e-3)
+}
+
+func TestJoinClipsCrossfadeLimits(t *testing.T) {
+	const rate = beep.SampleRate(1000)
+
+	// Overlap is capped at half the shorter clip, so a clip is never faded out while fading in
+	streamer, _ := JoinClips([]Clip{
+		{Buffer: constantBuffer(rate, 100, 0.5), Crossfade: time.Second},
+		{Buffer: constantBuffer(rate, 40, 0.5)},
+	})
+	assert.Len(t, drain(streamer), 100+40-20)
+
+	// A pause wins over the crossfade
+	streamer, _ = JoinClips([]Clip{
+		{Buffer: constantBuffer(rate, 100, 0.5), Pause: 10 * time.Millisecond, Crossfade: 50 * time.Millisecond},
+		{Buffer: constantBuffer(rate, 100, 0.5)},
+	})
+	assert.Len(t, drain(streamer), 210)
+
+	// Clips at another sample rate are resampled before the overlap is cut
+	streamer, _ = JoinClips([]Clip{
+		{Buffer: constantBuffer(rate, 100, 0.5), Crossfade: 10 * time.Millisecond},
+		{Buffer: constantBuffer(2*rate, 200, 0.5)},
+	})
+	samples := drain(streamer)
+	require.InDelta(t, 190, len(samples), 2)
+}
+
+func first(s beep.Streamer, _ beep.Format) beep.Streamer {
+	return s
+}
+
+// recordingProvider records the options of each synthesis and answers with a tone
+type recordingProvider struct {
+	opts []Options
+}
+
+func (p *recordingProvider) Synthesize(ctx context.Context, opts Options) (*Audio, error) {
+	p.opts = append(p.opts, opts)
+	return testModeTone(8000), nil
+}
+
+func TestProviderSegmentsContext(t *testing.T) {
+	provider := &recordingProvider{}
+	synth := ProviderSegments(provider, Options{Voice: "v", PreviousText: "Before.", NextText: "After."})
+	_, _, err := RenderSegments(context.Background(), []Segment{
+		{Text: "One.", NextText: "Two."},
+		{Text: "Two.", PreviousText: "One.", NextText: "Three."},
+		{Text: "Three.", PreviousText: "Two."},
+	}, synth)
+	require.NoError(t, err)
+	require.Len(t, provider.opts, 3)
+	// The caller's context applies at the ends, neighbouring segments in between
+	assert.Equal(t, [2]string{"Before.", "Two."}, [2]string{provider.opts[0].PreviousText, provider.opts[0].NextText})
+	assert.Equal(t, [2]string{"One.", "Three."}, [2]string{provider.opts[1].PreviousText, provider.opts[1].NextText})
+	assert.Equal(t, [2]string{"Two.", "After."}, [2]string{provider.opts[2].PreviousText, provider.opts[2].NextText})
+	assert.Equal(t, "v", provider.opts[1].Voice)
+}
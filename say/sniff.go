@@ -0,0 +1,94 @@
+package say
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+	"github.com/gopxl/beep/v2/flac"
+	"github.com/gopxl/beep/v2/wav"
+)
+
+// Bytes needed to recognize every supported format
+const sniffLen = 12
+
+// SniffEncoding detects the format of encoded audio from its first bytes. ok is false
+// when no WAV, FLAC or MP3 signature is found, e.g. for raw PCM or an error message.
+func SniffEncoding(head []byte) (encoding Encoding, ok bool) {
+	switch {
+	case len(head) >= 12 && bytes.HasPrefix(head, []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WAVE")):
+		return EncodingWAV, true
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		return EncodingFLAC, true
+	case bytes.HasPrefix(head, []byte("ID3")), isMP3FrameSync(head):
+		return EncodingMP3, true
+	default:
+		return "", false
+	}
+}
+
+// isMP3FrameSync reports whether head starts with an MPEG audio frame header.
+// ADTS AAC shares the sync word but has layer bits 00, so it doesn't match.
+func isMP3FrameSync(head []byte) bool {
+	return len(head) >= 2 && head[0] == 0xFF && head[1]&0xE0 == 0xE0 && head[1]&0x06 != 0
+}
+
+// sniffEncoding returns the detected encoding of data, or fallback when unknown
+func sniffEncoding(data []byte, fallback Encoding) Encoding {
+	if encoding, ok := SniffEncoding(data); ok {
+		if encoding != fallback {
+			log.Debug("Audio is not in the expected format", "expected", fallback, "detected", encoding)
+		}
+		return encoding
+	}
+	return fallback
+}
+
+// decodeStream decodes audio as it arrives, picking the decoder from the first bytes.
+// Streams without a known signature are decoded as MP3.
+func decodeStream(r io.Reader) (beep.StreamSeekCloser, beep.Format, error) {
+	br := bufio.NewReaderSize(r, sniffLen)
+	// A short stream fails to peek, the decoder reports it
+	head, _ := br.Peek(sniffLen)
+	return decodeEncoded(br, sniffEncoding(head, EncodingMP3))
+}
+
+// decodeEncoded decodes MP3, WAV or FLAC audio
+func decodeEncoded(r io.Reader, encoding Encoding) (beep.StreamSeekCloser, beep.Format, error) {
+	switch encoding {
+	case EncodingMP3:
+		return decodeMP3(r)
+	case EncodingWAV:
+		streamer, format, err := wav.Decode(fullReader{r})
+		if err != nil {
+			return nil, beep.Format{}, fmt.Errorf("failed to decode response: %v", err)
+		}
+		return streamer, format, nil
+	case EncodingFLAC:
+		streamer, format, err := flac.Decode(r)
+		if err != nil {
+			return nil, beep.Format{}, fmt.Errorf("failed to decode FLAC: %v", err)
+		}
+		return streamer, format, nil
+	default:
+		return nil, beep.Format{}, fmt.Errorf("unsupported audio encoding: %s", encoding)
+	}
+}
+
+// fullReader fills every read unless the stream ends. beep's WAV decoder reads
+// once per buffer and drops the bytes of a partial frame, which network reads
+// regularly return.
+type fullReader struct {
+	r io.Reader
+}
+
+func (f fullReader) Read(p []byte) (int, error) {
+	n, err := io.ReadFull(f.r, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
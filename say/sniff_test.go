@@ -0,0 +1,99 @@
+package say
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSniffEncoding(t *testing.T) {
+	wavData := (&Audio{Data: make([]byte, 480), Encoding: EncodingPCM, SampleRate: 24000}).Encoded()
+	tests := []struct {
+		name     string
+		head     []byte
+		encoding Encoding
+		ok       bool
+	}{
+		{"wav", wavData, EncodingWAV, true},
+		{"flac", []byte("fLaC\x00\x00\x00\x22"), EncodingFLAC, true},
+		{"mp3 with id3 tag", []byte("ID3\x04\x00"), EncodingMP3, true},
+		{"mp3 frame", silentMP3Frames(1), EncodingMP3, true},
+		{"adts aac", []byte{0xFF, 0xF1, 0x50, 0x80}, "", false},
+		{"riff without wave", []byte("RIFF\x00\x00\x00\x00AVI "), "", false},
+		{"json error", []byte(`{"error": "quota exceeded"}`), "", false},
+		{"empty", nil, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoding, ok := SniffEncoding(tt.head)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.encoding, encoding)
+		})
+	}
+}
+
+func TestDecodeMislabelledAudio(t *testing.T) {
+	wavData := (&Audio{Data: make([]byte, 480), Encoding: EncodingPCM, SampleRate: 24000}).Encoded()
+
+	t.Run("wav labelled as mp3", func(t *testing.T) {
+		buffer, err := (&Audio{Data: wavData, Encoding: EncodingMP3}).Buffer()
+		require.NoError(t, err)
+		assert.Equal(t, 240, buffer.Len())
+		assert.Equal(t, beep.SampleRate(24000), buffer.Format().SampleRate)
+	})
+
+	t.Run("mp3 labelled as wav", func(t *testing.T) {
+		buffer, err := (&Audio{Data: silentMP3Frames(3), Encoding: EncodingWAV}).Buffer()
+		require.NoError(t, err)
+		assert.Equal(t, 3*1152, buffer.Len())
+	})
+
+	t.Run("pcm that looks like an mp3 frame stays pcm", func(t *testing.T) {
+		pcm := append([]byte{0xFF, 0xFB}, make([]byte, 98)...)
+		streamer, format, err := (&Audio{Data: pcm, Encoding: EncodingPCM, SampleRate: 24000}).Decode()
+		require.NoError(t, err)
+		assert.Equal(t, 50, streamer.Len())
+		assert.Equal(t, 1, format.NumChannels)
+	})
+}
+
+func TestDecodeStream(t *testing.T) {
+	wavData := (&Audio{Data: make([]byte, 480), Encoding: EncodingPCM, SampleRate: 24000}).Encoded()
+	tests := []struct {
+		name    string
+		data    []byte
+		samples int
+	}{
+		{"wav", wavData, 240},
+		{"mp3", silentMP3Frames(2), 2 * 1152},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Deliver one byte at a time like a slow network stream
+			streamer, format, err := decodeStream(oneByteReader{bytes.NewReader(tt.data)})
+			require.NoError(t, err)
+			buffer := beep.NewBuffer(format)
+			buffer.Append(streamer)
+			assert.NoError(t, streamer.Err())
+			assert.Equal(t, tt.samples, buffer.Len())
+		})
+	}
+
+	_, _, err := decodeStream(bytes.NewReader([]byte("Unauthorized")))
+	assert.ErrorContains(t, err, "Unauthorized")
+}
+
+type oneByteReader struct {
+	r io.Reader
+}
+
+func (o oneByteReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return o.r.Read(p[:1])
+}
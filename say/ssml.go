@@ -0,0 +1,182 @@
+package say
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxSSMLBreak is the longest single <break> honored
+const MaxSSMLBreak = 10 * time.Second
+
+// ssmlBreakStrengths maps <break strength="..."> values to pause durations
+var ssmlBreakStrengths = map[string]time.Duration{
+	"none":     0,
+	"x-weak":   100 * time.Millisecond,
+	"weak":     250 * time.Millisecond,
+	"medium":   500 * time.Millisecond,
+	"strong":   750 * time.Millisecond,
+	"x-strong": 1000 * time.Millisecond,
+}
+
+// SSMLToSegments validates an SSML document and downconverts it into plain text
+// segments for providers without SSML support. <break> tags end a segment and become
+// its pause, <sub alias="..."> is replaced by its alias and all other tags are
+// stripped keeping their text. Malformed XML is an error.
+func SSMLToSegments(ssml string) ([]Segment, error) {
+	if strings.TrimSpace(ssml) == "" {
+		return nil, errors.New("empty SSML provided")
+	}
+
+	decoder := xml.NewDecoder(strings.NewReader(ssml))
+	decoder.Strict = true
+
+	var (
+		segments []Segment
+		current  strings.Builder
+		depth    int
+		rootSeen bool
+		subDepth int
+	)
+
+	flush := func(pause time.Duration) {
+		text := strings.Join(strings.Fields(current.String()), " ")
+		current.Reset()
+		if text == "" {
+			if len(segments) > 0 {
+				// Merge consecutive breaks
+				segments[len(segments)-1].Pause += pause
+				return
+			}
+			if pause == 0 {
+				return
+			}
+		}
+		segments = append(segments, Segment{Text: text, Pause: pause})
+	}
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid SSML: %v", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				if rootSeen || t.Name.Local != "speak" {
+					return nil, errors.New("invalid SSML: document must have a single <speak> root element")
+				}
+				rootSeen = true
+			}
+			depth++
+			if subDepth != 0 {
+				// Everything inside <sub> is replaced by its alias
+				continue
+			}
+			switch t.Name.Local {
+			case "break":
+				pause, err := parseSSMLBreak(t)
+				if err != nil {
+					return nil, err
+				}
+				flush(pause)
+			case "sub":
+				if alias := ssmlAttr(t, "alias"); alias != "" {
+					current.WriteString(alias)
+					subDepth = depth
+				}
+			case "p", "s":
+				current.WriteString(" ")
+			}
+		case xml.EndElement:
+			if subDepth == depth {
+				subDepth = 0
+			}
+			depth--
+			if subDepth == 0 && (t.Name.Local == "p" || t.Name.Local == "s") {
+				current.WriteString(" ")
+			}
+		case xml.CharData:
+			if depth == 0 {
+				if strings.TrimSpace(string(t)) != "" {
+					return nil, errors.New("invalid SSML: text outside of <speak> element")
+				}
+				continue
+			}
+			if subDepth == 0 {
+				current.Write(t)
+			}
+		}
+	}
+
+	if !rootSeen {
+		return nil, errors.New("invalid SSML: missing <speak> root element")
+	}
+	flush(0)
+
+	// Drop a trailing pause, there is nothing left to separate
+	if n := len(segments); n > 0 {
+		segments[n-1].Pause = 0
+		if segments[n-1].Text == "" {
+			segments = segments[:n-1]
+		}
+	}
+	if len(segments) == 0 {
+		return nil, errors.New("SSML contains no speakable text")
+	}
+	return segments, nil
+}
+
+// parseSSMLBreak returns the pause for a <break> element
+func parseSSMLBreak(el xml.StartElement) (time.Duration, error) {
+	if value := ssmlAttr(el, "time"); value != "" {
+		var (
+			pause time.Duration
+			err   error
+		)
+		switch {
+		case strings.HasSuffix(value, "ms"):
+			var ms float64
+			ms, err = strconv.ParseFloat(strings.TrimSuffix(value, "ms"), 64)
+			pause = time.Duration(ms * float64(time.Millisecond))
+		case strings.HasSuffix(value, "s"):
+			var s float64
+			s, err = strconv.ParseFloat(strings.TrimSuffix(value, "s"), 64)
+			pause = time.Duration(s * float64(time.Second))
+		default:
+			err = errors.New("missing unit")
+		}
+		if err != nil || pause < 0 {
+			return 0, fmt.Errorf("invalid SSML: bad <break> time %q (use e.g. 500ms or 1.5s)", value)
+		}
+		if pause > MaxSSMLBreak {
+			return 0, fmt.Errorf("invalid SSML: <break> time %q exceeds %v", value, MaxSSMLBreak)
+		}
+		return pause, nil
+	}
+	if strength := ssmlAttr(el, "strength"); strength != "" {
+		pause, ok := ssmlBreakStrengths[strength]
+		if !ok {
+			return 0, fmt.Errorf("invalid SSML: bad <break> strength %q", strength)
+		}
+		return pause, nil
+	}
+	return ssmlBreakStrengths["medium"], nil
+}
+
+func ssmlAttr(el xml.StartElement, name string) string {
+	for _, attr := range el.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
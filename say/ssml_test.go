@@ -0,0 +1,112 @@
+package say
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSMLToSegments(t *testing.T) {
+	tests := []struct {
+		name     string
+		ssml     string
+		expected []Segment
+	}{
+		{
+			name:     "plain text",
+			ssml:     "<speak>Hello world</speak>",
+			expected: []Segment{{Text: "Hello world"}},
+		},
+		{
+			name: "break with time",
+			ssml: `<speak>Hello <break time="500ms"/> world</speak>`,
+			expected: []Segment{
+				{Text: "Hello", Pause: 500 * time.Millisecond},
+				{Text: "world"},
+			},
+		},
+		{
+			name: "break with seconds and strength",
+			ssml: `<speak>One<break time="1.5s"/>Two<break strength="weak"/>Three</speak>`,
+			expected: []Segment{
+				{Text: "One", Pause: 1500 * time.Millisecond},
+				{Text: "Two", Pause: 250 * time.Millisecond},
+				{Text: "Three"},
+			},
+		},
+		{
+			name: "leading and consecutive breaks",
+			ssml: `<speak><break time="1s"/>Hi<break time="200ms"/><break time="300ms"/>there<break time="2s"/></speak>`,
+			expected: []Segment{
+				{Pause: time.Second},
+				{Text: "Hi", Pause: 500 * time.Millisecond},
+				{Text: "there"},
+			},
+		},
+		{
+			name:     "strips tags and applies sub alias",
+			ssml:     `<speak><p><s>I work at <sub alias="World Wide Web Consortium">W3C</sub>.</s></p><emphasis level="strong">Really</emphasis></speak>`,
+			expected: []Segment{{Text: "I work at World Wide Web Consortium. Really"}},
+		},
+		{
+			name: "breaks in nested tags",
+			ssml: `<speak><p><s>First <emphasis>sentence<break time="250ms"/></emphasis></s><s>second</s></p><p><prosody rate="slow">Last</prosody></p></speak>`,
+			expected: []Segment{
+				{Text: "First sentence", Pause: 250 * time.Millisecond},
+				{Text: "second Last"},
+			},
+		},
+		{
+			name:     "everything inside sub is replaced by its alias",
+			ssml:     `<speak>Say <sub alias="Kubernetes">k8s <sub alias="inner">x</sub><break time="1s"/></sub> now</speak>`,
+			expected: []Segment{{Text: "Say Kubernetes now"}},
+		},
+		{
+			name:     "sub without alias keeps its text",
+			ssml:     `<speak>Say <sub>k8s</sub></speak>`,
+			expected: []Segment{{Text: "Say k8s"}},
+		},
+		{
+			name:     "entities",
+			ssml:     `<speak>Tom &amp; Jerry &lt;3</speak>`,
+			expected: []Segment{{Text: "Tom & Jerry <3"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments, err := SSMLToSegments(tt.ssml)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, segments)
+		})
+	}
+}
+
+func TestSSMLToSegmentsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		ssml string
+	}{
+		{"empty", "   "},
+		{"not xml", "Hello <break"},
+		{"wrong root", "<voice>Hello</voice>"},
+		{"unclosed tag", "<speak>Hello <emphasis>world</speak>"},
+		{"text outside root", "<speak>Hello</speak> world"},
+		{"bad break time", `<speak>Hello <break time="soon"/></speak>`},
+		{"break too long", `<speak>Hello <break time="30s"/></speak>`},
+		{"bad break strength", `<speak>Hello <break strength="huge"/></speak>`},
+		{"no speakable text", `<speak><break time="1s"/></speak>`},
+		{"mismatched nesting", "<speak><p><s>Hello</p></s></speak>"},
+		{"unknown entity", "<speak>Hello &nbsp; world</speak>"},
+		{"two roots", "<speak>One</speak><speak>Two</speak>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SSMLToSegments(tt.ssml)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package say
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+	"github.com/gopxl/beep/v2"
+)
+
+// The synthetic audio test mode answers with
+const (
+	testModeFrequency = 440.0
+	testModeDuration  = 250 * time.Millisecond
+	testModeVolume    = 0.2
+)
+
+// testMode is set by SetTestMode
+var testMode atomic.Bool
+
+// SetTestMode makes every provider request answer with synthetic audio instead of reaching
+// the network, and the say command return a tone instead of running. It is meant for
+// integration tests of the real request paths, never for production use.
+func SetTestMode(enabled bool) {
+	testMode.Store(enabled)
+}
+
+// TestMode reports whether provider requests are stubbed, see SetTestMode
+func TestMode() bool {
+	return testMode.Load()
+}
+
+// testModeTone is the PCM audio test mode answers with at sampleRate
+func testModeTone(sampleRate beep.SampleRate) *Audio {
+	return ToneAudio(ToneParams{
+		SampleRate: sampleRate,
+		Waveform:   WaveformSine,
+		Frequency:  testModeFrequency,
+		Duration:   testModeDuration,
+		Volume:     testModeVolume,
+	})
+}
+
+// silentMP3Frames returns n silent MPEG-1 Layer III frames (128kbps, 44.1kHz)
+func silentMP3Frames(n int) []byte {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x64})
+	return bytes.Repeat(frame, n)
+}
+
+// testModeMP3 is silence as long as the tone, there is no MP3 encoder to make a tone with
+func testModeMP3() []byte {
+	// A frame holds 1152 samples
+	return silentMP3Frames(int(testModeDuration.Seconds()*44100)/1152 + 1)
+}
+
+// testModeAlignment spreads the characters of text evenly over the tone
+func testModeAlignment(text string) elevenLabsAlignment {
+	var a elevenLabsAlignment
+	step := testModeDuration.Seconds() / float64(max(1, utf8.RuneCountInString(text)))
+	for i, r := range []rune(text) {
+		a.Characters = append(a.Characters, string(r))
+		a.Starts = append(a.Starts, float64(i)*step)
+		a.Ends = append(a.Ends, float64(i+1)*step)
+	}
+	return a
+}
+
+// testModeTransport answers provider requests with synthetic audio while test mode is
+// enabled and sends them otherwise. Requests it has no stub for fail without being sent.
+type testModeTransport struct {
+	base http.RoundTripper
+}
+
+func (t *testModeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !TestMode() {
+		return t.base.RoundTrip(req)
+	}
+	var body struct {
+		Text           string `json:"text"`
+		ResponseFormat string `json:"response_format"`
+	}
+	if req.Body != nil {
+		data, _ := io.ReadAll(req.Body)
+		req.Body.Close()
+		json.Unmarshal(data, &body)
+	}
+	log.Debug("Test mode, answering provider request with synthetic audio", "method", req.Method, "url", req.URL.Redacted())
+
+	path := req.URL.Path
+	switch {
+	case strings.HasSuffix(path, "/audio/speech"):
+		switch body.ResponseFormat {
+		case "pcm":
+			return testModeResponse(req, "audio/pcm", testModeTone(openAIPCMSampleRate).Data), nil
+		case "wav":
+			return testModeResponse(req, "audio/wav", testModeTone(openAIPCMSampleRate).Encoded()), nil
+		}
+		return testModeResponse(req, "audio/mpeg", testModeMP3()), nil
+	case strings.HasSuffix(path, ":generateContent"):
+		data, _ := json.Marshal(map[string]any{
+			"candidates": []any{map[string]any{
+				"content": map[string]any{
+					"role": "model",
+					"parts": []any{map[string]any{
+						"inlineData": map[string]any{
+							"mimeType": fmt.Sprintf("audio/L16;codec=pcm;rate=%d", GoogleSampleRate),
+							"data":     base64.StdEncoding.EncodeToString(testModeTone(GoogleSampleRate).Data),
+						},
+					}},
+				},
+			}},
+		})
+		return testModeResponse(req, "application/json", data), nil
+	case strings.HasSuffix(path, "/with-timestamps"):
+		data, _ := json.Marshal(map[string]any{
+			"audio_base64": base64.StdEncoding.EncodeToString(testModeMP3()),
+			"alignment":    testModeAlignment(body.Text),
+		})
+		return testModeResponse(req, "application/json", data), nil
+	case strings.HasSuffix(path, "/stream"), strings.HasSuffix(path, "/sound-generation"):
+		return testModeResponse(req, "audio/mpeg", testModeMP3()), nil
+	}
+	return nil, fmt.Errorf("test mode has no stub for %s %s, the request was not sent", req.Method, path)
+}
+
+// testModeResponse is a successful response carrying data
+func testModeResponse(req *http.Request, contentType string, data []byte) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {contentType}},
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}
+}
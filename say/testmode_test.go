@@ -0,0 +1,58 @@
+package say
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestMode(t *testing.T) {
+	SetTestMode(true)
+	t.Cleanup(func() { SetTestMode(false) })
+	// Nothing listens here, a request that got through would fail
+	t.Setenv("OPENAI_BASE_URL", "http://127.0.0.1:1")
+	ctx := context.Background()
+	tone := testModeTone(GoogleSampleRate).Data
+
+	pcm, err := StreamOpenAI(ctx, OpenAISpeechParams{APIKey: "sk-test", Text: "Hello", PCM: true})
+	require.NoError(t, err)
+	data, err := io.ReadAll(pcm)
+	require.NoError(t, err)
+	assert.Equal(t, tone, data)
+
+	mp3, err := SynthesizeOpenAI(ctx, OpenAISpeechParams{APIKey: "sk-test", Text: "Hello"})
+	require.NoError(t, err)
+	buffer, err := (&Audio{Data: mp3, Encoding: EncodingMP3}).Buffer()
+	require.NoError(t, err)
+	assert.Greater(t, buffer.Len(), 0)
+
+	data, err = SynthesizeGoogle(ctx, GoogleSpeechParams{APIKey: "ai-test", Text: "Hello"})
+	require.NoError(t, err)
+	assert.Equal(t, tone, data)
+
+	_, words, err := SynthesizeElevenLabsWithTimestamps(ctx, ElevenLabsSpeechParams{APIKey: "xi-test", Text: "Hello there"})
+	require.NoError(t, err)
+	require.Len(t, words, 2)
+	assert.Equal(t, "there", words[1].Word)
+	assert.InDelta(t, testModeDuration.Seconds(), words[1].End, 1e-9)
+
+	// Missing keys still fail the way they would for real
+	t.Setenv("OPENAI_API_KEY", "")
+	_, err = SynthesizeOpenAI(ctx, OpenAISpeechParams{Text: "Hello"})
+	assert.ErrorContains(t, err, "OPENAI_API_KEY is not set")
+
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:1/v1/voices", nil)
+	require.NoError(t, err)
+	_, err = HTTPClient().Do(req)
+	assert.ErrorContains(t, err, "test mode has no stub")
+
+	// Dry runs still capture the request first
+	dryCtx, captured := WithDryRun(ctx)
+	_, err = SynthesizeOpenAI(dryCtx, OpenAISpeechParams{APIKey: "sk-test", Text: "Hello"})
+	assert.Error(t, err)
+	assert.Len(t, captured(), 1)
+}
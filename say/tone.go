@@ -0,0 +1,132 @@
+package say
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+)
+
+// Fade applied to both ends of a tone so it starts and stops without a click
+const toneFade = 5 * time.Millisecond
+
+// Limits for ToneParams, the frequency range is what most people can hear
+const (
+	MinToneFrequency = 20.0
+	MaxToneFrequency = 20000.0
+	MinToneDuration  = 10 * time.Millisecond
+	MaxToneDuration  = 10 * time.Second
+)
+
+// Waveform is the shape of a generated tone
+type Waveform string
+
+const (
+	WaveformSine   Waveform = "sine"
+	WaveformSquare Waveform = "square"
+	WaveformSaw    Waveform = "saw"
+)
+
+// ToneParams describes a generated tone
+type ToneParams struct {
+	SampleRate beep.SampleRate
+	Waveform   Waveform
+	// Frequency in Hz
+	Frequency float64
+	Duration  time.Duration
+	// Volume from 0 to 1
+	Volume float64
+}
+
+// Validate checks the tone against the limits and the sample rate's Nyquist frequency
+func (p ToneParams) Validate() error {
+	switch p.Waveform {
+	case WaveformSine, WaveformSquare, WaveformSaw:
+	default:
+		return fmt.Errorf("waveform must be sine, square or saw, got %q", p.Waveform)
+	}
+	if p.Frequency < MinToneFrequency || p.Frequency > MaxToneFrequency {
+		return fmt.Errorf("frequency must be between %g and %g Hz, got %g", MinToneFrequency, MaxToneFrequency, p.Frequency)
+	}
+	if nyquist := float64(p.SampleRate) / 2; p.Frequency >= nyquist {
+		return fmt.Errorf("frequency must be below %g Hz at a %d Hz sample rate, got %g", nyquist, p.SampleRate, p.Frequency)
+	}
+	if p.Duration < MinToneDuration || p.Duration > MaxToneDuration {
+		return fmt.Errorf("duration must be between %v and %v, got %v", MinToneDuration, MaxToneDuration, p.Duration)
+	}
+	if p.Volume < 0 || p.Volume > 1 {
+		return fmt.Errorf("volume must be between 0 and 1, got %g", p.Volume)
+	}
+	return nil
+}
+
+// polyBLEP is the band-limited step correction at phase t for a phase increment of dt.
+// Subtracting it around each jump of a square or saw wave removes most of the aliasing
+// a naive waveform produces at high frequencies.
+func polyBLEP(t, dt float64) float64 {
+	switch {
+	case t < dt:
+		t /= dt
+		return t + t - t*t - 1
+	case t > 1-dt:
+		t = (t - 1) / dt
+		return t*t + t + t + 1
+	}
+	return 0
+}
+
+// sample returns the waveform's value from -1 to 1 at phase (0 to 1), dt is the phase increment per sample
+func (w Waveform) sample(phase, dt float64) float64 {
+	switch w {
+	case WaveformSquare:
+		v := 1.0
+		if phase >= 0.5 {
+			v = -1
+		}
+		return v + polyBLEP(phase, dt) - polyBLEP(math.Mod(phase+0.5, 1), dt)
+	case WaveformSaw:
+		return 2*phase - 1 - polyBLEP(phase, dt)
+	default:
+		return math.Sin(2 * math.Pi * phase)
+	}
+}
+
+// ToneAudio generates a tone as 16-bit mono PCM, faded in and out to avoid clicks.
+// The parameters are not validated, see ToneParams.Validate.
+func ToneAudio(params ToneParams) *Audio {
+	n := params.SampleRate.N(params.Duration)
+	fade := min(params.SampleRate.N(toneFade), n/2)
+	dt := params.Frequency / float64(params.SampleRate)
+	data := make([]byte, 2*n)
+	phase := 0.0
+	for i := range n {
+		gain := params.Volume
+		if i < fade {
+			gain *= float64(i) / float64(fade)
+		} else if i >= n-fade {
+			gain *= float64(n-1-i) / float64(fade)
+		}
+		v := max(-1, min(1, params.Waveform.sample(phase, dt)))
+		sample := int16(gain * math.MaxInt16 * v)
+		data[2*i] = byte(sample)
+		data[2*i+1] = byte(sample >> 8)
+		// Accumulating the phase keeps it precise for long tones
+		if phase += dt; phase >= 1 {
+			phase--
+		}
+	}
+	return &Audio{Data: data, Encoding: EncodingPCM, SampleRate: params.SampleRate}
+}
+
+// Tone returns a sine tone at volume (0 to 1) lasting duration
+func Tone(sampleRate beep.SampleRate, frequency float64, duration time.Duration, volume float64) beep.StreamSeeker {
+	audio := ToneAudio(ToneParams{
+		SampleRate: sampleRate,
+		Waveform:   WaveformSine,
+		Frequency:  frequency,
+		Duration:   duration,
+		Volume:     volume,
+	})
+	return NewPCMStream(audio.Data, sampleRate)
+}
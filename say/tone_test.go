@@ -0,0 +1,81 @@
+package say
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// toneSamples decodes a generated tone's PCM to -1..1 floats
+func toneSamples(audio *Audio) []float64 {
+	samples := make([]float64, len(audio.Data)/2)
+	for i := range samples {
+		samples[i] = float64(int16(audio.Data[2*i])|int16(audio.Data[2*i+1])<<8) / math.MaxInt16
+	}
+	return samples
+}
+
+func TestToneAudio(t *testing.T) {
+	for _, waveform := range []Waveform{WaveformSine, WaveformSquare, WaveformSaw} {
+		t.Run(string(waveform), func(t *testing.T) {
+			params := ToneParams{SampleRate: 48000, Waveform: waveform, Frequency: 1000, Duration: 100 * time.Millisecond, Volume: 0.5}
+			require.NoError(t, params.Validate())
+			audio := ToneAudio(params)
+			assert.Equal(t, EncodingPCM, audio.Encoding)
+			samples := toneSamples(audio)
+			require.Len(t, samples, 4800)
+
+			// Faded in and out, and never louder than the volume
+			assert.Zero(t, samples[0])
+			assert.Zero(t, samples[len(samples)-1])
+			peak := 0.0
+			crossings := 0
+			for i, s := range samples {
+				peak = max(peak, math.Abs(s))
+				if i > 0 && samples[i-1] < 0 && s >= 0 {
+					crossings++
+				}
+			}
+			assert.InDelta(t, 0.5, peak, 0.05)
+			// One upward zero crossing per cycle, 100 cycles in 100ms at 1kHz
+			assert.InDelta(t, 100, crossings, 2)
+		})
+	}
+}
+
+func TestToneWaveformShapes(t *testing.T) {
+	// Far from the band-limiting corrections, the shapes are the ideal ones
+	dt := 0.001
+	assert.InDelta(t, 1, WaveformSine.sample(0.25, dt), 1e-9)
+	assert.InDelta(t, 1, WaveformSquare.sample(0.25, dt), 1e-9)
+	assert.InDelta(t, -1, WaveformSquare.sample(0.75, dt), 1e-9)
+	assert.InDelta(t, 0, WaveformSaw.sample(0.5, dt), 1e-9)
+	assert.InDelta(t, -0.5, WaveformSaw.sample(0.25, dt), 1e-9)
+}
+
+func TestToneParamsValidate(t *testing.T) {
+	valid := ToneParams{SampleRate: 24000, Waveform: WaveformSine, Frequency: 440, Duration: time.Second, Volume: 1}
+	require.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(*ToneParams)
+		errMsg string
+	}{
+		{"waveform", func(p *ToneParams) { p.Waveform = "noise" }, "waveform must be"},
+		{"too low", func(p *ToneParams) { p.Frequency = 10 }, "frequency must be between"},
+		{"above nyquist", func(p *ToneParams) { p.Frequency = 15000 }, "below 12000 Hz"},
+		{"too short", func(p *ToneParams) { p.Duration = time.Millisecond }, "duration must be between"},
+		{"negative volume", func(p *ToneParams) { p.Volume = -0.1 }, "volume must be between"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid
+			tt.modify(&p)
+			assert.ErrorContains(t, p.Validate(), tt.errMsg)
+		})
+	}
+}
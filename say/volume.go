@@ -0,0 +1,57 @@
+package say
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/gopxl/beep/v2/effects"
+	"github.com/gopxl/beep/v2/speaker"
+)
+
+// MaxMasterVolume is the highest master volume, the level streams are played at
+const MaxMasterVolume = 1.0
+
+// Every SpeakerPlayer stream plays through masterMixer, and masterVolume scales the mix,
+// so changing the master volume reaches streams that are already playing.
+// Both are guarded by the speaker lock.
+var (
+	masterLevel  = MaxMasterVolume
+	masterMixer  beep.Mixer
+	masterVolume = &effects.Volume{Streamer: &masterMixer, Base: 2}
+)
+
+// startMaster plays the master mix through the speaker, once it is initialized
+func startMaster() {
+	speaker.Play(masterVolume)
+}
+
+// playMaster adds a stream to the master mix
+func playMaster(s beep.Streamer) {
+	speaker.Lock()
+	masterMixer.Add(s)
+	speaker.Unlock()
+}
+
+// SetMasterVolume sets the linear volume, from 0 to MaxMasterVolume, applied to everything
+// the speaker plays, including streams already playing. It lasts until the process exits.
+func SetMasterVolume(level float64) error {
+	if math.IsNaN(level) || level < 0 || level > MaxMasterVolume {
+		return fmt.Errorf("master volume must be from 0 to %g", MaxMasterVolume)
+	}
+	speaker.Lock()
+	defer speaker.Unlock()
+	masterLevel = level
+	masterVolume.Silent = level == 0
+	if level > 0 {
+		masterVolume.Volume = math.Log2(level)
+	}
+	return nil
+}
+
+// MasterVolume returns the master volume set with SetMasterVolume, MaxMasterVolume by default
+func MasterVolume() float64 {
+	speaker.Lock()
+	defer speaker.Unlock()
+	return masterLevel
+}
@@ -0,0 +1,60 @@
+package say
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/texttospeech/apiv1/texttospeechpb"
+	"google.golang.org/grpc/metadata"
+)
+
+// Warmer is a Provider that can open its connection ahead of the first request.
+// Warmup makes a free, authenticated call, so it also checks the credentials.
+type Warmer interface {
+	Provider
+	Warmup(ctx context.Context) error
+}
+
+// Warmup implements Warmer by looking up the default TTS model
+func (p *OpenAI) Warmup(ctx context.Context) error {
+	client, err := OpenAIAccount{APIKey: p.APIKey, Organization: p.Organization, Project: p.Project}.client()
+	if err != nil {
+		return err
+	}
+	if _, err := client.Models.Get(ctx, DefaultOpenAIModel); err != nil {
+		return fmt.Errorf("failed to reach OpenAI: %v", err)
+	}
+	return nil
+}
+
+// Warmup implements Warmer by looking up the default TTS model
+func (p *Google) Warmup(ctx context.Context) error {
+	apiKey := cmp.Or(p.APIKey, googleAPIKey())
+	if apiKey == "" {
+		return fmt.Errorf("GOOGLE_AI_API_KEY or GEMINI_API_KEY is not set")
+	}
+	client, err := googleClient(ctx, apiKey)
+	if err != nil {
+		return err
+	}
+	if _, err := client.Models.Get(ctx, DefaultGoogleModel, nil); err != nil {
+		return fmt.Errorf("failed to reach Gemini: %v", err)
+	}
+	return nil
+}
+
+// Warmup implements Warmer by dialing the shared gRPC client and listing the en-US voices
+func (p *GoogleCloud) Warmup(ctx context.Context) error {
+	client, err := sharedGoogleCloudClient()
+	if err != nil {
+		return err
+	}
+	for name, values := range providerHeaders(ProviderGoogle) {
+		ctx = metadata.AppendToOutgoingContext(ctx, name, values[0])
+	}
+	if _, err := client.ListVoices(ctx, &texttospeechpb.ListVoicesRequest{LanguageCode: googleCloudDefaultLanguage}); err != nil {
+		return fmt.Errorf("failed to reach Cloud Text-to-Speech: %v", err)
+	}
+	return nil
+}
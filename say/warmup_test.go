@@ -0,0 +1,33 @@
+package say
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIWarmup(t *testing.T) {
+	var paths []string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"id": "gpt-4o-mini-tts", "object": "model"}`))
+	}))
+	defer server.Close()
+	t.Setenv("OPENAI_BASE_URL", server.URL)
+
+	var provider Provider = &OpenAI{APIKey: "sk-test"}
+	warmer, ok := provider.(Warmer)
+	require.True(t, ok)
+	require.NoError(t, warmer.Warmup(context.Background()))
+	assert.Equal(t, []string{"GET /models/" + DefaultOpenAIModel}, paths)
+
+	status = http.StatusUnauthorized
+	assert.ErrorContains(t, warmer.Warmup(context.Background()), "failed to reach OpenAI")
+}